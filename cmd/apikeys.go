@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ezhdanovskiy/wallets/internal/application"
+	"github.com/ezhdanovskiy/wallets/internal/audit"
+	"github.com/ezhdanovskiy/wallets/internal/config"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+const apiKeyUsage = `Usage:
+  wallets api-key create -name NAME -scopes read,deposit,transfer,admin [-wallets W1,W2] [-owners O1,O2]
+  wallets api-key list
+  wallets api-key revoke NAME`
+
+// runAPIKeyCommand manages API keys directly in the database, so it works even when no admin key exists yet.
+func runAPIKeyCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(apiKeyUsage)
+	}
+
+	app, err := application.NewApplication(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = app.Close() }()
+	svc := app.Service()
+	ctx := audit.NewContext(context.Background(), audit.Source{Endpoint: "cli api-key " + args[0]})
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("api-key create", flag.ExitOnError)
+		name := fs.String("name", "", "unique key name")
+		scopes := fs.String("scopes", "", "comma separated scopes: read, deposit, transfer, admin")
+		wallets := fs.String("wallets", "", "comma separated wallets allowed for the key, * allows all")
+		owners := fs.String("owners", "", "comma separated owners whose wallets are allowed for the key")
+		_ = fs.Parse(args[1:])
+
+		key, err := svc.CreateAPIKey(ctx, dto.CreateAPIKeyRequest{
+			Name:    *name,
+			Scopes:  splitList(*scopes),
+			Wallets: splitList(*wallets),
+			Owners:  splitList(*owners),
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("API key %q created, store it now, it can't be shown again:\n%s\n", key.Name, key.Key)
+
+	case "list":
+		keys, err := svc.ListAPIKeys(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSCOPES\tWALLETS\tOWNERS\tCREATED\tREVOKED")
+		for _, k := range keys {
+			revoked := ""
+			if k.RevokedAt != nil {
+				revoked = k.RevokedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", k.Name, strings.Join(k.Scopes, ","),
+				strings.Join(k.Wallets, ","), strings.Join(k.Owners, ","), k.CreatedAt.Format("2006-01-02 15:04:05"), revoked)
+		}
+		return w.Flush()
+
+	case "revoke":
+		if len(args) != 2 {
+			return errors.New(apiKeyUsage)
+		}
+		if err := svc.RevokeAPIKey(ctx, args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("API key %q revoked\n", args[1])
+
+	default:
+		return errors.New(apiKeyUsage)
+	}
+
+	return nil
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/config"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+const balanceUsage = `Usage:
+  wallets balance show -wallet NAME [-at TIME] [-tz ZONE]
+  wallets balance daily -wallet NAME [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-tz ZONE]
+  wallets balance snapshot [-day YYYY-MM-DD]`
+
+// runBalanceCommand shows historical balances of a wallet or stores balance snapshots of all wallets,
+// like the server does every BALANCE_SNAPSHOT_INTERVAL.
+func runBalanceCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(balanceUsage)
+	}
+	switch args[0] {
+	case "show":
+		return runBalanceShowCommand(cfg, args[1:])
+	case "daily":
+		return runBalanceDailyCommand(cfg, args[1:])
+	case "snapshot":
+		return runBalanceSnapshotCommand(cfg, args[1:])
+	}
+	return errors.New(balanceUsage)
+}
+
+func runBalanceShowCommand(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("balance show", flag.ExitOnError)
+	wallet := fs.String("wallet", "", "wallet name")
+	at := fs.String("at", "", "unix seconds, RFC 3339 timestamp or YYYY-MM-DD for the end of the day, now by default")
+	tz := fs.String("tz", "UTC", "time zone of dates")
+	_ = fs.Parse(args)
+	if fs.NArg() != 0 || *wallet == "" {
+		return errors.New(balanceUsage)
+	}
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return fmt.Errorf("parse tz: %w", err)
+	}
+	var t time.Time
+	if *at != "" {
+		if t, err = dto.ParseOperationsTime(*at, loc, true); err != nil {
+			return fmt.Errorf("parse at: %w", err)
+		}
+	}
+
+	return withService(cfg, "balance show", func(c cli) error {
+		balance, err := c.svc.GetBalance(c.ctx, *wallet, t)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Balance of %q at %s: %.2f\n", balance.Wallet, balance.At.In(loc).Format(time.RFC3339), balance.Balance)
+		return nil
+	})
+}
+
+func runBalanceDailyCommand(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("balance daily", flag.ExitOnError)
+	wallet := fs.String("wallet", "", "wallet name")
+	from := fs.String("from", "", "first day, 30 days until the last one by default")
+	to := fs.String("to", "", "last day, today by default")
+	tz := fs.String("tz", "UTC", "time zone of days")
+	_ = fs.Parse(args)
+	if fs.NArg() != 0 || *wallet == "" {
+		return errors.New(balanceUsage)
+	}
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return fmt.Errorf("parse tz: %w", err)
+	}
+	filter := dto.DailyBalancesFilter{Wallet: *wallet, Location: loc}
+	for _, p := range []struct {
+		name, value string
+		date        *time.Time
+	}{
+		{"from", *from, &filter.StartDate},
+		{"to", *to, &filter.EndDate},
+	} {
+		if p.value != "" {
+			if *p.date, err = time.ParseInLocation(time.DateOnly, p.value, loc); err != nil {
+				return fmt.Errorf("parse %s: %w", p.name, err)
+			}
+		}
+	}
+
+	return withService(cfg, "balance daily", func(c cli) error {
+		series, err := c.svc.GetDailyBalances(c.ctx, filter)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "DATE\tBALANCE")
+		for _, day := range series {
+			fmt.Fprintf(w, "%s\t%.2f\n", day.Date, day.Balance)
+		}
+		return w.Flush()
+	})
+}
+
+func runBalanceSnapshotCommand(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("balance snapshot", flag.ExitOnError)
+	day := fs.String("day", "", "UTC day to take balances at the end of, the last ended day by default")
+	_ = fs.Parse(args)
+	if fs.NArg() != 0 {
+		return errors.New(balanceUsage)
+	}
+	at := dto.BalanceSnapshotTime(time.Now())
+	if *day != "" {
+		d, err := time.Parse(time.DateOnly, *day)
+		if err != nil {
+			return fmt.Errorf("parse day: %w", err)
+		}
+		at = d.AddDate(0, 0, 1)
+	}
+
+	return withService(cfg, "balance snapshot", func(c cli) error {
+		n, err := c.svc.TakeBalanceSnapshots(c.ctx, at)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Took %d balance snapshots at %s\n", n, at.Format(time.RFC3339))
+		return nil
+	})
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+
+	"github.com/ezhdanovskiy/wallets/internal/application"
+	"github.com/ezhdanovskiy/wallets/internal/audit"
+	"github.com/ezhdanovskiy/wallets/internal/config"
+	"github.com/ezhdanovskiy/wallets/internal/service"
+)
+
+// cli is the environment of a command working with the service.
+type cli struct {
+	ctx context.Context
+	svc *service.Service
+}
+
+// withService runs f with the service connected to the configured database.
+// Changes are recorded in the audit log with "cli <command>" endpoint.
+func withService(cfg *config.Config, command string, f func(c cli) error) error {
+	app, err := application.NewApplication(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = app.Close() }()
+
+	ctx := audit.NewContext(context.Background(), audit.Source{Endpoint: "cli " + command})
+	return f(cli{ctx: ctx, svc: app.Service()})
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/ezhdanovskiy/wallets/internal/config"
+)
+
+const configUsage = `Usage:
+  wallets [-config FILE] config print`
+
+// runConfigCommand prints the effective config in YAML, it can be used as a config file after filling in secrets.
+func runConfigCommand(cfg *config.Config, args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return errors.New(configUsage)
+	}
+
+	data, err := yaml.Marshal(cfg.Settings())
+	if err != nil {
+		return fmt.Errorf("marshal config: %w", err)
+	}
+	fmt.Print(string(data))
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ezhdanovskiy/wallets/internal/config"
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/export"
+	"github.com/ezhdanovskiy/wallets/internal/imports"
+)
+
+const importUsage = `Usage:
+  wallets import -kind deposits|transfers [-dry-run] [-chunk-size N] [-delimiter CHAR] [-result FILE] FILE`
+
+// runImportCommand validates the CSV file and applies it like POST /v1/imports, the import is applied
+// by the command instead of waiting for a server. Errors of the file are printed by lines.
+func runImportCommand(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	kind := fs.String("kind", "", "kind of the file: deposits or transfers")
+	dryRun := fs.Bool("dry-run", false, "only validate the file")
+	chunkSize := fs.Int("chunk-size", consts.ImportChunkSizeDefault, "number of rows applied in a transaction")
+	delimiter := fs.String("delimiter", ",", "csv field delimiter, a character or tab")
+	result := fs.String("result", "", "file for the statuses of the rows in CSV")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 || *kind == "" {
+		return errors.New(importUsage)
+	}
+	comma, err := export.ParseDelimiter(*delimiter)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return withService(cfg, "import", func(c cli) error {
+		created, err := c.svc.CreateImport(c.ctx, dto.ImportRequest{
+			Kind:      *kind,
+			File:      data,
+			Delimiter: comma,
+			DryRun:    *dryRun,
+			ChunkSize: *chunkSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		report := created.Report
+		for _, e := range report.Errors {
+			fmt.Fprintf(os.Stderr, "line %d: %s\n", e.Line, e.Error)
+		}
+		if report.Truncated {
+			fmt.Fprintf(os.Stderr, "only the first %d errors are listed\n", len(report.Errors))
+		}
+		if len(report.Errors) > 0 {
+			return errors.New("import file has errors, nothing is applied")
+		}
+		if created.Job == nil {
+			fmt.Printf("File is valid, %d rows\n", report.Rows)
+			return nil
+		}
+
+		// Other pending imports are applied too, the server may be stopped.
+		if _, err := c.svc.ProcessImportJobs(c.ctx); err != nil {
+			return err
+		}
+		job, rows, err := c.svc.GetImportResult(c.ctx, created.Job.ID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Import %d %s: %d rows, %d applied, %d skipped, %d failed\n",
+			job.ID, job.Status, job.Rows, job.Applied, job.Skipped, job.Failed)
+
+		if *result == "" {
+			return nil
+		}
+		return writeImportResult(*result, job.Kind, rows)
+	})
+}
+
+func writeImportResult(name, kind string, rows []dto.ImportRow) (err error) {
+	out, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	return imports.WriteResult(out, kind, rows)
+}
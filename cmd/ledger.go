@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ezhdanovskiy/wallets/internal/application"
+	"github.com/ezhdanovskiy/wallets/internal/config"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/ledger"
+)
+
+const verifyLedgerUsage = `Usage:
+  wallets verify-ledger [-checkpoint FILE [-public-key FILE]]`
+
+// errLedgerBroken makes the command exit with non-zero code, the details are already printed.
+var errLedgerBroken = errors.New("ledger verification failed")
+
+// runVerifyLedgerCommand walks the operations hash chain and prints the first broken link.
+// An archived checkpoint can be passed to detect operations removed after it was created.
+func runVerifyLedgerCommand(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("verify-ledger", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprintln(fs.Output(), verifyLedgerUsage); fs.PrintDefaults() }
+	checkpointFile := fs.String("checkpoint", "", "archived checkpoint JSON, the latest stored checkpoint is used by default")
+	publicKeyFile := fs.String("public-key", "", "PEM encoded Ed25519 public key verifying the archived checkpoint")
+	_ = fs.Parse(args)
+
+	var checkpoint *dto.LedgerCheckpoint
+	if *checkpointFile != "" {
+		data, err := os.ReadFile(*checkpointFile)
+		if err != nil {
+			return fmt.Errorf("read checkpoint: %w", err)
+		}
+		checkpoint = &dto.LedgerCheckpoint{}
+		if err := json.Unmarshal(data, checkpoint); err != nil {
+			return fmt.Errorf("parse checkpoint: %w", err)
+		}
+
+		if *publicKeyFile != "" {
+			publicKey, err := ledger.LoadPublicKey(*publicKeyFile)
+			if err != nil {
+				return err
+			}
+			if err := ledger.VerifyCheckpoint(publicKey, checkpoint); err != nil {
+				return fmt.Errorf("checkpoint %s: %w", *checkpointFile, err)
+			}
+		}
+	}
+
+	app, err := application.NewApplication(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = app.Close() }()
+
+	report, err := app.Service().VerifyLedger(context.Background(), checkpoint)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Verified %d operations of %d wallets", report.Operations, report.Wallets)
+	if report.CheckpointID != 0 {
+		fmt.Printf(" against checkpoint %d", report.CheckpointID)
+	}
+	fmt.Println()
+
+	if report.CheckpointError != "" {
+		fmt.Printf("Checkpoint is invalid: %s\n", report.CheckpointError)
+	}
+	if report.Broken != nil {
+		fmt.Printf("Broken link: wallet %q seq %d operation %d: %s\n",
+			report.Broken.Wallet, report.Broken.Seq, report.Broken.OperationID, report.Broken.Reason)
+	}
+	if !report.OK {
+		return errLedgerBroken
+	}
+
+	fmt.Println("Ledger is intact")
+	return nil
+}
@@ -1,30 +1,108 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata" // time zones of operations filters, the image has no zoneinfo
 
 	"github.com/ezhdanovskiy/wallets/internal/application"
+	"github.com/ezhdanovskiy/wallets/internal/config"
 )
 
+const usage = `Usage:
+  wallets [-config FILE] [COMMAND]
+
+Commands:
+  serve              run HTTP and gRPC servers, the default command
+  migrate            apply, roll back or force database migrations, show their status
+  wallet             create, show or list wallets
+  deposit            deposit money to a wallet
+  transfer           transfer money between wallets
+  operations export  export operations of a wallet in CSV or JSON
+  import             import deposits or transfers from a CSV file
+  statements         show a monthly statement of a wallet or store statements of all wallets
+  balance            show historical balances of a wallet or store balance snapshots of all wallets
+  api-key            manage API keys
+  verify-ledger      verify the operations hash chain
+  config print       print the effective config with secrets masked
+
+Commands changing data go through the same validation as the API and are recorded in the audit log.
+The config file may also be set by CONFIG_FILE environment variable.
+
+Flags:`
+
 func main() {
-	app, err := application.NewApplication()
+	fs := flag.NewFlagSet("wallets", flag.ExitOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path to YAML, TOML or JSON config file")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(os.Args[1:])
+	args := fs.Args()
+
+	cfg, err := config.NewConfig(*configFile)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	go shutdownMonitor(app)
+	command := "serve"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	if err := runCommand(cfg, command, args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func runCommand(cfg *config.Config, command string, args []string) error {
+	switch command {
+	case "serve":
+		return runServeCommand(cfg)
+	case "migrate":
+		return runMigrateCommand(cfg, args)
+	case "wallet":
+		return runWalletCommand(cfg, args)
+	case "deposit":
+		return runDepositCommand(cfg, args)
+	case "transfer":
+		return runTransferCommand(cfg, args)
+	case "operations":
+		return runOperationsCommand(cfg, args)
+	case "import":
+		return runImportCommand(cfg, args)
+	case "statements":
+		return runStatementsCommand(cfg, args)
+	case "balance":
+		return runBalanceCommand(cfg, args)
+	case "api-key":
+		return runAPIKeyCommand(cfg, args)
+	case "verify-ledger":
+		return runVerifyLedgerCommand(cfg, args)
+	case "config":
+		return runConfigCommand(cfg, args)
+	}
+	return fmt.Errorf("unknown command %q\n%s", command, usage)
+}
 
-	err = app.Run()
+// runServeCommand runs the servers until SIGINT or SIGTERM.
+func runServeCommand(cfg *config.Config) error {
+	app, err := application.NewApplication(cfg)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+
+	go shutdownMonitor(app)
+
+	return app.Run()
 }
 
 func shutdownMonitor(app *application.Application) {
-	stopping := make(chan os.Signal)
+	stopping := make(chan os.Signal, 1)
 	signal.Notify(stopping, os.Interrupt, syscall.SIGTERM)
 	<-stopping
 
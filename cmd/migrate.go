@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/ezhdanovskiy/wallets/internal/application"
+	"github.com/ezhdanovskiy/wallets/internal/config"
+	"github.com/ezhdanovskiy/wallets/internal/repository"
+	"github.com/ezhdanovskiy/wallets/internal/repository/sqlite"
+)
+
+const migrateUsage = `Usage:
+  wallets migrate up
+  wallets migrate down [N]     roll back the last N migrations, 1 by default
+  wallets migrate status
+  wallets migrate force VERSION  set the version and clear the dirty flag after fixing a failed migration`
+
+// runMigrateCommand manages the database schema with the migrations from MIGRATIONS_SOURCE.
+// Changes wait for the migration lock at most MIGRATE_LOCK_TIMEOUT.
+func runMigrateCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	log, err := application.NewLogger(cfg.LogLevel, cfg.LogEncoding)
+	if err != nil {
+		return fmt.Errorf("new logger: %w", err)
+	}
+	switch cfg.Storage {
+	case config.StorageSQLite:
+		return runSQLiteMigrateCommand(log, cfg, args)
+	case config.StorageMemory:
+		return fmt.Errorf("storage %s has no schema to migrate", cfg.Storage)
+	}
+	migrator, err := repository.ConnectMigrator(log, cfg.DB)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = migrator.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DB.MigrateLockTimeout)
+	defer cancel()
+
+	switch args[0] {
+	case "up":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+		if err := migrator.Up(ctx); err != nil {
+			return err
+		}
+
+	case "down":
+		steps := 1
+		if len(args) == 2 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of migrations %q\n%s", args[1], migrateUsage)
+			}
+		} else if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+		if err := migrator.Down(ctx, steps); err != nil {
+			return err
+		}
+
+	case "force":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q\n%s", args[1], migrateUsage)
+		}
+		if err := migrator.Force(ctx, version); err != nil {
+			return err
+		}
+
+	case "status":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("version: %d\ndirty: %t\nlatest: %d\n", status.Version, status.Dirty, status.Latest)
+	return nil
+}
+
+// runSQLiteMigrateCommand manages the schema of the SQLite database with the embedded migrations.
+// They are transactional, so the schema is never dirty and there is nothing to force.
+func runSQLiteMigrateCommand(log *zap.SugaredLogger, cfg *config.Config, args []string) error {
+	repo, err := sqlite.Open(log, cfg.SQLite)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = repo.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DB.MigrateLockTimeout)
+	defer cancel()
+
+	switch args[0] {
+	case "up":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+		if err := repo.MigrateUp(ctx); err != nil {
+			return err
+		}
+
+	case "down":
+		steps := 1
+		if len(args) == 2 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of migrations %q\n%s", args[1], migrateUsage)
+			}
+		} else if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+		if err := repo.MigrateDown(ctx, steps); err != nil {
+			return err
+		}
+
+	case "force":
+		return fmt.Errorf("storage %s doesn't need force, failed migrations are rolled back", cfg.Storage)
+
+	case "status":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	status, err := repo.MigrationStatus(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("version: %d\ndirty: %t\nlatest: %d\n", status.Version, status.Dirty, status.Latest)
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/config"
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/export"
+)
+
+const operationsUsage = `Usage:
+  wallets operations export -wallet NAME [-type deposit,withdrawal] [-other-wallet NAME]
+    [-min-amount AMOUNT] [-max-amount AMOUNT] [-from DATE] [-to DATE] [-tz ZONE] [-order asc|desc]
+    [-format csv|ndjson|xlsx|camt053|json] [-delimiter CHAR] [-bom] [-output FILE]`
+
+// runOperationsCommand exports all operations of the wallet, formats other than json are streamed from the database.
+func runOperationsCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "export" {
+		return errors.New(operationsUsage)
+	}
+
+	fs := flag.NewFlagSet("operations export", flag.ExitOnError)
+	wallet := fs.String("wallet", "", "wallet name")
+	opTypes := fs.String("type", "", "comma separated operation types: deposit, withdrawal")
+	otherWallet := fs.String("other-wallet", "", "counterparty wallet name")
+	minAmount := fs.Float64("min-amount", 0, "minimum amount")
+	maxAmount := fs.Float64("max-amount", 0, "maximum amount")
+	from := fs.String("from", "", "start date, YYYY-MM-DD, RFC3339 or unix seconds")
+	to := fs.String("to", "", "end date inclusive, YYYY-MM-DD, RFC3339 or unix seconds")
+	tz := fs.String("tz", "UTC", "time zone of dates without time")
+	order := fs.String("order", consts.OperationsOrderAsc, "order by creation time: asc or desc")
+	format := fs.String("format", export.CSV.Name, "output format: "+strings.Join(export.Names(), ", ")+" or json")
+	delimiter := fs.String("delimiter", ",", "csv field delimiter, a character or tab")
+	bom := fs.Bool("bom", false, "start csv with the UTF-8 byte order mark")
+	output := fs.String("output", "", "output file, stdout by default")
+	_ = fs.Parse(args[1:])
+	if fs.NArg() != 0 {
+		return errors.New(operationsUsage)
+	}
+	exportFormat, ok := export.Lookup(*format)
+	if !ok && *format != "json" {
+		return errors.New(operationsUsage)
+	}
+	comma, err := export.ParseDelimiter(*delimiter)
+	if err != nil {
+		return err
+	}
+	opts := export.Options{Delimiter: comma, BOM: *bom}
+
+	filter := dto.OperationsFilter{
+		Wallet:      *wallet,
+		OtherWallet: *otherWallet,
+		MinAmount:   dto.Amount(*minAmount),
+		MaxAmount:   dto.Amount(*maxAmount),
+		Order:       *order,
+	}
+	if *opTypes != "" {
+		filter.Types = strings.Split(*opTypes, ",")
+	}
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return fmt.Errorf("invalid time zone %q: %w", *tz, err)
+	}
+	if filter.StartDate, err = parseDate(*from, loc, false); err != nil {
+		return err
+	}
+	if filter.EndDate, err = parseDate(*to, loc, true); err != nil {
+		return err
+	}
+
+	return withService(cfg, "operations export", func(c cli) (err error) {
+		out := os.Stdout
+		if *output != "" {
+			if out, err = os.Create(*output); err != nil {
+				return err
+			}
+			defer func() {
+				if closeErr := out.Close(); err == nil {
+					err = closeErr
+				}
+			}()
+		}
+
+		if *format == "json" {
+			operations := make([]dto.Operation, 0)
+			err := c.svc.ScanOperations(c.ctx, filter, func(op dto.Operation) error {
+				operations = append(operations, op)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			data, err := json.MarshalIndent(operations, "", "  ")
+			if err != nil {
+				return fmt.Errorf("convert operations: %w", err)
+			}
+			_, err = out.Write(append(data, '\n'))
+			return err
+		}
+
+		if exportFormat.Summary {
+			if filter.StartDate.IsZero() {
+				return fmt.Errorf("-from is required by format %s", exportFormat.Name)
+			}
+			if filter.EndDate.IsZero() {
+				filter.EndDate = time.Now().UTC().Truncate(time.Microsecond)
+			}
+			if opts.Summary, err = c.svc.GetOperationsSummary(c.ctx, filter); err != nil {
+				return err
+			}
+		}
+
+		enc, err := exportFormat.New(out, opts)
+		if err != nil {
+			return err
+		}
+		if err := c.svc.ScanOperations(c.ctx, filter, enc.Encode); err != nil {
+			return err
+		}
+		return enc.Close()
+	})
+}
+
+// parseDate converts the date in loc to time, empty date is the zero time that disables the filter.
+func parseDate(s string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return dto.ParseOperationsTime(s, loc, endOfDay)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/config"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/statements"
+)
+
+const statementsUsage = `Usage:
+  wallets statements get -wallet NAME -period YYYY-MM [-format csv|json|html] [-output FILE]
+  wallets statements generate [-period YYYY-MM]`
+
+// runStatementsCommand writes the statement of a wallet or stores the statements of all wallets for a past month,
+// like the server does every STATEMENTS_INTERVAL.
+func runStatementsCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(statementsUsage)
+	}
+	switch args[0] {
+	case "get":
+		return runStatementsGetCommand(cfg, args[1:])
+	case "generate":
+		return runStatementsGenerateCommand(cfg, args[1:])
+	}
+	return errors.New(statementsUsage)
+}
+
+func runStatementsGetCommand(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("statements get", flag.ExitOnError)
+	wallet := fs.String("wallet", "", "wallet name")
+	period := fs.String("period", "", "month of the statement, YYYY-MM")
+	format := fs.String("format", statements.CSV.Name, "output format: "+strings.Join(statements.Names(), ", "))
+	output := fs.String("output", "", "output file, stdout by default")
+	_ = fs.Parse(args)
+	statementFormat, ok := statements.Lookup(*format)
+	if fs.NArg() != 0 || *wallet == "" || *period == "" || !ok {
+		return errors.New(statementsUsage)
+	}
+
+	return withService(cfg, "statements get", func(c cli) (err error) {
+		st, err := c.svc.GetStatement(c.ctx, *wallet, *period)
+		if err != nil {
+			return err
+		}
+
+		out := os.Stdout
+		if *output != "" {
+			if out, err = os.Create(*output); err != nil {
+				return err
+			}
+			defer func() {
+				if closeErr := out.Close(); err == nil {
+					err = closeErr
+				}
+			}()
+		}
+		return statementFormat.Write(out, st)
+	})
+}
+
+func runStatementsGenerateCommand(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("statements generate", flag.ExitOnError)
+	period := fs.String("period", dto.LastSettledStatementPeriod(time.Now()), "month ended at least an hour ago, YYYY-MM")
+	_ = fs.Parse(args)
+	if fs.NArg() != 0 {
+		return errors.New(statementsUsage)
+	}
+
+	return withService(cfg, "statements generate", func(c cli) error {
+		n, err := c.svc.GenerateStatements(c.ctx, *period)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Generated %d statements for %s\n", n, *period)
+		return nil
+	})
+}
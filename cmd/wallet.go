@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/ezhdanovskiy/wallets/internal/config"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+const walletUsage = `Usage:
+  wallets wallet create [-owner OWNER] NAME
+  wallets wallet show NAME
+  wallets wallet list [-owner OWNER] [-limit N] [-offset N]`
+
+const moneyUsage = `Usage:
+  wallets deposit -wallet NAME -amount AMOUNT
+  wallets transfer -from NAME -to NAME -amount AMOUNT`
+
+// runWalletCommand manages wallets through the service, so the same validation as in the API is applied.
+func runWalletCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(walletUsage)
+	}
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("wallet create", flag.ExitOnError)
+		owner := fs.String("owner", "", "owner of the wallet")
+		_ = fs.Parse(args[1:])
+		if fs.NArg() != 1 {
+			return errors.New(walletUsage)
+		}
+
+		return withService(cfg, "wallet create", func(c cli) error {
+			err := c.svc.CreateWallet(c.ctx, dto.CreateWalletRequest{Name: fs.Arg(0), Owner: *owner})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Wallet %q created\n", fs.Arg(0))
+			return nil
+		})
+
+	case "show":
+		if len(args) != 2 {
+			return errors.New(walletUsage)
+		}
+
+		return withService(cfg, "wallet show", func(c cli) error {
+			wallet, err := c.svc.GetWallet(c.ctx, args[1])
+			if err != nil {
+				return err
+			}
+			return printWallets([]dto.Wallet{*wallet})
+		})
+
+	case "list":
+		fs := flag.NewFlagSet("wallet list", flag.ExitOnError)
+		owner := fs.String("owner", "", "list only wallets of the owner")
+		limit := fs.Int64("limit", 0, "maximum number of wallets, 100 by default")
+		offset := fs.Int64("offset", 0, "number of wallets to skip")
+		_ = fs.Parse(args[1:])
+
+		return withService(cfg, "wallet list", func(c cli) error {
+			wallets, err := c.svc.ListWallets(c.ctx, dto.WalletsFilter{Owner: *owner, Limit: *limit, Offset: *offset})
+			if err != nil {
+				return err
+			}
+			return printWallets(wallets)
+		})
+	}
+
+	return errors.New(walletUsage)
+}
+
+func printWallets(wallets []dto.Wallet) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tOWNER\tBALANCE")
+	for _, wallet := range wallets {
+		var balance dto.Amount
+		balance.SetAmount(wallet.Balance)
+		fmt.Fprintf(w, "%s\t%s\t%.2f\n", wallet.Name, wallet.Owner, balance)
+	}
+	return w.Flush()
+}
+
+// runDepositCommand deposits money to the wallet.
+func runDepositCommand(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("deposit", flag.ExitOnError)
+	wallet := fs.String("wallet", "", "wallet to deposit to")
+	amount := fs.String("amount", "", "amount, e.g. 10.50")
+	_ = fs.Parse(args)
+	if fs.NArg() != 0 {
+		return errors.New(moneyUsage)
+	}
+
+	a, err := parseAmount(*amount)
+	if err != nil {
+		return err
+	}
+
+	return withService(cfg, "deposit", func(c cli) error {
+		if err := c.svc.IncreaseWalletBalance(c.ctx, dto.Deposit{Wallet: *wallet, Amount: a}); err != nil {
+			return err
+		}
+		fmt.Printf("Deposited %.2f to %q\n", a, *wallet)
+		return nil
+	})
+}
+
+// runTransferCommand transfers money between wallets.
+func runTransferCommand(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("transfer", flag.ExitOnError)
+	from := fs.String("from", "", "wallet to debit")
+	to := fs.String("to", "", "wallet to credit")
+	amount := fs.String("amount", "", "amount, e.g. 10.50")
+	_ = fs.Parse(args)
+	if fs.NArg() != 0 {
+		return errors.New(moneyUsage)
+	}
+
+	a, err := parseAmount(*amount)
+	if err != nil {
+		return err
+	}
+
+	return withService(cfg, "transfer", func(c cli) error {
+		if err := c.svc.Transfer(c.ctx, dto.Transfer{WalletFrom: *from, WalletTo: *to, Amount: a}); err != nil {
+			return err
+		}
+		fmt.Printf("Transferred %.2f from %q to %q\n", a, *from, *to)
+		return nil
+	})
+}
+
+func parseAmount(s string) (dto.Amount, error) {
+	if s == "" {
+		return 0, errors.New(moneyUsage)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return dto.Amount(f), nil
+}
@@ -2,73 +2,265 @@
 package application
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/ezhdanovskiy/wallets/internal/auth"
 	"github.com/ezhdanovskiy/wallets/internal/config"
+	"github.com/ezhdanovskiy/wallets/internal/grpc"
+	"github.com/ezhdanovskiy/wallets/internal/health"
 	"github.com/ezhdanovskiy/wallets/internal/http"
-	"github.com/ezhdanovskiy/wallets/internal/repository"
+	"github.com/ezhdanovskiy/wallets/internal/ledger"
+	"github.com/ezhdanovskiy/wallets/internal/ratelimit"
 	"github.com/ezhdanovskiy/wallets/internal/service"
+	"github.com/ezhdanovskiy/wallets/internal/tracing"
 )
 
+// tracingShutdownTimeout limits the time of exporting the remaining spans on stop.
+const tracingShutdownTimeout = 5 * time.Second
+
 // Application contains all components of application.
 type Application struct {
-	log *zap.SugaredLogger
-	cfg *config.Config
-	svc *service.Service
+	log  *zap.SugaredLogger
+	cfg  *config.Config
+	svc  *service.Service
+	repo backend
+
+	// authenticator is shared by HTTP and gRPC servers, it is nil when authentication is disabled.
+	authenticator auth.Authenticator
+	// limiter limits HTTP requests, it is nil when no limits are configured.
+	limiter *ratelimit.Limiter
+	// checker reports readiness, it starts failing when the application is stopping.
+	checker *health.Checker
 
 	httpServer *http.Server
+	grpcServer *grpc.Server
+
+	// workersCtx is canceled to stop background workers, workers waits for them to return.
+	workersCtx  context.Context
+	stopWorkers context.CancelFunc
+	workers     sync.WaitGroup
+
+	stopOnce sync.Once
+	// shutdownTracing flushes spans that aren't exported yet.
+	shutdownTracing func(context.Context) error
 }
 
 // NewApplication creates and connects instances of all components required to run Application.
-func NewApplication() (*Application, error) {
-	cfg, err := config.NewConfig()
+func NewApplication(cfg *config.Config) (_ *Application, err error) {
+	log, err := NewLogger(cfg.LogLevel, cfg.LogEncoding)
 	if err != nil {
-		return nil, fmt.Errorf("new config: %w", err)
+		return nil, fmt.Errorf("new logger: %w", err)
+	}
+	if cfgJSON, err := json.Marshal(cfg.Settings()); err == nil {
+		log.Debugf("cfg: %s", cfgJSON)
 	}
 
-	log, err := newLogger(cfg.LogLevel, cfg.LogEncoding)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
 	if err != nil {
-		return nil, fmt.Errorf("new logger: %w", err)
+		return nil, fmt.Errorf("setup tracing: %w", err)
 	}
-	log.Debugf("cfg: %+v", cfg)
 
-	repo, err := repository.NewRepo(log, cfg.DB)
+	repo, migrationVersion, err := newBackend(log, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("new repo: %w", err)
+		return nil, err
 	}
+	// The database is closed by Stop or Close, there is nothing to call them on if the application isn't created.
+	defer func() {
+		if err != nil {
+			_ = repo.Close()
+		}
+	}()
 
-	svc := service.NewService(log, repo)
+	var signer *ledger.Signer
+	if cfg.Ledger.SigningKeyFile != "" {
+		signer, err = ledger.LoadSigner(cfg.Ledger.SigningKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load ledger signing key: %w", err)
+		}
+	}
+
+	svc := service.NewService(log, repo, signer, cfg.Currency)
+
+	app := &Application{
+		log:             log,
+		cfg:             cfg,
+		svc:             svc,
+		repo:            repo,
+		checker:         health.NewChecker(repo, migrationVersion),
+		shutdownTracing: shutdownTracing,
+	}
 
-	return &Application{
-		log: log,
-		cfg: cfg,
-		svc: svc,
-	}, nil
+	app.authenticator, err = newAuthenticator(cfg.Auth, repo)
+	if err != nil {
+		return nil, fmt.Errorf("new authenticator: %w", err)
+	}
+	if app.authenticator == nil {
+		log.Warn("Authentication is disabled")
+	}
+
+	app.limiter, err = newLimiter(log, cfg.RateLimit, repo)
+	if err != nil {
+		return nil, fmt.Errorf("new rate limiter: %w", err)
+	}
+
+	app.httpServer = http.NewServer(log, cfg.HttpPort, svc, app.authenticator, app.limiter, app.checker)
+	app.grpcServer = grpc.NewServer(log, cfg.GrpcPort, svc, app.authenticator)
+	app.workersCtx, app.stopWorkers = context.WithCancel(context.Background())
+
+	return app, nil
 }
 
-// Run runs configured components.
+// Service returns the service for running commands without the servers.
+func (a *Application) Service() *service.Service {
+	return a.svc
+}
+
+// Close closes the database, it is used instead of Stop when the servers weren't run, e.g. by CLI commands.
+func (a *Application) Close() error {
+	return a.repo.Close()
+}
+
+// Run runs configured components and waits until all of them are stopped.
+// If one of the components fails, the others are stopped too.
 func (a *Application) Run() error {
 	a.log.Info("Run application")
 
-	a.httpServer = http.NewServer(a.log, a.cfg.HttpPort, a.svc)
+	if a.cfg.Ledger.CheckpointInterval > 0 {
+		a.runWorker(a.runLedgerCheckpoints)
+	}
+	if a.cfg.Import.PollInterval > 0 {
+		a.runWorker(a.runImports)
+	}
+	if a.cfg.Statements.Interval > 0 {
+		a.runWorker(a.runStatements)
+	}
+	if a.cfg.Balance.SnapshotInterval > 0 {
+		a.runWorker(a.runBalanceSnapshots)
+	}
+
+	errs := make(chan error, 2)
 
-	a.log.Infof("Run HTTP server on port %v", a.cfg.HttpPort)
-	err := a.httpServer.Run()
-	if err != nil {
-		return fmt.Errorf("HTTP server run: %w", err)
+	go func() {
+		a.log.Infof("Run HTTP server on port %v", a.cfg.HttpPort)
+		if err := a.httpServer.Run(); err != nil {
+			errs <- fmt.Errorf("HTTP server run: %w", err)
+			return
+		}
+		a.log.Info("HTTP server stopped")
+		errs <- nil
+	}()
+
+	go func() {
+		a.log.Infof("Run gRPC server on port %v", a.cfg.GrpcPort)
+		if err := a.grpcServer.Run(); err != nil {
+			errs <- fmt.Errorf("gRPC server run: %w", err)
+			return
+		}
+		a.log.Info("gRPC server stopped")
+		errs <- nil
+	}()
+
+	var runErr error
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err != nil && runErr == nil {
+			runErr = err
+			a.Stop()
+		}
 	}
-	a.log.Info("HTTP server stopped")
 
-	a.log.Info("Application stopped")
-	return nil
+	// The servers stop when Stop is called, wait until it closes the remaining components.
+	a.Stop()
+
+	return runErr
 }
 
-// Stop terminates configured components.
+// runWorker runs f in background until Stop is called.
+func (a *Application) runWorker(f func(ctx context.Context)) {
+	a.workers.Add(1)
+	go func() {
+		defer a.workers.Done()
+		f(a.workersCtx)
+	}()
+}
+
+// Stop terminates configured components, it may be called several times and returns when all of them are stopped.
+// Readiness fails first, so load balancers stop sending new requests before the servers are stopped.
+// Then the servers wait for in-flight requests, background workers are stopped and the database is closed.
 func (a *Application) Stop() {
-	if a.httpServer != nil {
+	a.stopOnce.Do(a.stop)
+}
+
+func (a *Application) stop() {
+	a.log.With("timeout", a.cfg.Shutdown.Timeout).Info("Stopping application")
+	started := time.Now()
+
+	a.checker.Shutdown()
+	if a.cfg.Shutdown.DrainDelay > 0 {
+		a.log.Infof("Waiting %s for load balancers to drain", a.cfg.Shutdown.DrainDelay)
+		time.Sleep(a.cfg.Shutdown.DrainDelay)
+	}
+
+	// The timeout is the total budget of stopping the servers and the workers. The servers are stopped together,
+	// so slow requests to one of them don't leave the other without time, the workers get the rest.
+	ctx, cancel := context.WithTimeout(context.Background(), a.cfg.Shutdown.Timeout)
+	defer cancel()
+
+	var servers sync.WaitGroup
+	servers.Add(2)
+	go func() {
+		defer servers.Done()
 		a.log.Info("Stopping HTTP server")
-		a.httpServer.Shutdown()
+		if err := a.httpServer.Shutdown(ctx); err != nil {
+			a.log.With("error", err).Error("Failed to stop HTTP server gracefully")
+		}
+	}()
+	go func() {
+		defer servers.Done()
+		a.log.Info("Stopping gRPC server")
+		if err := a.grpcServer.Shutdown(ctx); err != nil {
+			a.log.With("error", err).Error("Failed to stop gRPC server gracefully")
+		}
+	}()
+	servers.Wait()
+
+	a.log.Info("Stopping background workers")
+	a.stopWorkers()
+	if err := wait(ctx, &a.workers); err != nil {
+		a.log.With("error", err).Error("Failed to wait for background workers")
+	}
+
+	a.log.Info("Closing database")
+	if err := a.repo.Close(); err != nil {
+		a.log.With("error", err).Error("Failed to close database")
+	}
+
+	tracingCtx, tracingCancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer tracingCancel()
+	if err := a.shutdownTracing(tracingCtx); err != nil {
+		a.log.With("error", err).Error("Failed to flush traces")
+	}
+
+	a.log.With("duration", time.Since(started)).Info("Application stopped")
+}
+
+// wait waits for wg until ctx is done.
+func wait(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
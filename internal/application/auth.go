@@ -0,0 +1,44 @@
+package application
+
+import (
+	"fmt"
+
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/config"
+)
+
+// newAuthenticator creates authenticator for the configured mode, it returns nil if authentication is disabled.
+func newAuthenticator(cfg config.Auth, keys auth.KeyStore) (auth.Authenticator, error) {
+	switch cfg.Mode {
+	case config.AuthModeNone:
+		return nil, nil
+	case config.AuthModeAPIKey:
+		return auth.NewAPIKeyAuthenticator(keys), nil
+	case config.AuthModeJWT:
+		return newJWTAuthenticator(cfg)
+	case config.AuthModeAPIKeyOrJWT:
+		jwtAuth, err := newJWTAuthenticator(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return auth.APIKeyOrJWT{
+			APIKeys: auth.NewAPIKeyAuthenticator(keys),
+			JWT:     jwtAuth,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported auth mode %q", cfg.Mode)
+}
+
+func newJWTAuthenticator(cfg config.Auth) (*auth.JWTAuthenticator, error) {
+	return auth.NewJWTAuthenticator(auth.JWTConfig{
+		JWKSFile:     cfg.JWKSFile,
+		JWKSURL:      cfg.JWKSURL,
+		JWKSRefresh:  cfg.JWKSRefresh,
+		Issuer:       cfg.JWTIssuer,
+		Audience:     cfg.JWTAudience,
+		Leeway:       cfg.JWTLeeway,
+		ScopesClaim:  cfg.JWTScopesClaim,
+		WalletsClaim: cfg.JWTWalletsClaim,
+		OwnersClaim:  cfg.JWTOwnersClaim,
+	})
+}
@@ -0,0 +1,38 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+// runBalanceSnapshots periodically stores the balances of all wallets at the end of the previous day
+// until ctx is canceled. A day is taken once by the instance, snapshots stored by other instances are kept.
+func (a *Application) runBalanceSnapshots(ctx context.Context) {
+	a.log.Infof("Run balance snapshots every %v", a.cfg.Balance.SnapshotInterval)
+
+	ticker := time.NewTicker(a.cfg.Balance.SnapshotInterval)
+	defer ticker.Stop()
+
+	var taken time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			a.log.Info("Balance snapshots stopped")
+			return
+		case <-ticker.C:
+			at := dto.BalanceSnapshotTime(time.Now())
+			if at.Equal(taken) {
+				continue
+			}
+			if _, err := a.svc.TakeBalanceSnapshots(ctx, at); err != nil {
+				if ctx.Err() == nil {
+					a.log.With("at", at, "error", err).Error("Failed to take balance snapshots")
+				}
+				continue
+			}
+			taken = at
+		}
+	}
+}
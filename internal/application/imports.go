@@ -0,0 +1,27 @@
+package application
+
+import (
+	"context"
+	"time"
+)
+
+// runImports periodically applies pending imports until ctx is canceled.
+// Every instance polls, an import is applied by the instance that claimed it.
+func (a *Application) runImports(ctx context.Context) {
+	a.log.Infof("Run imports every %v", a.cfg.Import.PollInterval)
+
+	ticker := time.NewTicker(a.cfg.Import.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.log.Info("Imports stopped")
+			return
+		case <-ticker.C:
+			if _, err := a.svc.ProcessImportJobs(ctx); err != nil && ctx.Err() == nil {
+				a.log.With("error", err).Error("Failed to process imports")
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+// runLedgerCheckpoints periodically creates signed checkpoints of the ledger until ctx is canceled.
+func (a *Application) runLedgerCheckpoints(ctx context.Context) {
+	a.log.Infof("Run ledger checkpoints every %v", a.cfg.Ledger.CheckpointInterval)
+
+	ticker := time.NewTicker(a.cfg.Ledger.CheckpointInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.log.Info("Ledger checkpoints stopped")
+			return
+		case <-ticker.C:
+			cp, err := a.svc.CreateLedgerCheckpoint(ctx)
+			if err != nil {
+				a.log.With("error", err).Error("Failed to create ledger checkpoint")
+				continue
+			}
+			if err := a.archiveCheckpoint(cp); err != nil {
+				a.log.With("error", err).Error("Failed to archive ledger checkpoint")
+			}
+		}
+	}
+}
+
+// archiveCheckpoint writes the checkpoint to the configured directory, it does nothing if the directory is not set.
+func (a *Application) archiveCheckpoint(cp *dto.LedgerCheckpoint) error {
+	if a.cfg.Ledger.CheckpointDir == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint: %w", err)
+	}
+
+	name := filepath.Join(a.cfg.Ledger.CheckpointDir, fmt.Sprintf("checkpoint-%06d.json", cp.ID))
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	return nil
+}
@@ -5,9 +5,12 @@ import (
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+
+	"github.com/ezhdanovskiy/wallets/internal/logging"
 )
 
-func newLogger(level, encoding string) (*zap.SugaredLogger, error) {
+// NewLogger creates the logger of the application, values of sensitive fields are redacted.
+func NewLogger(level, encoding string) (*zap.SugaredLogger, error) {
 	logConf := zap.NewProductionConfig()
 	if strings.ToLower(level) == "debug" {
 		logConf.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
@@ -17,7 +20,7 @@ func newLogger(level, encoding string) (*zap.SugaredLogger, error) {
 	}
 	logConf.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 
-	logger, err := logConf.Build()
+	logger, err := logConf.Build(zap.WrapCore(logging.RedactingCore))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,39 @@
+package application
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/ezhdanovskiy/wallets/internal/config"
+	"github.com/ezhdanovskiy/wallets/internal/ratelimit"
+	"github.com/ezhdanovskiy/wallets/internal/repository"
+)
+
+// newLimiter creates rate limiter with the configured store, it returns nil if no limits are configured.
+func newLimiter(log *zap.SugaredLogger, cfg config.RateLimit, repo backend) (*ratelimit.Limiter, error) {
+	limits := ratelimit.Limits{
+		APIKey: ratelimit.Limit{Rate: cfg.APIKeyRate, Burst: cfg.APIKeyBurst},
+		IP:     ratelimit.Limit{Rate: cfg.IPRate, Burst: cfg.IPBurst},
+		Wallet: ratelimit.Limit{Rate: cfg.WalletRate, Burst: cfg.WalletBurst},
+	}
+	if !limits.APIKey.Enabled() && !limits.IP.Enabled() && !limits.Wallet.Enabled() {
+		return nil, nil
+	}
+
+	var store ratelimit.Store
+	switch cfg.Store {
+	case config.RateLimitStoreMemory:
+		store = ratelimit.NewMemoryStore()
+	case config.RateLimitStorePostgres:
+		pg, ok := repo.(*repository.Repo)
+		if !ok {
+			return nil, fmt.Errorf("rate limit store %q requires postgres storage", cfg.Store)
+		}
+		store = ratelimit.StoreFunc(pg.TakeRateLimitToken)
+	default:
+		return nil, fmt.Errorf("unsupported rate limit store %q", cfg.Store)
+	}
+
+	return ratelimit.NewLimiter(log, store, limits), nil
+}
@@ -0,0 +1,38 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+// runStatements periodically stores the statements of the last settled month until ctx is canceled.
+// A month is generated once by the instance, statements stored by other instances are skipped.
+func (a *Application) runStatements(ctx context.Context) {
+	a.log.Infof("Run statements every %v", a.cfg.Statements.Interval)
+
+	ticker := time.NewTicker(a.cfg.Statements.Interval)
+	defer ticker.Stop()
+
+	var generated string
+	for {
+		select {
+		case <-ctx.Done():
+			a.log.Info("Statements stopped")
+			return
+		case <-ticker.C:
+			period := dto.LastSettledStatementPeriod(time.Now())
+			if period == generated {
+				continue
+			}
+			if _, err := a.svc.GenerateStatements(ctx, period); err != nil {
+				if ctx.Err() == nil {
+					a.log.With("period", period, "error", err).Error("Failed to generate statements")
+				}
+				continue
+			}
+			generated = period
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package application
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/config"
+	"github.com/ezhdanovskiy/wallets/internal/health"
+	"github.com/ezhdanovskiy/wallets/internal/repository"
+	"github.com/ezhdanovskiy/wallets/internal/repository/memory"
+	"github.com/ezhdanovskiy/wallets/internal/repository/sqlite"
+	"github.com/ezhdanovskiy/wallets/internal/service"
+)
+
+// backend is the storage of wallets selected by the config.
+type backend interface {
+	service.Repository
+	auth.KeyStore
+	health.DB
+	Close() error
+}
+
+// newBackend creates the configured storage backend and returns it with the migration version required for readiness.
+func newBackend(log *zap.SugaredLogger, cfg *config.Config) (backend, uint, error) {
+	switch cfg.Storage {
+	case config.StorageMemory:
+		log.Warn("Data is stored in memory, it is lost on exit")
+		return memory.NewRepo(log), 0, nil
+
+	case config.StoragePostgres:
+		repo, err := repository.NewRepo(log, cfg.DB)
+		if err != nil {
+			return nil, 0, fmt.Errorf("new repo: %w", err)
+		}
+		if err := prometheus.Register(repo.StatsCollector()); err != nil {
+			_ = repo.Close()
+			return nil, 0, fmt.Errorf("register db stats collector: %w", err)
+		}
+
+		migrationVersion, err := repository.LatestMigrationVersion(cfg.DB)
+		if err != nil {
+			_ = repo.Close()
+			return nil, 0, fmt.Errorf("latest migration version: %w", err)
+		}
+		return repo, migrationVersion, nil
+
+	case config.StorageSQLite:
+		repo, err := sqlite.NewRepo(log, cfg.SQLite, cfg.DB.MigrateOnStart)
+		if err != nil {
+			return nil, 0, fmt.Errorf("new sqlite repo: %w", err)
+		}
+		if err := prometheus.Register(repo.StatsCollector()); err != nil {
+			_ = repo.Close()
+			return nil, 0, fmt.Errorf("register db stats collector: %w", err)
+		}
+
+		migrationVersion, err := sqlite.LatestMigrationVersion()
+		if err != nil {
+			_ = repo.Close()
+			return nil, 0, fmt.Errorf("latest migration version: %w", err)
+		}
+		return repo, migrationVersion, nil
+
+	default:
+		return nil, 0, fmt.Errorf("unsupported storage %q", cfg.Storage)
+	}
+}
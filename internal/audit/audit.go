@@ -0,0 +1,58 @@
+// Package audit contains the data recorded in the audit log for every mutating call.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Actions recorded in the audit log.
+const (
+	ActionCreateWallet = "create_wallet"
+	ActionDeposit      = "deposit"
+	ActionTransfer     = "transfer"
+	ActionCreateAPIKey = "create_api_key"
+	ActionRevokeAPIKey = "revoke_api_key"
+
+	ActionCreateLedgerCheckpoint = "create_ledger_checkpoint"
+	ActionCreateImport           = "create_import"
+)
+
+// Results of audited actions.
+const (
+	ResultOK    = "ok"
+	ResultError = "error"
+)
+
+// Source describes where the call came from.
+type Source struct {
+	RequestID string
+	IP        string
+	Endpoint  string
+}
+
+type sourceKey struct{}
+
+// NewContext returns a copy of ctx carrying the source of the call.
+func NewContext(ctx context.Context, source Source) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// FromContext returns the source stored in ctx or empty source for internal calls.
+func FromContext(ctx context.Context) Source {
+	source, _ := ctx.Value(sourceKey{}).(Source)
+	return source
+}
+
+// PayloadHash returns SHA-256 of the JSON representation of the request payload,
+// it allows to match the request with the record without storing the payload itself.
+func PayloadHash(payload interface{}) string {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		data = []byte(err.Error())
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
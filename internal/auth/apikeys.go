@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/httperr"
+)
+
+// APIKeyPrefix starts every generated API key, so leaked keys are easy to find.
+const APIKeyPrefix = "wlt_"
+
+var (
+	ErrMissingCredentials = httperr.New(http.StatusUnauthorized, "missing credentials")
+	ErrInvalidAPIKey      = httperr.New(http.StatusUnauthorized, "invalid api key")
+	ErrAuthStorage        = httperr.New(http.StatusInternalServerError, "failed to check credentials")
+)
+
+// KeyStore describes the storage of hashed API keys.
+type KeyStore interface {
+	GetAPIKeyByHash(ctx context.Context, hash string) (*dto.APIKey, error)
+}
+
+//go:generate mockgen -destination=./mocks/keystore_mock.go -package=mocks . KeyStore
+
+// APIKeyAuthenticator authenticates callers by API keys stored hashed in KeyStore.
+type APIKeyAuthenticator struct {
+	store KeyStore
+}
+
+// NewAPIKeyAuthenticator creates authenticator using store.
+func NewAPIKeyAuthenticator(store KeyStore) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{store: store}
+}
+
+// Authenticate finds the not revoked API key and returns the caller described by it.
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, token string) (*Caller, error) {
+	if token == "" {
+		return nil, ErrMissingCredentials
+	}
+	if !strings.HasPrefix(token, APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := a.store.GetAPIKeyByHash(ctx, HashAPIKey(token))
+	if err != nil {
+		return nil, ErrAuthStorage.Wrap(err)
+	}
+	if key == nil || key.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	return &Caller{
+		ID:      key.Name,
+		Scopes:  key.Scopes,
+		Wallets: key.Wallets,
+		Owners:  key.Owners,
+	}, nil
+}
+
+// GenerateAPIKey returns a new random API key.
+func GenerateAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("read random: %w", err)
+	}
+	return APIKeyPrefix + hex.EncodeToString(b), nil
+}
+
+// HashAPIKey returns the hash of the API key that is stored instead of the key itself.
+// Keys are long random strings, so a fast hash is enough to make them useless when the DB leaks.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// BearerToken extracts the token from the value of the Authorization header.
+func BearerToken(authorization string) string {
+	const prefix = "bearer "
+	if len(authorization) > len(prefix) && strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return strings.TrimSpace(authorization[len(prefix):])
+	}
+	return ""
+}
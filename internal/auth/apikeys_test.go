@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ezhdanovskiy/wallets/internal/auth/mocks"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+func TestAPIKeyAuthenticator_Authenticate(t *testing.T) {
+	const testKey = APIKeyPrefix + "0123456789abcdef"
+
+	t.Run("missing key", func(t *testing.T) {
+		a := NewAPIKeyAuthenticator(mocks.NewMockKeyStore(gomock.NewController(t)))
+		_, err := a.Authenticate(context.Background(), "")
+		assert.Equal(t, ErrMissingCredentials, err)
+	})
+
+	t.Run("wrong prefix", func(t *testing.T) {
+		a := NewAPIKeyAuthenticator(mocks.NewMockKeyStore(gomock.NewController(t)))
+		_, err := a.Authenticate(context.Background(), "0123456789abcdef")
+		assert.Equal(t, ErrInvalidAPIKey, err)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		store := mocks.NewMockKeyStore(gomock.NewController(t))
+		store.EXPECT().GetAPIKeyByHash(gomock.Any(), HashAPIKey(testKey)).Return(nil, nil)
+
+		_, err := NewAPIKeyAuthenticator(store).Authenticate(context.Background(), testKey)
+		assert.Equal(t, ErrInvalidAPIKey, err)
+	})
+
+	t.Run("revoked key", func(t *testing.T) {
+		store := mocks.NewMockKeyStore(gomock.NewController(t))
+		revokedAt := time.Now()
+		store.EXPECT().GetAPIKeyByHash(gomock.Any(), HashAPIKey(testKey)).Return(&dto.APIKey{Name: "key", RevokedAt: &revokedAt}, nil)
+
+		_, err := NewAPIKeyAuthenticator(store).Authenticate(context.Background(), testKey)
+		assert.Equal(t, ErrInvalidAPIKey, err)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		store := mocks.NewMockKeyStore(gomock.NewController(t))
+		store.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Any()).Return(nil, sql.ErrConnDone)
+
+		_, err := NewAPIKeyAuthenticator(store).Authenticate(context.Background(), testKey)
+		assert.Equal(t, ErrAuthStorage.Wrap(sql.ErrConnDone), err)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		store := mocks.NewMockKeyStore(gomock.NewController(t))
+		store.EXPECT().GetAPIKeyByHash(gomock.Any(), HashAPIKey(testKey)).Return(&dto.APIKey{
+			Name:    "key",
+			Scopes:  []string{ScopeTransfer},
+			Wallets: []string{"w1"},
+			Owners:  []string{"alice"},
+		}, nil)
+
+		caller, err := NewAPIKeyAuthenticator(store).Authenticate(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, &Caller{
+			ID:      "key",
+			Scopes:  []string{ScopeTransfer},
+			Wallets: []string{"w1"},
+			Owners:  []string{"alice"},
+		}, caller)
+	})
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	key1, err := GenerateAPIKey()
+	require.NoError(t, err)
+	key2, err := GenerateAPIKey()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(key1, APIKeyPrefix))
+	assert.NotEqual(t, key1, key2)
+	assert.NotEqual(t, HashAPIKey(key1), HashAPIKey(key2))
+	assert.Equal(t, HashAPIKey(key1), HashAPIKey(key1))
+}
+
+func TestBearerToken(t *testing.T) {
+	assert.Equal(t, "abc", BearerToken("Bearer abc"))
+	assert.Equal(t, "abc", BearerToken("bearer  abc"))
+	assert.Equal(t, "", BearerToken("Basic abc"))
+	assert.Equal(t, "", BearerToken(""))
+}
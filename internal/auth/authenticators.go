@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"context"
+	"strings"
+)
+
+// Authenticator validates credentials passed with requests.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Caller, error)
+}
+
+// APIKeyOrJWT accepts both API keys and JWTs, they are distinguished by the API key prefix.
+type APIKeyOrJWT struct {
+	APIKeys Authenticator
+	JWT     Authenticator
+}
+
+// Authenticate passes the token to the authenticator of its kind.
+func (a APIKeyOrJWT) Authenticate(ctx context.Context, token string) (*Caller, error) {
+	if strings.HasPrefix(token, APIKeyPrefix) {
+		return a.APIKeys.Authenticate(ctx, token)
+	}
+	return a.JWT.Authenticate(ctx, token)
+}
@@ -0,0 +1,92 @@
+// Package auth contains caller identity and the authentication of API requests.
+package auth
+
+import (
+	"context"
+)
+
+// Scopes that can be granted to a caller.
+const (
+	ScopeRead     = "read"
+	ScopeDeposit  = "deposit"
+	ScopeTransfer = "transfer"
+	ScopeAdmin    = "admin"
+)
+
+// AllWallets in the wallets allow-list grants access to every wallet.
+const AllWallets = "*"
+
+// Caller describes the authenticated client and what it is allowed to do.
+type Caller struct {
+	ID      string
+	Scopes  []string
+	Wallets []string
+	Owners  []string
+}
+
+// HasScope reports whether the caller has at least one of the scopes.
+// The admin scope implies all others.
+func (c *Caller) HasScope(scopes ...string) bool {
+	for _, granted := range c.Scopes {
+		if granted == ScopeAdmin {
+			return true
+		}
+		for _, scope := range scopes {
+			if granted == scope {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CanAccessWallet reports whether the wallet is in the caller allow-list by its name or by its owner.
+// Admins can access every wallet.
+func (c *Caller) CanAccessWallet(name, owner string) bool {
+	if c.HasScope(ScopeAdmin) {
+		return true
+	}
+	for _, w := range c.Wallets {
+		if w == AllWallets || w == name {
+			return true
+		}
+	}
+	if owner == "" {
+		return false
+	}
+	for _, o := range c.Owners {
+		if o == owner {
+			return true
+		}
+	}
+	return false
+}
+
+// RestrictedByOwner reports whether the caller allow-list contains wallet owners,
+// so the wallet owner is required to decide about access.
+func (c *Caller) RestrictedByOwner() bool {
+	return len(c.Owners) > 0
+}
+
+type callerKey struct{}
+
+// NewContext returns a copy of ctx carrying the caller.
+func NewContext(ctx context.Context, caller *Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// FromContext returns the caller stored in ctx.
+// Calls without a caller come from trusted code like CLI commands or from a server with disabled authentication.
+func FromContext(ctx context.Context) (*Caller, bool) {
+	caller, ok := ctx.Value(callerKey{}).(*Caller)
+	return caller, ok && caller != nil
+}
+
+// ValidScope reports whether the scope is known.
+func ValidScope(scope string) bool {
+	switch scope {
+	case ScopeRead, ScopeDeposit, ScopeTransfer, ScopeAdmin:
+		return true
+	}
+	return false
+}
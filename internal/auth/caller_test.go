@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaller_HasScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  []string
+		required []string
+		expected bool
+	}{
+		{"granted", []string{ScopeRead, ScopeTransfer}, []string{ScopeTransfer}, true},
+		{"one of required", []string{ScopeDeposit}, []string{ScopeTransfer, ScopeDeposit}, true},
+		{"not granted", []string{ScopeRead}, []string{ScopeTransfer}, false},
+		{"admin implies all", []string{ScopeAdmin}, []string{ScopeTransfer}, true},
+		{"no scopes", nil, []string{ScopeRead}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Caller{Scopes: tt.granted}
+			assert.Equal(t, tt.expected, c.HasScope(tt.required...))
+		})
+	}
+}
+
+func TestCaller_CanAccessWallet(t *testing.T) {
+	tests := []struct {
+		name     string
+		caller   Caller
+		wallet   string
+		owner    string
+		expected bool
+	}{
+		{"wallet in list", Caller{Wallets: []string{"w1", "w2"}}, "w2", "", true},
+		{"wallet not in list", Caller{Wallets: []string{"w1"}}, "w2", "", false},
+		{"all wallets", Caller{Wallets: []string{AllWallets}}, "w2", "", true},
+		{"owner in list", Caller{Owners: []string{"alice"}}, "w2", "alice", true},
+		{"owner not in list", Caller{Owners: []string{"alice"}}, "w2", "bob", false},
+		{"empty owner", Caller{Owners: []string{"alice"}}, "w2", "", false},
+		{"admin", Caller{Scopes: []string{ScopeAdmin}}, "w2", "", true},
+		{"empty allow-list", Caller{Scopes: []string{ScopeTransfer}}, "w2", "bob", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.caller.CanAccessWallet(tt.wallet, tt.owner))
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	caller := &Caller{ID: "key"}
+	got, ok := FromContext(NewContext(context.Background(), caller))
+	require.True(t, ok)
+	assert.Equal(t, caller, got)
+}
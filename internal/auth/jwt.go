@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+
+	"github.com/ezhdanovskiy/wallets/internal/httperr"
+)
+
+var (
+	ErrInvalidToken = httperr.New(http.StatusUnauthorized, "invalid token")
+	ErrJWKS         = httperr.New(http.StatusInternalServerError, "failed to load signing keys")
+)
+
+// signatureAlgorithms are accepted in JWT headers, symmetric algorithms are not allowed with JWKS.
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// JWTConfig describes how bearer tokens are validated and mapped to the caller.
+type JWTConfig struct {
+	// JWKSFile or JWKSURL is the source of public keys, the file takes precedence.
+	JWKSFile string
+	JWKSURL  string
+	// JWKSRefresh is the period of reloading keys.
+	// Keys are also reloaded when a token is signed by an unknown key, but not more often than once a minute.
+	JWKSRefresh time.Duration
+
+	// Issuer and Audience are required, tokens must have exactly this iss and contain this aud.
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+
+	// Claims with the caller scopes and allow-lists. Values are arrays or space separated strings.
+	ScopesClaim  string
+	WalletsClaim string
+	OwnersClaim  string
+}
+
+// JWTAuthenticator authenticates callers by JWTs signed by keys from JWKS.
+type JWTAuthenticator struct {
+	cfg    JWTConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu       sync.RWMutex
+	keys     *jose.JSONWebKeySet
+	loadedAt time.Time
+}
+
+// NewJWTAuthenticator creates authenticator and loads keys.
+func NewJWTAuthenticator(cfg JWTConfig) (*JWTAuthenticator, error) {
+	if cfg.JWKSFile == "" && cfg.JWKSURL == "" {
+		return nil, fmt.Errorf("JWKS file or URL is required")
+	}
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return nil, fmt.Errorf("JWT issuer and audience are required")
+	}
+	if cfg.ScopesClaim == "" {
+		cfg.ScopesClaim = "scope"
+	}
+	if cfg.WalletsClaim == "" {
+		cfg.WalletsClaim = "wallets"
+	}
+	if cfg.OwnersClaim == "" {
+		cfg.OwnersClaim = "owners"
+	}
+
+	a := &JWTAuthenticator{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+	}
+	if err := a.reloadKeys(context.Background()); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Authenticate verifies the token signature and iss/aud/exp/sub claims and returns the caller described by claims.
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, token string) (*Caller, error) {
+	if token == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	tok, err := jwt.ParseSigned(token, signatureAlgorithms)
+	if err != nil {
+		return nil, ErrInvalidToken.Wrap(err)
+	}
+
+	key, err := a.key(ctx, tok.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims jwt.Claims
+	custom := make(map[string]interface{})
+	if err := tok.Claims(key, &claims, &custom); err != nil {
+		return nil, ErrInvalidToken.Wrap(err)
+	}
+
+	if claims.Expiry == nil {
+		return nil, ErrInvalidToken.Wrap(fmt.Errorf("missing exp claim"))
+	}
+	// The subject identifies the caller in rate limits and the audit log.
+	if claims.Subject == "" {
+		return nil, ErrInvalidToken.Wrap(fmt.Errorf("missing sub claim"))
+	}
+	expected := jwt.Expected{
+		Issuer:      a.cfg.Issuer,
+		AnyAudience: jwt.Audience{a.cfg.Audience},
+		Time:        a.now(),
+	}
+	if err := claims.ValidateWithLeeway(expected, a.cfg.Leeway); err != nil {
+		return nil, ErrInvalidToken.Wrap(err)
+	}
+
+	return &Caller{
+		ID:      claims.Subject,
+		Scopes:  claimList(custom[a.cfg.ScopesClaim]),
+		Wallets: claimList(custom[a.cfg.WalletsClaim]),
+		Owners:  claimList(custom[a.cfg.OwnersClaim]),
+	}, nil
+}
+
+// key finds the public key by kid, reloading keys if they are outdated or the kid is unknown.
+func (a *JWTAuthenticator) key(ctx context.Context, kid string) (*jose.JSONWebKey, error) {
+	a.mu.RLock()
+	keys, loadedAt := a.keys, a.loadedAt
+	a.mu.RUnlock()
+
+	found := findKey(keys, kid)
+	outdated := a.cfg.JWKSRefresh > 0 && a.now().Sub(loadedAt) > a.cfg.JWKSRefresh
+	if outdated || (found == nil && a.now().Sub(loadedAt) > time.Minute) {
+		if err := a.reloadKeys(ctx); err != nil {
+			if found == nil {
+				return nil, err
+			}
+			// Keep using the known key when the source is temporarily unavailable.
+		} else {
+			a.mu.RLock()
+			found = findKey(a.keys, kid)
+			a.mu.RUnlock()
+		}
+	}
+
+	if found == nil {
+		return nil, ErrInvalidToken.Wrap(fmt.Errorf("unknown key %q", kid))
+	}
+	return found, nil
+}
+
+func findKey(keys *jose.JSONWebKeySet, kid string) *jose.JSONWebKey {
+	if keys == nil {
+		return nil
+	}
+	if kid == "" {
+		// Tokens without kid are accepted only when the choice is unambiguous.
+		if len(keys.Keys) == 1 {
+			return &keys.Keys[0]
+		}
+		return nil
+	}
+	if found := keys.Key(kid); len(found) > 0 {
+		return &found[0]
+	}
+	return nil
+}
+
+func (a *JWTAuthenticator) reloadKeys(ctx context.Context) error {
+	data, err := a.readJWKS(ctx)
+	if err != nil {
+		return ErrJWKS.Wrap(err)
+	}
+
+	var keys jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return ErrJWKS.Wrap(fmt.Errorf("unmarshal JWKS: %w", err))
+	}
+	for _, k := range keys.Keys {
+		if !k.IsPublic() {
+			return ErrJWKS.Wrap(fmt.Errorf("key %q is not public", k.KeyID))
+		}
+	}
+
+	a.mu.Lock()
+	a.keys = &keys
+	a.loadedAt = a.now()
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *JWTAuthenticator) readJWKS(ctx context.Context) ([]byte, error) {
+	if a.cfg.JWKSFile != "" {
+		return os.ReadFile(a.cfg.JWKSFile)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.cfg.JWKSURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get JWKS: unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// claimList converts claim value which is an array or a space separated string to a list.
+func claimList(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
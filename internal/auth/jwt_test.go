@@ -0,0 +1,234 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ezhdanovskiy/wallets/internal/httperr"
+)
+
+const (
+	testIssuer   = "https://gateway.example.com"
+	testAudience = "wallets"
+)
+
+func TestJWTAuthenticator_Authenticate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwksFile := writeJWKS(t, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: rsaKey.Public(), KeyID: "rsa", Algorithm: string(jose.RS256), Use: "sig"},
+		{Key: ecKey.Public(), KeyID: "ec", Algorithm: string(jose.ES256), Use: "sig"},
+	}})
+
+	a, err := NewJWTAuthenticator(JWTConfig{
+		JWKSFile: jwksFile,
+		Issuer:   testIssuer,
+		Audience: testAudience,
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss":     testIssuer,
+			"aud":     []string{testAudience},
+			"sub":     "client-42",
+			"exp":     now.Add(time.Hour).Unix(),
+			"scope":   "read transfer",
+			"wallets": []string{"w1", "w2"},
+			"owners":  "alice",
+		}
+	}
+
+	t.Run("rsa", func(t *testing.T) {
+		caller, err := a.Authenticate(context.Background(), signToken(t, rsaKey, jose.RS256, "rsa", validClaims()))
+		require.NoError(t, err)
+		assert.Equal(t, &Caller{
+			ID:      "client-42",
+			Scopes:  []string{ScopeRead, ScopeTransfer},
+			Wallets: []string{"w1", "w2"},
+			Owners:  []string{"alice"},
+		}, caller)
+	})
+
+	t.Run("ecdsa", func(t *testing.T) {
+		caller, err := a.Authenticate(context.Background(), signToken(t, ecKey, jose.ES256, "ec", validClaims()))
+		require.NoError(t, err)
+		assert.Equal(t, "client-42", caller.ID)
+	})
+
+	invalid := []struct {
+		name   string
+		token  func() string
+		expErr *httperr.Error
+	}{
+		{"missing", func() string { return "" }, ErrMissingCredentials},
+		{"malformed", func() string { return "not.a.jwt" }, ErrInvalidToken},
+		{"wrong issuer", func() string {
+			c := validClaims()
+			c["iss"] = "https://evil.example.com"
+			return signToken(t, rsaKey, jose.RS256, "rsa", c)
+		}, ErrInvalidToken},
+		{"wrong audience", func() string {
+			c := validClaims()
+			c["aud"] = "payments"
+			return signToken(t, rsaKey, jose.RS256, "rsa", c)
+		}, ErrInvalidToken},
+		{"missing issuer", func() string {
+			c := validClaims()
+			delete(c, "iss")
+			return signToken(t, rsaKey, jose.RS256, "rsa", c)
+		}, ErrInvalidToken},
+		{"missing audience", func() string {
+			c := validClaims()
+			delete(c, "aud")
+			return signToken(t, rsaKey, jose.RS256, "rsa", c)
+		}, ErrInvalidToken},
+		{"expired", func() string {
+			c := validClaims()
+			c["exp"] = now.Add(-time.Hour).Unix()
+			return signToken(t, rsaKey, jose.RS256, "rsa", c)
+		}, ErrInvalidToken},
+		{"missing exp", func() string {
+			c := validClaims()
+			delete(c, "exp")
+			return signToken(t, rsaKey, jose.RS256, "rsa", c)
+		}, ErrInvalidToken},
+		{"missing subject", func() string {
+			c := validClaims()
+			delete(c, "sub")
+			return signToken(t, rsaKey, jose.RS256, "rsa", c)
+		}, ErrInvalidToken},
+		{"signed by other key", func() string {
+			return signToken(t, otherKey, jose.RS256, "rsa", validClaims())
+		}, ErrInvalidToken},
+		{"unknown kid", func() string {
+			return signToken(t, otherKey, jose.RS256, "other", validClaims())
+		}, ErrInvalidToken},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			caller, err := a.Authenticate(context.Background(), tt.token())
+			assert.Nil(t, caller)
+			assertHTTPErr(t, tt.expErr, err)
+		})
+	}
+}
+
+func TestJWTAuthenticator_JWKSURL(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: key.Public(), KeyID: "rsa", Algorithm: string(jose.RS256), Use: "sig"},
+	}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jwks)
+	}))
+	defer srv.Close()
+
+	a, err := NewJWTAuthenticator(JWTConfig{JWKSURL: srv.URL, Issuer: testIssuer, Audience: testAudience})
+	require.NoError(t, err)
+
+	caller, err := a.Authenticate(context.Background(), signToken(t, key, jose.RS256, "rsa", map[string]interface{}{
+		"iss":    testIssuer,
+		"aud":    testAudience,
+		"sub":    "client-42",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"scope":  []string{ScopeAdmin},
+		"owners": []string{"alice"},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{ScopeAdmin}, caller.Scopes)
+	assert.Equal(t, []string{"alice"}, caller.Owners)
+}
+
+func TestNewJWTAuthenticator_errors(t *testing.T) {
+	_, err := NewJWTAuthenticator(JWTConfig{})
+	assert.Error(t, err)
+
+	_, err = NewJWTAuthenticator(JWTConfig{JWKSFile: filepath.Join(t.TempDir(), "missing.json"),
+		Issuer: testIssuer, Audience: testAudience})
+	assert.Error(t, err)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, err = NewJWTAuthenticator(JWTConfig{JWKSFile: writeJWKS(t, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: key, KeyID: "private", Algorithm: string(jose.RS256)},
+	}}), Issuer: testIssuer, Audience: testAudience})
+	assert.Error(t, err, "private keys must not be accepted")
+}
+
+func TestAPIKeyOrJWT(t *testing.T) {
+	a := APIKeyOrJWT{
+		APIKeys: staticAuthenticator{ID: "api-key"},
+		JWT:     staticAuthenticator{ID: "jwt"},
+	}
+
+	caller, err := a.Authenticate(context.Background(), APIKeyPrefix+"123")
+	require.NoError(t, err)
+	assert.Equal(t, "api-key", caller.ID)
+
+	caller, err = a.Authenticate(context.Background(), "eyJhbGciOiJSUzI1NiJ9.e30.sig")
+	require.NoError(t, err)
+	assert.Equal(t, "jwt", caller.ID)
+}
+
+type staticAuthenticator Caller
+
+func (a staticAuthenticator) Authenticate(context.Context, string) (*Caller, error) {
+	c := Caller(a)
+	return &c, nil
+}
+
+func assertHTTPErr(t *testing.T, expected *httperr.Error, actual error) {
+	t.Helper()
+	e, ok := actual.(*httperr.Error)
+	require.True(t, ok, "expected *httperr.Error, got %v", actual)
+	assert.Equal(t, expected.StatusCode, e.StatusCode)
+	assert.Equal(t, expected.Message, e.Message)
+}
+
+func signToken(t *testing.T, key interface{}, alg jose.SignatureAlgorithm, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: alg, Key: jose.JSONWebKey{Key: key, KeyID: kid}},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	require.NoError(t, err)
+
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	require.NoError(t, err)
+	return token
+}
+
+func writeJWKS(t *testing.T, jwks jose.JSONWebKeySet) string {
+	t.Helper()
+	data, err := json.Marshal(jwks)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
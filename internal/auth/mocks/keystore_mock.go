@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/ezhdanovskiy/wallets/internal/auth (interfaces: KeyStore)
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mocks/keystore_mock.go -package=mocks . KeyStore
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/ezhdanovskiy/wallets/internal/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockKeyStore is a mock of KeyStore interface.
+type MockKeyStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockKeyStoreMockRecorder is the mock recorder for MockKeyStore.
+type MockKeyStoreMockRecorder struct {
+	mock *MockKeyStore
+}
+
+// NewMockKeyStore creates a new mock instance.
+func NewMockKeyStore(ctrl *gomock.Controller) *MockKeyStore {
+	mock := &MockKeyStore{ctrl: ctrl}
+	mock.recorder = &MockKeyStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyStore) EXPECT() *MockKeyStoreMockRecorder {
+	return m.recorder
+}
+
+// GetAPIKeyByHash mocks base method.
+func (m *MockKeyStore) GetAPIKeyByHash(ctx context.Context, hash string) (*dto.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByHash", ctx, hash)
+	ret0, _ := ret[0].(*dto.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByHash indicates an expected call of GetAPIKeyByHash.
+func (mr *MockKeyStoreMockRecorder) GetAPIKeyByHash(ctx, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByHash", reflect.TypeOf((*MockKeyStore)(nil).GetAPIKeyByHash), ctx, hash)
+}
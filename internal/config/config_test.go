@@ -0,0 +1,352 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestNewConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := NewConfig("")
+		require.NoError(t, err)
+		assert.Equal(t, 8080, cfg.HttpPort)
+		assert.Equal(t, "localhost", cfg.DB.Host)
+		assert.Equal(t, 15*time.Second, cfg.Shutdown.Timeout)
+		assert.Equal(t, 5*time.Second, cfg.Import.PollInterval)
+		assert.Equal(t, time.Hour, cfg.Balance.SnapshotInterval)
+	})
+
+	t.Run("yaml file", func(t *testing.T) {
+		file := writeFile(t, "config.yaml", "http_port: 8081\ndb_host: db\nshutdown_timeout: 30s\n")
+
+		cfg, err := NewConfig(file)
+		require.NoError(t, err)
+		assert.Equal(t, 8081, cfg.HttpPort)
+		assert.Equal(t, "db", cfg.DB.Host)
+		assert.Equal(t, 30*time.Second, cfg.Shutdown.Timeout)
+		assert.Equal(t, 9090, cfg.GrpcPort)
+	})
+
+	t.Run("toml file", func(t *testing.T) {
+		file := writeFile(t, "config.toml", "http_port = 8082\nauth_mode = \"api_key\"\n")
+
+		cfg, err := NewConfig(file)
+		require.NoError(t, err)
+		assert.Equal(t, 8082, cfg.HttpPort)
+		assert.Equal(t, AuthModeAPIKey, cfg.Auth.Mode)
+	})
+
+	t.Run("environment overrides file", func(t *testing.T) {
+		file := writeFile(t, "config.yaml", "http_port: 8081\n")
+		t.Setenv("HTTP_PORT", "8083")
+
+		cfg, err := NewConfig(file)
+		require.NoError(t, err)
+		assert.Equal(t, 8083, cfg.HttpPort)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := NewConfig(filepath.Join(t.TempDir(), "config.yaml"))
+		assert.Error(t, err)
+	})
+
+	t.Run("secret file", func(t *testing.T) {
+		t.Setenv("DB_PASSWORD_FILE", writeFile(t, "db_password", "s3cret\n"))
+
+		cfg, err := NewConfig("")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", cfg.DB.Password)
+	})
+
+	t.Run("missing secret file", func(t *testing.T) {
+		t.Setenv("DB_PASSWORD_FILE", filepath.Join(t.TempDir(), "db_password"))
+
+		_, err := NewConfig("")
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		file := writeFile(t, "config.yaml", "http_port: 70000\ndb_host: ''\nauth_mode: basic\n")
+
+		_, err := NewConfig(file)
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{
+			"http_port must be in range 1-65535, got 70000",
+			"db_host must not be empty",
+			`auth_mode must be one of none/api_key/jwt/api_key_or_jwt, got "basic"`,
+		}, validationErr.Problems)
+	})
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := func() *Config {
+		cfg, err := NewConfig("")
+		require.NoError(t, err)
+		return cfg
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(cfg *Config)
+		problems []string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name: "same ports",
+			modify: func(cfg *Config) {
+				cfg.GrpcPort = cfg.HttpPort
+			},
+			problems: []string{"http_port and grpc_port must differ, both are 8080"},
+		},
+		{
+			name: "invalid currency",
+			modify: func(cfg *Config) {
+				cfg.Currency = "usd"
+			},
+			problems: []string{`currency must be an ISO 4217 code of 3 upper case letters, got "usd"`},
+		},
+		{
+			name: "jwt without keys",
+			modify: func(cfg *Config) {
+				cfg.Auth.Mode = AuthModeJWT
+			},
+			problems: []string{
+				"auth_jwks_file or auth_jwks_url is required for auth_mode jwt",
+				"auth_jwt_issuer is required for auth_mode jwt",
+				"auth_jwt_audience is required for auth_mode jwt",
+			},
+		},
+		{
+			name: "jwt without audience",
+			modify: func(cfg *Config) {
+				cfg.Auth.Mode = AuthModeAPIKeyOrJWT
+				cfg.Auth.JWKSURL = "https://gateway.example.com/jwks.json"
+				cfg.Auth.JWTIssuer = "https://gateway.example.com"
+			},
+			problems: []string{"auth_jwt_audience is required for auth_mode api_key_or_jwt"},
+		},
+		{
+			name: "checkpoints without signing key",
+			modify: func(cfg *Config) {
+				cfg.Ledger.CheckpointInterval = time.Hour
+			},
+			problems: []string{"ledger_signing_key_file is required for periodic checkpoints"},
+		},
+		{
+			name: "negative import poll interval",
+			modify: func(cfg *Config) {
+				cfg.Import.PollInterval = -time.Second
+			},
+			problems: []string{"import_poll_interval must not be negative"},
+		},
+		{
+			name: "negative statements interval",
+			modify: func(cfg *Config) {
+				cfg.Statements.Interval = -time.Hour
+			},
+			problems: []string{"statements_interval must not be negative"},
+		},
+		{
+			name: "negative balance snapshot interval",
+			modify: func(cfg *Config) {
+				cfg.Balance.SnapshotInterval = -time.Hour
+			},
+			problems: []string{"balance_snapshot_interval must not be negative"},
+		},
+		{
+			name: "negative limits",
+			modify: func(cfg *Config) {
+				cfg.RateLimit.IPRate = -1
+				cfg.RateLimit.WalletBurst = -1
+			},
+			problems: []string{"rate_limit_ip_rate must not be negative", "rate_limit_wallet_burst must not be negative"},
+		},
+		{
+			name: "tracing",
+			modify: func(cfg *Config) {
+				cfg.Tracing.Exporter = TracingExporterOTLP
+				cfg.Tracing.OTLPEndpoint = ""
+				cfg.Tracing.SampleRatio = 2
+			},
+			problems: []string{
+				"tracing_otlp_endpoint is required for tracing_exporter otlp",
+				"tracing_sample_ratio must be in range 0-1, got 2",
+			},
+		},
+		{
+			name: "database url",
+			modify: func(cfg *Config) {
+				cfg.DB.URL = "mysql://db"
+				cfg.DB.Host = ""
+			},
+			problems: []string{"database_url must start with postgres:// or postgresql://"},
+		},
+		{
+			name: "tls and pool",
+			modify: func(cfg *Config) {
+				cfg.DB.SSLMode = "on"
+				cfg.DB.SSLCert = "client.pem"
+				cfg.DB.MaxOpenConns = -1
+			},
+			problems: []string{
+				`db_sslmode must be one of disable/allow/prefer/require/verify-ca/verify-full, got "on"`,
+				"db_sslcert and db_sslkey must be set together",
+				"db_max_open_conns must not be negative",
+			},
+		},
+		{
+			name: "embedded migrations without path",
+			modify: func(cfg *Config) {
+				cfg.DB.MigrationsSource = MigrationsSourceEmbed
+				cfg.DB.MigrationsPath = ""
+			},
+		},
+		{
+			name: "migrations",
+			modify: func(cfg *Config) {
+				cfg.DB.MigrationsSource = "s3"
+				cfg.DB.MigrateLockTimeout = 0
+			},
+			problems: []string{
+				`migrations_source must be one of file/embed, got "s3"`,
+				"migrate_lock_timeout must be positive",
+			},
+		},
+		{
+			name: "migrations path",
+			modify: func(cfg *Config) {
+				cfg.DB.MigrationsPath = ""
+			},
+			problems: []string{"migrations_path must not be empty for migrations_source file"},
+		},
+		{
+			name: "storage",
+			modify: func(cfg *Config) {
+				cfg.Storage = "mysql"
+			},
+			problems: []string{`storage must be one of postgres/sqlite/memory, got "mysql"`},
+		},
+		{
+			name: "sqlite",
+			modify: func(cfg *Config) {
+				cfg.Storage = StorageSQLite
+				cfg.SQLite.Path = ""
+				cfg.SQLite.BusyTimeout = -time.Second
+			},
+			problems: []string{
+				"sqlite_path must not be empty for storage sqlite",
+				"sqlite_busy_timeout must not be negative",
+			},
+		},
+		{
+			name: "postgres rate limits in memory",
+			modify: func(cfg *Config) {
+				cfg.Storage = StorageMemory
+				cfg.RateLimit.Store = RateLimitStorePostgres
+			},
+			problems: []string{"rate_limit_store postgres requires storage postgres"},
+		},
+		{
+			name: "shutdown",
+			modify: func(cfg *Config) {
+				cfg.Shutdown.Timeout = 0
+			},
+			problems: []string{"shutdown_timeout must be positive"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.problems == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.problems, validationErr.Problems)
+		})
+	}
+}
+
+func TestConfig_Settings(t *testing.T) {
+	cfg, err := NewConfig("")
+	require.NoError(t, err)
+
+	settings := cfg.Settings()
+	assert.Equal(t, Masked, settings["db_password"])
+	assert.Equal(t, "", settings["database_url"])
+	assert.Equal(t, "localhost", settings["db_host"])
+	assert.Equal(t, 8080, settings["http_port"])
+	assert.Equal(t, "15s", settings["shutdown_timeout"])
+	assert.Equal(t, AuthModeNone, settings["auth_mode"])
+
+	cfg.DB.Password = ""
+	assert.Equal(t, "", cfg.Settings()["db_password"])
+}
+
+func TestDB_DSN(t *testing.T) {
+	tests := []struct {
+		name string
+		db   DB
+		dsn  string
+	}{
+		{
+			name: "url",
+			db:   DB{URL: "postgres://user:pass@db:5432/wallets?sslmode=require", Host: "ignored"},
+			dsn:  "postgres://user:pass@db:5432/wallets?sslmode=require",
+		},
+		{
+			name: "minimal",
+			db:   DB{Host: "localhost", Port: 5432, User: "postgres", Password: "postgres", DBName: "wallets", SSLMode: "disable"},
+			dsn:  "host=localhost port=5432 user=postgres password=postgres dbname=wallets sslmode=disable",
+		},
+		{
+			name: "tls and timeouts",
+			db: DB{
+				Host:             "db",
+				Port:             5433,
+				User:             "app",
+				DBName:           "wallets",
+				SSLMode:          "verify-full",
+				SSLRootCert:      "/certs/ca.pem",
+				SSLCert:          "/certs/client.pem",
+				SSLKey:           "/certs/client.key",
+				ApplicationName:  "wallets",
+				ConnectTimeout:   1500 * time.Millisecond,
+				StatementTimeout: 30 * time.Second,
+			},
+			dsn: "host=db port=5433 user=app dbname=wallets sslmode=verify-full sslrootcert=/certs/ca.pem " +
+				"sslcert=/certs/client.pem sslkey=/certs/client.key application_name=wallets connect_timeout=2 statement_timeout=30000",
+		},
+		{
+			name: "quoted values",
+			db:   DB{Host: "localhost", Port: 5432, User: "postgres", Password: `it's a \secret`, DBName: "wallets"},
+			dsn:  `host=localhost port=5432 user=postgres password='it\'s a \\secret' dbname=wallets`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.dsn, tt.db.DSN())
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Masked replaces values of secrets in Settings.
+const Masked = "******"
+
+// Settings returns the effective parameters keyed by their names, values of secrets are masked.
+// Durations are formatted as strings, so the result can be written back to a config file.
+func (c *Config) Settings() map[string]interface{} {
+	settings := make(map[string]interface{})
+	collectSettings(reflect.ValueOf(*c), settings)
+	return settings
+}
+
+func collectSettings(v reflect.Value, settings map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("mapstructure")
+		name := strings.Split(tag, ",")[0]
+		if strings.HasSuffix(tag, ",squash") {
+			collectSettings(v.Field(i), settings)
+			continue
+		}
+		if name == "" {
+			continue
+		}
+
+		value := v.Field(i).Interface()
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+		if isSecret(name) && value != "" {
+			value = Masked
+		}
+		settings[name] = value
+	}
+}
+
+func isSecret(name string) bool {
+	for _, key := range secretKeys {
+		if name == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ValidationError contains all problems found in the config.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks that the config is consistent, all problems are reported at once.
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+	oneOf := func(key, value string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		problems = append(problems, fmt.Sprintf("%s must be one of %s, got %q", key, strings.Join(allowed, "/"), value))
+	}
+	port := func(key string, value int) {
+		check(value > 0 && value <= 65535, "%s must be in range 1-65535, got %d", key, value)
+	}
+
+	oneOf("log_level", strings.ToLower(c.LogLevel), "debug", "info", "warn", "error")
+	oneOf("log_encoding", c.LogEncoding, "json", "console")
+	port("http_port", c.HttpPort)
+	port("grpc_port", c.GrpcPort)
+	check(c.HttpPort != c.GrpcPort, "http_port and grpc_port must differ, both are %d", c.HttpPort)
+	oneOf("storage", c.Storage, StoragePostgres, StorageSQLite, StorageMemory)
+	check(currencyCode.MatchString(c.Currency), "currency must be an ISO 4217 code of 3 upper case letters, got %q", c.Currency)
+
+	if c.DB.URL != "" {
+		check(strings.HasPrefix(c.DB.URL, "postgres://") || strings.HasPrefix(c.DB.URL, "postgresql://"),
+			"database_url must start with postgres:// or postgresql://")
+	} else {
+		check(c.DB.Host != "", "db_host must not be empty")
+		port("db_port", c.DB.Port)
+		check(c.DB.User != "", "db_user must not be empty")
+		check(c.DB.DBName != "", "db_name must not be empty")
+		oneOf("db_sslmode", c.DB.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+		check((c.DB.SSLCert == "") == (c.DB.SSLKey == ""), "db_sslcert and db_sslkey must be set together")
+		check(c.DB.ConnectTimeout >= 0, "db_connect_timeout must not be negative")
+		check(c.DB.StatementTimeout >= 0, "db_statement_timeout must not be negative")
+	}
+	if c.DB.ReplicaURL != "" {
+		check(strings.HasPrefix(c.DB.ReplicaURL, "postgres://") || strings.HasPrefix(c.DB.ReplicaURL, "postgresql://"),
+			"db_replica_url must start with postgres:// or postgresql://")
+	}
+	oneOf("migrations_source", c.DB.MigrationsSource, MigrationsSourceFile, MigrationsSourceEmbed)
+	check(c.DB.MigrationsSource != MigrationsSourceFile || c.DB.MigrationsPath != "",
+		"migrations_path must not be empty for migrations_source %s", MigrationsSourceFile)
+	check(c.DB.MigrateLockTimeout > 0, "migrate_lock_timeout must be positive")
+	check(c.DB.MaxOpenConns >= 0, "db_max_open_conns must not be negative")
+	check(c.DB.MaxIdleConns >= 0, "db_max_idle_conns must not be negative")
+	check(c.DB.ConnMaxLifetime >= 0, "db_conn_max_lifetime must not be negative")
+	check(c.DB.ConnMaxIdleTime >= 0, "db_conn_max_idle_time must not be negative")
+	check(c.DB.ConnectRetryTimeout >= 0, "db_connect_retry_timeout must not be negative")
+
+	if c.Storage == StorageSQLite {
+		check(c.SQLite.Path != "", "sqlite_path must not be empty for storage %s", StorageSQLite)
+		check(c.SQLite.BusyTimeout >= 0, "sqlite_busy_timeout must not be negative")
+	}
+
+	oneOf("auth_mode", c.Auth.Mode, AuthModeNone, AuthModeAPIKey, AuthModeJWT, AuthModeAPIKeyOrJWT)
+	if c.Auth.Mode == AuthModeJWT || c.Auth.Mode == AuthModeAPIKeyOrJWT {
+		check(c.Auth.JWKSFile != "" || c.Auth.JWKSURL != "", "auth_jwks_file or auth_jwks_url is required for auth_mode %s", c.Auth.Mode)
+		check(c.Auth.JWTIssuer != "", "auth_jwt_issuer is required for auth_mode %s", c.Auth.Mode)
+		check(c.Auth.JWTAudience != "", "auth_jwt_audience is required for auth_mode %s", c.Auth.Mode)
+		check(c.Auth.JWKSRefresh >= 0, "auth_jwks_refresh must not be negative")
+		check(c.Auth.JWTLeeway >= 0, "auth_jwt_leeway must not be negative")
+	}
+
+	check(c.Ledger.CheckpointInterval >= 0, "ledger_checkpoint_interval must not be negative")
+	check(c.Ledger.CheckpointInterval == 0 || c.Ledger.SigningKeyFile != "",
+		"ledger_signing_key_file is required for periodic checkpoints")
+
+	check(c.Import.PollInterval >= 0, "import_poll_interval must not be negative")
+	check(c.Statements.Interval >= 0, "statements_interval must not be negative")
+	check(c.Balance.SnapshotInterval >= 0, "balance_snapshot_interval must not be negative")
+
+	oneOf("rate_limit_store", c.RateLimit.Store, RateLimitStoreMemory, RateLimitStorePostgres)
+	check(c.RateLimit.Store != RateLimitStorePostgres || c.Storage == StoragePostgres,
+		"rate_limit_store %s requires storage %s", RateLimitStorePostgres, StoragePostgres)
+	for _, l := range []struct {
+		name  string
+		rate  float64
+		burst int
+	}{
+		{"api_key", c.RateLimit.APIKeyRate, c.RateLimit.APIKeyBurst},
+		{"ip", c.RateLimit.IPRate, c.RateLimit.IPBurst},
+		{"wallet", c.RateLimit.WalletRate, c.RateLimit.WalletBurst},
+	} {
+		check(l.rate >= 0, "rate_limit_%s_rate must not be negative", l.name)
+		check(l.burst >= 0, "rate_limit_%s_burst must not be negative", l.name)
+	}
+
+	oneOf("tracing_exporter", c.Tracing.Exporter, TracingExporterNone, TracingExporterStdout, TracingExporterOTLP)
+	check(c.Tracing.Exporter != TracingExporterOTLP || c.Tracing.OTLPEndpoint != "",
+		"tracing_otlp_endpoint is required for tracing_exporter %s", TracingExporterOTLP)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+		"tracing_sample_ratio must be in range 0-1, got %v", c.Tracing.SampleRatio)
+
+	check(c.Shutdown.Timeout > 0, "shutdown_timeout must be positive")
+	check(c.Shutdown.DrainDelay >= 0, "shutdown_drain_delay must not be negative")
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
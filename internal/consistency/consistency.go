@@ -0,0 +1,35 @@
+// Package consistency controls whether reads may be served by a database replica.
+package consistency
+
+import (
+	"context"
+	"strings"
+)
+
+// Header requests strong consistency of reads in HTTP requests, in lower case it is the gRPC metadata key.
+const Header = "X-Consistency"
+
+// StrongValue is the value of Header requesting reads from the primary database.
+const StrongValue = "strong"
+
+type contextKey struct{}
+
+// Strong returns a copy of ctx in which reads go to the primary database,
+// so they see all committed writes, e.g. the wallet created by the previous request.
+func Strong(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, true)
+}
+
+// IsStrong reports whether reads in ctx must go to the primary database.
+func IsStrong(ctx context.Context) bool {
+	strong, _ := ctx.Value(contextKey{}).(bool)
+	return strong
+}
+
+// FromHeader returns Strong(ctx) if the header value requests strong consistency, otherwise ctx.
+func FromHeader(ctx context.Context, value string) context.Context {
+	if strings.EqualFold(strings.TrimSpace(value), StrongValue) {
+		return Strong(ctx)
+	}
+	return ctx
+}
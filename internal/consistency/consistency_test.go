@@ -0,0 +1,32 @@
+package consistency
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrong(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, IsStrong(ctx))
+	assert.True(t, IsStrong(Strong(ctx)))
+}
+
+func TestFromHeader(t *testing.T) {
+	tests := []struct {
+		value  string
+		strong bool
+	}{
+		{value: "", strong: false},
+		{value: "strong", strong: true},
+		{value: " Strong ", strong: true},
+		{value: "eventual", strong: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.strong, IsStrong(FromHeader(context.Background(), tt.value)))
+		})
+	}
+}
@@ -8,4 +8,35 @@ const (
 
 	OperationsLimitDefault = 20
 	OperationsLimitMax     = 1000
+
+	// Orders of operations by (created_at, id).
+	OperationsOrderAsc  = "asc"
+	OperationsOrderDesc = "desc"
+
+	WalletsLimitDefault = 100
+
+	// Number of days of the daily balance series.
+	BalanceDaysDefault = 30
+	BalanceDaysMax     = 366
+
+	// Kinds of import files.
+	ImportKindDeposits  = "deposits"
+	ImportKindTransfers = "transfers"
+
+	// Statuses of import jobs: pending, running and done.
+	// Rows are pending until they are applied, skipped as applied before or failed.
+	ImportStatusPending = "pending"
+	ImportStatusRunning = "running"
+	ImportStatusDone    = "done"
+	ImportStatusApplied = "applied"
+	ImportStatusSkipped = "skipped"
+	ImportStatusFailed  = "failed"
+
+	ImportChunkSizeDefault = 100
+	ImportChunkSizeMax     = 1000
+	ImportRowsMax          = 100000
+	// ImportFileSizeMax limits the size of the uploaded import file in bytes.
+	ImportFileSizeMax = 32 << 20
+	// ImportErrorsMax limits the number of errors of the import file that are reported.
+	ImportErrorsMax = 1000
 )
@@ -0,0 +1,44 @@
+// Package csv contains methods for generating csv.
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+// ConvertAuditRecords converts audit records to csv format.
+func ConvertAuditRecords(records []dto.AuditRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	csvWriter := csv.NewWriter(&buf)
+
+	err := csvWriter.Write([]string{"id", "timestamp", "action", "actor", "request_id", "source_ip", "endpoint",
+		"payload_hash", "result", "error"})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rec := range records {
+		err := csvWriter.Write([]string{
+			strconv.FormatInt(rec.ID, 10),
+			rec.Timestamp.UTC().Format(time.RFC3339Nano),
+			rec.Action,
+			rec.Actor,
+			rec.RequestID,
+			rec.SourceIP,
+			rec.Endpoint,
+			rec.PayloadHash,
+			rec.Result,
+			rec.Error,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	csvWriter.Flush()
+	return buf.Bytes(), csvWriter.Error()
+}
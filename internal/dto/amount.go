@@ -11,6 +11,11 @@ func (a Amount) GetInt() uint64 {
 	return uint64(a * 100)
 }
 
+// InRange reports whether the amount is a non-negative number which cents fit into a bigint column.
+func (a Amount) InRange() bool {
+	return a >= 0 && a*100 < 1<<63
+}
+
 func (a *Amount) SetAmount(amount uint64) {
 	*a = Amount(amount) / 100
 }
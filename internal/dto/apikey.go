@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"time"
+)
+
+type APIKey struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	Wallets   []string   `json:"wallets"`
+	Owners    []string   `json:"owners"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name    string   `json:"name"`
+	Scopes  []string `json:"scopes"`
+	Wallets []string `json:"wallets"`
+	Owners  []string `json:"owners"`
+}
+
+// CreatedAPIKey contains the plain key, it is returned only once when the key is created.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"time"
+)
+
+type AuditRecord struct {
+	ID          int64     `json:"id"`
+	Action      string    `json:"action"`
+	Actor       string    `json:"actor"`
+	RequestID   string    `json:"request_id"`
+	SourceIP    string    `json:"source_ip"`
+	Endpoint    string    `json:"endpoint"`
+	PayloadHash string    `json:"payload_hash"`
+	Result      string    `json:"result"`
+	Error       string    `json:"error,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+type AuditFilter struct {
+	Actor     string
+	Action    string
+	RequestID string
+	StartDate int64
+	EndDate   int64
+	Limit     int64
+	Offset    int64
+}
@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+)
+
+// BalanceSnapshot is the balance of the wallet in cents including all operations created until TakenAt.
+type BalanceSnapshot struct {
+	Wallet  string
+	TakenAt time.Time
+	Balance uint64
+}
+
+// Balance is the balance of the wallet at the moment.
+type Balance struct {
+	Wallet  string    `json:"wallet"`
+	At      time.Time `json:"at"`
+	Balance Amount    `json:"balance"`
+}
+
+// DailyBalance is the balance of the wallet at the end of the day.
+type DailyBalance struct {
+	Date    string `json:"date"`
+	Balance Amount `json:"balance"`
+}
+
+// DailyBalancesFilter selects the days of the balance series.
+type DailyBalancesFilter struct {
+	Wallet string
+	// StartDate and EndDate are the first and the last days, zero EndDate is today,
+	// zero StartDate is consts.BalanceDaysDefault days until EndDate.
+	StartDate time.Time
+	EndDate   time.Time
+	// Location is the time zone of the days, UTC if nil.
+	Location *time.Location
+}
+
+// BalanceSnapshotTime returns the end of the last UTC day which ended an hour before now. Snapshots are taken
+// at the ends of days, the hour lets transactions started before the end of the day commit their operations.
+func BalanceSnapshotTime(now time.Time) time.Time {
+	return now.UTC().Add(-time.Hour).Truncate(24 * time.Hour)
+}
@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"time"
+)
+
+// ImportRequest is a CSV file of deposits or transfers applied in bulk.
+type ImportRequest struct {
+	// Kind is consts.ImportKindDeposits or consts.ImportKindTransfers.
+	Kind string
+	File []byte
+	// Delimiter separates fields, comma if 0.
+	Delimiter rune
+	// DryRun only validates the file.
+	DryRun bool
+	// ChunkSize is the number of rows applied in a transaction, consts.ImportChunkSizeDefault if 0.
+	ChunkSize int
+}
+
+// ImportError is a problem of the import file, line 1 is the header.
+type ImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ImportReport is the result of the validation of the import file.
+type ImportReport struct {
+	Kind   string        `json:"kind"`
+	Rows   int           `json:"rows"`
+	Errors []ImportError `json:"errors"`
+	// Truncated is set if there are more errors than listed.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// ImportResult is the report of the import file and the created job, the job is nil for a dry run
+// or if the file has errors.
+type ImportResult struct {
+	Report ImportReport
+	Job    *ImportJob
+}
+
+// ImportJob tracks applying the rows of the import file.
+type ImportJob struct {
+	ID        int64  `json:"id"`
+	Kind      string `json:"kind"`
+	Status    string `json:"status"`
+	FileHash  string `json:"file_hash"`
+	ChunkSize int    `json:"chunk_size"`
+	Rows      int    `json:"rows"`
+	Applied   int    `json:"applied"`
+	Skipped   int    `json:"skipped"`
+	Failed    int    `json:"failed"`
+	// CreatedBy and RequestID are recorded in the audit log with the applied rows.
+	CreatedBy  string     `json:"created_by,omitempty"`
+	RequestID  string     `json:"request_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// ImportRow is a deposit or a transfer of the import file.
+type ImportRow struct {
+	JobID int64
+	Line  int
+	// Key is the idempotency key, a row with the key applied before is skipped.
+	Key string
+	// Wallet is credited by a deposit and debited by a transfer.
+	Wallet   string
+	WalletTo string
+	// Amount is in cents like wallet balances, so it isn't rounded.
+	Amount uint64
+	Status string
+	Error  string
+}
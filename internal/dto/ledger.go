@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+)
+
+// LedgerHead is the last operation of the wallet chain.
+type LedgerHead struct {
+	Seq  int64  `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+// LedgerCheckpoint is a signed snapshot of heads of all wallet chains.
+type LedgerCheckpoint struct {
+	ID              int64                 `json:"id,omitempty"`
+	CreatedAt       time.Time             `json:"created_at"`
+	Operations      int64                 `json:"operations"`
+	LastOperationID int64                 `json:"last_operation_id"`
+	RootHash        string                `json:"root_hash"`
+	Heads           map[string]LedgerHead `json:"heads"`
+	KeyID           string                `json:"key_id"`
+	Signature       string                `json:"signature"`
+}
+
+// LedgerBrokenLink describes the first operation that doesn't match the chain.
+type LedgerBrokenLink struct {
+	Wallet      string `json:"wallet"`
+	Seq         int64  `json:"seq"`
+	OperationID int64  `json:"operation_id,omitempty"`
+	Reason      string `json:"reason"`
+}
+
+// LedgerReport is the result of the ledger verification.
+type LedgerReport struct {
+	OK           bool  `json:"ok"`
+	Wallets      int   `json:"wallets"`
+	Operations   int64 `json:"operations"`
+	CheckpointID int64 `json:"checkpoint_id,omitempty"`
+	// CheckpointError is set if the checkpoint signature is invalid, then the checkpoint is not used.
+	CheckpointError string            `json:"checkpoint_error,omitempty"`
+	Broken          *LedgerBrokenLink `json:"broken,omitempty"`
+}
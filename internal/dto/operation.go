@@ -1,10 +1,15 @@
 package dto
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
 	"time"
 )
 
 type Operation struct {
+	ID          int64     `json:"-"`
 	Wallet      string    `json:"wallet"`
 	Amount      Amount    `json:"amount"`
 	Type        string    `json:"type"`
@@ -13,10 +18,144 @@ type Operation struct {
 }
 
 type OperationsFilter struct {
-	Wallet    string
-	Type      string
-	StartDate int64
-	EndDate   int64
+	Wallet string
+	// Types selects operations of any of the types, all types if empty.
+	Types []string
+	// OtherWallet selects operations with the counterparty, any counterparty if empty.
+	OtherWallet string
+	// MinAmount and MaxAmount are inclusive bounds of the amount, zero disables a bound.
+	MinAmount Amount
+	MaxAmount Amount
+	// StartDate and EndDate are inclusive bounds of the creation time, zero time disables a bound.
+	StartDate time.Time
+	EndDate   time.Time
 	Limit     int64
-	Offset    int64
+	// Offset is kept for backward compatibility, deep pages are slow and may skip or repeat operations, use Cursor.
+	Offset int64
+	// Order is consts.OperationsOrderAsc (default) or consts.OperationsOrderDesc by (created_at, id).
+	Order string
+	// Cursor selects the page after or before an operation, it can't be combined with Offset.
+	Cursor *OperationsCursor
+}
+
+// OperationsPage is a page of operations with cursors of the adjacent pages, a cursor is empty if there is no page.
+type OperationsPage struct {
+	Operations []Operation
+	NextCursor string
+	PrevCursor string
+}
+
+// OperationsCursor is the position of an operation in the history ordered by (created_at, id).
+type OperationsCursor struct {
+	CreatedAt time.Time
+	ID        int64
+	// Backward selects the operations before the position in the order of the filter, otherwise after it.
+	Backward bool
+}
+
+// ErrInvalidCursor is returned by ParseOperationsCursor for a token that isn't created by Encode.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Encode returns the opaque token of the cursor, which is safe to use in URLs.
+func (c OperationsCursor) Encode() string {
+	direction := "n"
+	if c.Backward {
+		direction = "p"
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%d:%d", direction, c.CreatedAt.UnixMicro(), c.ID)))
+}
+
+// ParseOperationsCursor decodes the token returned by Encode.
+func ParseOperationsCursor(token string) (*OperationsCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var (
+		direction byte
+		micros    int64
+		c         OperationsCursor
+	)
+	if _, err := fmt.Sscanf(string(data), "%c:%d:%d", &direction, &micros, &c.ID); err != nil ||
+		direction != 'n' && direction != 'p' || c.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	c.CreatedAt = time.UnixMicro(micros).UTC()
+	c.Backward = direction == 'p'
+	if c.Encode() != token { // trailing garbage or non-canonical numbers
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// ParseOperationsTime parses a bound of the operations period: unix seconds, an RFC 3339 timestamp
+// or a YYYY-MM-DD date in loc. A date is the start of the day, or its last microsecond if endOfDay is set,
+// so the end bound includes the whole day. Zero seconds is the zero time that disables the bound.
+func ParseOperationsTime(value string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return UnixOperationsTime(seconds), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, use unix seconds, RFC 3339 or YYYY-MM-DD", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Microsecond)
+	}
+	return t.UTC(), nil
+}
+
+// UnixOperationsTime converts a bound of the operations period in unix seconds, 0 is the zero time.
+func UnixOperationsTime(seconds int64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0).UTC()
+}
+
+// OperationsTotal is the number and the sum of operations. Repositories return the sum in Cents, so it isn't rounded,
+// Amount is set from it only for the response.
+type OperationsTotal struct {
+	Count  int64  `json:"count"`
+	Amount Amount `json:"amount"`
+	Cents  uint64 `json:"-"`
+}
+
+// OperationsTotals are the totals of operations by type.
+type OperationsTotals struct {
+	Deposits    OperationsTotal `json:"deposits"`
+	Withdrawals OperationsTotal `json:"withdrawals"`
+}
+
+// Net returns deposits minus withdrawals.
+func (t OperationsTotals) Net() Amount {
+	return t.Deposits.Amount - t.Withdrawals.Amount
+}
+
+// NetCents returns deposits minus withdrawals in cents.
+func (t OperationsTotals) NetCents() int64 {
+	return int64(t.Deposits.Cents) - int64(t.Withdrawals.Cents)
+}
+
+// SetAmounts sets the amounts of the totals from their cents.
+func (t *OperationsTotals) SetAmounts() {
+	t.Deposits.Amount.SetAmount(t.Deposits.Cents)
+	t.Withdrawals.Amount.SetAmount(t.Withdrawals.Cents)
+}
+
+// OperationsSummary describes the operations of the wallet in a period like a bank statement:
+// balances at the bounds of the period and the totals of the selected operations.
+type OperationsSummary struct {
+	Wallet   string `json:"wallet"`
+	Currency string `json:"currency"`
+	// StartDate and EndDate are the bounds of the period, zero StartDate is the beginning of the history.
+	StartDate      time.Time `json:"start_date"`
+	EndDate        time.Time `json:"end_date"`
+	OpeningBalance Amount    `json:"opening_balance"`
+	ClosingBalance Amount    `json:"closing_balance"`
+	OperationsTotals
 }
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"errors"
+	"time"
+)
+
+// StatementPeriodLayout is the layout of the statement period, a calendar month in UTC.
+const StatementPeriodLayout = "2006-01"
+
+// StatementSettleDelay is the time after the end of the month until its operations are final:
+// transactions started before the end of the month commit their operations by then.
+const StatementSettleDelay = time.Hour
+
+// ErrInvalidStatementPeriod is returned by ParseStatementPeriod for a period that isn't a month in YYYY-MM.
+var ErrInvalidStatementPeriod = errors.New("invalid period, use YYYY-MM")
+
+// Statement lists the operations of the wallet in a calendar month with the balances at its bounds.
+// EndDate of the current month is the time of generation, past months end with their last microsecond.
+type Statement struct {
+	Period string `json:"period"`
+	OperationsSummary
+	Entries     []StatementEntry `json:"entries"`
+	GeneratedAt time.Time        `json:"generated_at"`
+}
+
+// StatementEntry is an operation of the statement with the balance of the wallet after it.
+type StatementEntry struct {
+	Operation
+	Balance Amount `json:"balance"`
+}
+
+// ParseStatementPeriod parses the month in YYYY-MM and returns its inclusive bounds in UTC.
+func ParseStatementPeriod(period string) (start, end time.Time, err error) {
+	start, err = time.Parse(StatementPeriodLayout, period)
+	if err != nil || start.Year() < 1970 {
+		return time.Time{}, time.Time{}, ErrInvalidStatementPeriod
+	}
+	return start, start.AddDate(0, 1, 0).Add(-time.Microsecond), nil
+}
+
+// StatementSettled reports whether operations of the period ending at end can't change at now.
+func StatementSettled(end, now time.Time) bool {
+	return now.Sub(end) >= StatementSettleDelay
+}
+
+// LastSettledStatementPeriod returns the period of the last month settled at t in UTC.
+func LastSettledStatementPeriod(t time.Time) string {
+	t = t.UTC().Add(-StatementSettleDelay)
+	return time.Date(t.Year(), t.Month()-1, 1, 0, 0, 0, 0, time.UTC).Format(StatementPeriodLayout)
+}
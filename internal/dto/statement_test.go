@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatementSettled(t *testing.T) {
+	_, end, err := ParseStatementPeriod("2026-09")
+	assert.NoError(t, err)
+
+	assert.False(t, StatementSettled(end, time.Date(2026, 9, 30, 12, 0, 0, 0, time.UTC)))
+	assert.False(t, StatementSettled(end, time.Date(2026, 10, 1, 0, 30, 0, 0, time.UTC)))
+	assert.True(t, StatementSettled(end, time.Date(2026, 10, 1, 1, 0, 0, 0, time.UTC)))
+}
+
+func TestLastSettledStatementPeriod(t *testing.T) {
+	assert.Equal(t, "2026-08", LastSettledStatementPeriod(time.Date(2026, 10, 1, 0, 30, 0, 0, time.UTC)))
+	assert.Equal(t, "2026-09", LastSettledStatementPeriod(time.Date(2026, 10, 1, 1, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "2025-12", LastSettledStatementPeriod(time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)))
+}
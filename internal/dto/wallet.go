@@ -2,9 +2,17 @@ package dto
 
 type Wallet struct {
 	Name    string `json:"name"`
+	Owner   string `json:"owner,omitempty"`
 	Balance uint64 `json:"balance"`
 }
 
 type CreateWalletRequest struct {
-	Name string `json:"name"`
+	Name  string `json:"name"`
+	Owner string `json:"owner,omitempty"`
+}
+
+type WalletsFilter struct {
+	Owner  string
+	Limit  int64
+	Offset int64
 }
@@ -0,0 +1,172 @@
+package export
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+// Camt053 writes an ISO 20022 bank to customer statement camt.053.001.08 with an entry per operation.
+// The wallet is the account, the statement has the opening and the closing balances and the totals
+// of Options.Summary. It is selected only by name, because browsers accept application/xml for any page.
+var Camt053 = Format{
+	Name:        "camt053",
+	ContentType: "application/xml; charset=utf-8",
+	Extension:   "xml",
+	Summary:     true,
+	New:         newCamt053Encoder,
+}
+
+// ErrNoSummary is returned by formats with Summary set if Options.Summary doesn't bound the period.
+var ErrNoSummary = errors.New("the statement requires the summary of a period with the start date")
+
+type camt053Encoder struct {
+	w        *bufio.Writer
+	currency string
+}
+
+func newCamt053Encoder(w io.Writer, opts Options) (Encoder, error) {
+	summary := opts.Summary
+	if summary == nil || summary.StartDate.IsZero() {
+		return nil, ErrNoSummary
+	}
+	created := opts.Created
+	if created.IsZero() {
+		created = time.Now()
+	}
+	end := summary.EndDate
+	if end.IsZero() {
+		end = created
+	}
+	// Identifiers are limited to 35 characters.
+	id := fmt.Sprintf("WALLETS%d", created.UnixNano())
+
+	e := &camt053Encoder{w: bufio.NewWriter(w), currency: summary.Currency}
+	e.raw(xml.Header + `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"><BkToCstmrStmt>`)
+	e.raw("<GrpHdr>")
+	e.element("MsgId", id)
+	e.element("CreDtTm", FormatTime(created))
+	e.raw("</GrpHdr><Stmt>")
+	e.element("Id", id)
+	e.element("CreDtTm", FormatTime(created))
+	e.raw("<FrToDt>")
+	e.element("FrDtTm", FormatTime(summary.StartDate))
+	e.element("ToDtTm", FormatTime(end))
+	e.raw("</FrToDt><Acct><Id><Othr>")
+	e.element("Id", summary.Wallet)
+	e.raw("</Othr></Id>")
+	e.element("Ccy", summary.Currency)
+	e.raw("</Acct>")
+	e.balance("OPBD", summary.OpeningBalance, summary.StartDate)
+	e.balance("CLBD", summary.ClosingBalance, end)
+
+	e.raw("<TxsSummry><TtlNtries>")
+	e.element("NbOfNtries", fmt.Sprint(summary.Deposits.Count+summary.Withdrawals.Count))
+	e.element("Sum", FormatAmount(summary.Deposits.Amount+summary.Withdrawals.Amount))
+	e.raw("<TtlNetNtry>")
+	net := summary.Net()
+	e.element("Amt", FormatAmount(abs(net)))
+	e.element("CdtDbtInd", creditDebit(net >= 0))
+	e.raw("</TtlNetNtry></TtlNtries>")
+	e.total("TtlCdtNtries", summary.Deposits)
+	e.total("TtlDbtNtries", summary.Withdrawals)
+	e.raw("</TxsSummry>")
+
+	if err := e.w.Flush(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *camt053Encoder) Encode(op dto.Operation) error {
+	credit := op.Type == consts.OperationTypeDeposit
+	e.raw("<Ntry>")
+	e.element("NtryRef", fmt.Sprint(op.ID))
+	e.amount(op.Amount)
+	e.element("CdtDbtInd", creditDebit(credit))
+	e.raw("<Sts><Cd>BOOK</Cd></Sts><BookgDt>")
+	e.element("DtTm", FormatTime(op.Timestamp))
+	e.raw("</BookgDt><ValDt>")
+	e.element("DtTm", FormatTime(op.Timestamp))
+	e.raw("</ValDt><BkTxCd><Prtry>")
+	e.element("Cd", op.Type)
+	e.raw("</Prtry></BkTxCd>")
+	if op.OtherWallet != "" {
+		// The other wallet is the debtor of incoming funds and the creditor of outgoing ones.
+		party := "Cdtr"
+		if credit {
+			party = "Dbtr"
+		}
+		e.raw("<NtryDtls><TxDtls><RltdPties><" + party + "><Pty>")
+		e.element("Nm", op.OtherWallet)
+		e.raw("</Pty></" + party + "></RltdPties></TxDtls></NtryDtls>")
+	}
+	_, err := e.w.WriteString("</Ntry>")
+	return err
+}
+
+func (e *camt053Encoder) Flush() error {
+	return e.w.Flush()
+}
+
+func (e *camt053Encoder) Close() error {
+	e.raw("</Stmt></BkToCstmrStmt></Document>\n")
+	return e.w.Flush()
+}
+
+// raw writes the markup, write errors are returned by Flush and Close.
+func (e *camt053Encoder) raw(s string) {
+	_, _ = e.w.WriteString(s)
+}
+
+// element writes the element with the escaped text.
+func (e *camt053Encoder) element(name, text string) {
+	e.raw("<" + name + ">")
+	_ = xml.EscapeText(e.w, []byte(text))
+	e.raw("</" + name + ">")
+}
+
+func (e *camt053Encoder) amount(a dto.Amount) {
+	e.raw(`<Amt Ccy="`)
+	_ = xml.EscapeText(e.w, []byte(e.currency))
+	e.raw(`">` + FormatAmount(abs(a)) + "</Amt>")
+}
+
+func (e *camt053Encoder) balance(code string, a dto.Amount, t time.Time) {
+	e.raw("<Bal><Tp><CdOrPrtry>")
+	e.element("Cd", code)
+	e.raw("</CdOrPrtry></Tp>")
+	e.amount(a)
+	e.element("CdtDbtInd", creditDebit(a >= 0))
+	e.raw("<Dt>")
+	e.element("DtTm", FormatTime(t))
+	e.raw("</Dt></Bal>")
+}
+
+func (e *camt053Encoder) total(name string, total dto.OperationsTotal) {
+	e.raw("<" + name + ">")
+	e.element("NbOfNtries", fmt.Sprint(total.Count))
+	e.element("Sum", FormatAmount(total.Amount))
+	e.raw("</" + name + ">")
+}
+
+// creditDebit returns the ISO 20022 indicator of the direction of funds.
+func creditDebit(credit bool) string {
+	if credit {
+		return "CRDT"
+	}
+	return "DBIT"
+}
+
+func abs(a dto.Amount) dto.Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+func TestCamt053(t *testing.T) {
+	summary := &dto.OperationsSummary{
+		Wallet:         "wallet1",
+		Currency:       "EUR",
+		StartDate:      time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:        time.Date(2026, 9, 30, 23, 59, 59, 999999000, time.UTC),
+		OpeningBalance: 1500,
+		ClosingBalance: 500.29,
+		OperationsTotals: dto.OperationsTotals{
+			Deposits:    dto.OperationsTotal{Count: 1, Amount: 0.29},
+			Withdrawals: dto.OperationsTotal{Count: 1, Amount: 1000},
+		},
+	}
+	created := time.Date(2026, 10, 1, 3, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, Camt053, Options{Summary: summary, Created: created}, testOperations))
+
+	const want = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"><BkToCstmrStmt>` +
+		`<GrpHdr><MsgId>WALLETS1790823600000000000</MsgId><CreDtTm>2026-10-01T03:00:00Z</CreDtTm></GrpHdr>` +
+		`<Stmt><Id>WALLETS1790823600000000000</Id><CreDtTm>2026-10-01T03:00:00Z</CreDtTm>` +
+		`<FrToDt><FrDtTm>2026-09-01T00:00:00Z</FrDtTm><ToDtTm>2026-09-30T23:59:59.999999Z</ToDtTm></FrToDt>` +
+		`<Acct><Id><Othr><Id>wallet1</Id></Othr></Id><Ccy>EUR</Ccy></Acct>` +
+		`<Bal><Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp><Amt Ccy="EUR">1500.00</Amt><CdtDbtInd>CRDT</CdtDbtInd>` +
+		`<Dt><DtTm>2026-09-01T00:00:00Z</DtTm></Dt></Bal>` +
+		`<Bal><Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp><Amt Ccy="EUR">500.29</Amt><CdtDbtInd>CRDT</CdtDbtInd>` +
+		`<Dt><DtTm>2026-09-30T23:59:59.999999Z</DtTm></Dt></Bal>` +
+		`<TxsSummry><TtlNtries><NbOfNtries>2</NbOfNtries><Sum>1000.29</Sum>` +
+		`<TtlNetNtry><Amt>999.71</Amt><CdtDbtInd>DBIT</CdtDbtInd></TtlNetNtry></TtlNtries>` +
+		`<TtlCdtNtries><NbOfNtries>1</NbOfNtries><Sum>0.29</Sum></TtlCdtNtries>` +
+		`<TtlDbtNtries><NbOfNtries>1</NbOfNtries><Sum>1000.00</Sum></TtlDbtNtries></TxsSummry>` +
+		`<Ntry><NtryRef>1</NtryRef><Amt Ccy="EUR">0.29</Amt><CdtDbtInd>CRDT</CdtDbtInd><Sts><Cd>BOOK</Cd></Sts>` +
+		`<BookgDt><DtTm>2026-09-01T12:30:00Z</DtTm></BookgDt><ValDt><DtTm>2026-09-01T12:30:00Z</DtTm></ValDt>` +
+		`<BkTxCd><Prtry><Cd>deposit</Cd></Prtry></BkTxCd>` +
+		`<NtryDtls><TxDtls><RltdPties><Dbtr><Pty><Nm>wallet2</Nm></Pty></Dbtr></RltdPties></TxDtls></NtryDtls></Ntry>` +
+		`<Ntry><NtryRef>2</NtryRef><Amt Ccy="EUR">1000.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts><Cd>BOOK</Cd></Sts>` +
+		`<BookgDt><DtTm>2026-09-02T05:00:00.123456Z</DtTm></BookgDt><ValDt><DtTm>2026-09-02T05:00:00.123456Z</DtTm></ValDt>` +
+		`<BkTxCd><Prtry><Cd>withdrawal</Cd></Prtry></BkTxCd>` +
+		`<NtryDtls><TxDtls><RltdPties><Cdtr><Pty><Nm>shop; &#34;main&#34;</Nm></Pty></Cdtr></RltdPties></TxDtls></NtryDtls></Ntry>` +
+		`</Stmt></BkToCstmrStmt></Document>
+`
+	assert.Equal(t, want, buf.String())
+}
+
+func TestCamt053_NoSummary(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := Camt053.New(&buf, Options{})
+	assert.Equal(t, ErrNoSummary, err)
+
+	_, err = Camt053.New(&buf, Options{Summary: &dto.OperationsSummary{Wallet: "wallet1"}})
+	assert.Equal(t, ErrNoSummary, err)
+	assert.Zero(t, buf.Len())
+}
@@ -0,0 +1,51 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+// CSV writes a row per operation after the row of column names. Amounts have two decimals,
+// timestamps are RFC 3339 in UTC. Options.Delimiter and Options.BOM adapt the file to spreadsheets.
+var CSV = Format{
+	Name:        "csv",
+	MediaType:   "text/csv",
+	ContentType: "text/csv; charset=utf-8",
+	Extension:   "csv",
+	New:         newCSVEncoder,
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVEncoder(w io.Writer, opts Options) (Encoder, error) {
+	cw := csv.NewWriter(w)
+	if opts.Delimiter != 0 {
+		cw.Comma = opts.Delimiter
+	}
+	if opts.BOM {
+		if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+			return nil, err
+		}
+	}
+	if err := cw.Write(columns); err != nil {
+		return nil, err
+	}
+	return &csvEncoder{w: cw}, nil
+}
+
+func (e *csvEncoder) Encode(op dto.Operation) error {
+	return e.w.Write([]string{op.Wallet, FormatAmount(op.Amount), op.Type, op.OtherWallet, FormatTime(op.Timestamp)})
+}
+
+func (e *csvEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) Close() error {
+	return e.Flush()
+}
@@ -0,0 +1,185 @@
+// Package export contains encoders of operations in file formats. Formats are registered by name,
+// so HTTP and CLI exports select them by the format parameter or the Accept header.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+// Encoder writes operations one by one, so exports of any size aren't collected in memory.
+type Encoder interface {
+	// Encode writes the operation, it may be buffered until Flush or Close.
+	Encode(op dto.Operation) error
+	// Flush writes the buffered operations to the underlying writer as far as the format allows.
+	Flush() error
+	// Close writes the end of the file and flushes it, the underlying writer isn't closed.
+	Close() error
+}
+
+// Options configure encoders, each format uses only its own options.
+type Options struct {
+	// Delimiter separates CSV fields, a comma if zero.
+	Delimiter rune
+	// BOM starts CSV with the UTF-8 byte order mark, so spreadsheets detect the encoding.
+	BOM bool
+	// Summary is the statement of the exported period, it is required by formats with Summary set.
+	Summary *dto.OperationsSummary
+	// Created is the creation time of the file, the current time if zero.
+	Created time.Time
+}
+
+// Format describes an export format.
+type Format struct {
+	// Name selects the format by the format parameter, e.g. csv.
+	Name string
+	// MediaType selects the format by the Accept header, the format is selected only by name if it is empty.
+	MediaType string
+	// ContentType is the Content-Type of the response.
+	ContentType string
+	// Extension is the file name extension without the dot.
+	Extension string
+	// Summary tells that the encoder needs Options.Summary, so the exported period must be bounded.
+	Summary bool
+	// New creates the encoder writing to w, the beginning of the file is written at once.
+	New func(w io.Writer, opts Options) (Encoder, error)
+}
+
+// ErrInvalidDelimiter is returned by ParseDelimiter for a delimiter which can't separate CSV fields.
+var ErrInvalidDelimiter = errors.New("delimiter must be a single character other than a quote or a line break")
+
+// formats are the registered formats, the first one is the default.
+var formats = []Format{CSV, NDJSON, XLSX, Camt053}
+
+// Register adds the format, it must be called before serving requests. It panics if the name is taken.
+func Register(f Format) {
+	if _, ok := Lookup(f.Name); ok {
+		panic(fmt.Sprintf("export format %s is already registered", f.Name))
+	}
+	formats = append(formats, f)
+}
+
+// Lookup returns the format by name.
+func Lookup(name string) (Format, bool) {
+	for _, f := range formats {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// Names returns the names of the registered formats.
+func Names() []string {
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// Negotiate selects the format by the Accept header, preferring media types with higher quality.
+// An empty header or */* selects the default format, false is returned if no format is acceptable.
+func Negotiate(accept string) (Format, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return formats[0], true
+	}
+
+	type acceptable struct {
+		mediaType string
+		q         float64
+	}
+	var ranges []acceptable
+	excluded := make(map[string]bool)
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			excluded[mediaType] = true
+			continue
+		}
+		ranges = append(ranges, acceptable{mediaType: mediaType, q: q})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	for _, r := range ranges {
+		for _, f := range formats {
+			if f.MediaType != "" && !excluded[f.MediaType] && matchMediaType(r.mediaType, f.MediaType) {
+				return f, true
+			}
+		}
+	}
+	return Format{}, false
+}
+
+// matchMediaType reports whether the media range of the Accept header, e.g. text/*, includes the media type.
+func matchMediaType(mediaRange, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(mediaRange, "*")
+	return ok && strings.HasSuffix(prefix, "/") && strings.HasPrefix(mediaType, prefix)
+}
+
+// ParseDelimiter parses the CSV delimiter, a single character or "tab".
+func ParseDelimiter(s string) (rune, error) {
+	if s == "tab" {
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 || size != len(s) || r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' {
+		return 0, ErrInvalidDelimiter
+	}
+	return r, nil
+}
+
+// Write encodes the operations in the format, it is used for pages which are already in memory.
+func Write(w io.Writer, format Format, opts Options, operations []dto.Operation) error {
+	enc, err := format.New(w, opts)
+	if err != nil {
+		return err
+	}
+	for _, op := range operations {
+		if err := enc.Encode(op); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+// columns are the names of the fields of operations in tabular formats.
+var columns = []string{"wallet", "amount", "type", "other_wallet", "timestamp"}
+
+// FormatAmount formats the amount with two decimals. Amounts are rounded to cents,
+// because float64 doesn't hold most of them exactly, e.g. 0.29*100 is 28.999999999999996.
+func FormatAmount(a dto.Amount) string {
+	cents := int64(math.Round(float64(a) * 100))
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// FormatTime formats the time in RFC 3339 in UTC with the fraction of a second if it isn't zero.
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,134 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+var testOperations = []dto.Operation{
+	{ID: 1, Wallet: "wallet1", Amount: 0.29, Type: consts.OperationTypeDeposit, OtherWallet: "wallet2",
+		Timestamp: time.Date(2026, 9, 1, 12, 30, 0, 0, time.UTC)},
+	{ID: 2, Wallet: "wallet1", Amount: 1000, Type: consts.OperationTypeWithdrawal, OtherWallet: "shop; \"main\"",
+		Timestamp: time.Date(2026, 9, 2, 8, 0, 0, 123456000, time.FixedZone("UTC+3", 3*60*60))},
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+		ok     bool
+	}{
+		{name: "no header", accept: "", want: "csv", ok: true},
+		{name: "any", accept: "*/*", want: "csv", ok: true},
+		{name: "exact", accept: "application/x-ndjson", want: "ndjson", ok: true},
+		{name: "quality", accept: "text/csv;q=0.5, application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", want: "xlsx", ok: true},
+		{name: "subtype range", accept: "application/json, text/*", want: "csv", ok: true},
+		{name: "excluded", accept: "text/csv;q=0, */*;q=0.1", want: "ndjson", ok: true},
+		{name: "browser", accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", want: "csv", ok: true},
+		{name: "xml isn't camt053", accept: "application/xml"},
+		{name: "not acceptable", accept: "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, ok := Negotiate(tt.accept)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, format.Name)
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	format, ok := Lookup("camt053")
+	assert.True(t, ok)
+	assert.Equal(t, "xml", format.Extension)
+
+	_, ok = Lookup("pdf")
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"csv", "ndjson", "xlsx", "camt053"}, Names())
+	assert.Panics(t, func() { Register(Format{Name: "csv"}) })
+}
+
+func TestParseDelimiter(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    rune
+		wantErr error
+	}{
+		{value: ";", want: ';'},
+		{value: "\t", want: '\t'},
+		{value: "tab", want: '\t'},
+		{value: "|", want: '|'},
+		{value: "", wantErr: ErrInvalidDelimiter},
+		{value: ";;", wantErr: ErrInvalidDelimiter},
+		{value: `"`, wantErr: ErrInvalidDelimiter},
+		{value: "\n", wantErr: ErrInvalidDelimiter},
+		{value: "\xff", wantErr: ErrInvalidDelimiter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDelimiter(tt.value)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	for amount, want := range map[dto.Amount]string{
+		0:       "0.00",
+		0.29:    "0.29",
+		1.5:     "1.50",
+		1000:    "1000.00",
+		-2.05:   "-2.05",
+		0.00001: "0.00",
+	} {
+		assert.Equal(t, want, FormatAmount(amount))
+	}
+}
+
+func TestCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "default",
+			want: "wallet,amount,type,other_wallet,timestamp\n" +
+				"wallet1,0.29,deposit,wallet2,2026-09-01T12:30:00Z\n" +
+				"wallet1,1000.00,withdrawal,\"shop; \"\"main\"\"\",2026-09-02T05:00:00.123456Z\n",
+		},
+		{
+			name: "delimiter and BOM",
+			opts: Options{Delimiter: ';', BOM: true},
+			want: "\uFEFFwallet;amount;type;other_wallet;timestamp\n" +
+				"wallet1;0.29;deposit;wallet2;2026-09-01T12:30:00Z\n" +
+				"wallet1;1000.00;withdrawal;\"shop; \"\"main\"\"\";2026-09-02T05:00:00.123456Z\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Write(&buf, CSV, tt.opts, testOperations))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, NDJSON, Options{}, testOperations))
+	assert.Equal(t,
+		`{"wallet":"wallet1","amount":0.29,"type":"deposit","other_wallet":"wallet2","timestamp":"2026-09-01T12:30:00Z"}`+"\n"+
+			`{"wallet":"wallet1","amount":1000.00,"type":"withdrawal","other_wallet":"shop; \"main\"","timestamp":"2026-09-02T05:00:00.123456Z"}`+"\n",
+		buf.String())
+}
@@ -0,0 +1,55 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+// NDJSON writes a JSON object per line with the fields of the CSV columns,
+// amounts are numbers with two decimals.
+var NDJSON = Format{
+	Name:        "ndjson",
+	MediaType:   "application/x-ndjson",
+	ContentType: "application/x-ndjson",
+	Extension:   "ndjson",
+	New:         newNDJSONEncoder,
+}
+
+type ndjsonOperation struct {
+	Wallet      string      `json:"wallet"`
+	Amount      json.Number `json:"amount"`
+	Type        string      `json:"type"`
+	OtherWallet string      `json:"other_wallet"`
+	Timestamp   string      `json:"timestamp"`
+}
+
+type ndjsonEncoder struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func newNDJSONEncoder(w io.Writer, _ Options) (Encoder, error) {
+	bw := bufio.NewWriter(w)
+	return &ndjsonEncoder{w: bw, enc: json.NewEncoder(bw)}, nil
+}
+
+func (e *ndjsonEncoder) Encode(op dto.Operation) error {
+	return e.enc.Encode(ndjsonOperation{
+		Wallet:      op.Wallet,
+		Amount:      json.Number(FormatAmount(op.Amount)),
+		Type:        op.Type,
+		OtherWallet: op.OtherWallet,
+		Timestamp:   FormatTime(op.Timestamp),
+	})
+}
+
+func (e *ndjsonEncoder) Flush() error {
+	return e.w.Flush()
+}
+
+func (e *ndjsonEncoder) Close() error {
+	return e.w.Flush()
+}
@@ -0,0 +1,226 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+// XLSX writes an Office Open XML workbook with sheets of operations. Amounts are numeric cells
+// with two decimals and timestamps are date cells in UTC, so spreadsheets sort and sum them.
+// Sheets are streamed into the zip archive, they aren't collected in memory. A sheet holds
+// at most 1048576 rows, the limit of spreadsheets, further operations continue in the next sheet.
+var XLSX = Format{
+	Name:        "xlsx",
+	MediaType:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	Extension:   "xlsx",
+	New:         newXLSXEncoder,
+}
+
+// Indexes of cell formats in xlsxStyles.
+const (
+	xlsxStyleHeader = 1
+	xlsxStyleAmount = 2
+	xlsxStyleTime   = 3
+)
+
+// xlsxEpoch is the day 0 of dates in spreadsheets, it accounts for the nonexistent 1900-02-29.
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxSheetRowsMax is the number of rows in a sheet spreadsheets open, including the header.
+// Operations beyond it continue in the next sheet. It is a variable, so tests lower it.
+var xlsxSheetRowsMax = 1 << 20
+
+// xlsxPart is a part of the workbook archive.
+type xlsxPart struct {
+	name    string
+	content string
+}
+
+// xlsxParts returns the parts of the workbook other than the sheets, they list the sheets.
+func xlsxParts(sheets int) []xlsxPart {
+	var types, names, rels strings.Builder
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" `+
+			`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+		name := "Operations"
+		if i > 1 {
+			name += " " + strconv.Itoa(i)
+		}
+		fmt.Fprintf(&names, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, name, i, i)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" `+
+			`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+
+	return []xlsxPart{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			types.String() +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + names.String() + `</sheets>` +
+			`</workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() +
+			fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheets+1) +
+			`</Relationships>`},
+		{"xl/styles.xml", xlsxStyles},
+	}
+}
+
+// xlsxStyles defines the cell formats: default, bold header, amount with two decimals and date with time.
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="2" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
+
+type xlsxEncoder struct {
+	zip     *zip.Writer
+	w       *bufio.Writer
+	sheets  int // number of started sheets
+	row     int // last written row of the current sheet
+	created time.Time
+}
+
+func newXLSXEncoder(w io.Writer, opts Options) (Encoder, error) {
+	e := &xlsxEncoder{zip: zip.NewWriter(w), created: opts.Created}
+	if e.created.IsZero() {
+		e.created = time.Now()
+	}
+	// Sheets are written first, the parts listing them are written by Close when their number is known.
+	if err := e.startSheet(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *xlsxEncoder) Encode(op dto.Operation) error {
+	if e.row == xlsxSheetRowsMax {
+		if err := e.endSheet(); err != nil {
+			return err
+		}
+		if err := e.startSheet(); err != nil {
+			return err
+		}
+	}
+
+	e.startRow()
+	e.stringCell(0, op.Wallet, 0)
+	e.cell(1, FormatAmount(op.Amount), xlsxStyleAmount)
+	e.stringCell(2, op.Type, 0)
+	e.stringCell(3, op.OtherWallet, 0)
+	e.cell(4, strconv.FormatFloat(xlsxDate(op.Timestamp), 'f', -1, 64), xlsxStyleTime)
+	_, err := e.w.WriteString("</row>")
+	return err
+}
+
+// Flush writes the buffered rows to the archive, the compressor may still hold a part of them.
+func (e *xlsxEncoder) Flush() error {
+	if err := e.w.Flush(); err != nil {
+		return err
+	}
+	return e.zip.Flush()
+}
+
+func (e *xlsxEncoder) Close() error {
+	if err := e.endSheet(); err != nil {
+		return err
+	}
+	for _, part := range xlsxParts(e.sheets) {
+		pw, err := e.create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(pw, part.content); err != nil {
+			return err
+		}
+	}
+	return e.zip.Close()
+}
+
+// startSheet starts the next sheet with the header row, rows are written into it until endSheet.
+func (e *xlsxEncoder) startSheet() error {
+	e.sheets++
+	e.row = 0
+	sw, err := e.create(fmt.Sprintf("xl/worksheets/sheet%d.xml", e.sheets))
+	if err != nil {
+		return err
+	}
+	e.w = bufio.NewWriter(sw)
+	_, _ = e.w.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>` +
+		`<cols><col min="1" max="5" width="20" customWidth="1"/></cols>` +
+		`<sheetData>`)
+
+	e.startRow()
+	for i, name := range columns {
+		e.stringCell(i, name, xlsxStyleHeader)
+	}
+	_, err = e.w.WriteString("</row>")
+	return err
+}
+
+// endSheet writes the end of the current sheet and flushes it into the archive.
+func (e *xlsxEncoder) endSheet() error {
+	if _, err := e.w.WriteString("</sheetData></worksheet>"); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+// create starts the compressed part of the archive, modified at the creation time of the file.
+func (e *xlsxEncoder) create(name string) (io.Writer, error) {
+	return e.zip.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: e.created})
+}
+
+func (e *xlsxEncoder) startRow() {
+	e.row++
+	_, _ = fmt.Fprintf(e.w, `<row r="%d">`, e.row)
+}
+
+// cell writes the numeric cell in the column of the current row, write errors are returned by Flush and Close.
+func (e *xlsxEncoder) cell(column int, value string, style int) {
+	_, _ = fmt.Fprintf(e.w, `<c r="%c%d" s="%d"><v>%s</v></c>`, 'A'+column, e.row, style, value)
+}
+
+// stringCell writes the inline string cell, so the workbook doesn't need the shared strings table.
+func (e *xlsxEncoder) stringCell(column int, value string, style int) {
+	_, _ = fmt.Fprintf(e.w, `<c r="%c%d" s="%d" t="inlineStr"><is><t xml:space="preserve">`, 'A'+column, e.row, style)
+	_ = xml.EscapeText(e.w, []byte(value))
+	_, _ = e.w.WriteString("</t></is></c>")
+}
+
+// xlsxDate converts the time to the serial date of spreadsheets: days since xlsxEpoch in UTC.
+func xlsxDate(t time.Time) float64 {
+	return float64(t.Sub(xlsxEpoch)) / float64(24*time.Hour)
+}
@@ -0,0 +1,132 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, XLSX, Options{}, testOperations))
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	parts := make(map[string][]byte)
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		parts[f.Name] = data
+
+		// Every part is well-formed XML.
+		dec := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			_, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err, f.Name)
+		}
+	}
+	assert.Len(t, parts, 6)
+
+	var sheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				R      string `xml:"r,attr"`
+				S      int    `xml:"s,attr"`
+				T      string `xml:"t,attr"`
+				V      string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	require.NoError(t, xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet))
+	require.Len(t, sheet.Rows, 3)
+
+	header := sheet.Rows[0].Cells
+	require.Len(t, header, 5)
+	assert.Equal(t, "A1", header[0].R)
+	assert.Equal(t, "wallet", header[0].Inline)
+	assert.Equal(t, xlsxStyleHeader, header[0].S)
+
+	row := sheet.Rows[2].Cells
+	require.Len(t, row, 5)
+	assert.Equal(t, 3, sheet.Rows[2].R)
+	assert.Equal(t, "inlineStr", row[0].T)
+	assert.Equal(t, "wallet1", row[0].Inline)
+	assert.Equal(t, "B3", row[1].R)
+	assert.Equal(t, "", row[1].T)
+	assert.Equal(t, "1000.00", row[1].V)
+	assert.Equal(t, xlsxStyleAmount, row[1].S)
+	assert.Equal(t, `shop; "main"`, row[3].Inline)
+	assert.Equal(t, xlsxStyleTime, row[4].S)
+	assert.Equal(t, "46267.208334762225", row[4].V)
+}
+
+func TestXLSXDate(t *testing.T) {
+	assert.Equal(t, 1.0, xlsxDate(time.Date(1899, 12, 31, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 45658.5, xlsxDate(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 45658.5, xlsxDate(time.Date(2025, 1, 1, 15, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60))))
+}
+
+func TestXLSX_sheetRowsMax(t *testing.T) {
+	defer func(rows int) { xlsxSheetRowsMax = rows }(xlsxSheetRowsMax)
+	xlsxSheetRowsMax = 2 // the header and an operation
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, XLSX, Options{}, testOperations))
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	parts := make(map[string][]byte)
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		parts[f.Name], err = io.ReadAll(r)
+		require.NoError(t, err)
+	}
+	assert.Len(t, parts, 7)
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	require.NoError(t, xml.Unmarshal(parts["xl/workbook.xml"], &workbook))
+	require.Len(t, workbook.Sheets, 2)
+	assert.Equal(t, "Operations", workbook.Sheets[0].Name)
+	assert.Equal(t, "Operations 2", workbook.Sheets[1].Name)
+	assert.Equal(t, "rId2", workbook.Sheets[1].ID)
+	assert.Contains(t, string(parts["xl/_rels/workbook.xml.rels"]), `Id="rId2" `+
+		`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet2.xml"`)
+	assert.Contains(t, string(parts["[Content_Types].xml"]), `PartName="/xl/worksheets/sheet2.xml"`)
+
+	for i, otherWallet := range []string{"wallet2", `shop; "main"`} {
+		var sheet struct {
+			Rows []struct {
+				R     int `xml:"r,attr"`
+				Cells []struct {
+					Inline string `xml:"is>t"`
+				} `xml:"c"`
+			} `xml:"sheetData>row"`
+		}
+		name := fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1)
+		require.NoError(t, xml.Unmarshal(parts[name], &sheet), name)
+		require.Len(t, sheet.Rows, 2, name)
+		assert.Equal(t, "wallet", sheet.Rows[0].Cells[0].Inline, name)
+		assert.Equal(t, 2, sheet.Rows[1].R, name)
+		assert.Equal(t, otherWallet, sheet.Rows[1].Cells[3].Inline, name)
+	}
+}
@@ -0,0 +1,24 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+//go:generate mockgen -source=dependencies.go -destination=mocks/service_mock.go -package=mocks
+
+// Service describes the service methods required for the server.
+type Service interface {
+	CreateWallet(context.Context, dto.CreateWalletRequest) error
+	GetWallet(ctx context.Context, walletName string) (*dto.Wallet, error)
+	IncreaseWalletBalance(context.Context, dto.Deposit) error
+	Transfer(context.Context, dto.Transfer) error
+	GetOperations(context.Context, dto.OperationsFilter) ([]dto.Operation, error)
+}
+
+// Authenticator describes the validation of credentials passed with requests.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*auth.Caller, error)
+}
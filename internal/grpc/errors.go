@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/httperr"
+)
+
+// errWrongLimit is returned for a limit of operations above the maximum page size of the HTTP API.
+var errWrongLimit = httperr.New(http.StatusBadRequest, "wrong limit, it have to be in [1, %d]", consts.OperationsLimitMax)
+
+// httpToGRPCCodes maps HTTP status codes used by the service to gRPC codes.
+var httpToGRPCCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.AlreadyExists,
+	http.StatusUnprocessableEntity: codes.FailedPrecondition,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusInternalServerError: codes.Internal,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+}
+
+// toStatus converts error returned by the service to gRPC status error.
+func (s *Server) toStatus(err error) error {
+	s.log.Error(err.Error())
+
+	if e, ok := err.(*httperr.Error); ok {
+		code, ok := httpToGRPCCodes[e.StatusCode]
+		if !ok {
+			code = codes.Unknown
+		}
+		return status.Error(code, e.Message)
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,148 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/grpc/pb"
+)
+
+func (s *Server) CreateWallet(ctx context.Context, req *pb.CreateWalletRequest) (*pb.CreateWalletResponse, error) {
+	err := s.svc.CreateWallet(ctx, dto.CreateWalletRequest{Name: req.GetName()})
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	return &pb.CreateWalletResponse{}, nil
+}
+
+func (s *Server) GetWallet(ctx context.Context, req *pb.GetWalletRequest) (*pb.Wallet, error) {
+	wallet, err := s.svc.GetWallet(ctx, req.GetName())
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	var balance dto.Amount
+	balance.SetAmount(wallet.Balance)
+
+	return &pb.Wallet{
+		Name:    wallet.Name,
+		Balance: float64(balance),
+	}, nil
+}
+
+func (s *Server) Deposit(ctx context.Context, req *pb.DepositRequest) (*pb.DepositResponse, error) {
+	err := s.svc.IncreaseWalletBalance(ctx, dto.Deposit{
+		Wallet: req.GetWallet(),
+		Amount: dto.Amount(req.GetAmount()),
+	})
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	return &pb.DepositResponse{}, nil
+}
+
+func (s *Server) Transfer(ctx context.Context, req *pb.TransferRequest) (*pb.TransferResponse, error) {
+	err := s.svc.Transfer(ctx, dto.Transfer{
+		WalletFrom: req.GetWalletFrom(),
+		WalletTo:   req.GetWalletTo(),
+		Amount:     dto.Amount(req.GetAmount()),
+	})
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	return &pb.TransferResponse{}, nil
+}
+
+// GetOperations provides a page of operations, the limit is at most consts.OperationsLimitMax like in the HTTP API.
+func (s *Server) GetOperations(ctx context.Context, req *pb.GetOperationsRequest) (*pb.GetOperationsResponse, error) {
+	if req.GetLimit() > consts.OperationsLimitMax {
+		return nil, s.toStatus(errWrongLimit)
+	}
+
+	operations, err := s.svc.GetOperations(ctx, operationsFilter(req))
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	resp := &pb.GetOperationsResponse{
+		Operations: make([]*pb.Operation, len(operations)),
+	}
+	for i := range operations {
+		resp.Operations[i] = convertOperation(operations[i])
+	}
+
+	return resp, nil
+}
+
+// StreamOperations reads operations page by page and sends them until the filter is exhausted.
+// The limit of the request restricts the total number of operations sent, zero means no restriction.
+func (s *Server) StreamOperations(req *pb.GetOperationsRequest, stream pb.Wallets_StreamOperationsServer) error {
+	filter := operationsFilter(req)
+	remaining := filter.Limit
+
+	for {
+		filter.Limit = consts.OperationsLimitMax
+		if remaining > 0 && remaining < filter.Limit {
+			filter.Limit = remaining
+		}
+
+		operations, err := s.svc.GetOperations(stream.Context(), filter)
+		if err != nil {
+			return s.toStatus(err)
+		}
+
+		for i := range operations {
+			if err := stream.Send(convertOperation(operations[i])); err != nil {
+				return err
+			}
+		}
+
+		if remaining > 0 {
+			remaining -= int64(len(operations))
+			if remaining == 0 {
+				return nil
+			}
+		}
+		if int64(len(operations)) < filter.Limit {
+			return nil
+		}
+		if err := stream.Context().Err(); err != nil {
+			return err
+		}
+
+		// The next pages start after the last sent operation, so concurrent inserts don't shift them.
+		last := operations[len(operations)-1]
+		filter.Cursor = &dto.OperationsCursor{CreatedAt: last.Timestamp, ID: last.ID}
+		filter.Offset = 0
+	}
+}
+
+func operationsFilter(req *pb.GetOperationsRequest) dto.OperationsFilter {
+	filter := dto.OperationsFilter{
+		Wallet:    req.GetWallet(),
+		StartDate: dto.UnixOperationsTime(req.GetStartDate()),
+		EndDate:   dto.UnixOperationsTime(req.GetEndDate()),
+		Limit:     req.GetLimit(),
+		Offset:    req.GetOffset(),
+	}
+	if req.GetType() != "" {
+		filter.Types = []string{req.GetType()}
+	}
+	return filter
+}
+
+func convertOperation(op dto.Operation) *pb.Operation {
+	return &pb.Operation{
+		Wallet:      op.Wallet,
+		Amount:      float64(op.Amount),
+		Type:        op.Type,
+		OtherWallet: op.OtherWallet,
+		Timestamp:   timestamppb.New(op.Timestamp),
+	}
+}
@@ -0,0 +1,98 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/ezhdanovskiy/wallets/internal/audit"
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/consistency"
+)
+
+// auditSourceUnary puts the source of the call into the context, it is recorded in the audit log.
+// Streaming calls only read data, so they are not audited.
+func (s *Server) auditSourceUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	source := audit.Source{
+		RequestID: firstValue(md, "x-request-id"),
+		Endpoint:  info.FullMethod,
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		source.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(source.IP); err == nil {
+			source.IP = host
+		}
+	}
+	return handler(audit.NewContext(ctx, source), req)
+}
+
+// readConsistencyUnary makes reads of the call go to the primary database if x-consistency metadata is strong.
+func readConsistencyUnary(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return handler(consistency.FromHeader(ctx, firstValue(md, strings.ToLower(consistency.Header))), req)
+}
+
+func readConsistencyStream(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	md, _ := metadata.FromIncomingContext(ss.Context())
+	ctx := consistency.FromHeader(ss.Context(), firstValue(md, strings.ToLower(consistency.Header)))
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+func (s *Server) authenticateUnary(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) authenticateStream(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticate checks credentials from the request metadata and puts the caller into the context.
+// The key is read from x-api-key or from authorization with Bearer scheme, like HTTP headers.
+func (s *Server) authenticate(ctx context.Context) (context.Context, error) {
+	if s.auth == nil {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	token := firstValue(md, "x-api-key")
+	if token == "" {
+		token = auth.BearerToken(firstValue(md, "authorization"))
+	}
+
+	caller, err := s.auth.Authenticate(ctx, token)
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	return auth.NewContext(ctx, caller), nil
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// serverStream overrides the context of the wrapped stream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
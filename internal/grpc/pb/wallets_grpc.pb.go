@@ -0,0 +1,331 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: wallets.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Wallets_CreateWallet_FullMethodName     = "/wallets.v1.Wallets/CreateWallet"
+	Wallets_Deposit_FullMethodName          = "/wallets.v1.Wallets/Deposit"
+	Wallets_Transfer_FullMethodName         = "/wallets.v1.Wallets/Transfer"
+	Wallets_GetOperations_FullMethodName    = "/wallets.v1.Wallets/GetOperations"
+	Wallets_StreamOperations_FullMethodName = "/wallets.v1.Wallets/StreamOperations"
+	Wallets_GetWallet_FullMethodName        = "/wallets.v1.Wallets/GetWallet"
+)
+
+// WalletsClient is the client API for Wallets service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Wallets mirrors the REST API described in swagger.yaml.
+type WalletsClient interface {
+	// CreateWallet adds wallet with unique name.
+	CreateWallet(ctx context.Context, in *CreateWalletRequest, opts ...grpc.CallOption) (*CreateWalletResponse, error)
+	// Deposit increases the wallet balance by a certain amount.
+	Deposit(ctx context.Context, in *DepositRequest, opts ...grpc.CallOption) (*DepositResponse, error)
+	// Transfer moves money from one wallet to another within the available balance.
+	Transfer(ctx context.Context, in *TransferRequest, opts ...grpc.CallOption) (*TransferResponse, error)
+	// GetOperations returns one page of wallet operations using filter.
+	GetOperations(ctx context.Context, in *GetOperationsRequest, opts ...grpc.CallOption) (*GetOperationsResponse, error)
+	// StreamOperations sends all wallet operations matching filter one by one.
+	StreamOperations(ctx context.Context, in *GetOperationsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Operation], error)
+	// GetWallet returns wallet with its current balance.
+	GetWallet(ctx context.Context, in *GetWalletRequest, opts ...grpc.CallOption) (*Wallet, error)
+}
+
+type walletsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWalletsClient(cc grpc.ClientConnInterface) WalletsClient {
+	return &walletsClient{cc}
+}
+
+func (c *walletsClient) CreateWallet(ctx context.Context, in *CreateWalletRequest, opts ...grpc.CallOption) (*CreateWalletResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateWalletResponse)
+	err := c.cc.Invoke(ctx, Wallets_CreateWallet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletsClient) Deposit(ctx context.Context, in *DepositRequest, opts ...grpc.CallOption) (*DepositResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DepositResponse)
+	err := c.cc.Invoke(ctx, Wallets_Deposit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletsClient) Transfer(ctx context.Context, in *TransferRequest, opts ...grpc.CallOption) (*TransferResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransferResponse)
+	err := c.cc.Invoke(ctx, Wallets_Transfer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletsClient) GetOperations(ctx context.Context, in *GetOperationsRequest, opts ...grpc.CallOption) (*GetOperationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetOperationsResponse)
+	err := c.cc.Invoke(ctx, Wallets_GetOperations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletsClient) StreamOperations(ctx context.Context, in *GetOperationsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Operation], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Wallets_ServiceDesc.Streams[0], Wallets_StreamOperations_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetOperationsRequest, Operation]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Wallets_StreamOperationsClient = grpc.ServerStreamingClient[Operation]
+
+func (c *walletsClient) GetWallet(ctx context.Context, in *GetWalletRequest, opts ...grpc.CallOption) (*Wallet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Wallet)
+	err := c.cc.Invoke(ctx, Wallets_GetWallet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WalletsServer is the server API for Wallets service.
+// All implementations must embed UnimplementedWalletsServer
+// for forward compatibility.
+//
+// Wallets mirrors the REST API described in swagger.yaml.
+type WalletsServer interface {
+	// CreateWallet adds wallet with unique name.
+	CreateWallet(context.Context, *CreateWalletRequest) (*CreateWalletResponse, error)
+	// Deposit increases the wallet balance by a certain amount.
+	Deposit(context.Context, *DepositRequest) (*DepositResponse, error)
+	// Transfer moves money from one wallet to another within the available balance.
+	Transfer(context.Context, *TransferRequest) (*TransferResponse, error)
+	// GetOperations returns one page of wallet operations using filter.
+	GetOperations(context.Context, *GetOperationsRequest) (*GetOperationsResponse, error)
+	// StreamOperations sends all wallet operations matching filter one by one.
+	StreamOperations(*GetOperationsRequest, grpc.ServerStreamingServer[Operation]) error
+	// GetWallet returns wallet with its current balance.
+	GetWallet(context.Context, *GetWalletRequest) (*Wallet, error)
+	mustEmbedUnimplementedWalletsServer()
+}
+
+// UnimplementedWalletsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWalletsServer struct{}
+
+func (UnimplementedWalletsServer) CreateWallet(context.Context, *CreateWalletRequest) (*CreateWalletResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWallet not implemented")
+}
+func (UnimplementedWalletsServer) Deposit(context.Context, *DepositRequest) (*DepositResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Deposit not implemented")
+}
+func (UnimplementedWalletsServer) Transfer(context.Context, *TransferRequest) (*TransferResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transfer not implemented")
+}
+func (UnimplementedWalletsServer) GetOperations(context.Context, *GetOperationsRequest) (*GetOperationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOperations not implemented")
+}
+func (UnimplementedWalletsServer) StreamOperations(*GetOperationsRequest, grpc.ServerStreamingServer[Operation]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamOperations not implemented")
+}
+func (UnimplementedWalletsServer) GetWallet(context.Context, *GetWalletRequest) (*Wallet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWallet not implemented")
+}
+func (UnimplementedWalletsServer) mustEmbedUnimplementedWalletsServer() {}
+func (UnimplementedWalletsServer) testEmbeddedByValue()                 {}
+
+// UnsafeWalletsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WalletsServer will
+// result in compilation errors.
+type UnsafeWalletsServer interface {
+	mustEmbedUnimplementedWalletsServer()
+}
+
+func RegisterWalletsServer(s grpc.ServiceRegistrar, srv WalletsServer) {
+	// If the following call pancis, it indicates UnimplementedWalletsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Wallets_ServiceDesc, srv)
+}
+
+func _Wallets_CreateWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletsServer).CreateWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Wallets_CreateWallet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletsServer).CreateWallet(ctx, req.(*CreateWalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Wallets_Deposit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DepositRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletsServer).Deposit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Wallets_Deposit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletsServer).Deposit(ctx, req.(*DepositRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Wallets_Transfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletsServer).Transfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Wallets_Transfer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletsServer).Transfer(ctx, req.(*TransferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Wallets_GetOperations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOperationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletsServer).GetOperations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Wallets_GetOperations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletsServer).GetOperations(ctx, req.(*GetOperationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Wallets_StreamOperations_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetOperationsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WalletsServer).StreamOperations(m, &grpc.GenericServerStream[GetOperationsRequest, Operation]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Wallets_StreamOperationsServer = grpc.ServerStreamingServer[Operation]
+
+func _Wallets_GetWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletsServer).GetWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Wallets_GetWallet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletsServer).GetWallet(ctx, req.(*GetWalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Wallets_ServiceDesc is the grpc.ServiceDesc for Wallets service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Wallets_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "wallets.v1.Wallets",
+	HandlerType: (*WalletsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateWallet",
+			Handler:    _Wallets_CreateWallet_Handler,
+		},
+		{
+			MethodName: "Deposit",
+			Handler:    _Wallets_Deposit_Handler,
+		},
+		{
+			MethodName: "Transfer",
+			Handler:    _Wallets_Transfer_Handler,
+		},
+		{
+			MethodName: "GetOperations",
+			Handler:    _Wallets_GetOperations_Handler,
+		},
+		{
+			MethodName: "GetWallet",
+			Handler:    _Wallets_GetWallet_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamOperations",
+			Handler:       _Wallets_StreamOperations_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "wallets.proto",
+}
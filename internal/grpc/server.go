@@ -0,0 +1,81 @@
+// Package grpc contains the gRPC server and associated method handlers.
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/ezhdanovskiy/wallets/internal/grpc/pb"
+)
+
+// Server serves the wallets gRPC API on top of the service.
+type Server struct {
+	pb.UnimplementedWalletsServer
+
+	log        *zap.SugaredLogger
+	grpcPort   int
+	grpcServer *grpc.Server
+	svc        Service
+	auth       Authenticator
+}
+
+// NewServer creates gRPC server listening on grpcPort, authentication is disabled if authenticator is nil.
+func NewServer(logger *zap.SugaredLogger, grpcPort int, svc Service, authenticator Authenticator) *Server {
+	s := &Server{
+		log:      logger,
+		grpcPort: grpcPort,
+		svc:      svc,
+		auth:     authenticator,
+	}
+
+	s.grpcServer = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.tracedUnary, readConsistencyUnary, s.auditSourceUnary, s.authenticateUnary),
+		grpc.ChainStreamInterceptor(s.tracedStream, readConsistencyStream, s.authenticateStream),
+	)
+	pb.RegisterWalletsServer(s.grpcServer, s)
+
+	return s
+}
+
+// Run listens on the gRPC port and serves calls until Shutdown is called.
+func (s *Server) Run() error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.grpcPort))
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	return s.Serve(lis)
+}
+
+// Serve accepts incoming connections on the listener until Shutdown is called.
+func (s *Server) Serve(lis net.Listener) error {
+	err := s.grpcServer.Serve(lis)
+	if err != nil && err != grpc.ErrServerStopped {
+		return fmt.Errorf("start grpc server: %w", err)
+	}
+
+	return nil
+}
+
+// Shutdown stops accepting new connections and waits for in-flight calls until ctx is done,
+// then stops the server forcibly.
+func (s *Server) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		<-stopped
+		return fmt.Errorf("grpc server shutdown: %w", ctx.Err())
+	}
+}
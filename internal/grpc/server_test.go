@@ -0,0 +1,429 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/grpc/mocks"
+	"github.com/ezhdanovskiy/wallets/internal/grpc/pb"
+	"github.com/ezhdanovskiy/wallets/internal/httperr"
+	"github.com/ezhdanovskiy/wallets/internal/service"
+	servicemocks "github.com/ezhdanovskiy/wallets/internal/service/mocks"
+)
+
+func TestServer_CreateWallet(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ts := newTestServer(t)
+		defer ts.Finish()
+
+		ts.mockSvc.EXPECT().CreateWallet(gomock.Any(), dto.CreateWalletRequest{Name: "wallet1"}).Return(nil)
+
+		_, err := ts.client.CreateWallet(context.Background(), &pb.CreateWalletRequest{Name: "wallet1"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		ts := newTestServer(t)
+		defer ts.Finish()
+
+		ts.mockSvc.EXPECT().CreateWallet(gomock.Any(), gomock.Any()).Return(httperr.New(http.StatusBadRequest, "empty wallet name"))
+
+		_, err := ts.client.CreateWallet(context.Background(), &pb.CreateWalletRequest{})
+		assertStatus(t, err, codes.InvalidArgument, "empty wallet name")
+	})
+}
+
+func TestServer_GetWallet(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ts := newTestServer(t)
+		defer ts.Finish()
+
+		ts.mockSvc.EXPECT().GetWallet(gomock.Any(), "wallet1").Return(&dto.Wallet{Name: "wallet1", Balance: 12345}, nil)
+
+		wallet, err := ts.client.GetWallet(context.Background(), &pb.GetWalletRequest{Name: "wallet1"})
+		require.NoError(t, err)
+		assert.Equal(t, "wallet1", wallet.GetName())
+		assert.Equal(t, 123.45, wallet.GetBalance())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ts := newTestServer(t)
+		defer ts.Finish()
+
+		ts.mockSvc.EXPECT().GetWallet(gomock.Any(), "wallet1").Return(nil, httperr.New(http.StatusNotFound, "wallet not found"))
+
+		_, err := ts.client.GetWallet(context.Background(), &pb.GetWalletRequest{Name: "wallet1"})
+		assertStatus(t, err, codes.NotFound, "wallet not found")
+	})
+}
+
+func TestServer_Deposit(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ts := newTestServer(t)
+		defer ts.Finish()
+
+		ts.mockSvc.EXPECT().IncreaseWalletBalance(gomock.Any(), dto.Deposit{Wallet: "wallet1", Amount: 100.5}).Return(nil)
+
+		_, err := ts.client.Deposit(context.Background(), &pb.DepositRequest{Wallet: "wallet1", Amount: 100.5})
+		assert.NoError(t, err)
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		ts := newTestServer(t)
+		defer ts.Finish()
+
+		ts.mockSvc.EXPECT().IncreaseWalletBalance(gomock.Any(), gomock.Any()).Return(errors.New("service error"))
+
+		_, err := ts.client.Deposit(context.Background(), &pb.DepositRequest{Wallet: "wallet1", Amount: 100.5})
+		assertStatus(t, err, codes.Internal, "service error")
+	})
+
+	t.Run("wrong amount", func(t *testing.T) {
+		ts, mockRepo := newTestServerWithRepo(t)
+		defer ts.Finish()
+
+		for _, amount := range []float64{math.NaN(), math.Inf(1), 1e17} {
+			mockRepo.EXPECT().InsertAuditRecord(gomock.Any(), gomock.Any()).Return(nil)
+			_, err := ts.client.Deposit(context.Background(), &pb.DepositRequest{Wallet: "wallet1", Amount: amount})
+			assertStatus(t, err, codes.InvalidArgument, service.ErrWrongAmount.Message)
+		}
+	})
+}
+
+func TestServer_Transfer(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ts := newTestServer(t)
+		defer ts.Finish()
+
+		ts.mockSvc.EXPECT().Transfer(gomock.Any(), dto.Transfer{WalletFrom: "wallet1", WalletTo: "wallet2", Amount: 50}).Return(nil)
+
+		_, err := ts.client.Transfer(context.Background(), &pb.TransferRequest{WalletFrom: "wallet1", WalletTo: "wallet2", Amount: 50})
+		assert.NoError(t, err)
+	})
+
+	t.Run("not enough money", func(t *testing.T) {
+		ts := newTestServer(t)
+		defer ts.Finish()
+
+		ts.mockSvc.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(httperr.New(http.StatusUnprocessableEntity, "not enough money"))
+
+		_, err := ts.client.Transfer(context.Background(), &pb.TransferRequest{WalletFrom: "wallet1", WalletTo: "wallet2", Amount: 50})
+		assertStatus(t, err, codes.FailedPrecondition, "not enough money")
+	})
+
+	t.Run("wrong amount", func(t *testing.T) {
+		ts, mockRepo := newTestServerWithRepo(t)
+		defer ts.Finish()
+
+		for _, amount := range []float64{math.NaN(), math.Inf(1), 1e17} {
+			mockRepo.EXPECT().InsertAuditRecord(gomock.Any(), gomock.Any()).Return(nil)
+			_, err := ts.client.Transfer(context.Background(), &pb.TransferRequest{WalletFrom: "wallet1", WalletTo: "wallet2",
+				Amount: amount})
+			assertStatus(t, err, codes.InvalidArgument, service.ErrWrongAmount.Message)
+		}
+	})
+}
+
+func TestServer_GetOperations(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ts := newTestServer(t)
+		defer ts.Finish()
+
+		ts.mockSvc.EXPECT().GetOperations(gomock.Any(), dto.OperationsFilter{
+			Wallet:    "wallet1",
+			Types:     []string{consts.OperationTypeDeposit},
+			StartDate: time.Unix(1234567890, 0).UTC(),
+			EndDate:   time.Unix(1234567899, 0).UTC(),
+			Limit:     50,
+			Offset:    10,
+		}).Return([]dto.Operation{{
+			Wallet:      "wallet1",
+			Amount:      100,
+			Type:        consts.OperationTypeDeposit,
+			OtherWallet: consts.SystemWalletName,
+			Timestamp:   time.Unix(1234567890, 0).UTC(),
+		}}, nil)
+
+		resp, err := ts.client.GetOperations(context.Background(), &pb.GetOperationsRequest{
+			Wallet:    "wallet1",
+			Type:      consts.OperationTypeDeposit,
+			StartDate: 1234567890,
+			EndDate:   1234567899,
+			Limit:     50,
+			Offset:    10,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.GetOperations(), 1)
+		op := resp.GetOperations()[0]
+		assert.Equal(t, "wallet1", op.GetWallet())
+		assert.Equal(t, float64(100), op.GetAmount())
+		assert.Equal(t, consts.OperationTypeDeposit, op.GetType())
+		assert.Equal(t, consts.SystemWalletName, op.GetOtherWallet())
+		assert.Equal(t, int64(1234567890), op.GetTimestamp().GetSeconds())
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		ts := newTestServer(t)
+		defer ts.Finish()
+
+		ts.mockSvc.EXPECT().GetOperations(gomock.Any(), gomock.Any()).Return(nil, httperr.New(http.StatusBadRequest, "unsupported operation type"))
+
+		_, err := ts.client.GetOperations(context.Background(), &pb.GetOperationsRequest{Wallet: "wallet1", Type: "123"})
+		assertStatus(t, err, codes.InvalidArgument, "unsupported operation type")
+	})
+
+	t.Run("limit above max", func(t *testing.T) {
+		ts := newTestServer(t)
+		defer ts.Finish()
+
+		_, err := ts.client.GetOperations(context.Background(), &pb.GetOperationsRequest{Wallet: "wallet1",
+			Limit: consts.OperationsLimitMax + 1})
+		assertStatus(t, err, codes.InvalidArgument, errWrongLimit.Message)
+	})
+}
+
+func TestServer_StreamOperations(t *testing.T) {
+	page := func(n int) []dto.Operation {
+		ops := make([]dto.Operation, n)
+		for i := range ops {
+			ops[i] = dto.Operation{ID: int64(i + 1), Wallet: "wallet1", Amount: 1, Type: consts.OperationTypeDeposit,
+				Timestamp: time.Unix(int64(i), 0).UTC()}
+		}
+		return ops
+	}
+
+	t.Run("all pages", func(t *testing.T) {
+		ts := newTestServer(t)
+		defer ts.Finish()
+
+		gomock.InOrder(
+			ts.mockSvc.EXPECT().GetOperations(gomock.Any(), dto.OperationsFilter{Wallet: "wallet1", Limit: consts.OperationsLimitMax}).
+				Return(page(consts.OperationsLimitMax), nil),
+			ts.mockSvc.EXPECT().GetOperations(gomock.Any(), dto.OperationsFilter{Wallet: "wallet1", Limit: consts.OperationsLimitMax,
+				Cursor: &dto.OperationsCursor{CreatedAt: time.Unix(consts.OperationsLimitMax-1, 0).UTC(), ID: consts.OperationsLimitMax}}).
+				Return(page(3), nil),
+		)
+
+		stream, err := ts.client.StreamOperations(context.Background(), &pb.GetOperationsRequest{Wallet: "wallet1"})
+		require.NoError(t, err)
+		assert.Equal(t, consts.OperationsLimitMax+3, ts.receiveAll(stream))
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		ts := newTestServer(t)
+		defer ts.Finish()
+
+		ts.mockSvc.EXPECT().GetOperations(gomock.Any(), dto.OperationsFilter{Wallet: "wallet1", Limit: 5}).
+			Return(page(5), nil)
+
+		stream, err := ts.client.StreamOperations(context.Background(), &pb.GetOperationsRequest{Wallet: "wallet1", Limit: 5})
+		require.NoError(t, err)
+		assert.Equal(t, 5, ts.receiveAll(stream))
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		ts := newTestServer(t)
+		defer ts.Finish()
+
+		ts.mockSvc.EXPECT().GetOperations(gomock.Any(), gomock.Any()).Return(nil, httperr.New(http.StatusBadRequest, "empty wallet name"))
+
+		stream, err := ts.client.StreamOperations(context.Background(), &pb.GetOperationsRequest{})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assertStatus(t, err, codes.InvalidArgument, "empty wallet name")
+	})
+}
+
+func TestServer_authenticate(t *testing.T) {
+	authenticator := fakeAuthenticator{"wlt_key": {ID: "payouts"}}
+
+	t.Run("missing credentials", func(t *testing.T) {
+		ts := newTestServerWithAuth(t, authenticator)
+		defer ts.Finish()
+
+		_, err := ts.client.CreateWallet(context.Background(), &pb.CreateWalletRequest{Name: "wallet1"})
+		assertStatus(t, err, codes.Unauthenticated, "missing credentials")
+	})
+
+	t.Run("unary with api key", func(t *testing.T) {
+		ts := newTestServerWithAuth(t, authenticator)
+		defer ts.Finish()
+
+		ts.mockSvc.EXPECT().CreateWallet(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ dto.CreateWalletRequest) error {
+				caller, ok := auth.FromContext(ctx)
+				require.True(t, ok)
+				assert.Equal(t, "payouts", caller.ID)
+				return nil
+			})
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "wlt_key")
+		_, err := ts.client.CreateWallet(ctx, &pb.CreateWalletRequest{Name: "wallet1"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("stream with bearer token", func(t *testing.T) {
+		ts := newTestServerWithAuth(t, authenticator)
+		defer ts.Finish()
+
+		ts.mockSvc.EXPECT().GetOperations(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ dto.OperationsFilter) ([]dto.Operation, error) {
+				caller, ok := auth.FromContext(ctx)
+				require.True(t, ok)
+				assert.Equal(t, "payouts", caller.ID)
+				return nil, nil
+			})
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer wlt_key")
+		stream, err := ts.client.StreamOperations(ctx, &pb.GetOperationsRequest{Wallet: "wallet1"})
+		require.NoError(t, err)
+		assert.Equal(t, 0, ts.receiveAll(stream))
+	})
+}
+
+func TestServer_toStatus(t *testing.T) {
+	s := &Server{log: zap.NewNop().Sugar()}
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode codes.Code
+		expectedMsg  string
+	}{
+		{"bad request", httperr.New(http.StatusBadRequest, "bad"), codes.InvalidArgument, "bad"},
+		{"unauthorized", httperr.New(http.StatusUnauthorized, "who"), codes.Unauthenticated, "who"},
+		{"forbidden", httperr.New(http.StatusForbidden, "no"), codes.PermissionDenied, "no"},
+		{"not found", httperr.New(http.StatusNotFound, "none"), codes.NotFound, "none"},
+		{"unprocessable", httperr.New(http.StatusUnprocessableEntity, "money"), codes.FailedPrecondition, "money"},
+		{"too many requests", httperr.New(http.StatusTooManyRequests, "slow"), codes.ResourceExhausted, "slow"},
+		{"internal", httperr.New(http.StatusInternalServerError, "db").Wrap(errors.New("conn")), codes.Internal, "db"},
+		{"unmapped status", httperr.New(http.StatusTeapot, "tea"), codes.Unknown, "tea"},
+		{"regular error", errors.New("boom"), codes.Internal, "boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertStatus(t, s.toStatus(tt.err), tt.expectedCode, tt.expectedMsg)
+		})
+	}
+}
+
+// fakeAuthenticator maps known tokens to callers.
+type fakeAuthenticator map[string]*auth.Caller
+
+func (a fakeAuthenticator) Authenticate(_ context.Context, token string) (*auth.Caller, error) {
+	if token == "" {
+		return nil, auth.ErrMissingCredentials
+	}
+	caller, ok := a[token]
+	if !ok {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	return caller, nil
+}
+
+func assertStatus(t *testing.T, err error, code codes.Code, msg string) {
+	t.Helper()
+	st, ok := status.FromError(err)
+	require.True(t, ok, "expected gRPC status error, got %v", err)
+	assert.Equal(t, code, st.Code())
+	assert.Equal(t, msg, st.Message())
+}
+
+// TestServer ---------------------------------------------------------------------------------------------------------
+type TestServer struct {
+	t        *testing.T
+	mockCtrl *gomock.Controller
+	mockSvc  *mocks.MockService
+	srv      *Server
+	conn     *grpc.ClientConn
+	client   pb.WalletsClient
+}
+
+func newTestServer(t *testing.T) TestServer {
+	return newTestServerWithAuth(t, nil)
+}
+
+func newTestServerWithAuth(t *testing.T, authenticator Authenticator) TestServer {
+	t.Parallel()
+	mockCtrl := gomock.NewController(t)
+	mockSvc := mocks.NewMockService(mockCtrl)
+
+	ts := serve(t, mockSvc, authenticator)
+	ts.mockCtrl = mockCtrl
+	ts.mockSvc = mockSvc
+	return ts
+}
+
+// newTestServerWithRepo serves the real service on top of the mock repository, so requests are validated
+// by the service like in production.
+func newTestServerWithRepo(t *testing.T) (TestServer, *servicemocks.MockRepository) {
+	t.Parallel()
+	mockCtrl := gomock.NewController(t)
+	mockRepo := servicemocks.NewMockRepository(mockCtrl)
+
+	ts := serve(t, service.NewService(zap.NewNop().Sugar(), mockRepo, nil, "XXX"), nil)
+	ts.mockCtrl = mockCtrl
+	return ts, mockRepo
+}
+
+func serve(t *testing.T, svc Service, authenticator Authenticator) TestServer {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := NewServer(zap.NewNop().Sugar(), 0, svc, authenticator)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	return TestServer{
+		t:      t,
+		srv:    srv,
+		conn:   conn,
+		client: pb.NewWalletsClient(conn),
+	}
+}
+
+func (ts *TestServer) receiveAll(stream pb.Wallets_StreamOperationsClient) int {
+	var received int
+	for {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			return received
+		}
+		require.NoError(ts.t, err)
+		received++
+	}
+}
+
+func (ts *TestServer) Finish() {
+	_ = ts.conn.Close()
+	_ = ts.srv.Shutdown(context.Background())
+	ts.mockCtrl.Finish()
+}
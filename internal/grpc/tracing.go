@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ezhdanovskiy/wallets/internal/tracing"
+)
+
+// tracedUnary runs the call in a server span continuing the trace from W3C trace context metadata.
+func (s *Server) tracedUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	ctx, span := startServerSpan(ctx, info.FullMethod)
+	defer func() { endServerSpan(span, err) }()
+
+	return handler(ctx, req)
+}
+
+func (s *Server) tracedStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	ctx, span := startServerSpan(ss.Context(), info.FullMethod)
+	defer func() { endServerSpan(span, err) }()
+
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+func startServerSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	return tracing.Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(semconv.RPCSystemGRPC))
+}
+
+func endServerSpan(span trace.Span, err error) {
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(status.Code(err))))
+	tracing.End(span, err)
+}
+
+// metadataCarrier adapts incoming metadata to propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	return firstValue(metadata.MD(c), key)
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
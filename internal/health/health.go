@@ -0,0 +1,132 @@
+// Package health checks whether the application is ready to serve requests.
+package health
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+)
+
+//go:generate mockgen -destination=./mocks/db_mock.go -package=mocks . DB
+
+// DB describes the database checks used for readiness.
+type DB interface {
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (version uint, dirty bool, err error)
+	Stats() sql.DBStats
+}
+
+// Statuses of checks.
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Report is the result of readiness checks.
+type Report struct {
+	Status       string          `json:"status"`
+	ShuttingDown bool            `json:"shutting_down,omitempty"`
+	Database     Check           `json:"database"`
+	Migrations   MigrationsCheck `json:"migrations"`
+	Pool         Pool            `json:"pool"`
+}
+
+// Check is the result of a single check.
+type Check struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// MigrationsCheck compares applied migrations with the migrations the application is built for.
+// A newer schema is ready, so the previous version keeps serving during a rolling update.
+type MigrationsCheck struct {
+	Check
+	Version  uint `json:"version"`
+	Expected uint `json:"expected"`
+	Dirty    bool `json:"dirty"`
+}
+
+// Pool reports saturation of the connection pool, it doesn't affect readiness.
+type Pool struct {
+	MaxOpen    int     `json:"max_open"`
+	Open       int     `json:"open"`
+	InUse      int     `json:"in_use"`
+	Idle       int     `json:"idle"`
+	WaitCount  int64   `json:"wait_count"`
+	Saturation float64 `json:"saturation"` // in_use / max_open, 0 if the pool is unlimited
+}
+
+// Checker checks readiness of the application.
+type Checker struct {
+	db              DB
+	expectedVersion uint
+	shuttingDown    int32 // accessed atomically
+}
+
+// NewChecker creates readiness checker, expectedVersion is the version of the latest known migration.
+func NewChecker(db DB, expectedVersion uint) *Checker {
+	return &Checker{
+		db:              db,
+		expectedVersion: expectedVersion,
+	}
+}
+
+// Shutdown makes the application not ready, so load balancers stop sending new requests.
+func (c *Checker) Shutdown() {
+	atomic.StoreInt32(&c.shuttingDown, 1)
+}
+
+// Ready runs all checks, the application is ready if none of them failed and it isn't shutting down.
+func (c *Checker) Ready(ctx context.Context) Report {
+	report := Report{
+		Status:       StatusOK,
+		ShuttingDown: atomic.LoadInt32(&c.shuttingDown) == 1,
+		Database:     Check{Status: StatusOK},
+		Pool:         pool(c.db.Stats()),
+	}
+
+	if err := c.db.Ping(ctx); err != nil {
+		report.Database = Check{Status: StatusFail, Error: err.Error()}
+		report.Migrations = MigrationsCheck{
+			Check:    Check{Status: StatusFail, Error: "database is unavailable"},
+			Expected: c.expectedVersion,
+		}
+	} else {
+		report.Migrations = c.checkMigrations(ctx)
+	}
+
+	if report.ShuttingDown || report.Database.Status != StatusOK || report.Migrations.Status != StatusOK {
+		report.Status = StatusFail
+	}
+	return report
+}
+
+func (c *Checker) checkMigrations(ctx context.Context) MigrationsCheck {
+	check := MigrationsCheck{Check: Check{Status: StatusOK}, Expected: c.expectedVersion}
+
+	var err error
+	check.Version, check.Dirty, err = c.db.MigrationVersion(ctx)
+	switch {
+	case err != nil:
+		check.Check = Check{Status: StatusFail, Error: err.Error()}
+	case check.Dirty:
+		check.Check = Check{Status: StatusFail, Error: "last migration failed, database is dirty"}
+	case check.Version < c.expectedVersion:
+		check.Check = Check{Status: StatusFail, Error: "database schema is behind the migrations"}
+	}
+	return check
+}
+
+func pool(stats sql.DBStats) Pool {
+	p := Pool{
+		MaxOpen:   stats.MaxOpenConnections,
+		Open:      stats.OpenConnections,
+		InUse:     stats.InUse,
+		Idle:      stats.Idle,
+		WaitCount: stats.WaitCount,
+	}
+	if p.MaxOpen > 0 {
+		p.Saturation = float64(p.InUse) / float64(p.MaxOpen)
+	}
+	return p
+}
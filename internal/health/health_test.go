@@ -0,0 +1,121 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ezhdanovskiy/wallets/internal/health/mocks"
+)
+
+func TestChecker_Ready(t *testing.T) {
+	stats := sql.DBStats{MaxOpenConnections: 10, OpenConnections: 4, InUse: 3, Idle: 1, WaitCount: 2}
+
+	tests := []struct {
+		name       string
+		prepare    func(db *mocks.MockDB)
+		shutdown   bool
+		status     string
+		database   string
+		migrations string
+	}{
+		{
+			name: "ready",
+			prepare: func(db *mocks.MockDB) {
+				db.EXPECT().Ping(gomock.Any()).Return(nil)
+				db.EXPECT().MigrationVersion(gomock.Any()).Return(uint(7), false, nil)
+			},
+			status:     StatusOK,
+			database:   StatusOK,
+			migrations: StatusOK,
+		},
+		{
+			name: "database unavailable",
+			prepare: func(db *mocks.MockDB) {
+				db.EXPECT().Ping(gomock.Any()).Return(errors.New("connection refused"))
+			},
+			status:     StatusFail,
+			database:   StatusFail,
+			migrations: StatusFail,
+		},
+		{
+			name: "dirty migrations",
+			prepare: func(db *mocks.MockDB) {
+				db.EXPECT().Ping(gomock.Any()).Return(nil)
+				db.EXPECT().MigrationVersion(gomock.Any()).Return(uint(7), true, nil)
+			},
+			status:     StatusFail,
+			database:   StatusOK,
+			migrations: StatusFail,
+		},
+		{
+			name: "schema behind",
+			prepare: func(db *mocks.MockDB) {
+				db.EXPECT().Ping(gomock.Any()).Return(nil)
+				db.EXPECT().MigrationVersion(gomock.Any()).Return(uint(6), false, nil)
+			},
+			status:     StatusFail,
+			database:   StatusOK,
+			migrations: StatusFail,
+		},
+		{
+			name: "schema newer",
+			prepare: func(db *mocks.MockDB) {
+				db.EXPECT().Ping(gomock.Any()).Return(nil)
+				db.EXPECT().MigrationVersion(gomock.Any()).Return(uint(8), false, nil)
+			},
+			status:     StatusOK,
+			database:   StatusOK,
+			migrations: StatusOK,
+		},
+		{
+			name: "migration version error",
+			prepare: func(db *mocks.MockDB) {
+				db.EXPECT().Ping(gomock.Any()).Return(nil)
+				db.EXPECT().MigrationVersion(gomock.Any()).Return(uint(0), false, errors.New("relation does not exist"))
+			},
+			status:     StatusFail,
+			database:   StatusOK,
+			migrations: StatusFail,
+		},
+		{
+			name: "shutting down",
+			prepare: func(db *mocks.MockDB) {
+				db.EXPECT().Ping(gomock.Any()).Return(nil)
+				db.EXPECT().MigrationVersion(gomock.Any()).Return(uint(7), false, nil)
+			},
+			shutdown:   true,
+			status:     StatusFail,
+			database:   StatusOK,
+			migrations: StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			db := mocks.NewMockDB(ctrl)
+			db.EXPECT().Stats().Return(stats)
+			tt.prepare(db)
+
+			checker := NewChecker(db, 7)
+			if tt.shutdown {
+				checker.Shutdown()
+			}
+			report := checker.Ready(context.Background())
+
+			assert.Equal(t, tt.status, report.Status)
+			assert.Equal(t, tt.shutdown, report.ShuttingDown)
+			assert.Equal(t, tt.database, report.Database.Status)
+			assert.Equal(t, tt.migrations, report.Migrations.Status)
+			assert.Equal(t, uint(7), report.Migrations.Expected)
+			assert.Equal(t, Pool{MaxOpen: 10, Open: 4, InUse: 3, Idle: 1, WaitCount: 2, Saturation: 0.3}, report.Pool)
+		})
+	}
+}
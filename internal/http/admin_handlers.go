@@ -0,0 +1,148 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/csv"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/httperr"
+)
+
+func (s *Server) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorResponse(w, r, ErrBodyDecode.Wrap(err))
+		return
+	}
+
+	key, err := s.svc.CreateAPIKey(r.Context(), req)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	s.writeResponse(w, r, http.StatusCreated, key)
+}
+
+func (s *Server) listAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := s.svc.ListAPIKeys(r.Context())
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	s.writeResponse(w, r, http.StatusOK, keys)
+}
+
+func (s *Server) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	err := s.svc.RevokeAPIKey(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	s.writeResponse(w, r, http.StatusOK, nil)
+}
+
+func (s *Server) getAuditRecords(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := dto.AuditFilter{
+		Actor:     query.Get("actor"),
+		Action:    query.Get("action"),
+		RequestID: query.Get("request_id"),
+		Limit:     consts.OperationsLimitDefault,
+	}
+
+	for _, param := range []struct {
+		name  string
+		value *int64
+	}{
+		{"start_date", &filter.StartDate},
+		{"end_date", &filter.EndDate},
+		{"limit", &filter.Limit},
+		{"offset", &filter.Offset},
+	} {
+		str := query.Get(param.name)
+		if str == "" {
+			continue
+		}
+		i, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			s.writeErrorResponse(w, r, httperr.Wrap(err, http.StatusBadRequest, "failed to parse %s", param.name))
+			return
+		}
+		*param.value = i
+	}
+
+	if filter.Limit < 1 || filter.Limit > consts.OperationsLimitMax {
+		s.writeErrorResponse(w, r, httperr.New(http.StatusBadRequest, "wrong limit, it have to be in [1, %d]", consts.OperationsLimitMax))
+		return
+	}
+
+	records, err := s.svc.GetAuditRecords(r.Context(), filter)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	if query.Get("format") == "csv" {
+		data, err := csv.ConvertAuditRecords(records)
+		if err != nil {
+			s.writeErrorResponse(w, r, err)
+			return
+		}
+		s.writeCSVResponse(w, r, "audit.csv", data)
+		return
+	}
+
+	s.writeResponse(w, r, http.StatusOK, records)
+}
+
+func (s *Server) verifyLedger(w http.ResponseWriter, r *http.Request) {
+	report, err := s.svc.VerifyLedger(r.Context(), nil)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	s.writeResponse(w, r, http.StatusOK, report)
+}
+
+func (s *Server) createLedgerCheckpoint(w http.ResponseWriter, r *http.Request) {
+	cp, err := s.svc.CreateLedgerCheckpoint(r.Context())
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	s.writeResponse(w, r, http.StatusCreated, cp)
+}
+
+func (s *Server) getLedgerCheckpoints(w http.ResponseWriter, r *http.Request) {
+	limit := int64(consts.OperationsLimitDefault)
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		i, err := strconv.ParseInt(limitStr, 10, 64)
+		if err != nil {
+			s.writeErrorResponse(w, r, httperr.Wrap(err, http.StatusBadRequest, "failed to parse limit"))
+			return
+		}
+		if i < 1 || i > consts.OperationsLimitMax {
+			s.writeErrorResponse(w, r, httperr.New(http.StatusBadRequest, "wrong limit, it have to be in [1, %d]", consts.OperationsLimitMax))
+			return
+		}
+		limit = i
+	}
+
+	checkpoints, err := s.svc.GetLedgerCheckpoints(r.Context(), limit)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	s.writeResponse(w, r, http.StatusOK, checkpoints)
+}
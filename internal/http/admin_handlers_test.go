@@ -0,0 +1,322 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/http/mocks"
+	"github.com/ezhdanovskiy/wallets/internal/httperr"
+)
+
+func TestServer_adminAPIKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockService(ctrl)
+	server := &Server{
+		log: zap.NewNop().Sugar(),
+		svc: mockService,
+	}
+	router := chi.NewMux()
+	router.Route("/v1", server.GetV1ApiRouters())
+
+	createdAt := time.Unix(1234567890, 0).UTC()
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "create",
+			method: http.MethodPost,
+			url:    "/v1/admin/api-keys",
+			body:   `{"name":"payouts","scopes":["transfer"],"wallets":["wallet1"]}`,
+			mockSetup: func() {
+				mockService.EXPECT().CreateAPIKey(gomock.Any(), dto.CreateAPIKeyRequest{
+					Name:    "payouts",
+					Scopes:  []string{auth.ScopeTransfer},
+					Wallets: []string{"wallet1"},
+				}).Return(&dto.CreatedAPIKey{
+					APIKey: dto.APIKey{Name: "payouts", Scopes: []string{auth.ScopeTransfer}, Wallets: []string{"wallet1"}, Owners: []string{}, CreatedAt: createdAt},
+					Key:    "wlt_key",
+				}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody: `{"data":{"name":"payouts","scopes":["transfer"],"wallets":["wallet1"],"owners":[],
+				"created_at":"2009-02-13T23:31:30Z","key":"wlt_key"}}`,
+		},
+		{
+			name:           "create invalid json",
+			method:         http.MethodPost,
+			url:            "/v1/admin/api-keys",
+			body:           "{",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"failed to decode body"}`,
+		},
+		{
+			name:   "list forbidden",
+			method: http.MethodGet,
+			url:    "/v1/admin/api-keys",
+			mockSetup: func() {
+				mockService.EXPECT().ListAPIKeys(gomock.Any()).Return(nil, httperr.New(http.StatusForbidden, "permission denied"))
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"permission denied"}`,
+		},
+		{
+			name:   "list",
+			method: http.MethodGet,
+			url:    "/v1/admin/api-keys",
+			mockSetup: func() {
+				mockService.EXPECT().ListAPIKeys(gomock.Any()).Return([]dto.APIKey{{Name: "payouts", CreatedAt: createdAt}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":[{"name":"payouts","scopes":null,"wallets":null,"owners":null,"created_at":"2009-02-13T23:31:30Z"}]}`,
+		},
+		{
+			name:   "revoke",
+			method: http.MethodDelete,
+			url:    "/v1/admin/api-keys/payouts",
+			mockSetup: func() {
+				mockService.EXPECT().RevokeAPIKey(gomock.Any(), "payouts").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_getAuditRecords(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockService(ctrl)
+	server := &Server{
+		log: zap.NewNop().Sugar(),
+		svc: mockService,
+	}
+
+	records := []dto.AuditRecord{{
+		ID:          1,
+		Action:      "transfer",
+		Actor:       "payouts",
+		RequestID:   "req-1",
+		SourceIP:    "10.0.0.1",
+		Endpoint:    "POST /v1/wallets/transfer",
+		PayloadHash: "abc",
+		Result:      "ok",
+		Timestamp:   time.Unix(1234567890, 0).UTC(),
+	}}
+
+	tests := []struct {
+		name           string
+		url            string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+		expectedType   string
+	}{
+		{
+			name: "success with filter",
+			url:  "/v1/admin/audit?actor=payouts&action=transfer&start_date=1234567890&limit=10&offset=5",
+			mockSetup: func() {
+				mockService.EXPECT().GetAuditRecords(gomock.Any(), dto.AuditFilter{
+					Actor:     "payouts",
+					Action:    "transfer",
+					StartDate: 1234567890,
+					Limit:     10,
+					Offset:    5,
+				}).Return(records, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"data":[{"id":1,"action":"transfer","actor":"payouts","request_id":"req-1",
+				"source_ip":"10.0.0.1","endpoint":"POST /v1/wallets/transfer","payload_hash":"abc","result":"ok",
+				"timestamp":"2009-02-13T23:31:30Z"}]}`,
+		},
+		{
+			name:           "invalid end_date",
+			url:            "/v1/admin/audit?end_date=invalid",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"failed to parse end_date"}`,
+		},
+		{
+			name:           "limit out of range",
+			url:            "/v1/admin/audit?limit=0",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"wrong limit, it have to be in [1, 1000]"}`,
+		},
+		{
+			name: "forbidden",
+			url:  "/v1/admin/audit",
+			mockSetup: func() {
+				mockService.EXPECT().GetAuditRecords(gomock.Any(), gomock.Any()).Return(nil, httperr.New(http.StatusForbidden, "permission denied"))
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"permission denied"}`,
+		},
+		{
+			name: "csv format",
+			url:  "/v1/admin/audit?format=csv",
+			mockSetup: func() {
+				mockService.EXPECT().GetAuditRecords(gomock.Any(), gomock.Any()).Return(records, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedType:   "text/csv; charset=utf-8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			rec := httptest.NewRecorder()
+
+			server.getAuditRecords(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedType != "" {
+				assert.Equal(t, tt.expectedType, rec.Header().Get("Content-Type"))
+			}
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_adminLedger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockService(ctrl)
+	server := &Server{
+		log: zap.NewNop().Sugar(),
+		svc: mockService,
+	}
+	router := chi.NewMux()
+	router.Route("/v1", server.GetV1ApiRouters())
+
+	createdAt := time.Unix(1234567890, 0).UTC()
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "verify broken",
+			method: http.MethodGet,
+			url:    "/v1/admin/ledger/verify",
+			mockSetup: func() {
+				mockService.EXPECT().VerifyLedger(gomock.Any(), nil).Return(&dto.LedgerReport{
+					Wallets:    1,
+					Operations: 1,
+					Broken:     &dto.LedgerBrokenLink{Wallet: "wallet1", Seq: 2, OperationID: 5, Reason: "unexpected seq"},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"data":{"ok":false,"wallets":1,"operations":1,
+				"broken":{"wallet":"wallet1","seq":2,"operation_id":5,"reason":"unexpected seq"}}}`,
+		},
+		{
+			name:   "create checkpoint",
+			method: http.MethodPost,
+			url:    "/v1/admin/ledger/checkpoints",
+			mockSetup: func() {
+				mockService.EXPECT().CreateLedgerCheckpoint(gomock.Any()).Return(&dto.LedgerCheckpoint{
+					ID:              1,
+					CreatedAt:       createdAt,
+					Operations:      1,
+					LastOperationID: 5,
+					RootHash:        "root",
+					Heads:           map[string]dto.LedgerHead{"wallet1": {Seq: 1, Hash: "hash"}},
+					KeyID:           "key",
+					Signature:       "sig",
+				}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody: `{"data":{"id":1,"created_at":"2009-02-13T23:31:30Z","operations":1,"last_operation_id":5,
+				"root_hash":"root","heads":{"wallet1":{"seq":1,"hash":"hash"}},"key_id":"key","signature":"sig"}}`,
+		},
+		{
+			name:   "create checkpoint disabled",
+			method: http.MethodPost,
+			url:    "/v1/admin/ledger/checkpoints",
+			mockSetup: func() {
+				mockService.EXPECT().CreateLedgerCheckpoint(gomock.Any()).
+					Return(nil, httperr.New(http.StatusNotImplemented, "ledger signing key is not configured"))
+			},
+			expectedStatus: http.StatusNotImplemented,
+			expectedBody:   `{"error":"ledger signing key is not configured"}`,
+		},
+		{
+			name:   "list checkpoints",
+			method: http.MethodGet,
+			url:    "/v1/admin/ledger/checkpoints?limit=5",
+			mockSetup: func() {
+				mockService.EXPECT().GetLedgerCheckpoints(gomock.Any(), int64(5)).Return([]dto.LedgerCheckpoint{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":[]}`,
+		},
+		{
+			name:           "list checkpoints invalid limit",
+			method:         http.MethodGet,
+			url:            "/v1/admin/ledger/checkpoints?limit=x",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"failed to parse limit"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
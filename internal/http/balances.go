@@ -0,0 +1,70 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/httperr"
+)
+
+// getBalance sends the balance of the wallet at the time of the at parameter, now by default.
+// A date without time is a day in tz, the balance is at the end of the day.
+func (s *Server) getBalance(w http.ResponseWriter, r *http.Request) {
+	loc, err := parseTimeZone(r)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	var at time.Time
+	if value := r.URL.Query().Get("at"); value != "" {
+		if at, err = dto.ParseOperationsTime(value, loc, true); err != nil {
+			s.writeErrorResponse(w, r, httperr.Wrap(err, http.StatusBadRequest, "failed to parse at"))
+			return
+		}
+	}
+
+	balance, err := s.svc.GetBalance(r.Context(), chi.URLParam(r, "name"), at)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	s.writeResponse(w, r, http.StatusOK, balance)
+}
+
+// getDailyBalances sends the closing balances of the wallet for the days from start_date to end_date in tz.
+func (s *Server) getDailyBalances(w http.ResponseWriter, r *http.Request) {
+	loc, err := parseTimeZone(r)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	filter := dto.DailyBalancesFilter{Wallet: chi.URLParam(r, "name"), Location: loc}
+	for _, p := range []struct {
+		name string
+		date *time.Time
+	}{
+		{"start_date", &filter.StartDate},
+		{"end_date", &filter.EndDate},
+	} {
+		if value := r.URL.Query().Get(p.name); value != "" {
+			if *p.date, err = time.ParseInLocation(time.DateOnly, value, loc); err != nil {
+				s.writeErrorResponse(w, r, httperr.Wrap(err, http.StatusBadRequest, "failed to parse "+p.name))
+				return
+			}
+		}
+	}
+
+	series, err := s.svc.GetDailyBalances(r.Context(), filter)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	s.writeResponse(w, r, http.StatusOK, series)
+}
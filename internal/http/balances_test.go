@@ -0,0 +1,157 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/http/mocks"
+)
+
+func TestServer_getBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockService(ctrl)
+	server := &Server{
+		log: zap.NewNop().Sugar(),
+		svc: mockService,
+	}
+	router := chi.NewMux()
+	router.Route("/v1", server.GetV1ApiRouters())
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+	at := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		url            string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "now",
+			url:  "/v1/wallets/wallet1/balance",
+			mockSetup: func() {
+				mockService.EXPECT().GetBalance(gomock.Any(), "wallet1", time.Time{}).
+					Return(&dto.Balance{Wallet: "wallet1", At: at, Balance: 10.5}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":{"wallet":"wallet1","at":"2026-09-01T12:00:00Z","balance":10.5}}`,
+		},
+		{
+			name: "timestamp",
+			url:  "/v1/wallets/wallet1/balance?at=2026-09-01T12:00:00Z",
+			mockSetup: func() {
+				mockService.EXPECT().GetBalance(gomock.Any(), "wallet1", at).
+					Return(&dto.Balance{Wallet: "wallet1", At: at, Balance: 10}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":{"wallet":"wallet1","at":"2026-09-01T12:00:00Z","balance":10}}`,
+		},
+		{
+			name: "end of the day in time zone",
+			url:  "/v1/wallets/wallet1/balance?at=2026-09-01&tz=Europe/Berlin",
+			mockSetup: func() {
+				mockService.EXPECT().GetBalance(gomock.Any(), "wallet1",
+					time.Date(2026, 9, 1, 23, 59, 59, 999999000, berlin).UTC()).Return(&dto.Balance{Wallet: "wallet1", At: at}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":{"wallet":"wallet1","at":"2026-09-01T12:00:00Z","balance":0}}`,
+		},
+		{
+			name:           "invalid at",
+			url:            "/v1/wallets/wallet1/balance?at=yesterday",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"failed to parse at"}`,
+		},
+		{
+			name:           "invalid tz",
+			url:            "/v1/wallets/wallet1/balance?tz=Mars/Olympus",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"failed to parse tz"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestServer_getDailyBalances(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockService(ctrl)
+	server := &Server{
+		log: zap.NewNop().Sugar(),
+		svc: mockService,
+	}
+	router := chi.NewMux()
+	router.Route("/v1", server.GetV1ApiRouters())
+
+	tests := []struct {
+		name           string
+		url            string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "period",
+			url:  "/v1/wallets/wallet1/balance/daily?start_date=2026-09-01&end_date=2026-09-02",
+			mockSetup: func() {
+				mockService.EXPECT().GetDailyBalances(gomock.Any(), dto.DailyBalancesFilter{
+					Wallet:    "wallet1",
+					StartDate: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+					EndDate:   time.Date(2026, 9, 2, 0, 0, 0, 0, time.UTC),
+					Location:  time.UTC,
+				}).Return([]dto.DailyBalance{{Date: "2026-09-01", Balance: 10}, {Date: "2026-09-02", Balance: 12.5}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":[{"date":"2026-09-01","balance":10},{"date":"2026-09-02","balance":12.5}]}`,
+		},
+		{
+			name:           "timestamp instead of date",
+			url:            "/v1/wallets/wallet1/balance/daily?end_date=2026-09-02T10:00:00Z",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"failed to parse end_date"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
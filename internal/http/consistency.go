@@ -0,0 +1,16 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/ezhdanovskiy/wallets/internal/consistency"
+)
+
+// readConsistency makes reads of the request go to the primary database if the client asks for strong consistency
+// with X-Consistency: strong header, e.g. to read operations right after a transfer.
+func readConsistency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := consistency.FromHeader(r.Context(), r.Header.Get(consistency.Header))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ezhdanovskiy/wallets/internal/consistency"
+)
+
+func TestReadConsistency(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		strong bool
+	}{
+		{name: "no header", header: "", strong: false},
+		{name: "strong", header: "strong", strong: true},
+		{name: "unknown value", header: "eventual", strong: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var strong bool
+			h := readConsistency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				strong = consistency.IsStrong(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/wallets/operations", nil)
+			if tt.header != "" {
+				req.Header.Set(consistency.Header, tt.header)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.strong, strong)
+		})
+	}
+}
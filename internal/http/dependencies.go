@@ -1,15 +1,48 @@
 package http
 
 import (
+	"context"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/auth"
 	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/health"
 )
 
 //go:generate mockgen -source=dependencies.go -destination=mocks/service_mock.go -package=mocks
 
 // Service describes the service methods required for the server.
 type Service interface {
-	CreateWallet(dto.CreateWalletRequest) error
-	IncreaseWalletBalance(dto.Deposit) error
-	Transfer(dto.Transfer) error
-	GetOperations(dto.OperationsFilter) ([]dto.Operation, error)
+	CreateWallet(context.Context, dto.CreateWalletRequest) error
+	IncreaseWalletBalance(context.Context, dto.Deposit) error
+	Transfer(context.Context, dto.Transfer) error
+	GetOperationsPage(context.Context, dto.OperationsFilter) (*dto.OperationsPage, error)
+	ScanOperations(ctx context.Context, filter dto.OperationsFilter, f func(dto.Operation) error) error
+	GetOperationsSummary(ctx context.Context, filter dto.OperationsFilter) (*dto.OperationsSummary, error)
+	GetStatement(ctx context.Context, walletName, period string) (*dto.Statement, error)
+	GetBalance(ctx context.Context, walletName string, at time.Time) (*dto.Balance, error)
+	GetDailyBalances(ctx context.Context, filter dto.DailyBalancesFilter) ([]dto.DailyBalance, error)
+
+	CreateImport(context.Context, dto.ImportRequest) (*dto.ImportResult, error)
+	GetImportJob(ctx context.Context, id int64) (*dto.ImportJob, error)
+	GetImportResult(ctx context.Context, id int64) (*dto.ImportJob, []dto.ImportRow, error)
+
+	CreateAPIKey(context.Context, dto.CreateAPIKeyRequest) (*dto.CreatedAPIKey, error)
+	ListAPIKeys(context.Context) ([]dto.APIKey, error)
+	RevokeAPIKey(ctx context.Context, name string) error
+	GetAuditRecords(context.Context, dto.AuditFilter) ([]dto.AuditRecord, error)
+
+	VerifyLedger(ctx context.Context, checkpoint *dto.LedgerCheckpoint) (*dto.LedgerReport, error)
+	CreateLedgerCheckpoint(context.Context) (*dto.LedgerCheckpoint, error)
+	GetLedgerCheckpoints(ctx context.Context, limit int64) ([]dto.LedgerCheckpoint, error)
+}
+
+// Authenticator describes the validation of credentials passed with requests.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*auth.Caller, error)
+}
+
+// ReadinessChecker describes the checks of whether the application can serve requests.
+type ReadinessChecker interface {
+	Ready(ctx context.Context) health.Report
 }
@@ -7,5 +7,7 @@ import (
 )
 
 var (
-	ErrBodyDecode = httperr.New(http.StatusBadRequest, "failed to decode body")
+	ErrBodyDecode    = httperr.New(http.StatusBadRequest, "failed to decode body")
+	ErrNotAcceptable = httperr.New(http.StatusNotAcceptable, "none of the accepted media types is supported, use the format parameter")
+	ErrRateLimited   = httperr.New(http.StatusTooManyRequests, "rate limit exceeded")
 )
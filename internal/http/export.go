@@ -0,0 +1,156 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/export"
+	"github.com/ezhdanovskiy/wallets/internal/httperr"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+)
+
+// exportFlushRows is the number of rows sent to the client at once.
+const exportFlushRows = 1000
+
+// exportOperations streams all operations selected by the filters of the history in the format chosen
+// by the format parameter or the Accept header, unlike pages of getOperations they aren't limited.
+// Rows are sent as they are read from the database, the export stops when the client disconnects.
+func (s *Server) exportOperations(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseOperationsFilter(r)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+	format, err := parseExportFormat(r)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+	opts, err := parseExportOptions(r)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	if format.Summary {
+		if filter.StartDate.IsZero() {
+			s.writeErrorResponse(w, r, httperr.New(http.StatusBadRequest, "start_date is required by format %s", format.Name))
+			return
+		}
+		// The statement and its entries cover the same period, operations created during the export are excluded.
+		if filter.EndDate.IsZero() {
+			filter.EndDate = time.Now().UTC().Truncate(time.Microsecond)
+		}
+		opts.Summary, err = s.svc.GetOperationsSummary(r.Context(), filter)
+		if err != nil {
+			s.writeErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	log := logging.FromContext(r.Context(), s.log)
+	flusher, _ := w.(http.Flusher)
+	var (
+		enc  export.Encoder
+		rows int
+	)
+	// The response starts with the first row, so invalid filters and failed queries are still reported as errors.
+	start := func() (err error) {
+		setAttachment(w, format.ContentType, fmt.Sprintf("operations-%s.%s", filter.Wallet, format.Extension))
+		w.WriteHeader(http.StatusOK)
+		enc, err = format.New(w, opts)
+		return err
+	}
+	flush := func() error {
+		if err := enc.Flush(); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	err = s.svc.ScanOperations(r.Context(), filter, func(op dto.Operation) error {
+		if rows == 0 {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(op); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushRows == 0 {
+			return flush()
+		}
+		return nil
+	})
+	switch {
+	case err != nil && rows == 0:
+		s.writeErrorResponse(w, r, err)
+		return
+	case err != nil:
+		if r.Context().Err() != nil {
+			log.With("rows", rows).Info("Export is canceled by the client")
+			return
+		}
+		// The status is already sent, breaking the connection tells the client the file is incomplete.
+		log.With("rows", rows, "error", err).Error("Export failed")
+		panic(http.ErrAbortHandler)
+	case rows == 0:
+		err = start()
+	}
+	if err == nil {
+		err = enc.Close()
+	}
+	if err == nil && flusher != nil {
+		flusher.Flush()
+	}
+	if err != nil {
+		log.With("rows", rows, "error", err).Error("http response")
+	}
+}
+
+// parseExportFormat selects the export format by the format parameter, otherwise by the Accept header.
+func parseExportFormat(r *http.Request) (export.Format, error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		format, ok := export.Lookup(name)
+		if !ok {
+			return format, httperr.New(http.StatusBadRequest, "unsupported format %s, use one of %s",
+				name, strings.Join(export.Names(), ", "))
+		}
+		return format, nil
+	}
+
+	format, ok := export.Negotiate(r.Header.Get("Accept"))
+	if !ok {
+		return format, ErrNotAcceptable
+	}
+	return format, nil
+}
+
+// parseExportOptions parses the options of CSV: delimiter and bom.
+func parseExportOptions(r *http.Request) (export.Options, error) {
+	var opts export.Options
+	if delimiter := r.URL.Query().Get("delimiter"); delimiter != "" {
+		d, err := export.ParseDelimiter(delimiter)
+		if err != nil {
+			return opts, httperr.Wrap(err, http.StatusBadRequest, "failed to parse delimiter")
+		}
+		opts.Delimiter = d
+	}
+
+	if bom := r.URL.Query().Get("bom"); bom != "" {
+		b, err := strconv.ParseBool(bom)
+		if err != nil {
+			return opts, httperr.Wrap(err, http.StatusBadRequest, "failed to parse bom")
+		}
+		opts.BOM = b
+	}
+	return opts, nil
+}
@@ -0,0 +1,235 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/http/mocks"
+	"github.com/ezhdanovskiy/wallets/internal/httperr"
+)
+
+func TestServer_exportOperations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockService(ctrl)
+	server := &Server{
+		log: zap.NewNop().Sugar(),
+		svc: mockService,
+	}
+	op := dto.Operation{Wallet: "wallet1", Type: "deposit", Amount: 1.5, OtherWallet: "system",
+		Timestamp: time.Unix(1234567890, 0).UTC()}
+	scan := func(ops []dto.Operation, err error) func(context.Context, dto.OperationsFilter, func(dto.Operation) error) error {
+		return func(_ context.Context, _ dto.OperationsFilter, f func(dto.Operation) error) error {
+			for _, op := range ops {
+				if err := f(op); err != nil {
+					return err
+				}
+			}
+			return err
+		}
+	}
+	const header = "wallet,amount,type,other_wallet,timestamp\n"
+	const row = "wallet1,1.50,deposit,system,2009-02-13T23:31:30Z\n"
+	summary := &dto.OperationsSummary{Wallet: "wallet1", Currency: "EUR", StartDate: time.Unix(1234567800, 0).UTC(),
+		EndDate: time.Unix(1234567900, 0).UTC(), ClosingBalance: 1.5}
+
+	tests := []struct {
+		name             string
+		url              string
+		accept           string
+		mockSetup        func()
+		expectedStatus   int
+		expectedBody     string
+		expectedType     string
+		expectedFilename string
+	}{
+		{
+			name: "success",
+			url:  "/v1/wallets/operations/export?wallet=wallet1&type=deposit&order=desc",
+			mockSetup: func() {
+				mockService.EXPECT().ScanOperations(gomock.Any(), dto.OperationsFilter{
+					Wallet: "wallet1",
+					Types:  []string{"deposit"},
+					Order:  "desc",
+				}, gomock.Any()).DoAndReturn(scan([]dto.Operation{op, op}, nil))
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   header + row + row,
+			expectedType:   "text/csv; charset=utf-8",
+		},
+		{
+			name: "no operations",
+			url:  "/v1/wallets/operations/export?wallet=wallet1",
+			mockSetup: func() {
+				mockService.EXPECT().ScanOperations(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(scan(nil, nil))
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   header,
+			expectedType:   "text/csv; charset=utf-8",
+		},
+		{
+			name: "rows are flushed in batches",
+			url:  "/v1/wallets/operations/export?wallet=wallet1",
+			mockSetup: func() {
+				ops := make([]dto.Operation, exportFlushRows+1)
+				for i := range ops {
+					ops[i] = op
+				}
+				mockService.EXPECT().ScanOperations(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(scan(ops, nil))
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   header + strings.Repeat(row, exportFlushRows+1),
+			expectedType:   "text/csv; charset=utf-8",
+		},
+		{
+			name: "csv options",
+			url:  "/v1/wallets/operations/export?wallet=wallet1&delimiter=tab&bom=true",
+			mockSetup: func() {
+				mockService.EXPECT().ScanOperations(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(scan([]dto.Operation{op}, nil))
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "\uFEFF" + strings.ReplaceAll(header+row, ",", "\t"),
+			expectedType:   "text/csv; charset=utf-8",
+		},
+		{
+			name:   "ndjson by accept header",
+			url:    "/v1/wallets/operations/export?wallet=wallet1",
+			accept: "application/x-ndjson, text/csv;q=0.5",
+			mockSetup: func() {
+				mockService.EXPECT().ScanOperations(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(scan([]dto.Operation{op}, nil))
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"wallet":"wallet1","amount":1.50,"type":"deposit","other_wallet":"system",` +
+				`"timestamp":"2009-02-13T23:31:30Z"}` + "\n",
+			expectedType:     "application/x-ndjson",
+			expectedFilename: "operations-wallet1.ndjson",
+		},
+		{
+			name: "camt053",
+			url:  "/v1/wallets/operations/export?wallet=wallet1&format=camt053&start_date=1234567800&end_date=1234567900",
+			mockSetup: func() {
+				filter := dto.OperationsFilter{Wallet: "wallet1", StartDate: summary.StartDate, EndDate: summary.EndDate}
+				mockService.EXPECT().GetOperationsSummary(gomock.Any(), filter).Return(summary, nil)
+				mockService.EXPECT().ScanOperations(gomock.Any(), filter, gomock.Any()).DoAndReturn(scan([]dto.Operation{op}, nil))
+			},
+			expectedStatus:   http.StatusOK,
+			expectedType:     "application/xml; charset=utf-8",
+			expectedFilename: "operations-wallet1.xml",
+		},
+		{
+			name:           "camt053 without start date",
+			url:            "/v1/wallets/operations/export?wallet=wallet1&format=camt053",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"start_date is required by format camt053"}`,
+		},
+		{
+			name:           "unsupported format",
+			url:            "/v1/wallets/operations/export?wallet=wallet1&format=pdf",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"unsupported format pdf, use one of csv, ndjson, xlsx, camt053"}`,
+		},
+		{
+			name:           "not acceptable",
+			url:            "/v1/wallets/operations/export?wallet=wallet1",
+			accept:         "application/json",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusNotAcceptable,
+			expectedBody:   `{"error":"none of the accepted media types is supported, use the format parameter"}`,
+		},
+		{
+			name:           "invalid bom",
+			url:            "/v1/wallets/operations/export?wallet=wallet1&bom=maybe",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"failed to parse bom"}`,
+		},
+		{
+			name:           "empty wallet",
+			url:            "/v1/wallets/operations/export",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"empty wallet parameter"}`,
+		},
+		{
+			name: "service error",
+			url:  "/v1/wallets/operations/export?wallet=wallet1&min_amount=10&max_amount=5",
+			mockSetup: func() {
+				mockService.EXPECT().ScanOperations(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(httperr.New(http.StatusBadRequest, "min_amount can't be greater than max_amount"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"min_amount can't be greater than max_amount"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			server.exportOperations(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedType == "" {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+				return
+			}
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, rec.Body.String())
+			}
+			if tt.expectedFilename == "" {
+				tt.expectedFilename = "operations-wallet1.csv"
+			}
+			assert.Equal(t, tt.expectedType, rec.Header().Get("content-type"))
+			assert.Equal(t, "attachment; filename="+tt.expectedFilename, rec.Header().Get("Content-Disposition"))
+			assert.True(t, rec.Flushed)
+		})
+	}
+
+	t.Run("failure after the first row aborts the response", func(t *testing.T) {
+		mockService.EXPECT().ScanOperations(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(scan([]dto.Operation{op}, errors.New("database error")))
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/operations/export?wallet=wallet1", nil)
+		rec := httptest.NewRecorder()
+
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() { server.exportOperations(rec, req) })
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("client disconnect stops the export", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		mockService.EXPECT().ScanOperations(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, filter dto.OperationsFilter, f func(dto.Operation) error) error {
+				if err := f(op); err != nil {
+					return err
+				}
+				cancel()
+				return ctx.Err()
+			})
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/operations/export?wallet=wallet1", nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+
+		assert.NotPanics(t, func() { server.exportOperations(rec, req) })
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
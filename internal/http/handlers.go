@@ -1,103 +1,86 @@
 package http
 
 import (
+	"bytes"
 	"encoding/json"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ezhdanovskiy/wallets/internal/consts"
-	"github.com/ezhdanovskiy/wallets/internal/csv"
 	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/export"
 	"github.com/ezhdanovskiy/wallets/internal/httperr"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
 )
 
 func (s *Server) createWallet(w http.ResponseWriter, r *http.Request) {
-	s.log.Debug("createWallet")
+	logging.FromContext(r.Context(), s.log).Debug("createWallet")
 
 	var wallet dto.CreateWalletRequest
 	if err := json.NewDecoder(r.Body).Decode(&wallet); err != nil {
-		s.writeErrorResponse(w, ErrBodyDecode.Wrap(err))
+		s.writeErrorResponse(w, r, ErrBodyDecode.Wrap(err))
 		return
 	}
 
-	err := s.svc.CreateWallet(wallet)
+	err := s.svc.CreateWallet(r.Context(), wallet)
 	if err != nil {
-		s.writeErrorResponse(w, err)
+		s.writeErrorResponse(w, r, err)
 		return
 	}
 
-	s.writeResponse(w, http.StatusOK, nil)
+	s.writeResponse(w, r, http.StatusOK, nil)
 }
 
 func (s *Server) deposit(w http.ResponseWriter, r *http.Request) {
 	var deposit dto.Deposit
 	if err := json.NewDecoder(r.Body).Decode(&deposit); err != nil {
-		s.writeErrorResponse(w, ErrBodyDecode.Wrap(err))
+		s.writeErrorResponse(w, r, ErrBodyDecode.Wrap(err))
 		return
 	}
 
-	err := s.svc.IncreaseWalletBalance(deposit)
+	err := s.svc.IncreaseWalletBalance(r.Context(), deposit)
 	if err != nil {
-		s.writeErrorResponse(w, err)
+		s.writeErrorResponse(w, r, err)
 		return
 	}
 
-	s.writeResponse(w, http.StatusOK, nil)
+	s.writeResponse(w, r, http.StatusOK, nil)
 }
 
 func (s *Server) transfer(w http.ResponseWriter, r *http.Request) {
 	var transfer dto.Transfer
 	if err := json.NewDecoder(r.Body).Decode(&transfer); err != nil {
-		s.writeErrorResponse(w, ErrBodyDecode.Wrap(err))
+		s.writeErrorResponse(w, r, ErrBodyDecode.Wrap(err))
 		return
 	}
 
-	err := s.svc.Transfer(transfer)
+	err := s.svc.Transfer(r.Context(), transfer)
 	if err != nil {
-		s.writeErrorResponse(w, err)
+		s.writeErrorResponse(w, r, err)
 		return
 	}
 
-	s.writeResponse(w, http.StatusOK, nil)
+	s.writeResponse(w, r, http.StatusOK, nil)
 }
 
 func (s *Server) getOperations(w http.ResponseWriter, r *http.Request) {
-	filter := dto.OperationsFilter{
-		Wallet: r.URL.Query().Get("wallet"),
-		Type:   r.URL.Query().Get("type"),
-	}
-
-	if filter.Wallet == "" {
-		s.writeErrorResponse(w, httperr.New(http.StatusBadRequest, "empty wallet parameter"))
+	filter, err := parseOperationsFilter(r)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
 		return
 	}
 
-	if startDate := r.URL.Query().Get("start_date"); startDate != "" {
-		i, err := strconv.ParseInt(startDate, 10, 64)
-		if err != nil {
-			s.writeErrorResponse(w, httperr.Wrap(err, http.StatusBadRequest, "failed to parse start_date"))
-			return
-		}
-		filter.StartDate = i
-	}
-
-	if endDate := r.URL.Query().Get("end_date"); endDate != "" {
-		i, err := strconv.ParseInt(endDate, 10, 64)
-		if err != nil {
-			s.writeErrorResponse(w, httperr.Wrap(err, http.StatusBadRequest, "failed to parse end_date"))
-			return
-		}
-		filter.EndDate = i
-	}
-
 	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
 		limit, err := strconv.ParseInt(limitStr, 10, 64)
 		if err != nil {
-			s.writeErrorResponse(w, httperr.Wrap(err, http.StatusBadRequest, "failed to parse limit"))
+			s.writeErrorResponse(w, r, httperr.Wrap(err, http.StatusBadRequest, "failed to parse limit"))
 			return
 		}
 		if limit < 1 || limit > consts.OperationsLimitMax {
-			s.writeErrorResponse(w, httperr.Wrap(err, http.StatusBadRequest, "wrong limit, it have to be in [1, 100]"))
+			s.writeErrorResponse(w, r, httperr.Wrap(err, http.StatusBadRequest, "wrong limit, it have to be in [1, 100]"))
 			return
 		}
 		filter.Limit = limit
@@ -108,27 +91,120 @@ func (s *Server) getOperations(w http.ResponseWriter, r *http.Request) {
 	if offset := r.URL.Query().Get("offset"); offset != "" {
 		i, err := strconv.ParseInt(offset, 10, 64)
 		if err != nil {
-			s.writeErrorResponse(w, httperr.Wrap(err, http.StatusBadRequest, "failed to parse offset"))
+			s.writeErrorResponse(w, r, httperr.Wrap(err, http.StatusBadRequest, "failed to parse offset"))
 			return
 		}
 		filter.Offset = i
 	}
 
-	operations, err := s.svc.GetOperations(filter)
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		c, err := dto.ParseOperationsCursor(cursor)
+		if err != nil {
+			s.writeErrorResponse(w, r, httperr.Wrap(err, http.StatusBadRequest, "failed to parse cursor"))
+			return
+		}
+		filter.Cursor = c
+	}
+
+	page, err := s.svc.GetOperationsPage(r.Context(), filter)
 	if err != nil {
-		s.writeErrorResponse(w, err)
+		s.writeErrorResponse(w, r, err)
 		return
 	}
 
 	if r.URL.Query().Get("format") == "csv" {
-		data, err := csv.ConvertOperations(operations)
+		opts, err := parseExportOptions(r)
 		if err != nil {
-			s.writeErrorResponse(w, err)
+			s.writeErrorResponse(w, r, err)
 			return
 		}
-		s.writeResponse(w, http.StatusOK, data)
+		var buf bytes.Buffer
+		if err := export.Write(&buf, export.CSV, opts, page.Operations); err != nil {
+			s.writeErrorResponse(w, r, err)
+			return
+		}
+		setPageLinks(w, r, page.NextCursor, page.PrevCursor)
+		s.writeCSVResponse(w, r, "operations.csv", buf.Bytes())
 		return
 	}
 
-	s.writeResponse(w, http.StatusOK, operations)
+	s.writePageResponse(w, r, page.Operations, page.NextCursor, page.PrevCursor)
+}
+
+// parseOperationsFilter parses the filters of operations history shared by pages and exports.
+func parseOperationsFilter(r *http.Request) (dto.OperationsFilter, error) {
+	filter := dto.OperationsFilter{
+		Wallet:      r.URL.Query().Get("wallet"),
+		OtherWallet: r.URL.Query().Get("other_wallet"),
+		Order:       r.URL.Query().Get("order"),
+	}
+
+	if filter.Wallet == "" {
+		return filter, httperr.New(http.StatusBadRequest, "empty wallet parameter")
+	}
+
+	// Types are repeated or comma separated: type=deposit&type=withdrawal or type=deposit,withdrawal.
+	for _, types := range r.URL.Query()["type"] {
+		for _, t := range strings.Split(types, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				filter.Types = append(filter.Types, t)
+			}
+		}
+	}
+
+	for _, p := range []struct {
+		name   string
+		amount *dto.Amount
+	}{
+		{"min_amount", &filter.MinAmount},
+		{"max_amount", &filter.MaxAmount},
+	} {
+		if value := r.URL.Query().Get(p.name); value != "" {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return filter, httperr.Wrap(err, http.StatusBadRequest, "failed to parse "+p.name)
+			}
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				return filter, httperr.New(http.StatusBadRequest, "failed to parse "+p.name)
+			}
+			*p.amount = dto.Amount(f)
+		}
+	}
+
+	// Dates without time are days in tz, the end date includes the whole day.
+	loc, err := parseTimeZone(r)
+	if err != nil {
+		return filter, err
+	}
+	for _, p := range []struct {
+		name     string
+		date     *time.Time
+		endOfDay bool
+	}{
+		{"start_date", &filter.StartDate, false},
+		{"end_date", &filter.EndDate, true},
+	} {
+		if value := r.URL.Query().Get(p.name); value != "" {
+			t, err := dto.ParseOperationsTime(value, loc, p.endOfDay)
+			if err != nil {
+				return filter, httperr.Wrap(err, http.StatusBadRequest, "failed to parse "+p.name)
+			}
+			*p.date = t
+		}
+	}
+
+	return filter, nil
+}
+
+// parseTimeZone returns the location of the tz parameter, UTC if it's missing.
+func parseTimeZone(r *http.Request) (*time.Location, error) {
+	tz := r.URL.Query().Get("tz")
+	if tz == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, httperr.Wrap(err, http.StatusBadRequest, "failed to parse tz")
+	}
+	return loc, nil
 }
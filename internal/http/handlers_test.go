@@ -40,7 +40,7 @@ func TestServer_createWallet(t *testing.T) {
 				Name: "Test Wallet",
 			},
 			mockSetup: func() {
-				mockService.EXPECT().CreateWallet(dto.CreateWalletRequest{
+				mockService.EXPECT().CreateWallet(gomock.Any(), dto.CreateWalletRequest{
 					Name: "Test Wallet",
 				}).Return(nil)
 			},
@@ -60,7 +60,7 @@ func TestServer_createWallet(t *testing.T) {
 				Name: "Test Wallet",
 			},
 			mockSetup: func() {
-				mockService.EXPECT().CreateWallet(gomock.Any()).Return(errors.New("service error"))
+				mockService.EXPECT().CreateWallet(gomock.Any(), gomock.Any()).Return(errors.New("service error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `{"error":"service error"}`,
@@ -71,7 +71,7 @@ func TestServer_createWallet(t *testing.T) {
 				Name: "Test Wallet",
 			},
 			mockSetup: func() {
-				mockService.EXPECT().CreateWallet(gomock.Any()).Return(httperr.New(http.StatusConflict, "wallet already exists"))
+				mockService.EXPECT().CreateWallet(gomock.Any(), gomock.Any()).Return(httperr.New(http.StatusConflict, "wallet already exists"))
 			},
 			expectedStatus: http.StatusConflict,
 			expectedBody:   `{"error":"wallet already exists"}`,
@@ -126,7 +126,7 @@ func TestServer_deposit(t *testing.T) {
 				Amount: dto.Amount(10050),
 			},
 			mockSetup: func() {
-				mockService.EXPECT().IncreaseWalletBalance(dto.Deposit{
+				mockService.EXPECT().IncreaseWalletBalance(gomock.Any(), dto.Deposit{
 					Wallet: "wallet1",
 					Amount: dto.Amount(10050),
 				}).Return(nil)
@@ -148,7 +148,7 @@ func TestServer_deposit(t *testing.T) {
 				Amount: dto.Amount(10050),
 			},
 			mockSetup: func() {
-				mockService.EXPECT().IncreaseWalletBalance(gomock.Any()).Return(errors.New("service error"))
+				mockService.EXPECT().IncreaseWalletBalance(gomock.Any(), gomock.Any()).Return(errors.New("service error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `{"error":"service error"}`,
@@ -204,7 +204,7 @@ func TestServer_transfer(t *testing.T) {
 				Amount:     dto.Amount(5000),
 			},
 			mockSetup: func() {
-				mockService.EXPECT().Transfer(dto.Transfer{
+				mockService.EXPECT().Transfer(gomock.Any(), dto.Transfer{
 					WalletFrom: "wallet1",
 					WalletTo:   "wallet2",
 					Amount:     dto.Amount(5000),
@@ -228,7 +228,7 @@ func TestServer_transfer(t *testing.T) {
 				Amount:     dto.Amount(5000),
 			},
 			mockSetup: func() {
-				mockService.EXPECT().Transfer(gomock.Any()).Return(errors.New("insufficient funds"))
+				mockService.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(errors.New("insufficient funds"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `{"error":"insufficient funds"}`,
@@ -268,6 +268,7 @@ func TestServer_getOperations(t *testing.T) {
 		log: zap.NewNop().Sugar(),
 		svc: mockService,
 	}
+	cursor := dto.OperationsCursor{CreatedAt: time.Unix(1234567890, 0).UTC(), ID: 7}
 
 	tests := []struct {
 		name           string
@@ -275,22 +276,25 @@ func TestServer_getOperations(t *testing.T) {
 		mockSetup      func()
 		expectedStatus int
 		expectedBody   string
+		expectedLinks  []string
+		expectedType   string
+		expectedCSV    string
 	}{
 		{
 			name: "success with default limit",
 			url:  "/v1/wallets/operations?wallet=wallet1",
 			mockSetup: func() {
-				mockService.EXPECT().GetOperations(dto.OperationsFilter{
+				mockService.EXPECT().GetOperationsPage(gomock.Any(), dto.OperationsFilter{
 					Wallet: "wallet1",
 					Limit:  20,
-				}).Return([]dto.Operation{
+				}).Return(&dto.OperationsPage{Operations: []dto.Operation{
 					{
 						Wallet:    "wallet1",
 						Type:      "deposit",
 						Amount:    dto.Amount(10000),
 						Timestamp: time.Unix(1234567890, 0).UTC(),
 					},
-				}, nil)
+				}}, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody: `{"data":[{
@@ -347,23 +351,118 @@ func TestServer_getOperations(t *testing.T) {
 			name: "with all parameters",
 			url:  "/v1/wallets/operations?wallet=wallet1&type=deposit&start_date=1234567890&end_date=1234567899&limit=50&offset=10",
 			mockSetup: func() {
-				mockService.EXPECT().GetOperations(dto.OperationsFilter{
+				mockService.EXPECT().GetOperationsPage(gomock.Any(), dto.OperationsFilter{
 					Wallet:    "wallet1",
-					Type:      "deposit",
-					StartDate: 1234567890,
-					EndDate:   1234567899,
+					Types:     []string{"deposit"},
+					StartDate: time.Unix(1234567890, 0).UTC(),
+					EndDate:   time.Unix(1234567899, 0).UTC(),
 					Limit:     50,
 					Offset:    10,
-				}).Return([]dto.Operation{}, nil)
+				}).Return(&dto.OperationsPage{Operations: []dto.Operation{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":[]}`,
+		},
+		{
+			name: "extended filters",
+			url: "/v1/wallets/operations?wallet=wallet1&type=deposit,withdrawal&other_wallet=wallet2" +
+				"&min_amount=10.5&max_amount=100&start_date=2024-01-01T10:00:00%2B03:00&end_date=2024-01-31&tz=Europe/Moscow",
+			mockSetup: func() {
+				mockService.EXPECT().GetOperationsPage(gomock.Any(), dto.OperationsFilter{
+					Wallet:      "wallet1",
+					Types:       []string{"deposit", "withdrawal"},
+					OtherWallet: "wallet2",
+					MinAmount:   10.5,
+					MaxAmount:   100,
+					StartDate:   time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC),
+					EndDate:     time.Date(2024, 1, 31, 20, 59, 59, 999999000, time.UTC),
+					Limit:       20,
+				}).Return(&dto.OperationsPage{Operations: []dto.Operation{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":[]}`,
+		},
+		{
+			name: "repeated types",
+			url:  "/v1/wallets/operations?wallet=wallet1&type=deposit&type=withdrawal",
+			mockSetup: func() {
+				mockService.EXPECT().GetOperationsPage(gomock.Any(), dto.OperationsFilter{
+					Wallet: "wallet1",
+					Types:  []string{"deposit", "withdrawal"},
+					Limit:  20,
+				}).Return(&dto.OperationsPage{Operations: []dto.Operation{}}, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody:   `{"data":[]}`,
 		},
+		{
+			name:           "invalid min_amount",
+			url:            "/v1/wallets/operations?wallet=wallet1&min_amount=invalid",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"failed to parse min_amount"}`,
+		},
+		{
+			name:           "NaN max_amount",
+			url:            "/v1/wallets/operations?wallet=wallet1&max_amount=NaN",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"failed to parse max_amount"}`,
+		},
+		{
+			name:           "infinite min_amount",
+			url:            "/v1/wallets/operations?wallet=wallet1&min_amount=Inf",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"failed to parse min_amount"}`,
+		},
+		{
+			name:           "invalid tz",
+			url:            "/v1/wallets/operations?wallet=wallet1&tz=Mars/Olympus",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"failed to parse tz"}`,
+		},
+		{
+			name: "cursor and order",
+			url:  "/v1/wallets/operations?wallet=wallet1&order=desc&limit=1&cursor=" + cursor.Encode(),
+			mockSetup: func() {
+				mockService.EXPECT().GetOperationsPage(gomock.Any(), dto.OperationsFilter{
+					Wallet: "wallet1",
+					Limit:  1,
+					Order:  "desc",
+					Cursor: &cursor,
+				}).Return(&dto.OperationsPage{
+					Operations: []dto.Operation{{Wallet: "wallet1", Type: "deposit", Amount: 1, Timestamp: time.Unix(1234567890, 0).UTC()}},
+					NextCursor: "next",
+					PrevCursor: "prev",
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"data":[{
+				"wallet":"wallet1",
+				"type":"deposit",
+				"amount":1,
+				"other_wallet":"",
+				"timestamp":"2009-02-13T23:31:30Z"
+			}],"next_cursor":"next","prev_cursor":"prev"}`,
+			expectedLinks: []string{
+				`</v1/wallets/operations?cursor=next&limit=1&order=desc&wallet=wallet1>; rel="next"`,
+				`</v1/wallets/operations?cursor=prev&limit=1&order=desc&wallet=wallet1>; rel="prev"`,
+			},
+		},
+		{
+			name:           "invalid cursor",
+			url:            "/v1/wallets/operations?wallet=wallet1&cursor=invalid",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"failed to parse cursor"}`,
+		},
 		{
 			name: "service error",
 			url:  "/v1/wallets/operations?wallet=wallet1",
 			mockSetup: func() {
-				mockService.EXPECT().GetOperations(gomock.Any()).Return(nil, errors.New("database error"))
+				mockService.EXPECT().GetOperationsPage(gomock.Any(), gomock.Any()).Return(nil, errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   `{"error":"database error"}`,
@@ -372,19 +471,40 @@ func TestServer_getOperations(t *testing.T) {
 			name: "csv format",
 			url:  "/v1/wallets/operations?wallet=wallet1&format=csv",
 			mockSetup: func() {
-				mockService.EXPECT().GetOperations(dto.OperationsFilter{
+				mockService.EXPECT().GetOperationsPage(gomock.Any(), dto.OperationsFilter{
 					Wallet: "wallet1",
 					Limit:  20,
-				}).Return([]dto.Operation{
+				}).Return(&dto.OperationsPage{Operations: []dto.Operation{
 					{
 						Wallet:    "wallet1",
 						Type:      "deposit",
 						Amount:    dto.Amount(10000),
 						Timestamp: time.Unix(1234567890, 0).UTC(),
 					},
-				}, nil)
+				}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedType:   "text/csv; charset=utf-8",
+			expectedCSV:    "wallet,amount,type,other_wallet,timestamp\nwallet1,10000.00,deposit,,2009-02-13T23:31:30Z\n",
+		},
+		{
+			name: "csv format with delimiter",
+			url:  "/v1/wallets/operations?wallet=wallet1&format=csv&delimiter=%3B",
+			mockSetup: func() {
+				mockService.EXPECT().GetOperationsPage(gomock.Any(), gomock.Any()).Return(&dto.OperationsPage{}, nil)
 			},
 			expectedStatus: http.StatusOK,
+			expectedType:   "text/csv; charset=utf-8",
+			expectedCSV:    "wallet;amount;type;other_wallet;timestamp\n",
+		},
+		{
+			name: "csv format with invalid delimiter",
+			url:  "/v1/wallets/operations?wallet=wallet1&format=csv&delimiter=ab",
+			mockSetup: func() {
+				mockService.EXPECT().GetOperationsPage(gomock.Any(), gomock.Any()).Return(&dto.OperationsPage{}, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"failed to parse delimiter"}`,
 		},
 	}
 
@@ -401,6 +521,13 @@ func TestServer_getOperations(t *testing.T) {
 			if tt.expectedBody != "" {
 				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
 			}
+			assert.Equal(t, tt.expectedLinks, rec.Header().Values("Link"))
+			if tt.expectedType != "" {
+				assert.Equal(t, tt.expectedType, rec.Header().Get("content-type"))
+			}
+			if tt.expectedCSV != "" {
+				assert.Equal(t, tt.expectedCSV, rec.Body.String())
+			}
 		})
 	}
 }
\ No newline at end of file
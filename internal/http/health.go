@@ -0,0 +1,35 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/health"
+)
+
+// readinessTimeout limits the time of readiness checks, so a hanging database fails the probe.
+const readinessTimeout = 2 * time.Second
+
+// healthz reports that the process is alive, it doesn't check dependencies.
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	s.writeResponse(w, r, http.StatusOK, map[string]string{"status": health.StatusOK})
+}
+
+// readyz reports whether the application can serve requests, it fails with 503 when any check fails.
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	if s.readiness == nil {
+		s.writeResponse(w, r, http.StatusOK, map[string]string{"status": health.StatusOK})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	report := s.readiness.Ready(ctx)
+	code := http.StatusOK
+	if report.Status != health.StatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	s.writeResponse(w, r, code, report)
+}
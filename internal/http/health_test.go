@@ -0,0 +1,68 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"github.com/ezhdanovskiy/wallets/internal/health"
+	"github.com/ezhdanovskiy/wallets/internal/http/mocks"
+)
+
+func TestServer_healthz(t *testing.T) {
+	server := &Server{log: zap.NewNop().Sugar()}
+
+	rec := httptest.NewRecorder()
+	server.healthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data":{"status":"ok"}}`, rec.Body.String())
+}
+
+func TestServer_readyz(t *testing.T) {
+	tests := []struct {
+		name         string
+		report       health.Report
+		expectedCode int
+	}{
+		{
+			name:         "ready",
+			report:       health.Report{Status: health.StatusOK},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "not ready",
+			report:       health.Report{Status: health.StatusFail, ShuttingDown: true},
+			expectedCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			readiness := mocks.NewMockReadinessChecker(ctrl)
+			readiness.EXPECT().Ready(gomock.Any()).Return(tt.report)
+
+			server := &Server{log: zap.NewNop().Sugar(), readiness: readiness}
+			rec := httptest.NewRecorder()
+			server.readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"status":"`+tt.report.Status+`"`)
+		})
+	}
+
+	t.Run("without checker", func(t *testing.T) {
+		server := &Server{log: zap.NewNop().Sugar()}
+		rec := httptest.NewRecorder()
+		server.readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
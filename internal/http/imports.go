@@ -0,0 +1,164 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/export"
+	"github.com/ezhdanovskiy/wallets/internal/httperr"
+	"github.com/ezhdanovskiy/wallets/internal/imports"
+)
+
+// createImport validates the uploaded CSV file of deposits or transfers and creates the import applying it.
+// The file is the body of the request or the file field of a multipart form. Errors of the file are reported
+// by lines with status 422, a dry run only reports them. The created import is applied in the background,
+// its status is polled by the URL in the Location header.
+func (s *Server) createImport(w http.ResponseWriter, r *http.Request) {
+	req, err := parseImportRequest(r)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, consts.ImportFileSizeMax)
+	req.File, err = readImportFile(r)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	result, err := s.svc.CreateImport(r.Context(), req)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	switch {
+	case len(result.Report.Errors) > 0:
+		w.Header().Set("content-type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		s.writeJSON(w, r, Resp{Error: "import file has errors", Data: result.Report})
+	case result.Job == nil:
+		s.writeResponse(w, r, http.StatusOK, result.Report)
+	default:
+		w.Header().Set("Location", fmt.Sprintf("/v1/imports/%d", result.Job.ID))
+		s.writeResponse(w, r, http.StatusAccepted, result.Job)
+	}
+}
+
+// parseImportRequest parses the parameters of the import: kind, dry_run, chunk_size and delimiter.
+func parseImportRequest(r *http.Request) (dto.ImportRequest, error) {
+	query := r.URL.Query()
+	req := dto.ImportRequest{Kind: query.Get("kind")}
+	if req.Kind == "" {
+		return req, httperr.New(http.StatusBadRequest, "kind is required")
+	}
+
+	if dryRun := query.Get("dry_run"); dryRun != "" {
+		b, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			return req, httperr.Wrap(err, http.StatusBadRequest, "failed to parse dry_run")
+		}
+		req.DryRun = b
+	}
+
+	if chunkSize := query.Get("chunk_size"); chunkSize != "" {
+		i, err := strconv.Atoi(chunkSize)
+		if err != nil {
+			return req, httperr.Wrap(err, http.StatusBadRequest, "failed to parse chunk_size")
+		}
+		req.ChunkSize = i
+	}
+
+	if delimiter := query.Get("delimiter"); delimiter != "" {
+		d, err := export.ParseDelimiter(delimiter)
+		if err != nil {
+			return req, httperr.Wrap(err, http.StatusBadRequest, "failed to parse delimiter")
+		}
+		req.Delimiter = d
+	}
+	return req, nil
+}
+
+// readImportFile reads the file from the file field of a multipart form or from the body.
+func readImportFile(r *http.Request) ([]byte, error) {
+	body := r.Body
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, importBodyError(err, "failed to read file of the form")
+		}
+		defer file.Close()
+		body = file
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, body); err != nil {
+		return nil, importBodyError(err, "failed to read import file")
+	}
+	return buf.Bytes(), nil
+}
+
+func importBodyError(err error, message string) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return httperr.Wrap(err, http.StatusRequestEntityTooLarge, "import file is larger than %d bytes", maxBytesErr.Limit)
+	}
+	return httperr.Wrap(err, http.StatusBadRequest, message)
+}
+
+func (s *Server) getImport(w http.ResponseWriter, r *http.Request) {
+	id, err := parseImportID(r)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	job, err := s.svc.GetImportJob(r.Context(), id)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	s.writeResponse(w, r, http.StatusOK, job)
+}
+
+// getImportResult sends the rows of the import file in CSV with their statuses and errors of applying.
+func (s *Server) getImportResult(w http.ResponseWriter, r *http.Request) {
+	id, err := parseImportID(r)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	job, rows, err := s.svc.GetImportResult(r.Context(), id)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := imports.WriteResult(&buf, job.Kind, rows); err != nil {
+		s.writeErrorResponse(w, r, httperr.Wrap(err, http.StatusInternalServerError, "failed to write import result"))
+		return
+	}
+
+	s.writeCSVResponse(w, r, fmt.Sprintf("import-%d-result.csv", job.ID), buf.Bytes())
+}
+
+func parseImportID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return 0, httperr.Wrap(err, http.StatusBadRequest, "failed to parse import id")
+	}
+	return id, nil
+}
@@ -0,0 +1,189 @@
+package http
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/http/mocks"
+)
+
+func TestServer_imports(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockService(ctrl)
+	server := &Server{
+		log: zap.NewNop().Sugar(),
+		svc: mockService,
+	}
+	router := chi.NewMux()
+	router.Route("/v1", server.GetV1ApiRouters())
+
+	const file = "wallet,amount\nwallet1,10.50\n"
+	createdAt := time.Unix(1234567890, 0).UTC()
+	job := &dto.ImportJob{ID: 7, Kind: consts.ImportKindDeposits, Status: consts.ImportStatusPending, FileHash: "hash",
+		ChunkSize: 50, Rows: 1, CreatedAt: createdAt, UpdatedAt: createdAt}
+
+	tests := []struct {
+		name             string
+		method           string
+		url              string
+		body             string
+		mockSetup        func()
+		expectedStatus   int
+		expectedBody     string
+		expectedLocation string
+	}{
+		{
+			name:   "create",
+			method: http.MethodPost,
+			url:    "/v1/imports?kind=deposits&chunk_size=50&delimiter=%3B",
+			body:   file,
+			mockSetup: func() {
+				mockService.EXPECT().CreateImport(gomock.Any(), dto.ImportRequest{
+					Kind: consts.ImportKindDeposits, File: []byte(file), Delimiter: ';', ChunkSize: 50,
+				}).Return(&dto.ImportResult{Report: dto.ImportReport{Kind: consts.ImportKindDeposits, Rows: 1}, Job: job}, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedBody: `{"data":{"id":7,"kind":"deposits","status":"pending","file_hash":"hash","chunk_size":50,"rows":1,
+				"applied":0,"skipped":0,"failed":0,"created_at":"2009-02-13T23:31:30Z","updated_at":"2009-02-13T23:31:30Z"}}`,
+			expectedLocation: "/v1/imports/7",
+		},
+		{
+			name:   "dry run",
+			method: http.MethodPost,
+			url:    "/v1/imports?kind=deposits&dry_run=true",
+			body:   file,
+			mockSetup: func() {
+				mockService.EXPECT().CreateImport(gomock.Any(), dto.ImportRequest{
+					Kind: consts.ImportKindDeposits, File: []byte(file), DryRun: true,
+				}).Return(&dto.ImportResult{Report: dto.ImportReport{Kind: consts.ImportKindDeposits, Rows: 1, Errors: []dto.ImportError{}}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":{"kind":"deposits","rows":1,"errors":[]}}`,
+		},
+		{
+			name:   "file with errors",
+			method: http.MethodPost,
+			url:    "/v1/imports?kind=deposits",
+			body:   file,
+			mockSetup: func() {
+				mockService.EXPECT().CreateImport(gomock.Any(), gomock.Any()).Return(&dto.ImportResult{Report: dto.ImportReport{
+					Kind: consts.ImportKindDeposits, Rows: 1, Errors: []dto.ImportError{{Line: 2, Error: "wallet wallet1 not found"}},
+				}}, nil)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody: `{"error":"import file has errors",
+				"data":{"kind":"deposits","rows":1,"errors":[{"line":2,"error":"wallet wallet1 not found"}]}}`,
+		},
+		{
+			name:           "missing kind",
+			method:         http.MethodPost,
+			url:            "/v1/imports",
+			body:           file,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"kind is required"}`,
+		},
+		{
+			name:           "invalid chunk size",
+			method:         http.MethodPost,
+			url:            "/v1/imports?kind=deposits&chunk_size=x",
+			body:           file,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"failed to parse chunk_size"}`,
+		},
+		{
+			name:           "too large file",
+			method:         http.MethodPost,
+			url:            "/v1/imports?kind=deposits",
+			body:           strings.Repeat("x", consts.ImportFileSizeMax+1),
+			mockSetup:      func() {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   `{"error":"import file is larger than 33554432 bytes"}`,
+		},
+		{
+			name:   "get",
+			method: http.MethodGet,
+			url:    "/v1/imports/7",
+			mockSetup: func() {
+				mockService.EXPECT().GetImportJob(gomock.Any(), int64(7)).Return(job, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"data":{"id":7,"kind":"deposits","status":"pending","file_hash":"hash","chunk_size":50,"rows":1,
+				"applied":0,"skipped":0,"failed":0,"created_at":"2009-02-13T23:31:30Z","updated_at":"2009-02-13T23:31:30Z"}}`,
+		},
+		{
+			name:           "invalid id",
+			method:         http.MethodGet,
+			url:            "/v1/imports/x",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"failed to parse import id"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			assert.Equal(t, tt.expectedLocation, rec.Header().Get("Location"))
+		})
+	}
+
+	t.Run("multipart form", func(t *testing.T) {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		fw, err := mw.CreateFormFile("file", "deposits.csv")
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(file))
+		require.NoError(t, err)
+		require.NoError(t, mw.Close())
+
+		mockService.EXPECT().CreateImport(gomock.Any(), dto.ImportRequest{
+			Kind: consts.ImportKindDeposits, File: []byte(file), DryRun: true,
+		}).Return(&dto.ImportResult{Report: dto.ImportReport{Kind: consts.ImportKindDeposits, Rows: 1, Errors: []dto.ImportError{}}}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/imports?kind=deposits&dry_run=1", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("result", func(t *testing.T) {
+		mockService.EXPECT().GetImportResult(gomock.Any(), int64(7)).Return(job, []dto.ImportRow{
+			{JobID: 7, Line: 2, Key: "hash:2", Wallet: "wallet1", Amount: 1050, Status: consts.ImportStatusApplied},
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/imports/7/result", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("content-type"))
+		assert.Equal(t, "attachment; filename=import-7-result.csv", rec.Header().Get("Content-Disposition"))
+		assert.Equal(t, "line,wallet,amount,idempotency_key,status,error\n2,wallet1,10.50,hash:2,applied,\n", rec.Body.String())
+	})
+}
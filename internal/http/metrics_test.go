@@ -0,0 +1,45 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstrument(t *testing.T) {
+	router := chi.NewMux()
+	router.Use(instrument)
+	router.Get("/v1/admin/api-keys/{name}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	router.Get("/v1/wallets/operations", func(w http.ResponseWriter, r *http.Request) {})
+
+	counter := func(method, route, status string) float64 {
+		return testutil.ToFloat64(requestsTotal.WithLabelValues(method, route, status))
+	}
+	before := []float64{
+		counter("GET", "/v1/admin/api-keys/{name}", "404"),
+		counter("GET", "/v1/wallets/operations", "200"),
+		counter("other", unmatchedRoute, "405"),
+		counter("GET", unmatchedRoute, "404"),
+	}
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/v1/admin/api-keys/payouts", nil),
+		httptest.NewRequest(http.MethodGet, "/v1/admin/api-keys/reports", nil),
+		httptest.NewRequest(http.MethodGet, "/v1/wallets/operations", nil),
+		httptest.NewRequest("BREW", "/v1/wallets/operations", nil),
+		httptest.NewRequest(http.MethodGet, "/random/path", nil),
+	} {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, before[0]+2, counter("GET", "/v1/admin/api-keys/{name}", "404"), "wallet names are not labels")
+	assert.Equal(t, before[1]+1, counter("GET", "/v1/wallets/operations", "200"))
+	assert.Equal(t, before[2]+1, counter("other", unmatchedRoute, "405"))
+	assert.Equal(t, before[3]+1, counter("GET", unmatchedRoute, "404"))
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/ezhdanovskiy/wallets/internal/audit"
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+)
+
+// authenticate checks credentials of the request and puts the caller into the request context.
+// The key is read from the X-API-Key header or from the Authorization header with Bearer scheme.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.auth == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("X-API-Key")
+		if token == "" {
+			token = auth.BearerToken(r.Header.Get("Authorization"))
+		}
+
+		caller, err := s.auth.Authenticate(r.Context(), token)
+		if err != nil {
+			s.writeErrorResponse(w, r, err)
+			return
+		}
+
+		setAccessCaller(r.Context(), caller)
+		next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), caller)))
+	})
+}
+
+// auditSource puts the source of the request into the context, it is recorded in the audit log.
+func (s *Server) auditSource(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		source := audit.Source{
+			RequestID: requestIDFromContext(r.Context()),
+			IP:        clientIP(r),
+			Endpoint:  r.Method + " " + r.URL.Path,
+		}
+		next.ServeHTTP(w, r.WithContext(audit.NewContext(r.Context(), source)))
+	})
+}
+
+// clientIP returns IP address of the client without port.
+func clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
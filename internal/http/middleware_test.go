@@ -0,0 +1,122 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/ezhdanovskiy/wallets/internal/audit"
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+)
+
+func TestServer_authenticate(t *testing.T) {
+	const testKey = "wlt_key"
+
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		expectedStatus int
+		expectedCaller string
+	}{
+		{
+			name:           "missing credentials",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "invalid key",
+			headers:        map[string]string{"X-API-Key": "wlt_wrong"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "api key header",
+			headers:        map[string]string{"X-API-Key": testKey},
+			expectedStatus: http.StatusOK,
+			expectedCaller: "payouts",
+		},
+		{
+			name:           "bearer token",
+			headers:        map[string]string{"Authorization": "Bearer " + testKey},
+			expectedStatus: http.StatusOK,
+			expectedCaller: "payouts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &Server{
+				log:  zap.NewNop().Sugar(),
+				auth: fakeAuthenticator{testKey: {ID: "payouts"}},
+			}
+
+			var caller *auth.Caller
+			handler := server.authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				caller, _ = auth.FromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/wallets/operations", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCaller != "" {
+				require.NotNil(t, caller)
+				assert.Equal(t, tt.expectedCaller, caller.ID)
+			} else {
+				assert.Nil(t, caller)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		server := &Server{log: zap.NewNop().Sugar()}
+
+		var called bool
+		handler := server.authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/wallets/operations", nil))
+		assert.True(t, called)
+	})
+}
+
+// fakeAuthenticator maps known tokens to callers.
+type fakeAuthenticator map[string]*auth.Caller
+
+func (a fakeAuthenticator) Authenticate(_ context.Context, token string) (*auth.Caller, error) {
+	if token == "" {
+		return nil, auth.ErrMissingCredentials
+	}
+	caller, ok := a[token]
+	if !ok {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	return caller, nil
+}
+
+func TestServer_auditSource(t *testing.T) {
+	server := &Server{log: zap.NewNop().Sugar()}
+
+	var source audit.Source
+	handler := server.requestID(server.auditSource(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		source = audit.FromContext(r.Context())
+	})))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/wallets/transfer", nil)
+	req.RemoteAddr = "10.0.0.1:54321"
+	req.Header.Set("X-Request-ID", "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, audit.Source{
+		RequestID: "req-1",
+		IP:        "10.0.0.1",
+		Endpoint:  "POST /v1/wallets/transfer",
+	}, source)
+}
@@ -10,9 +10,13 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 
+	auth "github.com/ezhdanovskiy/wallets/internal/auth"
 	dto "github.com/ezhdanovskiy/wallets/internal/dto"
+	health "github.com/ezhdanovskiy/wallets/internal/health"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,59 +44,360 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// CreateAPIKey mocks base method.
+func (m *MockService) CreateAPIKey(arg0 context.Context, arg1 dto.CreateAPIKeyRequest) (*dto.CreatedAPIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(*dto.CreatedAPIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockServiceMockRecorder) CreateAPIKey(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockService)(nil).CreateAPIKey), arg0, arg1)
+}
+
+// CreateImport mocks base method.
+func (m *MockService) CreateImport(arg0 context.Context, arg1 dto.ImportRequest) (*dto.ImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateImport", arg0, arg1)
+	ret0, _ := ret[0].(*dto.ImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateImport indicates an expected call of CreateImport.
+func (mr *MockServiceMockRecorder) CreateImport(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateImport", reflect.TypeOf((*MockService)(nil).CreateImport), arg0, arg1)
+}
+
+// CreateLedgerCheckpoint mocks base method.
+func (m *MockService) CreateLedgerCheckpoint(arg0 context.Context) (*dto.LedgerCheckpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLedgerCheckpoint", arg0)
+	ret0, _ := ret[0].(*dto.LedgerCheckpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLedgerCheckpoint indicates an expected call of CreateLedgerCheckpoint.
+func (mr *MockServiceMockRecorder) CreateLedgerCheckpoint(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLedgerCheckpoint", reflect.TypeOf((*MockService)(nil).CreateLedgerCheckpoint), arg0)
+}
+
 // CreateWallet mocks base method.
-func (m *MockService) CreateWallet(arg0 dto.CreateWalletRequest) error {
+func (m *MockService) CreateWallet(arg0 context.Context, arg1 dto.CreateWalletRequest) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateWallet", arg0)
+	ret := m.ctrl.Call(m, "CreateWallet", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateWallet indicates an expected call of CreateWallet.
-func (mr *MockServiceMockRecorder) CreateWallet(arg0 any) *gomock.Call {
+func (mr *MockServiceMockRecorder) CreateWallet(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockService)(nil).CreateWallet), arg0, arg1)
+}
+
+// GetAuditRecords mocks base method.
+func (m *MockService) GetAuditRecords(arg0 context.Context, arg1 dto.AuditFilter) ([]dto.AuditRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditRecords", arg0, arg1)
+	ret0, _ := ret[0].([]dto.AuditRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditRecords indicates an expected call of GetAuditRecords.
+func (mr *MockServiceMockRecorder) GetAuditRecords(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditRecords", reflect.TypeOf((*MockService)(nil).GetAuditRecords), arg0, arg1)
+}
+
+// GetBalance mocks base method.
+func (m *MockService) GetBalance(ctx context.Context, walletName string, at time.Time) (*dto.Balance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalance", ctx, walletName, at)
+	ret0, _ := ret[0].(*dto.Balance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalance indicates an expected call of GetBalance.
+func (mr *MockServiceMockRecorder) GetBalance(ctx, walletName, at any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockService)(nil).CreateWallet), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockService)(nil).GetBalance), ctx, walletName, at)
 }
 
-// GetOperations mocks base method.
-func (m *MockService) GetOperations(arg0 dto.OperationsFilter) ([]dto.Operation, error) {
+// GetDailyBalances mocks base method.
+func (m *MockService) GetDailyBalances(ctx context.Context, filter dto.DailyBalancesFilter) ([]dto.DailyBalance, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOperations", arg0)
-	ret0, _ := ret[0].([]dto.Operation)
+	ret := m.ctrl.Call(m, "GetDailyBalances", ctx, filter)
+	ret0, _ := ret[0].([]dto.DailyBalance)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetOperations indicates an expected call of GetOperations.
-func (mr *MockServiceMockRecorder) GetOperations(arg0 any) *gomock.Call {
+// GetDailyBalances indicates an expected call of GetDailyBalances.
+func (mr *MockServiceMockRecorder) GetDailyBalances(ctx, filter any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOperations", reflect.TypeOf((*MockService)(nil).GetOperations), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDailyBalances", reflect.TypeOf((*MockService)(nil).GetDailyBalances), ctx, filter)
+}
+
+// GetImportJob mocks base method.
+func (m *MockService) GetImportJob(ctx context.Context, id int64) (*dto.ImportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImportJob", ctx, id)
+	ret0, _ := ret[0].(*dto.ImportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImportJob indicates an expected call of GetImportJob.
+func (mr *MockServiceMockRecorder) GetImportJob(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImportJob", reflect.TypeOf((*MockService)(nil).GetImportJob), ctx, id)
+}
+
+// GetImportResult mocks base method.
+func (m *MockService) GetImportResult(ctx context.Context, id int64) (*dto.ImportJob, []dto.ImportRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImportResult", ctx, id)
+	ret0, _ := ret[0].(*dto.ImportJob)
+	ret1, _ := ret[1].([]dto.ImportRow)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetImportResult indicates an expected call of GetImportResult.
+func (mr *MockServiceMockRecorder) GetImportResult(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImportResult", reflect.TypeOf((*MockService)(nil).GetImportResult), ctx, id)
+}
+
+// GetLedgerCheckpoints mocks base method.
+func (m *MockService) GetLedgerCheckpoints(ctx context.Context, limit int64) ([]dto.LedgerCheckpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLedgerCheckpoints", ctx, limit)
+	ret0, _ := ret[0].([]dto.LedgerCheckpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLedgerCheckpoints indicates an expected call of GetLedgerCheckpoints.
+func (mr *MockServiceMockRecorder) GetLedgerCheckpoints(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLedgerCheckpoints", reflect.TypeOf((*MockService)(nil).GetLedgerCheckpoints), ctx, limit)
+}
+
+// GetOperationsPage mocks base method.
+func (m *MockService) GetOperationsPage(arg0 context.Context, arg1 dto.OperationsFilter) (*dto.OperationsPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOperationsPage", arg0, arg1)
+	ret0, _ := ret[0].(*dto.OperationsPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOperationsPage indicates an expected call of GetOperationsPage.
+func (mr *MockServiceMockRecorder) GetOperationsPage(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOperationsPage", reflect.TypeOf((*MockService)(nil).GetOperationsPage), arg0, arg1)
+}
+
+// GetOperationsSummary mocks base method.
+func (m *MockService) GetOperationsSummary(ctx context.Context, filter dto.OperationsFilter) (*dto.OperationsSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOperationsSummary", ctx, filter)
+	ret0, _ := ret[0].(*dto.OperationsSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOperationsSummary indicates an expected call of GetOperationsSummary.
+func (mr *MockServiceMockRecorder) GetOperationsSummary(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOperationsSummary", reflect.TypeOf((*MockService)(nil).GetOperationsSummary), ctx, filter)
+}
+
+// GetStatement mocks base method.
+func (m *MockService) GetStatement(ctx context.Context, walletName, period string) (*dto.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatement", ctx, walletName, period)
+	ret0, _ := ret[0].(*dto.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatement indicates an expected call of GetStatement.
+func (mr *MockServiceMockRecorder) GetStatement(ctx, walletName, period any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatement", reflect.TypeOf((*MockService)(nil).GetStatement), ctx, walletName, period)
 }
 
 // IncreaseWalletBalance mocks base method.
-func (m *MockService) IncreaseWalletBalance(arg0 dto.Deposit) error {
+func (m *MockService) IncreaseWalletBalance(arg0 context.Context, arg1 dto.Deposit) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IncreaseWalletBalance", arg0)
+	ret := m.ctrl.Call(m, "IncreaseWalletBalance", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // IncreaseWalletBalance indicates an expected call of IncreaseWalletBalance.
-func (mr *MockServiceMockRecorder) IncreaseWalletBalance(arg0 any) *gomock.Call {
+func (mr *MockServiceMockRecorder) IncreaseWalletBalance(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseWalletBalance", reflect.TypeOf((*MockService)(nil).IncreaseWalletBalance), arg0, arg1)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockService) ListAPIKeys(arg0 context.Context) ([]dto.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", arg0)
+	ret0, _ := ret[0].([]dto.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockServiceMockRecorder) ListAPIKeys(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockService)(nil).ListAPIKeys), arg0)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockService) RevokeAPIKey(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockServiceMockRecorder) RevokeAPIKey(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockService)(nil).RevokeAPIKey), ctx, name)
+}
+
+// ScanOperations mocks base method.
+func (m *MockService) ScanOperations(ctx context.Context, filter dto.OperationsFilter, f func(dto.Operation) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanOperations", ctx, filter, f)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScanOperations indicates an expected call of ScanOperations.
+func (mr *MockServiceMockRecorder) ScanOperations(ctx, filter, f any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseWalletBalance", reflect.TypeOf((*MockService)(nil).IncreaseWalletBalance), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanOperations", reflect.TypeOf((*MockService)(nil).ScanOperations), ctx, filter, f)
 }
 
 // Transfer mocks base method.
-func (m *MockService) Transfer(arg0 dto.Transfer) error {
+func (m *MockService) Transfer(arg0 context.Context, arg1 dto.Transfer) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Transfer", arg0)
+	ret := m.ctrl.Call(m, "Transfer", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Transfer indicates an expected call of Transfer.
-func (mr *MockServiceMockRecorder) Transfer(arg0 any) *gomock.Call {
+func (mr *MockServiceMockRecorder) Transfer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockService)(nil).Transfer), arg0, arg1)
+}
+
+// VerifyLedger mocks base method.
+func (m *MockService) VerifyLedger(ctx context.Context, checkpoint *dto.LedgerCheckpoint) (*dto.LedgerReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyLedger", ctx, checkpoint)
+	ret0, _ := ret[0].(*dto.LedgerReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyLedger indicates an expected call of VerifyLedger.
+func (mr *MockServiceMockRecorder) VerifyLedger(ctx, checkpoint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyLedger", reflect.TypeOf((*MockService)(nil).VerifyLedger), ctx, checkpoint)
+}
+
+// MockAuthenticator is a mock of Authenticator interface.
+type MockAuthenticator struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthenticatorMockRecorder
+	isgomock struct{}
+}
+
+// MockAuthenticatorMockRecorder is the mock recorder for MockAuthenticator.
+type MockAuthenticatorMockRecorder struct {
+	mock *MockAuthenticator
+}
+
+// NewMockAuthenticator creates a new mock instance.
+func NewMockAuthenticator(ctrl *gomock.Controller) *MockAuthenticator {
+	mock := &MockAuthenticator{ctrl: ctrl}
+	mock.recorder = &MockAuthenticatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthenticator) EXPECT() *MockAuthenticatorMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockAuthenticator) Authenticate(ctx context.Context, token string) (*auth.Caller, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, token)
+	ret0, _ := ret[0].(*auth.Caller)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockAuthenticatorMockRecorder) Authenticate(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAuthenticator)(nil).Authenticate), ctx, token)
+}
+
+// MockReadinessChecker is a mock of ReadinessChecker interface.
+type MockReadinessChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockReadinessCheckerMockRecorder
+	isgomock struct{}
+}
+
+// MockReadinessCheckerMockRecorder is the mock recorder for MockReadinessChecker.
+type MockReadinessCheckerMockRecorder struct {
+	mock *MockReadinessChecker
+}
+
+// NewMockReadinessChecker creates a new mock instance.
+func NewMockReadinessChecker(ctrl *gomock.Controller) *MockReadinessChecker {
+	mock := &MockReadinessChecker{ctrl: ctrl}
+	mock.recorder = &MockReadinessCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReadinessChecker) EXPECT() *MockReadinessCheckerMockRecorder {
+	return m.recorder
+}
+
+// Ready mocks base method.
+func (m *MockReadinessChecker) Ready(ctx context.Context) health.Report {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready", ctx)
+	ret0, _ := ret[0].(health.Report)
+	return ret0
+}
+
+// Ready indicates an expected call of Ready.
+func (mr *MockReadinessCheckerMockRecorder) Ready(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockService)(nil).Transfer), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*MockReadinessChecker)(nil).Ready), ctx)
 }
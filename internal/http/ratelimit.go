@@ -0,0 +1,89 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/ratelimit"
+)
+
+// maxPeekedBody limits the body read by rateLimitWallet, bigger bodies are not limited by wallet.
+const maxPeekedBody = 1 << 20
+
+// rateLimitIP limits requests per client IP, it runs before authentication to slow down guessing of keys.
+func (s *Server) rateLimitIP(next http.Handler) http.Handler {
+	if s.limiter == nil || !s.limiter.Enabled(ratelimit.KindIP) {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if allowed, retryAfter := s.limiter.Allow(r.Context(), ratelimit.KindIP, clientIP(r)); !allowed {
+			s.writeRateLimited(w, r, retryAfter)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitCaller limits requests per authenticated caller, an API key or a JWT subject.
+func (s *Server) rateLimitCaller(next http.Handler) http.Handler {
+	if s.limiter == nil || !s.limiter.Enabled(ratelimit.KindAPIKey) {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if caller, ok := auth.FromContext(r.Context()); ok {
+			if allowed, retryAfter := s.limiter.Allow(r.Context(), ratelimit.KindAPIKey, caller.ID); !allowed {
+				s.writeRateLimited(w, r, retryAfter)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitWallet limits requests per wallet taken from the field of JSON body,
+// it protects hot wallets from lock contention. The body is restored for the handler.
+func (s *Server) rateLimitWallet(field string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if s.limiter == nil || !s.limiter.Enabled(ratelimit.KindWallet) {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxPeekedBody))
+			if err != nil {
+				s.writeErrorResponse(w, r, ErrBodyDecode.Wrap(err))
+				return
+			}
+			r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+
+			var fields map[string]json.RawMessage
+			var wallet string
+			if json.Unmarshal(body, &fields) == nil {
+				_ = json.Unmarshal(fields[field], &wallet)
+			}
+
+			if allowed, retryAfter := s.limiter.Allow(r.Context(), ratelimit.KindWallet, wallet); !allowed {
+				s.writeRateLimited(w, r, retryAfter)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (s *Server) writeRateLimited(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	s.writeErrorResponse(w, r, ErrRateLimited)
+}
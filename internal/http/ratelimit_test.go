@@ -0,0 +1,97 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/ratelimit"
+)
+
+func TestServer_rateLimit(t *testing.T) {
+	limits := ratelimit.Limits{
+		APIKey: ratelimit.Limit{Rate: 1, Burst: 1},
+		IP:     ratelimit.Limit{Rate: 1, Burst: 1},
+		Wallet: ratelimit.Limit{Rate: 0.5, Burst: 1},
+	}
+
+	newServer := func() *Server {
+		return &Server{
+			log:     zap.NewNop().Sugar(),
+			limiter: ratelimit.NewLimiter(zap.NewNop().Sugar(), ratelimit.NewMemoryStore(), limits),
+		}
+	}
+
+	do := func(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	t.Run("per ip", func(t *testing.T) {
+		h := newServer().rateLimitIP(ok)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/operations", nil)
+		req.RemoteAddr = "10.0.0.1:1000"
+		assert.Equal(t, http.StatusOK, do(h, req).Code)
+
+		req.RemoteAddr = "10.0.0.1:1001"
+		rec := do(h, req)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+		assert.JSONEq(t, `{"error":"rate limit exceeded"}`, rec.Body.String())
+
+		req.RemoteAddr = "10.0.0.2:1000"
+		assert.Equal(t, http.StatusOK, do(h, req).Code)
+	})
+
+	t.Run("per caller", func(t *testing.T) {
+		h := newServer().rateLimitCaller(ok)
+		req := func(id string) *http.Request {
+			r := httptest.NewRequest(http.MethodGet, "/v1/wallets/operations", nil)
+			return r.WithContext(auth.NewContext(context.Background(), &auth.Caller{ID: id}))
+		}
+
+		assert.Equal(t, http.StatusOK, do(h, req("payouts")).Code)
+		assert.Equal(t, http.StatusTooManyRequests, do(h, req("payouts")).Code)
+		assert.Equal(t, http.StatusOK, do(h, req("reports")).Code)
+	})
+
+	t.Run("per wallet", func(t *testing.T) {
+		var bodies []string
+		h := newServer().rateLimitWallet("wallet_from")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+		}))
+		req := func(body string) *http.Request {
+			return httptest.NewRequest(http.MethodPost, "/v1/wallets/transfer", bytes.NewBufferString(body))
+		}
+
+		const body = `{"wallet_from":"hot","wallet_to":"w2","amount":1}`
+		assert.Equal(t, http.StatusOK, do(h, req(body)).Code)
+		assert.Equal(t, []string{body}, bodies, "body is restored for the handler")
+
+		rec := do(h, req(body))
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+
+		assert.Equal(t, http.StatusOK, do(h, req(`{"wallet_from":"cold","wallet_to":"hot","amount":1}`)).Code)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		s := &Server{log: zap.NewNop().Sugar()}
+		h := s.rateLimitIP(s.rateLimitCaller(s.rateLimitWallet("wallet")(ok)))
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusOK, do(h, httptest.NewRequest(http.MethodPost, "/v1/wallets/deposit", nil)).Code)
+		}
+	})
+}
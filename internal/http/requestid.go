@@ -0,0 +1,109 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+)
+
+// requestIDHeader is accepted from clients and echoed in responses.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength limits request IDs accepted from clients, longer IDs are replaced.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// requestID accepts the request ID from the client or generates a new one, echoes it in the response
+// and puts the request-scoped logger with request_id into the context.
+func (s *Server) requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		ctx = logging.NewContext(ctx, s.log.With("request_id", id))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requestIDFromContext returns the ID of the request set by requestID middleware.
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// validRequestID allows only short IDs of printable ASCII characters, so they can't break log lines.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// accessEntry collects data of the access log line that is known only deeper in the middleware chain.
+type accessEntry struct {
+	caller string
+}
+
+type accessEntryKey struct{}
+
+// accessLog writes one line per request with its route pattern, status, latency and the caller.
+func (s *Server) accessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		entry := &accessEntry{}
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		ctx := context.WithValue(r.Context(), accessEntryKey{}, entry)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		logging.FromContext(ctx, s.log).With(
+			"method", r.Method,
+			"route", route,
+			"status", status,
+			"latency", time.Since(start),
+			"bytes", ww.BytesWritten(),
+			"ip", clientIP(r),
+			"caller", entry.caller,
+		).Info("HTTP request")
+	})
+}
+
+// setAccessCaller records the identity of the authenticated caller for the access log.
+func setAccessCaller(ctx context.Context, caller *auth.Caller) {
+	if entry, ok := ctx.Value(accessEntryKey{}).(*accessEntry); ok {
+		entry.caller = caller.ID
+	}
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+)
+
+func TestServer_requestID(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		generated bool
+	}{
+		{name: "accepted from client", header: "req-1"},
+		{name: "generated if missing", generated: true},
+		{name: "replaced if too long", header: strings.Repeat("a", maxRequestIDLength+1), generated: true},
+		{name: "replaced if has control characters", header: "req-1\nfake log line", generated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.DebugLevel)
+			server := &Server{log: zap.New(core).Sugar()}
+
+			var ctxID string
+			handler := server.requestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctxID = requestIDFromContext(r.Context())
+				logging.FromContext(r.Context(), zap.NewNop().Sugar()).Info("handler")
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/wallets/operations", nil)
+			if tt.header != "" {
+				req.Header.Set(requestIDHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(requestIDHeader)
+			if tt.generated {
+				assert.Len(t, id, 32)
+			} else {
+				assert.Equal(t, tt.header, id)
+			}
+			assert.Equal(t, id, ctxID)
+
+			require.Equal(t, 1, logs.Len())
+			assert.Equal(t, id, logs.All()[0].ContextMap()["request_id"], "handlers log with request-scoped logger")
+		})
+	}
+}
+
+func TestServer_accessLog(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	server := &Server{
+		log:  zap.New(core).Sugar(),
+		auth: fakeAuthenticator{"wlt_key": {ID: "payouts"}},
+	}
+
+	router := chi.NewMux()
+	router.Use(server.requestID)
+	router.Use(server.accessLog)
+	router.Route("/v1", func(r chi.Router) {
+		r.Use(server.authenticate)
+		r.Get("/admin/api-keys/{name}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/api-keys/reports", nil)
+	req.RemoteAddr = "10.0.0.1:54321"
+	req.Header.Set("X-API-Key", "wlt_key")
+	req.Header.Set(requestIDHeader, "req-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, "HTTP request", entry.Message)
+	fields := entry.ContextMap()
+	assert.Equal(t, "req-1", fields["request_id"])
+	assert.Equal(t, http.MethodGet, fields["method"])
+	assert.Equal(t, "/v1/admin/api-keys/{name}", fields["route"])
+	assert.EqualValues(t, http.StatusNotFound, fields["status"])
+	assert.Equal(t, "10.0.0.1", fields["ip"])
+	assert.Equal(t, "payouts", fields["caller"])
+	assert.Contains(t, fields, "latency")
+}
+
+func TestSetAccessCaller_withoutEntry(t *testing.T) {
+	assert.NotPanics(t, func() {
+		setAccessCaller(context.Background(), &auth.Caller{ID: "payouts"})
+	})
+}
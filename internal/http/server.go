@@ -5,14 +5,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"mime"
+	"net"
 	"net/http"
-	"time"
+	"net/url"
 
 	"github.com/go-chi/chi"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 
 	"github.com/ezhdanovskiy/wallets/internal/httperr"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+	"github.com/ezhdanovskiy/wallets/internal/ratelimit"
 )
 
 type Server struct {
@@ -20,63 +24,125 @@ type Server struct {
 	httpPort   int
 	httpServer *http.Server
 	svc        Service
+	auth       Authenticator
+	limiter    *ratelimit.Limiter
+	readiness  ReadinessChecker
 }
 
-func NewServer(logger *zap.SugaredLogger, httpPort int, svc Service) *Server {
-	return &Server{
-		log:      logger,
-		httpPort: httpPort,
-		svc:      svc,
+// NewServer creates HTTP server, authentication is disabled if authenticator is nil,
+// rate limiting is disabled if limiter is nil, readiness checks are skipped if readiness is nil.
+func NewServer(logger *zap.SugaredLogger, httpPort int, svc Service, authenticator Authenticator,
+	limiter *ratelimit.Limiter, readiness ReadinessChecker) *Server {
+	s := &Server{
+		log:       logger,
+		httpPort:  httpPort,
+		svc:       svc,
+		auth:      authenticator,
+		limiter:   limiter,
+		readiness: readiness,
 	}
+
+	s.httpServer = &http.Server{
+		Addr:    fmt.Sprintf(":%d", httpPort),
+		Handler: s.router(),
+	}
+
+	return s
 }
 
 func (s *Server) Run() error {
+	lis, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	return s.Serve(lis)
+}
+
+// Serve accepts incoming connections on the listener until Shutdown is called.
+func (s *Server) Serve(lis net.Listener) error {
+	err := s.httpServer.Serve(lis)
+	if err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("start http server: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) router() http.Handler {
 	router := chi.NewMux()
+	router.Use(traced)
+	router.Use(instrument)
+	router.Use(s.requestID)
+	router.Use(s.accessLog)
+	router.Use(readConsistency)
 
 	router.Handle(
 		"/metrics",
 		promhttp.Handler(),
 	)
+	router.Get("/healthz", s.healthz)
+	router.Get("/readyz", s.readyz)
 
 	router.Route("/v1", s.GetV1ApiRouters())
 
-	s.httpServer = &http.Server{
-		Addr:    fmt.Sprintf(":%d", s.httpPort),
-		Handler: router,
-	}
-
-	err := s.httpServer.ListenAndServe()
-	if err != nil && err != http.ErrServerClosed {
-		return fmt.Errorf("start http server: %w", err)
-	}
-
-	return nil
+	return router
 }
 
 func (s *Server) GetV1ApiRouters() func(chi.Router) {
 	return func(r chi.Router) {
+		r.Use(s.auditSource)
+		r.Use(s.rateLimitIP)
+		r.Use(s.authenticate)
+		r.Use(s.rateLimitCaller)
+
 		r.Post("/wallets", s.createWallet)
-		r.Post("/wallets/deposit", s.deposit)
-		r.Post("/wallets/transfer", s.transfer)
+		r.With(s.rateLimitWallet("wallet")).Post("/wallets/deposit", s.deposit)
+		r.With(s.rateLimitWallet("wallet_from")).Post("/wallets/transfer", s.transfer)
 		r.Get("/wallets/operations", s.getOperations)
+		r.Get("/wallets/operations/export", s.exportOperations)
+		r.Get("/wallets/{name}/statements", s.getStatement)
+		r.Get("/wallets/{name}/balance", s.getBalance)
+		r.Get("/wallets/{name}/balance/daily", s.getDailyBalances)
+
+		r.Post("/imports", s.createImport)
+		r.Get("/imports/{id}", s.getImport)
+		r.Get("/imports/{id}/result", s.getImportResult)
+
+		r.Post("/admin/api-keys", s.createAPIKey)
+		r.Get("/admin/api-keys", s.listAPIKeys)
+		r.Delete("/admin/api-keys/{name}", s.revokeAPIKey)
+		r.Get("/admin/audit", s.getAuditRecords)
+		r.Get("/admin/ledger/verify", s.verifyLedger)
+		r.Post("/admin/ledger/checkpoints", s.createLedgerCheckpoint)
+		r.Get("/admin/ledger/checkpoints", s.getLedgerCheckpoints)
 	}
 }
 
-func (s *Server) Shutdown() {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+// Shutdown stops accepting new connections and waits for in-flight requests until ctx is done,
+// then closes the remaining connections.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.httpServer == nil {
+		return nil
+	}
 
 	if err := s.httpServer.Shutdown(ctx); err != nil {
-		s.log.Errorf("http server shutdown: %s", err)
+		_ = s.httpServer.Close()
+		return fmt.Errorf("http server shutdown: %w", err)
 	}
+	return nil
 }
 
 type Resp struct {
 	Error string      `json:"error,omitempty"`
 	Data  interface{} `json:"data,omitempty"`
+	// NextCursor and PrevCursor are set for paginated data if there are the next or the previous pages.
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
-func (s *Server) writeResponse(w http.ResponseWriter, code int, payload interface{}) {
+func (s *Server) writeResponse(w http.ResponseWriter, r *http.Request, code int, payload interface{}) {
+	log := logging.FromContext(r.Context(), s.log)
 	w.Header().Set("content-type", "application/json; charset=utf-8")
 	w.WriteHeader(code)
 
@@ -86,31 +152,81 @@ func (s *Server) writeResponse(w http.ResponseWriter, code int, payload interfac
 
 	if data, ok := payload.([]byte); ok {
 		if _, err := w.Write(data); err != nil {
-			s.log.Errorf("http response: %s", err.Error())
+			log.Errorf("http response: %s", err.Error())
 		}
 		return
 	}
 
-	data, err := json.Marshal(Resp{Data: payload})
+	s.writeJSON(w, r, Resp{Data: payload})
+}
+
+// writeCSVResponse writes csv data as an attachment with the file name.
+func (s *Server) writeCSVResponse(w http.ResponseWriter, r *http.Request, filename string, data []byte) {
+	s.writeFileResponse(w, r, "text/csv; charset=utf-8", filename, data)
+}
+
+// writeFileResponse sends the data as a downloaded file.
+func (s *Server) writeFileResponse(w http.ResponseWriter, r *http.Request, contentType, filename string, data []byte) {
+	setAttachment(w, contentType, filename)
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(data); err != nil {
+		logging.FromContext(r.Context(), s.log).Errorf("http response: %s", err.Error())
+	}
+}
+
+// setAttachment sets headers of a downloaded file, the name is escaped as RFC 6266 requires.
+func setAttachment(w http.ResponseWriter, contentType, filename string) {
+	w.Header().Set("content-type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+}
+
+// writePageResponse writes the page of data with cursors of the adjacent pages,
+// they are also sent as Link headers with the URLs of the pages.
+func (s *Server) writePageResponse(w http.ResponseWriter, r *http.Request, payload interface{}, next, prev string) {
+	setPageLinks(w, r, next, prev)
+	w.Header().Set("content-type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	s.writeJSON(w, r, Resp{Data: payload, NextCursor: next, PrevCursor: prev})
+}
+
+// setPageLinks sets Link headers of the adjacent pages, the URL of the request with the cursor replaced.
+func setPageLinks(w http.ResponseWriter, r *http.Request, next, prev string) {
+	for _, link := range []struct{ rel, cursor string }{{"next", next}, {"prev", prev}} {
+		if link.cursor == "" {
+			continue
+		}
+		query := r.URL.Query()
+		query.Del("offset")
+		query.Set("cursor", link.cursor)
+		u := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+		w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="%s"`, u.String(), link.rel))
+	}
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, r *http.Request, resp Resp) {
+	log := logging.FromContext(r.Context(), s.log)
+	data, err := json.Marshal(resp)
 	if err != nil {
-		s.log.With("error", err).Error("failed to marshal json")
+		log.With("error", err).Error("failed to marshal json")
 		return
 	}
-	s.log.Debugf("Send response: %s", data)
+	log.Debugf("Send response: %s", logging.RedactJSON(data))
 
 	if _, err := w.Write(data); err != nil {
-		s.log.Errorf("http response: %s", err.Error())
+		log.Errorf("http response: %s", err.Error())
 	}
 }
 
-func (s *Server) writeErrorResponse(w http.ResponseWriter, err error) {
+func (s *Server) writeErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	log := logging.FromContext(r.Context(), s.log)
 	w.Header().Set("content-type", "application/json; charset=utf-8")
 	if err == nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	s.log.Error(err.Error())
+	log.Error(err.Error())
 
 	var resp Resp
 	if e, ok := err.(*httperr.Error); ok {
@@ -123,12 +239,12 @@ func (s *Server) writeErrorResponse(w http.ResponseWriter, err error) {
 
 	data, err := json.Marshal(resp)
 	if err != nil {
-		s.log.With("error", err).Error("failed to marshal json")
+		log.With("error", err).Error("failed to marshal json")
 		return
 	}
 
 	if _, err := w.Write(data); err != nil {
-		s.log.Errorf("http error response: %s", err.Error())
+		log.Errorf("http error response: %s", err.Error())
 	}
 	return
 }
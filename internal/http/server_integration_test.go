@@ -1,8 +1,12 @@
 package http
 
 import (
+	"context"
 	"errors"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,6 +14,7 @@ import (
 	"github.com/ezhdanovskiy/wallets/internal/http/mocks"
 	"go.uber.org/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -18,7 +23,7 @@ func TestServer_Run(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockService := mocks.NewMockService(ctrl)
-	server := NewServer(zap.NewNop().Sugar(), 0, mockService)
+	server := NewServer(zap.NewNop().Sugar(), 0, mockService, nil, nil, nil)
 
 	// Start server in goroutine
 	go func() {
@@ -29,7 +34,95 @@ func TestServer_Run(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 
 	// Shutdown server
-	server.Shutdown()
+	assert.NoError(t, server.Shutdown(context.Background()))
+}
+
+func TestServer_Shutdown_waits_for_in_flight_transfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockService := mocks.NewMockService(ctrl)
+	mockService.EXPECT().Transfer(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, dto.Transfer) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	server := NewServer(zap.NewNop().Sugar(), 0, mockService, nil, nil, nil)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(lis) }()
+
+	type result struct {
+		code int
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		body := `{"wallet_from":"wallet1","wallet_to":"wallet2","amount":10}`
+		resp, err := http.Post("http://"+lis.Addr().String()+"/v1/wallets/transfer", "application/json", strings.NewReader(body))
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		_ = resp.Body.Close()
+		responses <- result{code: resp.StatusCode}
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdown <- server.Shutdown(ctx)
+	}()
+
+	select {
+	case err := <-shutdown:
+		t.Fatalf("shutdown returned before the transfer completed: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	res := <-responses
+	require.NoError(t, res.err)
+	assert.Equal(t, http.StatusOK, res.code)
+	assert.NoError(t, <-shutdown)
+	assert.NoError(t, <-served)
+}
+
+func TestServer_Shutdown_timeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	started := make(chan struct{})
+	mockService := mocks.NewMockService(ctrl)
+	mockService.EXPECT().Transfer(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ dto.Transfer) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	server := NewServer(zap.NewNop().Sugar(), 0, mockService, nil, nil, nil)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(lis) }()
+
+	go func() {
+		body := `{"wallet_from":"wallet1","wallet_to":"wallet2","amount":10}`
+		resp, err := http.Post("http://"+lis.Addr().String()+"/v1/wallets/transfer", "application/json", strings.NewReader(body))
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, server.Shutdown(ctx), context.DeadlineExceeded)
 }
 
 func TestServer_GetV1ApiRouters(t *testing.T) {
@@ -55,14 +148,14 @@ func TestServer_handlers_with_CSV_error(t *testing.T) {
 	req, _ := http.NewRequest("GET", "/v1/wallets/operations?wallet=wallet1&format=csv", nil)
 	w := &errResponseWriter{}
 
-	mockService.EXPECT().GetOperations(gomock.Any()).Return([]dto.Operation{
+	mockService.EXPECT().GetOperationsPage(gomock.Any(), gomock.Any()).Return(&dto.OperationsPage{Operations: []dto.Operation{
 		{
 			Wallet:    "wallet1",
 			Type:      "deposit",
 			Amount:    dto.Amount(10000),
 			Timestamp: time.Unix(1234567890, 0).UTC(),
 		},
-	}, nil)
+	}}, nil)
 
 	server.getOperations(w, req)
 	assert.Equal(t, http.StatusOK, w.statusCode)
@@ -77,7 +170,7 @@ func TestServer_writeResponse_marshal_error(t *testing.T) {
 	ch := make(chan int)
 	
 	w := &errResponseWriter{}
-	server.writeResponse(w, http.StatusOK, ch)
+	server.writeResponse(w, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, ch)
 	
 	assert.Equal(t, http.StatusOK, w.statusCode)
 }
@@ -91,7 +184,7 @@ func TestServer_writeErrorResponse_marshal_error(t *testing.T) {
 	customErr := &customError{}
 	
 	w := &errResponseWriter{}
-	server.writeErrorResponse(w, customErr)
+	server.writeErrorResponse(w, httptest.NewRequest(http.MethodGet, "/", nil), customErr)
 	
 	assert.Equal(t, http.StatusInternalServerError, w.statusCode)
 }
@@ -16,12 +16,13 @@ func TestNewServer(t *testing.T) {
 	logger := zap.NewNop().Sugar()
 	port := 8080
 	
-	server := NewServer(logger, port, nil)
+	server := NewServer(logger, port, nil, nil, nil, nil)
 	
 	assert.NotNil(t, server)
 	assert.Equal(t, logger, server.log)
 	assert.Equal(t, port, server.httpPort)
 	assert.Nil(t, server.svc)
+	assert.Nil(t, server.auth)
 }
 
 func TestServer_writeResponse(t *testing.T) {
@@ -70,7 +71,7 @@ func TestServer_writeResponse(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			rec := httptest.NewRecorder()
 			
-			server.writeResponse(rec, tt.code, tt.payload)
+			server.writeResponse(rec, httptest.NewRequest(http.MethodGet, "/", nil), tt.code, tt.payload)
 			
 			assert.Equal(t, tt.expectedCode, rec.Code)
 			assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("content-type"))
@@ -127,7 +128,7 @@ func TestServer_writeErrorResponse(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			rec := httptest.NewRecorder()
 			
-			server.writeErrorResponse(rec, tt.err)
+			server.writeErrorResponse(rec, httptest.NewRequest(http.MethodGet, "/", nil), tt.err)
 			
 			assert.Equal(t, tt.expectedCode, rec.Code)
 			assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("content-type"))
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+
+	"github.com/ezhdanovskiy/wallets/internal/httperr"
+	"github.com/ezhdanovskiy/wallets/internal/statements"
+)
+
+// getStatement sends the statement of the wallet for the month of the period parameter as a file
+// in the format of the format parameter, CSV by default.
+func (s *Server) getStatement(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	period := query.Get("period")
+	if period == "" {
+		s.writeErrorResponse(w, r, httperr.New(http.StatusBadRequest, "period is required"))
+		return
+	}
+
+	format := statements.CSV
+	if name := query.Get("format"); name != "" {
+		var ok bool
+		if format, ok = statements.Lookup(name); !ok {
+			s.writeErrorResponse(w, r, httperr.New(http.StatusBadRequest, "unsupported format %s, use one of %s",
+				name, strings.Join(statements.Names(), ", ")))
+			return
+		}
+	}
+
+	st, err := s.svc.GetStatement(r.Context(), chi.URLParam(r, "name"), period)
+	if err != nil {
+		s.writeErrorResponse(w, r, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := format.Write(&buf, st); err != nil {
+		s.writeErrorResponse(w, r, httperr.Wrap(err, http.StatusInternalServerError, "failed to write statement"))
+		return
+	}
+
+	s.writeFileResponse(w, r, format.ContentType, fmt.Sprintf("statement-%s-%s.%s", st.Wallet, st.Period, format.Extension),
+		buf.Bytes())
+}
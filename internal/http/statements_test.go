@@ -0,0 +1,130 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/http/mocks"
+)
+
+func TestServer_getStatement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockService(ctrl)
+	server := &Server{
+		log: zap.NewNop().Sugar(),
+		svc: mockService,
+	}
+	router := chi.NewMux()
+	router.Route("/v1", server.GetV1ApiRouters())
+
+	statement := func(wallet string) *dto.Statement {
+		return &dto.Statement{
+			Period: "2026-09",
+			OperationsSummary: dto.OperationsSummary{
+				Wallet:         wallet,
+				Currency:       "USD",
+				StartDate:      time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:        time.Date(2026, 9, 30, 23, 59, 59, 999999000, time.UTC),
+				OpeningBalance: 10,
+				ClosingBalance: 10,
+			},
+			Entries:     []dto.StatementEntry{},
+			GeneratedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+
+	tests := []struct {
+		name                string
+		url                 string
+		mockSetup           func()
+		expectedStatus      int
+		expectedContentType string
+		expectedDisposition string
+		expectedBody        string
+	}{
+		{
+			name: "csv",
+			url:  "/v1/wallets/wallet1/statements?period=2026-09",
+			mockSetup: func() {
+				mockService.EXPECT().GetStatement(gomock.Any(), "wallet1", "2026-09").Return(statement("wallet1"), nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedDisposition: "attachment; filename=statement-wallet1-2026-09.csv",
+			expectedBody: "timestamp,type,other_wallet,amount,balance\n" +
+				"2026-09-01T00:00:00Z,opening_balance,,,10.00\n" +
+				"2026-09-30T23:59:59.999999Z,closing_balance,,,10.00\n",
+		},
+		{
+			name: "json of the wallet named like a route",
+			url:  "/v1/wallets/operations/statements?period=2026-09&format=json",
+			mockSetup: func() {
+				mockService.EXPECT().GetStatement(gomock.Any(), "operations", "2026-09").Return(statement("operations"), nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedDisposition: "attachment; filename=statement-operations-2026-09.json",
+			expectedBody: `{
+  "period": "2026-09",
+  "wallet": "operations",
+  "currency": "USD",
+  "start_date": "2026-09-01T00:00:00Z",
+  "end_date": "2026-09-30T23:59:59.999999Z",
+  "opening_balance": 10,
+  "closing_balance": 10,
+  "deposits": {
+    "count": 0,
+    "amount": 0
+  },
+  "withdrawals": {
+    "count": 0,
+    "amount": 0
+  },
+  "entries": [],
+  "generated_at": "2026-10-01T00:00:00Z"
+}
+`,
+		},
+		{
+			name:                "missing period",
+			url:                 "/v1/wallets/wallet1/statements",
+			mockSetup:           func() {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"period is required"}`,
+		},
+		{
+			name:                "unsupported format",
+			url:                 "/v1/wallets/wallet1/statements?period=2026-09&format=pdf",
+			mockSetup:           func() {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"unsupported format pdf, use one of csv, json, html"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedContentType, rec.Header().Get("content-type"))
+			assert.Equal(t, tt.expectedDisposition, rec.Header().Get("Content-Disposition"))
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
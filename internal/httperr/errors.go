@@ -24,6 +24,11 @@ func (e Error) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the wrapped error, so errors.Is and errors.As can inspect it.
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
 func (e Error) Wrap(err error) *Error {
 	return &Error{
 		Message:    e.Message,
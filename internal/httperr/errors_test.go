@@ -92,6 +92,7 @@ func TestWrap(t *testing.T) {
 	assert.Equal(t, originalErr.Message, newErr.Message)
 	assert.Equal(t, originalErr.StatusCode, newErr.StatusCode)
 	assert.Equal(t, wrappedErr, newErr.Err)
+	assert.True(t, errors.Is(newErr, wrappedErr))
 }
 
 func TestWrapFunction(t *testing.T) {
@@ -0,0 +1,246 @@
+// Package imports reads CSV files of deposits and transfers applied in bulk and writes the results of their rows.
+package imports
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+// ErrUnknownKind is returned for a kind other than deposits and transfers.
+var ErrUnknownKind = errors.New("unknown kind of import")
+
+// KeyColumn is the optional column of idempotency keys, the key of a row without it is derived from
+// the hash of the file and the line, so the same file uploaded again is skipped.
+const KeyColumn = "idempotency_key"
+
+// maxKeyLength limits idempotency keys, they are stored in an index.
+const maxKeyLength = 255
+
+// columns are the required columns of files of every kind in the order of the result file.
+var columns = map[string][]string{
+	consts.ImportKindDeposits:  {"wallet", "amount"},
+	consts.ImportKindTransfers: {"wallet_from", "wallet_to", "amount"},
+}
+
+// amountPattern is a positive decimal with at most two digits after the point, amounts aren't rounded.
+var amountPattern = regexp.MustCompile(`^([0-9]+)(?:\.([0-9]{1,2}))?$`)
+
+// File is the parsed import file.
+type File struct {
+	// Hash is SHA-256 of the content.
+	Hash string
+	// Total is the number of rows including invalid ones.
+	Total int
+	// Rows are the valid rows, they are pending.
+	Rows   []dto.ImportRow
+	Errors []dto.ImportError
+}
+
+// Parse reads the import file of the kind, every row is checked and problems are reported by lines.
+// The header names the columns in any order, unknown columns are ignored.
+// A UTF-8 BOM added by spreadsheets is skipped, delimiter is comma if it is 0.
+func Parse(data []byte, kind string, delimiter rune) (*File, error) {
+	required, ok := columns[kind]
+	if !ok {
+		return nil, ErrUnknownKind
+	}
+
+	sum := sha256.Sum256(data)
+	file := &File{Hash: hex.EncodeToString(sum[:])}
+	addError := func(line int, format string, args ...interface{}) {
+		file.Errors = append(file.Errors, dto.ImportError{Line: line, Error: fmt.Sprintf(format, args...)})
+	}
+
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\uFEFF"))))
+	if delimiter != 0 {
+		r.Comma = delimiter
+	}
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		addError(1, "header is missing")
+		return file, nil
+	}
+	if err != nil {
+		addError(1, "%s", parseError(err))
+		return file, nil
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := index[name]; ok {
+			addError(1, "column %s repeats", name)
+		}
+		index[name] = i
+	}
+	for _, name := range required {
+		if _, ok := index[name]; !ok {
+			addError(1, "column %s is missing", name)
+		}
+	}
+	if len(file.Errors) > 0 {
+		return file, nil
+	}
+
+	keyLines := make(map[string]int)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Fields after a broken quote can't be told apart, the rest of the file isn't checked.
+			var pe *csv.ParseError
+			if errors.As(err, &pe) {
+				file.Total++
+				addError(pe.StartLine, "%s", parseError(err))
+			}
+			break
+		}
+		file.Total++
+		line, _ := r.FieldPos(0)
+
+		if len(record) != len(header) {
+			addError(line, "expected %d fields, got %d", len(header), len(record))
+			continue
+		}
+		field := func(name string) string {
+			return strings.TrimSpace(record[index[name]])
+		}
+
+		row := dto.ImportRow{Line: line, Status: consts.ImportStatusPending}
+		var problems []string
+		switch kind {
+		case consts.ImportKindDeposits:
+			row.Wallet = field("wallet")
+			if row.Wallet == "" {
+				problems = append(problems, "wallet is empty")
+			}
+		case consts.ImportKindTransfers:
+			row.Wallet, row.WalletTo = field("wallet_from"), field("wallet_to")
+			if row.Wallet == "" {
+				problems = append(problems, "wallet_from is empty")
+			}
+			if row.WalletTo == "" {
+				problems = append(problems, "wallet_to is empty")
+			}
+			if row.Wallet != "" && row.Wallet == row.WalletTo {
+				problems = append(problems, "wallet_from and wallet_to are the same")
+			}
+		}
+
+		row.Amount, err = ParseAmount(field("amount"))
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+
+		if _, ok := index[KeyColumn]; ok {
+			row.Key = field(KeyColumn)
+		}
+		if row.Key == "" {
+			row.Key = fmt.Sprintf("%s:%d", file.Hash, line)
+		}
+		if len(row.Key) > maxKeyLength {
+			problems = append(problems, fmt.Sprintf("%s is longer than %d characters", KeyColumn, maxKeyLength))
+		} else if first, ok := keyLines[row.Key]; ok {
+			problems = append(problems, fmt.Sprintf("%s %q repeats line %d", KeyColumn, row.Key, first))
+		} else {
+			keyLines[row.Key] = line
+		}
+
+		if len(problems) > 0 {
+			addError(line, "%s", strings.Join(problems, ", "))
+			continue
+		}
+		file.Rows = append(file.Rows, row)
+	}
+
+	if file.Total == 0 {
+		addError(1, "file has no rows")
+	}
+	return file, nil
+}
+
+// ParseAmount parses the positive amount with at most two decimals into cents,
+// the cents must fit into the bigint column of balances.
+func ParseAmount(s string) (uint64, error) {
+	m := amountPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("amount %q must be a number with at most two decimals", s)
+	}
+
+	units, err := strconv.ParseUint(m[1], 10, 64)
+	if err != nil || units >= math.MaxInt64/100 {
+		return 0, fmt.Errorf("amount %q is too large", s)
+	}
+	cents := units * 100
+	if m[2] != "" {
+		fraction, _ := strconv.ParseUint(m[2], 10, 64)
+		if len(m[2]) == 1 {
+			fraction *= 10
+		}
+		cents += fraction
+	}
+	if cents == 0 {
+		return 0, errors.New("amount must be positive")
+	}
+	return cents, nil
+}
+
+// parseError returns the message of the CSV error without the position, it is reported as the line.
+func parseError(err error) string {
+	var pe *csv.ParseError
+	if errors.As(err, &pe) {
+		return pe.Err.Error()
+	}
+	return err.Error()
+}
+
+// WriteResult writes the rows of the import with their statuses in CSV, the columns are the line of the row,
+// the columns of the kind, the idempotency key, the status and the error.
+func WriteResult(w io.Writer, kind string, rows []dto.ImportRow) error {
+	required, ok := columns[kind]
+	if !ok {
+		return ErrUnknownKind
+	}
+
+	cw := csv.NewWriter(w)
+	header := append([]string{"line"}, required...)
+	if err := cw.Write(append(header, KeyColumn, "status", "error")); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := []string{strconv.Itoa(row.Line), row.Wallet}
+		if kind == consts.ImportKindTransfers {
+			record = append(record, row.WalletTo)
+		}
+		record = append(record, FormatAmount(row.Amount), row.Key, row.Status, row.Error)
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// FormatAmount formats cents with two decimals.
+func FormatAmount(cents uint64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
@@ -0,0 +1,115 @@
+package imports
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("deposits", func(t *testing.T) {
+		data := []byte("\uFEFFAmount; Wallet; Note\n10.5; wallet1; first\n0.01;wallet2;\n")
+		file, err := Parse(data, consts.ImportKindDeposits, ';')
+		require.NoError(t, err)
+		assert.Empty(t, file.Errors)
+		assert.Equal(t, 2, file.Total)
+		assert.Len(t, file.Hash, 64)
+		assert.Equal(t, []dto.ImportRow{
+			{Line: 2, Key: file.Hash + ":2", Wallet: "wallet1", Amount: 1050, Status: consts.ImportStatusPending},
+			{Line: 3, Key: file.Hash + ":3", Wallet: "wallet2", Amount: 1, Status: consts.ImportStatusPending},
+		}, file.Rows)
+	})
+
+	t.Run("transfers", func(t *testing.T) {
+		data := []byte("wallet_from,wallet_to,amount,idempotency_key\n" +
+			"wallet1,wallet2,1,key-1\n" +
+			"wallet1,wallet1,1,key-2\n" +
+			",wallet2,1,key-1\n" +
+			"wallet1,wallet2,0,\n" +
+			"wallet1,wallet2\n" +
+			"wallet2,wallet1,2.345,key-3\n")
+		file, err := Parse(data, consts.ImportKindTransfers, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 6, file.Total)
+		assert.Equal(t, []dto.ImportRow{
+			{Line: 2, Key: "key-1", Wallet: "wallet1", WalletTo: "wallet2", Amount: 100, Status: consts.ImportStatusPending},
+		}, file.Rows)
+		assert.Equal(t, []dto.ImportError{
+			{Line: 3, Error: "wallet_from and wallet_to are the same"},
+			{Line: 4, Error: `wallet_from is empty, idempotency_key "key-1" repeats line 2`},
+			{Line: 5, Error: "amount must be positive"},
+			{Line: 6, Error: "expected 4 fields, got 2"},
+			{Line: 7, Error: `amount "2.345" must be a number with at most two decimals`},
+		}, file.Errors)
+	})
+
+	t.Run("header", func(t *testing.T) {
+		for name, tt := range map[string]struct {
+			data string
+			want []dto.ImportError
+		}{
+			"missing":         {"", []dto.ImportError{{Line: 1, Error: "header is missing"}}},
+			"no rows":         {"wallet,amount\n", []dto.ImportError{{Line: 1, Error: "file has no rows"}}},
+			"missing column":  {"wallet\nwallet1\n", []dto.ImportError{{Line: 1, Error: "column amount is missing"}}},
+			"repeated column": {"wallet,amount,Wallet\n", []dto.ImportError{{Line: 1, Error: "column wallet repeats"}}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				file, err := Parse([]byte(tt.data), consts.ImportKindDeposits, 0)
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, file.Errors)
+				assert.Empty(t, file.Rows)
+			})
+		}
+	})
+
+	t.Run("broken quote", func(t *testing.T) {
+		data := []byte("wallet,amount\nwallet1,1\n\"wallet2,2\nwallet3,3\n")
+		file, err := Parse(data, consts.ImportKindDeposits, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 2, file.Total)
+		assert.Len(t, file.Rows, 1)
+		require.Len(t, file.Errors, 1)
+		assert.Equal(t, 3, file.Errors[0].Line)
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		_, err := Parse([]byte("wallet,amount\n"), "withdrawals", 0)
+		assert.Equal(t, ErrUnknownKind, err)
+	})
+}
+
+func TestParseAmount(t *testing.T) {
+	for s, want := range map[string]uint64{"1": 100, "0.5": 50, "0.29": 29, "123.45": 12345, "007.10": 710} {
+		got, err := ParseAmount(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, got, s)
+	}
+
+	for _, s := range []string{"", "0", "0.00", "-1", "1.234", "1,5", ".5", "1e3", "92233720368547758"} {
+		_, err := ParseAmount(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestWriteResult(t *testing.T) {
+	rows := []dto.ImportRow{
+		{Line: 2, Key: "key-1", Wallet: "wallet1", WalletTo: "wallet2", Amount: 1050, Status: consts.ImportStatusApplied},
+		{Line: 3, Key: "key-2", Wallet: "wallet1", WalletTo: "wallet2", Amount: 7, Status: consts.ImportStatusFailed,
+			Error: "not enough money"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteResult(&buf, consts.ImportKindTransfers, rows))
+	assert.Equal(t, "line,wallet_from,wallet_to,amount,idempotency_key,status,error\n"+
+		"2,wallet1,wallet2,10.50,key-1,applied,\n"+
+		"3,wallet1,wallet2,0.07,key-2,failed,not enough money\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteResult(&buf, consts.ImportKindDeposits, rows[:1]))
+	assert.Equal(t, "line,wallet,amount,idempotency_key,status,error\n2,wallet1,10.50,key-1,applied,\n", buf.String())
+}
@@ -0,0 +1,176 @@
+package ledger
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+// Signer signs checkpoints with Ed25519 key, so they can be archived outside the database and checked later.
+type Signer struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// NewSigner creates a signer with the key.
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	return &Signer{
+		key:   key,
+		keyID: KeyID(key.Public().(ed25519.PublicKey)),
+	}
+}
+
+// LoadSigner reads PEM encoded PKCS #8 Ed25519 private key, like one generated by
+// `openssl genpkey -algorithm ed25519`.
+func LoadSigner(path string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("signing key is not PEM encoded")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse signing key: %w", err)
+	}
+
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key must be Ed25519, got %T", key)
+	}
+
+	return NewSigner(edKey), nil
+}
+
+// LoadPublicKey reads PEM encoded PKIX Ed25519 public key, like one printed by `openssl pkey -pubout`.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read public key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("public key is not PEM encoded")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key must be Ed25519, got %T", key)
+	}
+	return edKey, nil
+}
+
+// PublicKey returns the public key that verifies signatures.
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// NewCheckpoint builds a signed checkpoint from heads of wallet chains.
+func (s *Signer) NewCheckpoint(heads map[string]dto.LedgerHead, operations, lastOperationID int64) (*dto.LedgerCheckpoint, error) {
+	cp := &dto.LedgerCheckpoint{
+		CreatedAt:       time.Now().UTC(),
+		Operations:      operations,
+		LastOperationID: lastOperationID,
+		RootHash:        RootHash(heads),
+		Heads:           heads,
+		KeyID:           s.keyID,
+	}
+
+	payload, err := signedPayload(cp)
+	if err != nil {
+		return nil, err
+	}
+	cp.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, payload))
+
+	return cp, nil
+}
+
+// VerifyCheckpoint checks the signature and the root hash of the checkpoint.
+func VerifyCheckpoint(publicKey ed25519.PublicKey, cp *dto.LedgerCheckpoint) error {
+	if cp.KeyID != KeyID(publicKey) {
+		return fmt.Errorf("checkpoint is signed by another key %q", cp.KeyID)
+	}
+	if cp.RootHash != RootHash(cp.Heads) {
+		return errors.New("checkpoint root hash doesn't match heads")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(cp.Signature)
+	if err != nil {
+		return fmt.Errorf("decode signature: %w", err)
+	}
+
+	payload, err := signedPayload(cp)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(publicKey, payload, signature) {
+		return errors.New("invalid checkpoint signature")
+	}
+	return nil
+}
+
+// RootHash returns SHA-256 of heads sorted by wallet.
+func RootHash(heads map[string]dto.LedgerHead) string {
+	wallets := make([]string, 0, len(heads))
+	for wallet := range heads {
+		wallets = append(wallets, wallet)
+	}
+	sort.Strings(wallets)
+
+	var b strings.Builder
+	for _, wallet := range wallets {
+		head := heads[wallet]
+		b.WriteString(strconv.Itoa(len(wallet)))
+		b.WriteByte(':')
+		b.WriteString(wallet)
+		b.WriteByte(',')
+		b.WriteString(strconv.FormatInt(head.Seq, 10))
+		b.WriteByte(',')
+		b.WriteString(head.Hash)
+		b.WriteByte('\n')
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// KeyID returns short fingerprint of the public key.
+func KeyID(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(sum[:8])
+}
+
+// signedPayload is JSON of the checkpoint without id and signature,
+// the id is assigned by the database after signing.
+func signedPayload(cp *dto.LedgerCheckpoint) ([]byte, error) {
+	unsigned := *cp
+	unsigned.ID = 0
+	unsigned.Signature = ""
+	data, err := json.Marshal(unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("marshal checkpoint: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,183 @@
+// Package ledger implements the tamper-evident hash chain over operations.
+// Every operation stores its hash and the hash of the previous operation of the same wallet,
+// so editing, inserting or removing a row breaks the chain from that row on.
+package ledger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+// Reasons of broken links.
+const (
+	ReasonSeqGap             = "unexpected seq"
+	ReasonPrevHashMismatch   = "prev_hash doesn't match hash of the previous operation"
+	ReasonHashMismatch       = "hash doesn't match operation contents"
+	ReasonCheckpointMismatch = "hash differs from checkpoint"
+	ReasonCheckpointMissing  = "operations covered by checkpoint are missing"
+)
+
+// timestampLayout keeps microseconds, the precision of Postgres timestamps.
+// The same format is used by the migration that hashes operations created before the chain.
+const timestampLayout = "2006-01-02T15:04:05.000000Z"
+
+// Entry is an operation as a link of the wallet chain.
+type Entry struct {
+	ID          int64
+	Wallet      string
+	Seq         int64
+	Type        string
+	Amount      uint64
+	OtherWallet string
+	CreatedAt   time.Time
+	PrevHash    string
+	Hash        string
+}
+
+// Now returns the current time with the precision stored in the database, so it can be hashed before insert.
+func Now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// Hash returns SHA-256 of the entry contents and the previous hash.
+// Fields are length-prefixed, so values can't be shifted from one field to another.
+func Hash(e Entry) string {
+	var b strings.Builder
+	for _, field := range []string{
+		e.Wallet,
+		strconv.FormatInt(e.Seq, 10),
+		e.Type,
+		strconv.FormatUint(e.Amount, 10),
+		e.OtherWallet,
+		e.CreatedAt.UTC().Format(timestampLayout),
+		e.PrevHash,
+	} {
+		b.WriteString(strconv.Itoa(len(field)))
+		b.WriteByte(':')
+		b.WriteString(field)
+		b.WriteByte(',')
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// Verifier walks entries ordered by wallet and seq and stops at the first broken link.
+type Verifier struct {
+	checkpoint *dto.LedgerCheckpoint
+	heads      map[string]dto.LedgerHead
+	wallet     string
+	head       dto.LedgerHead
+	operations int64
+	lastID     int64
+	broken     *dto.LedgerBrokenLink
+}
+
+// NewVerifier creates a verifier, operations covered by the checkpoint must be present in the chain.
+func NewVerifier(checkpoint *dto.LedgerCheckpoint) *Verifier {
+	return &Verifier{
+		checkpoint: checkpoint,
+		heads:      make(map[string]dto.LedgerHead),
+	}
+}
+
+// Add checks the next entry, it returns false when the chain is broken.
+func (v *Verifier) Add(e Entry) bool {
+	if v.broken != nil {
+		return false
+	}
+
+	if e.Wallet != v.wallet {
+		if !v.finishWallet() {
+			return false
+		}
+		v.wallet = e.Wallet
+		v.head = dto.LedgerHead{}
+	}
+
+	switch {
+	case e.Seq != v.head.Seq+1:
+		v.breakAt(e, ReasonSeqGap)
+	case e.PrevHash != v.head.Hash:
+		v.breakAt(e, ReasonPrevHashMismatch)
+	case Hash(e) != e.Hash:
+		v.breakAt(e, ReasonHashMismatch)
+	case v.checkpoint != nil && v.checkpoint.Heads[e.Wallet].Seq == e.Seq && v.checkpoint.Heads[e.Wallet].Hash != e.Hash:
+		v.breakAt(e, ReasonCheckpointMismatch)
+	}
+	if v.broken != nil {
+		return false
+	}
+
+	v.head = dto.LedgerHead{Seq: e.Seq, Hash: e.Hash}
+	v.operations++
+	if e.ID > v.lastID {
+		v.lastID = e.ID
+	}
+	return true
+}
+
+// Heads returns the last entry of every wallet chain, it is valid after Report.
+func (v *Verifier) Heads() map[string]dto.LedgerHead {
+	return v.heads
+}
+
+// LastOperationID returns the biggest id of the verified operations.
+func (v *Verifier) LastOperationID() int64 {
+	return v.lastID
+}
+
+// Report finishes the verification and returns its result.
+func (v *Verifier) Report() dto.LedgerReport {
+	if v.broken == nil && v.finishWallet() && v.checkpoint != nil {
+		for wallet, head := range v.checkpoint.Heads {
+			if _, ok := v.heads[wallet]; !ok {
+				v.broken = &dto.LedgerBrokenLink{Wallet: wallet, Seq: head.Seq, Reason: ReasonCheckpointMissing}
+				break
+			}
+		}
+	}
+
+	report := dto.LedgerReport{
+		OK:         v.broken == nil,
+		Wallets:    len(v.heads),
+		Operations: v.operations,
+		Broken:     v.broken,
+	}
+	if v.checkpoint != nil {
+		report.CheckpointID = v.checkpoint.ID
+	}
+	return report
+}
+
+// finishWallet stores the head of the current wallet and checks that the chain isn't shorter than in checkpoint.
+func (v *Verifier) finishWallet() bool {
+	if v.wallet == "" || v.broken != nil {
+		return v.broken == nil
+	}
+	if _, done := v.heads[v.wallet]; done {
+		return true
+	}
+
+	v.heads[v.wallet] = v.head
+	if v.checkpoint != nil {
+		if cp, ok := v.checkpoint.Heads[v.wallet]; ok && cp.Seq > v.head.Seq {
+			v.broken = &dto.LedgerBrokenLink{Wallet: v.wallet, Seq: v.head.Seq + 1, Reason: ReasonCheckpointMissing}
+			return false
+		}
+	}
+	return true
+}
+
+func (v *Verifier) breakAt(e Entry, reason string) {
+	v.broken = &dto.LedgerBrokenLink{
+		Wallet:      e.Wallet,
+		Seq:         e.Seq,
+		OperationID: e.ID,
+		Reason:      reason,
+	}
+}
@@ -0,0 +1,199 @@
+package ledger
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+// chain builds valid chains of n operations for every wallet, ordered by wallet and seq.
+func chain(n int, wallets ...string) []Entry {
+	var entries []Entry
+	id := int64(0)
+	for _, wallet := range wallets {
+		prev := ""
+		for seq := int64(1); seq <= int64(n); seq++ {
+			id++
+			e := Entry{
+				ID:          id,
+				Wallet:      wallet,
+				Seq:         seq,
+				Type:        "deposit",
+				Amount:      uint64(seq * 100),
+				OtherWallet: "system",
+				CreatedAt:   time.Date(2026, 1, 2, 3, 4, 5, int(seq)*1000, time.UTC),
+				PrevHash:    prev,
+			}
+			e.Hash = Hash(e)
+			prev = e.Hash
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+func verify(entries []Entry, cp *dto.LedgerCheckpoint) (*Verifier, dto.LedgerReport) {
+	v := NewVerifier(cp)
+	for _, e := range entries {
+		if !v.Add(e) {
+			break
+		}
+	}
+	return v, v.Report()
+}
+
+func TestHash(t *testing.T) {
+	e := chain(1, "w1")[0]
+	assert.Len(t, e.Hash, 64)
+
+	shifted := e
+	shifted.Wallet, shifted.OtherWallet = "w1s", "ystem"
+	assert.NotEqual(t, e.Hash, Hash(shifted))
+
+	local := e
+	local.CreatedAt = e.CreatedAt.In(time.FixedZone("UTC+3", 3*60*60))
+	assert.Equal(t, e.Hash, Hash(local))
+}
+
+func TestVerifier(t *testing.T) {
+	t.Run("intact", func(t *testing.T) {
+		v, report := verify(chain(3, "w1", "w2"), nil)
+		assert.True(t, report.OK)
+		assert.Equal(t, 2, report.Wallets)
+		assert.Equal(t, int64(6), report.Operations)
+		assert.Equal(t, int64(6), v.LastOperationID())
+		assert.Equal(t, int64(3), v.Heads()["w2"].Seq)
+	})
+
+	tests := []struct {
+		name   string
+		tamper func([]Entry) []Entry
+		broken dto.LedgerBrokenLink
+	}{
+		{
+			name:   "amount changed",
+			tamper: func(e []Entry) []Entry { e[1].Amount = 1; return e },
+			broken: dto.LedgerBrokenLink{Wallet: "w1", Seq: 2, OperationID: 2, Reason: ReasonHashMismatch},
+		},
+		{
+			name: "row rehashed",
+			tamper: func(e []Entry) []Entry {
+				e[1].Amount = 1
+				e[1].Hash = Hash(e[1])
+				return e
+			},
+			broken: dto.LedgerBrokenLink{Wallet: "w1", Seq: 3, OperationID: 3, Reason: ReasonPrevHashMismatch},
+		},
+		{
+			name:   "row removed",
+			tamper: func(e []Entry) []Entry { return append(e[:1], e[2:]...) },
+			broken: dto.LedgerBrokenLink{Wallet: "w1", Seq: 3, OperationID: 3, Reason: ReasonSeqGap},
+		},
+		{
+			name:   "first row removed",
+			tamper: func(e []Entry) []Entry { return e[1:] },
+			broken: dto.LedgerBrokenLink{Wallet: "w1", Seq: 2, OperationID: 2, Reason: ReasonSeqGap},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, report := verify(tt.tamper(chain(3, "w1", "w2")), nil)
+			assert.False(t, report.OK)
+			require.NotNil(t, report.Broken)
+			assert.Equal(t, tt.broken, *report.Broken)
+		})
+	}
+}
+
+func TestVerifier_checkpoint(t *testing.T) {
+	entries := chain(3, "w1", "w2")
+	v, _ := verify(entries, nil)
+	cp := &dto.LedgerCheckpoint{ID: 7, Heads: v.Heads()}
+
+	t.Run("new operations after checkpoint", func(t *testing.T) {
+		_, report := verify(chain(4, "w1", "w2", "w3"), cp)
+		assert.True(t, report.OK)
+		assert.Equal(t, int64(7), report.CheckpointID)
+	})
+
+	t.Run("last operation removed", func(t *testing.T) {
+		_, report := verify(entries[:5], cp)
+		require.NotNil(t, report.Broken)
+		assert.Equal(t, dto.LedgerBrokenLink{Wallet: "w2", Seq: 3, Reason: ReasonCheckpointMissing}, *report.Broken)
+	})
+
+	t.Run("wallet removed", func(t *testing.T) {
+		_, report := verify(entries[:3], cp)
+		require.NotNil(t, report.Broken)
+		assert.Equal(t, dto.LedgerBrokenLink{Wallet: "w2", Seq: 3, Reason: ReasonCheckpointMissing}, *report.Broken)
+	})
+
+	t.Run("chain rebuilt", func(t *testing.T) {
+		rebuilt := chain(3, "w1", "w2")
+		rebuilt[0].Amount = 1
+		prev := ""
+		for i := 0; i < 3; i++ {
+			rebuilt[i].PrevHash = prev
+			rebuilt[i].Hash = Hash(rebuilt[i])
+			prev = rebuilt[i].Hash
+		}
+
+		_, report := verify(rebuilt, cp)
+		require.NotNil(t, report.Broken)
+		assert.Equal(t, dto.LedgerBrokenLink{Wallet: "w1", Seq: 3, OperationID: 3, Reason: ReasonCheckpointMismatch}, *report.Broken)
+	})
+}
+
+func TestSigner(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	der, err = x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	pubFile := filepath.Join(dir, "pub.pem")
+	require.NoError(t, os.WriteFile(pubFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	signer, err := LoadSigner(keyFile)
+	require.NoError(t, err)
+	loadedPublicKey, err := LoadPublicKey(pubFile)
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, loadedPublicKey)
+
+	v, _ := verify(chain(2, "w1", "w2"), nil)
+	cp, err := signer.NewCheckpoint(v.Heads(), 4, v.LastOperationID())
+	require.NoError(t, err)
+	assert.Equal(t, KeyID(publicKey), cp.KeyID)
+	assert.NoError(t, VerifyCheckpoint(publicKey, cp))
+
+	stored := *cp
+	stored.ID = 1
+	assert.NoError(t, VerifyCheckpoint(publicKey, &stored), "id is assigned after signing")
+
+	tampered := *cp
+	tampered.Operations = 5
+	assert.EqualError(t, VerifyCheckpoint(publicKey, &tampered), "invalid checkpoint signature")
+
+	tampered = *cp
+	tampered.Heads = map[string]dto.LedgerHead{"w1": cp.Heads["w1"]}
+	assert.EqualError(t, VerifyCheckpoint(publicKey, &tampered), "checkpoint root hash doesn't match heads")
+
+	otherKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	assert.Error(t, VerifyCheckpoint(otherKey, cp))
+}
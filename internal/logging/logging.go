@@ -0,0 +1,27 @@
+// Package logging carries the request-scoped logger in the context and redacts sensitive fields from logs.
+package logging
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/ezhdanovskiy/wallets/internal/tracing"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the request-scoped logger.
+func NewContext(ctx context.Context, log *zap.SugaredLogger) context.Context {
+	return context.WithValue(ctx, contextKey{}, log)
+}
+
+// FromContext returns the request-scoped logger from ctx or fallback if there is none.
+// The logger has trace_id and span_id of the current span.
+func FromContext(ctx context.Context, fallback *zap.SugaredLogger) *zap.SugaredLogger {
+	log, ok := ctx.Value(contextKey{}).(*zap.SugaredLogger)
+	if !ok {
+		log = fallback
+	}
+	return tracing.Logger(ctx, log)
+}
@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFromContext(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	fallback := zap.New(core).Sugar()
+
+	FromContext(context.Background(), fallback).Info("fallback")
+	ctx := NewContext(context.Background(), fallback.With("request_id", "req-1"))
+	FromContext(ctx, zap.NewNop().Sugar()).Info("request")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+	assert.Empty(t, entries[0].Context)
+	assert.Equal(t, map[string]interface{}{"request_id": "req-1"}, entries[1].ContextMap())
+}
+
+func TestRedactingCore(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	log := zap.New(RedactingCore(core)).Sugar()
+
+	log.With("Authorization", "Bearer wlt_secret").Infow("request", "password", "postgres", "wallet", "w1")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 1)
+	assert.Equal(t, map[string]interface{}{
+		"Authorization": Redacted,
+		"password":      Redacted,
+		"wallet":        "w1",
+	}, entries[0].ContextMap())
+}
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			name:     "created api key",
+			data:     `{"data":{"name":"payouts","key":"wlt_secret","scopes":["deposit"]}}`,
+			expected: `{"data":{"key":"[REDACTED]","name":"payouts","scopes":["deposit"]}}`,
+		},
+		{
+			name:     "nested in array",
+			data:     `[{"DB":{"Host":"localhost","Password":"postgres"}}]`,
+			expected: `[{"DB":{"Host":"localhost","Password":"[REDACTED]"}}]`,
+		},
+		{
+			name:     "not json",
+			data:     `wallet,type`,
+			expected: `wallet,type`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, string(RedactJSON([]byte(tt.data))))
+		})
+	}
+}
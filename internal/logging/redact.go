@@ -0,0 +1,104 @@
+package logging
+
+import (
+	"encoding/json"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted replaces values of sensitive fields.
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are lower case names of fields whose values must never be logged.
+var sensitiveKeys = map[string]bool{
+	"authorization":  true,
+	"x-api-key":      true,
+	"api_key":        true,
+	"key":            true,
+	"password":       true,
+	"db_password":    true,
+	"database_url":   true,
+	"db_replica_url": true,
+	"secret":         true,
+	"token":          true,
+}
+
+// IsSensitive reports whether the value of the field with the name must be redacted.
+func IsSensitive(name string) bool {
+	return sensitiveKeys[strings.ToLower(name)]
+}
+
+// RedactingCore wraps the core, so values of sensitive fields are replaced with Redacted.
+func RedactingCore(core zapcore.Core) zapcore.Core {
+	return &redactingCore{Core: core}
+}
+
+type redactingCore struct {
+	zapcore.Core
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c *redactingCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	var redacted []zapcore.Field
+	for i, f := range fields {
+		if !IsSensitive(f.Key) {
+			continue
+		}
+		if redacted == nil {
+			redacted = append([]zapcore.Field(nil), fields...)
+		}
+		redacted[i] = zapcore.Field{Key: f.Key, Type: zapcore.StringType, String: Redacted}
+	}
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
+
+// RedactJSON replaces values of sensitive fields at any depth of the JSON document.
+// Data that isn't valid JSON is returned as is.
+func RedactJSON(data []byte) []byte {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return data
+	}
+
+	redacted, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return data
+	}
+	return redacted
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if IsSensitive(k) {
+				v[k] = Redacted
+			} else {
+				v[k] = redactValue(val)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	}
+	return v
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "wallets_rate_limit_requests_total",
+	Help: "Requests checked by rate limiter by kind of limit and result: allowed, limited or error.",
+}, []string{"kind", "result"})
+
+// Limits contains limits for every kind of client, zero limit is disabled.
+type Limits struct {
+	APIKey Limit
+	IP     Limit
+	Wallet Limit
+}
+
+// Limiter checks requests against configured limits.
+type Limiter struct {
+	log    *zap.SugaredLogger
+	store  Store
+	limits Limits
+}
+
+// NewLimiter creates limiter keeping buckets in the store.
+func NewLimiter(logger *zap.SugaredLogger, store Store, limits Limits) *Limiter {
+	return &Limiter{
+		log:    logger,
+		store:  store,
+		limits: limits,
+	}
+}
+
+// Allow takes a token for the client of the kind. Requests are allowed if the store fails,
+// so problems with the store don't make the whole API unavailable.
+func (l *Limiter) Allow(ctx context.Context, kind, key string) (bool, time.Duration) {
+	limit := l.limit(kind)
+	if !limit.Enabled() || key == "" {
+		return true, 0
+	}
+
+	allowed, retryAfter, err := l.store.Take(ctx, kind+":"+key, limit)
+	switch {
+	case err != nil:
+		l.log.With("kind", kind, "key", key, "error", err).Error("Rate limiter store failed")
+		requestsTotal.WithLabelValues(kind, "error").Inc()
+		return true, 0
+	case !allowed:
+		requestsTotal.WithLabelValues(kind, "limited").Inc()
+	default:
+		requestsTotal.WithLabelValues(kind, "allowed").Inc()
+	}
+	return allowed, retryAfter
+}
+
+// Enabled reports whether the limit of the kind is configured.
+func (l *Limiter) Enabled(kind string) bool {
+	return l.limit(kind).Enabled()
+}
+
+func (l *Limiter) limit(kind string) Limit {
+	switch kind {
+	case KindAPIKey:
+		return l.limits.APIKey
+	case KindIP:
+		return l.limits.IP
+	case KindWallet:
+		return l.limits.Wallet
+	}
+	return Limit{}
+}
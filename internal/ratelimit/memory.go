@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps buckets in memory of the process, limits are not shared by replicas.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*Bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore creates an empty store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*Bucket),
+		now:     time.Now,
+	}
+}
+
+// Take takes a token from the bucket of the key.
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		bucket := NewBucket(now, limit)
+		b = &bucket
+		s.buckets[key] = b
+	}
+
+	allowed, retryAfter := b.Take(now, limit)
+	return allowed, retryAfter, nil
+}
+
+// sweep removes idle buckets, it runs at most once per minute.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.UpdatedAt) > IdleTimeout {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Package ratelimit implements token bucket rate limiting with pluggable storage of buckets.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Kinds of limited clients.
+const (
+	KindAPIKey = "api_key"
+	KindIP     = "ip"
+	KindWallet = "wallet"
+)
+
+// IdleTimeout is how long unused buckets are kept by stores.
+// Buckets that are idle longer are considered full.
+const IdleTimeout = time.Hour
+
+// Limit allows Rate requests per second on average with bursts up to Burst requests.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Enabled reports whether the limit is configured.
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Bucket holds tokens left at the time of the last update.
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// NewBucket returns a full bucket.
+func NewBucket(now time.Time, limit Limit) Bucket {
+	return Bucket{Tokens: float64(limit.Burst), UpdatedAt: now}
+}
+
+// Take refills the bucket up to now and takes a token if there is one.
+// If there are no tokens, it returns the time until the next token.
+func (b *Bucket) Take(now time.Time, limit Limit) (bool, time.Duration) {
+	if elapsed := now.Sub(b.UpdatedAt).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(float64(limit.Burst), b.Tokens+elapsed*limit.Rate)
+		b.UpdatedAt = now
+	}
+
+	if b.Tokens >= 1 {
+		b.Tokens--
+		return true, 0
+	}
+
+	wait := (1 - b.Tokens) / limit.Rate
+	return false, time.Duration(math.Ceil(wait * float64(time.Second)))
+}
+
+// Store keeps buckets, stores shared by replicas make limits hold across them.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// StoreFunc adapts a function to Store.
+type StoreFunc func(ctx context.Context, key string, limit Limit) (bool, time.Duration, error)
+
+// Take calls f.
+func (f StoreFunc) Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	return f(ctx, key, limit)
+}
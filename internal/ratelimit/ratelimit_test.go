@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestBucket_Take(t *testing.T) {
+	limit := Limit{Rate: 2, Burst: 3}
+	now := time.Unix(1234567890, 0)
+	b := NewBucket(now, limit)
+
+	for i := 0; i < 3; i++ {
+		allowed, _ := b.Take(now, limit)
+		require.True(t, allowed, "burst request %d", i)
+	}
+
+	allowed, retryAfter := b.Take(now, limit)
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	allowed, _ = b.Take(now.Add(500*time.Millisecond), limit)
+	assert.True(t, allowed, "token refilled")
+
+	b.Take(now.Add(time.Hour), limit)
+	assert.Equal(t, float64(limit.Burst-1), b.Tokens, "refill is capped by burst")
+}
+
+func TestMemoryStore(t *testing.T) {
+	limit := Limit{Rate: 1, Burst: 1}
+	now := time.Unix(1234567890, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	allowed, _, err := s.Take(ctx, "ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, retryAfter, err := s.Take(ctx, "ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, time.Second, retryAfter)
+
+	allowed, _, err = s.Take(ctx, "ip:10.0.0.2", limit)
+	require.NoError(t, err)
+	assert.True(t, allowed, "buckets are separate per key")
+
+	now = now.Add(IdleTimeout + time.Minute)
+	s.sweep(now)
+	assert.Empty(t, s.buckets, "idle buckets are removed")
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	limits := Limits{IP: Limit{Rate: 1, Burst: 1}}
+
+	t.Run("disabled kind", func(t *testing.T) {
+		l := NewLimiter(zap.NewNop().Sugar(), StoreFunc(func(context.Context, string, Limit) (bool, time.Duration, error) {
+			t.Fatal("store must not be called")
+			return false, 0, nil
+		}), limits)
+
+		allowed, _ := l.Allow(context.Background(), KindWallet, "wallet01")
+		assert.True(t, allowed)
+		assert.False(t, l.Enabled(KindWallet))
+	})
+
+	t.Run("key prefixed by kind", func(t *testing.T) {
+		var key string
+		l := NewLimiter(zap.NewNop().Sugar(), StoreFunc(func(_ context.Context, k string, _ Limit) (bool, time.Duration, error) {
+			key = k
+			return false, time.Second, nil
+		}), limits)
+
+		allowed, retryAfter := l.Allow(context.Background(), KindIP, "10.0.0.1")
+		assert.False(t, allowed)
+		assert.Equal(t, time.Second, retryAfter)
+		assert.Equal(t, "ip:10.0.0.1", key)
+	})
+
+	t.Run("store error allows request", func(t *testing.T) {
+		l := NewLimiter(zap.NewNop().Sugar(), StoreFunc(func(context.Context, string, Limit) (bool, time.Duration, error) {
+			return false, 0, errors.New("connection refused")
+		}), limits)
+
+		allowed, _ := l.Allow(context.Background(), KindIP, "10.0.0.1")
+		assert.True(t, allowed)
+	})
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
+)
+
+// CreateAPIKeyTx stores API key with its hash using transaction, the plain key is never stored.
+// It returns nil if a key with the same name already exists.
+func (r *Repo) CreateAPIKeyTx(ctx context.Context, tx storage.Tx, key dto.APIKey, keyHash string) (*dto.APIKey, error) {
+	logging.FromContext(ctx, r.log).With("name", key.Name, "scopes", key.Scopes).Debug("CreateAPIKey")
+	const query = `
+INSERT INTO api_keys (name, key_hash, scopes, wallets, owners)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT DO NOTHING
+RETURNING *
+`
+
+	var dbKey APIKey
+	err := get(ctx, sqlTx(tx), &dbKey, query, key.Name, keyHash,
+		pq.StringArray(key.Scopes), pq.StringArray(key.Wallets), pq.StringArray(key.Owners))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("insert api_keys: %w", err)
+	}
+
+	return convertAPIKey(dbKey), nil
+}
+
+// GetAPIKeyByHash selects API key by the hash of the key.
+func (r *Repo) GetAPIKeyByHash(ctx context.Context, keyHash string) (*dto.APIKey, error) {
+	const query = `
+SELECT * 
+FROM api_keys 
+WHERE key_hash = $1
+`
+
+	var dbKey APIKey
+	err := get(ctx, r.db, &dbKey, query, keyHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select: %w", err)
+	}
+
+	return convertAPIKey(dbKey), nil
+}
+
+// ListAPIKeys selects all API keys including revoked ones ordered by name.
+func (r *Repo) ListAPIKeys(ctx context.Context) ([]dto.APIKey, error) {
+	logging.FromContext(ctx, r.log).Debug("ListAPIKeys")
+	const query = `
+SELECT * 
+FROM api_keys 
+ORDER BY name
+`
+
+	dbKeys := make([]APIKey, 0)
+	err := selectx(ctx, r.db, &dbKeys, query)
+	if err != nil {
+		return nil, fmt.Errorf("select: %w", err)
+	}
+
+	keys := make([]dto.APIKey, len(dbKeys))
+	for i := range dbKeys {
+		keys[i] = *convertAPIKey(dbKeys[i])
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKeyTx marks API key as revoked using transaction,
+// it returns false if there is no active key with the name.
+func (r *Repo) RevokeAPIKeyTx(ctx context.Context, tx storage.Tx, name string) (bool, error) {
+	logging.FromContext(ctx, r.log).With("name", name).Debug("RevokeAPIKey")
+	const query = `
+UPDATE api_keys
+SET revoked_at = now()
+WHERE name = $1 AND revoked_at IS NULL
+`
+
+	res, err := exec(ctx, sqlTx(tx), query, name)
+	if err != nil {
+		return false, fmt.Errorf("update api_keys: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("rows affected: %w", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func convertAPIKey(dbKey APIKey) *dto.APIKey {
+	return &dto.APIKey{
+		Name:      dbKey.Name,
+		Scopes:    dbKey.Scopes,
+		Wallets:   dbKey.Wallets,
+		Owners:    dbKey.Owners,
+		CreatedAt: dbKey.CreatedAt,
+		RevokedAt: dbKey.RevokedAt,
+	}
+}
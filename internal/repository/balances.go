@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+)
+
+// InsertBalanceSnapshot stores the snapshot, a stored snapshot of the wallet at the same time is kept.
+func (r *Repo) InsertBalanceSnapshot(ctx context.Context, snapshot dto.BalanceSnapshot) error {
+	logging.FromContext(ctx, r.log).With("wallet", snapshot.Wallet, "taken_at", snapshot.TakenAt).Debug("InsertBalanceSnapshot")
+	const query = `
+INSERT INTO balance_snapshots (wallet, taken_at, balance)
+VALUES ($1, $2, $3)
+ON CONFLICT (wallet, taken_at) DO NOTHING
+`
+
+	if _, err := exec(ctx, r.db, query, snapshot.Wallet, snapshot.TakenAt, snapshot.Balance); err != nil {
+		return fmt.Errorf("insert balance_snapshots: %w", err)
+	}
+	return nil
+}
+
+// GetLatestBalanceSnapshot selects the latest snapshot of the wallet taken at or before the time,
+// it returns nil if there is no such snapshot.
+// The snapshot is read from the replica unless ctx requires strong consistency.
+func (r *Repo) GetLatestBalanceSnapshot(ctx context.Context, walletName string, at time.Time) (*dto.BalanceSnapshot, error) {
+	logging.FromContext(ctx, r.log).With("wallet", walletName, "at", at).Debug("GetLatestBalanceSnapshot")
+	const query = `
+SELECT *
+FROM balance_snapshots
+WHERE wallet = $1 AND taken_at <= $2
+ORDER BY taken_at DESC
+LIMIT 1
+`
+
+	var dbSnapshot BalanceSnapshot
+	err := r.read(ctx, func(db *sqlx.DB) error {
+		return get(ctx, db, &dbSnapshot, query, walletName, at)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select balance_snapshots: %w", err)
+	}
+
+	return &dto.BalanceSnapshot{
+		Wallet:  dbSnapshot.Wallet,
+		TakenAt: dbSnapshot.TakenAt.UTC(),
+		Balance: dbSnapshot.Balance,
+	}, nil
+}
@@ -0,0 +1,865 @@
+// Package conformance contains the tests every repository backend must pass, so backends are interchangeable.
+// Names of created data are unique for the run, the suite may run against a database shared with other tests.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/ledger"
+	"github.com/ezhdanovskiy/wallets/internal/service"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
+)
+
+var errRollback = errors.New("rollback")
+
+// Run runs the suite against repo.
+func Run(t *testing.T, repo service.Repository) {
+	s := suite{repo: repo, prefix: fmt.Sprintf("conformance-%d-", time.Now().UnixNano())}
+
+	t.Run("wallets", s.testWallets)
+	t.Run("deposit", s.testDeposit)
+	t.Run("pagination", s.testPagination)
+	t.Run("operations filter", s.testOperationsFilter)
+	t.Run("transfer", s.testTransfer)
+	t.Run("rollback", s.testRollback)
+	t.Run("concurrent updates", s.testConcurrentUpdates)
+	t.Run("lock for update", s.testLockForUpdate)
+	t.Run("api keys", s.testAPIKeys)
+	t.Run("audit", s.testAudit)
+	t.Run("ledger", s.testLedger)
+	t.Run("checkpoints", s.testCheckpoints)
+	t.Run("imports", s.testImports)
+	t.Run("statements", s.testStatements)
+	t.Run("balance snapshots", s.testBalanceSnapshots)
+}
+
+type suite struct {
+	repo   service.Repository
+	prefix string
+}
+
+func (s suite) name(name string) string {
+	return s.prefix + name
+}
+
+// tx runs f in a transaction and requires it to commit.
+func (s suite) tx(t *testing.T, f func(ctx context.Context, tx storage.Tx) error) {
+	require.NoError(t, s.repo.RunWithTransaction(context.Background(), f))
+}
+
+func (s suite) createWallet(t *testing.T, name, owner string, balance uint64) {
+	s.tx(t, func(ctx context.Context, tx storage.Tx) error {
+		if err := s.repo.CreateWalletTx(ctx, tx, name, owner); err != nil {
+			return err
+		}
+		if balance == 0 {
+			return nil
+		}
+		return s.repo.IncreaseWalletBalanceTx(ctx, tx, name, balance)
+	})
+}
+
+func (s suite) balance(t *testing.T, name string) uint64 {
+	wallet, err := s.repo.GetWallet(context.Background(), name)
+	require.NoError(t, err)
+	require.NotNil(t, wallet, name)
+	return wallet.Balance
+}
+
+func (s suite) testWallets(t *testing.T) {
+	ctx := context.Background()
+	owner := s.name("owner")
+	walletA, walletB := s.name("wallets-a"), s.name("wallets-b")
+
+	s.createWallet(t, walletB, owner, 0)
+	s.createWallet(t, walletA, owner, 0)
+	// Creating the existing wallet changes nothing.
+	s.createWallet(t, walletA, s.name("other-owner"), 0)
+
+	wallet, err := s.repo.GetWallet(ctx, walletA)
+	require.NoError(t, err)
+	assert.Equal(t, &dto.Wallet{Name: walletA, Owner: owner}, wallet)
+
+	wallet, err = s.repo.GetWallet(ctx, s.name("missing"))
+	require.NoError(t, err)
+	assert.Nil(t, wallet)
+
+	wallets, err := s.repo.ListWallets(ctx, dto.WalletsFilter{Owner: owner, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []dto.Wallet{{Name: walletA, Owner: owner}, {Name: walletB, Owner: owner}}, wallets)
+
+	wallets, err = s.repo.ListWallets(ctx, dto.WalletsFilter{Owner: owner, Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []dto.Wallet{{Name: walletB, Owner: owner}}, wallets)
+}
+
+func (s suite) testDeposit(t *testing.T) {
+	ctx := context.Background()
+	wallet := s.name("deposit")
+
+	s.createWallet(t, wallet, "", 100)
+	s.tx(t, func(ctx context.Context, tx storage.Tx) error {
+		return s.repo.IncreaseWalletBalanceTx(ctx, tx, wallet, 50)
+	})
+	assert.EqualValues(t, 150, s.balance(t, wallet))
+
+	operations, err := s.repo.GetOperations(ctx, dto.OperationsFilter{Wallet: wallet})
+	require.NoError(t, err)
+	require.Len(t, operations, 2)
+	for i, amount := range []dto.Amount{1, 0.5} {
+		assert.Equal(t, wallet, operations[i].Wallet)
+		assert.Equal(t, consts.OperationTypeDeposit, operations[i].Type)
+		assert.Equal(t, amount, operations[i].Amount)
+		assert.Equal(t, consts.SystemWalletName, operations[i].OtherWallet)
+		assert.False(t, operations[i].Timestamp.IsZero())
+	}
+
+	operations, err = s.repo.GetOperations(ctx, dto.OperationsFilter{Wallet: wallet, Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, operations, 1)
+	assert.Equal(t, dto.Amount(0.5), operations[0].Amount)
+
+	operations, err = s.repo.GetOperations(ctx, dto.OperationsFilter{Wallet: wallet, Types: []string{consts.OperationTypeWithdrawal}})
+	require.NoError(t, err)
+	assert.Empty(t, operations)
+
+	future := time.Now().Add(time.Hour)
+	operations, err = s.repo.GetOperations(ctx, dto.OperationsFilter{Wallet: wallet, StartDate: future})
+	require.NoError(t, err)
+	assert.Empty(t, operations)
+
+	operations, err = s.repo.GetOperations(ctx, dto.OperationsFilter{Wallet: wallet, EndDate: future})
+	require.NoError(t, err)
+	assert.Len(t, operations, 2)
+}
+
+func (s suite) testPagination(t *testing.T) {
+	ctx := context.Background()
+	wallet := s.name("pagination")
+
+	// Operations of a transaction share created_at, so the id breaks ties.
+	s.createWallet(t, wallet, "", 100)
+	s.tx(t, func(ctx context.Context, tx storage.Tx) error {
+		for i := 0; i < 3; i++ {
+			if err := s.repo.IncreaseWalletBalanceTx(ctx, tx, wallet, 100); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	s.tx(t, func(ctx context.Context, tx storage.Tx) error {
+		return s.repo.IncreaseWalletBalanceTx(ctx, tx, wallet, 100)
+	})
+
+	all, err := s.repo.GetOperations(ctx, dto.OperationsFilter{Wallet: wallet})
+	require.NoError(t, err)
+	require.Len(t, all, 5)
+	for i := 1; i < len(all); i++ {
+		assert.False(t, all[i].Timestamp.Before(all[i-1].Timestamp))
+		assert.Greater(t, all[i].ID, all[i-1].ID)
+	}
+	cursor := func(op dto.Operation, backward bool) *dto.OperationsCursor {
+		return &dto.OperationsCursor{CreatedAt: op.Timestamp, ID: op.ID, Backward: backward}
+	}
+	ids := func(ops ...dto.Operation) []int64 {
+		result := make([]int64, len(ops))
+		for i, op := range ops {
+			result[i] = op.ID
+		}
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		filter dto.OperationsFilter
+		want   []int64
+	}{
+		{
+			name:   "descending",
+			filter: dto.OperationsFilter{Order: consts.OperationsOrderDesc, Limit: 2},
+			want:   ids(all[4], all[3]),
+		},
+		{
+			name:   "after cursor",
+			filter: dto.OperationsFilter{Limit: 2, Cursor: cursor(all[1], false)},
+			want:   ids(all[2], all[3]),
+		},
+		{
+			name:   "before cursor",
+			filter: dto.OperationsFilter{Limit: 2, Cursor: cursor(all[3], true)},
+			want:   ids(all[1], all[2]),
+		},
+		{
+			name:   "after cursor descending",
+			filter: dto.OperationsFilter{Order: consts.OperationsOrderDesc, Limit: 2, Cursor: cursor(all[3], false)},
+			want:   ids(all[2], all[1]),
+		},
+		{
+			name:   "before cursor descending",
+			filter: dto.OperationsFilter{Order: consts.OperationsOrderDesc, Limit: 2, Cursor: cursor(all[1], true)},
+			want:   ids(all[3], all[2]),
+		},
+		{
+			name:   "before first",
+			filter: dto.OperationsFilter{Limit: 2, Cursor: cursor(all[0], true)},
+			want:   ids(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Wallet = wallet
+			operations, err := s.repo.GetOperations(ctx, tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ids(operations...))
+		})
+	}
+
+	// Scans read all operations and stop on the first error.
+	var scanned []dto.Operation
+	err = s.repo.ScanOperations(ctx, dto.OperationsFilter{Wallet: wallet, Order: consts.OperationsOrderDesc},
+		func(op dto.Operation) error {
+			scanned = append(scanned, op)
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, ids(all[4], all[3], all[2], all[1], all[0]), ids(scanned...))
+
+	var calls int
+	err = s.repo.ScanOperations(ctx, dto.OperationsFilter{Wallet: wallet}, func(dto.Operation) error {
+		calls++
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+	assert.Equal(t, 1, calls)
+}
+
+func (s suite) testOperationsFilter(t *testing.T) {
+	ctx := context.Background()
+	wallet, other := s.name("filter"), s.name("filter-other")
+	s.createWallet(t, wallet, "", 1000)
+	s.createWallet(t, other, "", 1000)
+	s.tx(t, func(ctx context.Context, tx storage.Tx) error {
+		if err := s.repo.TransferTx(ctx, tx, wallet, other, 300); err != nil {
+			return err
+		}
+		return s.repo.TransferTx(ctx, tx, other, wallet, 50)
+	})
+
+	all, err := s.repo.GetOperations(ctx, dto.OperationsFilter{Wallet: wallet})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	amounts := func(ops []dto.Operation) []dto.Amount {
+		result := make([]dto.Amount, len(ops))
+		for i, op := range ops {
+			result[i] = op.Amount
+		}
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		filter dto.OperationsFilter
+		want   []dto.Amount
+	}{
+		{
+			name:   "types",
+			filter: dto.OperationsFilter{Types: []string{consts.OperationTypeDeposit, consts.OperationTypeWithdrawal}},
+			want:   []dto.Amount{10, 3, 0.5},
+		},
+		{
+			name:   "other wallet",
+			filter: dto.OperationsFilter{OtherWallet: other},
+			want:   []dto.Amount{3, 0.5},
+		},
+		{
+			name:   "amount range",
+			filter: dto.OperationsFilter{MinAmount: 0.5, MaxAmount: 3},
+			want:   []dto.Amount{3, 0.5},
+		},
+		{
+			name:   "min amount",
+			filter: dto.OperationsFilter{MinAmount: 3.01},
+			want:   []dto.Amount{10},
+		},
+		{
+			name:   "start date",
+			filter: dto.OperationsFilter{StartDate: all[1].Timestamp},
+			want:   amounts(all[1:]),
+		},
+		{
+			name:   "end date",
+			filter: dto.OperationsFilter{EndDate: all[0].Timestamp},
+			want:   amounts(all[:1]),
+		},
+		{
+			name:   "deposits from other wallet",
+			filter: dto.OperationsFilter{Types: []string{consts.OperationTypeDeposit}, OtherWallet: other},
+			want:   []dto.Amount{0.5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Wallet = wallet
+			operations, err := s.repo.GetOperations(ctx, tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, amounts(operations))
+		})
+	}
+
+	totals, err := s.repo.GetOperationsTotals(ctx, dto.OperationsFilter{Wallet: wallet, Limit: 1, Order: consts.OperationsOrderDesc})
+	require.NoError(t, err)
+	assert.Equal(t, dto.OperationsTotals{
+		Deposits:    dto.OperationsTotal{Count: 2, Cents: 1050},
+		Withdrawals: dto.OperationsTotal{Count: 1, Cents: 300},
+	}, *totals)
+	assert.Equal(t, int64(750), totals.NetCents())
+
+	totals, err = s.repo.GetOperationsTotals(ctx, dto.OperationsFilter{Wallet: wallet, EndDate: all[0].Timestamp})
+	require.NoError(t, err)
+	assert.Equal(t, dto.OperationsTotals{Deposits: dto.OperationsTotal{Count: 1, Cents: 1000}}, *totals)
+
+	totals, err = s.repo.GetOperationsTotals(ctx, dto.OperationsFilter{Wallet: s.name("filter-unknown")})
+	require.NoError(t, err)
+	assert.Equal(t, dto.OperationsTotals{}, *totals)
+}
+
+func (s suite) testTransfer(t *testing.T) {
+	ctx := context.Background()
+	from, to := s.name("transfer-from"), s.name("transfer-to")
+	s.createWallet(t, from, "", 100)
+	s.createWallet(t, to, "", 0)
+
+	s.tx(t, func(ctx context.Context, tx storage.Tx) error {
+		wallets, err := s.repo.GetWalletsForUpdateTx(ctx, tx, []string{from, to})
+		if err != nil {
+			return err
+		}
+		if len(wallets) != 2 {
+			return fmt.Errorf("locked %d wallets", len(wallets))
+		}
+		return s.repo.TransferTx(ctx, tx, from, to, 30)
+	})
+	assert.EqualValues(t, 70, s.balance(t, from))
+	assert.EqualValues(t, 30, s.balance(t, to))
+
+	operations, err := s.repo.GetOperations(ctx, dto.OperationsFilter{Wallet: from, Types: []string{consts.OperationTypeWithdrawal}})
+	require.NoError(t, err)
+	require.Len(t, operations, 1)
+	assert.Equal(t, to, operations[0].OtherWallet)
+	assert.Equal(t, dto.Amount(0.3), operations[0].Amount)
+
+	operations, err = s.repo.GetOperations(ctx, dto.OperationsFilter{Wallet: to})
+	require.NoError(t, err)
+	require.Len(t, operations, 1)
+	assert.Equal(t, consts.OperationTypeDeposit, operations[0].Type)
+	assert.Equal(t, from, operations[0].OtherWallet)
+
+	// Insufficient funds fail the transfer and nothing is changed.
+	err = s.repo.RunWithTransaction(ctx, func(ctx context.Context, tx storage.Tx) error {
+		return s.repo.TransferTx(ctx, tx, from, to, 1000)
+	})
+	assert.Error(t, err)
+	assert.EqualValues(t, 70, s.balance(t, from))
+	assert.EqualValues(t, 30, s.balance(t, to))
+
+	// Missing wallets are skipped.
+	var wallets []dto.Wallet
+	s.tx(t, func(ctx context.Context, tx storage.Tx) (err error) {
+		wallets, err = s.repo.GetWalletsForUpdateTx(ctx, tx, []string{from, s.name("missing")})
+		return err
+	})
+	require.Len(t, wallets, 1)
+	assert.Equal(t, from, wallets[0].Name)
+}
+
+func (s suite) testRollback(t *testing.T) {
+	ctx := context.Background()
+	wallet, created := s.name("rollback"), s.name("rollback-created")
+	requestID := s.name("rollback-request")
+	s.createWallet(t, wallet, "", 10)
+
+	err := s.repo.RunWithTransaction(ctx, func(ctx context.Context, tx storage.Tx) error {
+		if err := s.repo.IncreaseWalletBalanceTx(ctx, tx, wallet, 5); err != nil {
+			return err
+		}
+		if err := s.repo.CreateWalletTx(ctx, tx, created, ""); err != nil {
+			return err
+		}
+		if err := s.repo.InsertAuditRecordTx(ctx, tx, dto.AuditRecord{
+			Action: "rollback", RequestID: requestID, PayloadHash: "hash", Result: "success",
+		}); err != nil {
+			return err
+		}
+
+		// Uncommitted changes aren't visible outside the transaction.
+		if balance := s.balance(t, wallet); balance != 10 {
+			return fmt.Errorf("uncommitted balance %d is visible", balance)
+		}
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	assert.EqualValues(t, 10, s.balance(t, wallet))
+	operations, err := s.repo.GetOperations(ctx, dto.OperationsFilter{Wallet: wallet})
+	require.NoError(t, err)
+	assert.Len(t, operations, 1)
+
+	w, err := s.repo.GetWallet(ctx, created)
+	require.NoError(t, err)
+	assert.Nil(t, w)
+
+	records, err := s.repo.GetAuditRecords(ctx, dto.AuditFilter{RequestID: requestID})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+// testConcurrentUpdates checks that concurrent transactions don't lose updates and keep the chain linear.
+func (s suite) testConcurrentUpdates(t *testing.T) {
+	// Backends may restart conflicting transactions a limited number of times, so the number is small.
+	const workers = 3
+	wallet := s.name("concurrent")
+	s.createWallet(t, wallet, "", 0)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.repo.RunWithTransaction(context.Background(), func(ctx context.Context, tx storage.Tx) error {
+				if _, err := s.repo.GetWalletsForUpdateTx(ctx, tx, []string{wallet}); err != nil {
+					return err
+				}
+				return s.repo.IncreaseWalletBalanceTx(ctx, tx, wallet, 1)
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	assert.EqualValues(t, workers, s.balance(t, wallet))
+	entries := s.chain(t, wallet)
+	assert.Len(t, entries, workers)
+}
+
+// testLockForUpdate checks that the transaction locking a wallet sees the changes of the transaction
+// that held the lock before.
+func (s suite) testLockForUpdate(t *testing.T) {
+	wallet := s.name("lock")
+	s.createWallet(t, wallet, "", 0)
+
+	locked := make(chan struct{})
+	first := make(chan error, 1)
+	go func() {
+		first <- s.repo.RunWithTransaction(context.Background(), func(ctx context.Context, tx storage.Tx) error {
+			if _, err := s.repo.GetWalletsForUpdateTx(ctx, tx, []string{wallet}); err != nil {
+				return err
+			}
+			select {
+			case <-locked: // the transaction is restarted
+			default:
+				close(locked)
+			}
+			time.Sleep(100 * time.Millisecond)
+			return s.repo.IncreaseWalletBalanceTx(ctx, tx, wallet, 5)
+		})
+	}()
+	<-locked
+
+	var balance uint64
+	s.tx(t, func(ctx context.Context, tx storage.Tx) error {
+		wallets, err := s.repo.GetWalletsForUpdateTx(ctx, tx, []string{wallet})
+		if err != nil {
+			return err
+		}
+		balance = wallets[0].Balance
+		return nil
+	})
+	require.NoError(t, <-first)
+	assert.EqualValues(t, 5, balance)
+}
+
+func (s suite) testAPIKeys(t *testing.T) {
+	ctx := context.Background()
+	name := s.name("key")
+
+	createKey := func(name, hash string) (key *dto.APIKey) {
+		s.tx(t, func(ctx context.Context, tx storage.Tx) (err error) {
+			key, err = s.repo.CreateAPIKeyTx(ctx, tx, dto.APIKey{
+				Name:    name,
+				Scopes:  []string{"read", "deposit"},
+				Wallets: []string{"w1"},
+			}, hash)
+			return err
+		})
+		return key
+	}
+	revokeKey := func(name string) (revoked bool) {
+		s.tx(t, func(ctx context.Context, tx storage.Tx) (err error) {
+			revoked, err = s.repo.RevokeAPIKeyTx(ctx, tx, name)
+			return err
+		})
+		return revoked
+	}
+	findKey := func() *dto.APIKey {
+		keys, err := s.repo.ListAPIKeys(ctx)
+		require.NoError(t, err)
+		for i := range keys {
+			if keys[i].Name == name {
+				return &keys[i]
+			}
+		}
+		return nil
+	}
+
+	key := createKey(name, s.name("hash"))
+	require.NotNil(t, key)
+	assert.Equal(t, name, key.Name)
+	assert.Equal(t, []string{"read", "deposit"}, key.Scopes)
+	assert.Equal(t, []string{"w1"}, key.Wallets)
+	assert.Empty(t, key.Owners)
+	assert.False(t, key.CreatedAt.IsZero())
+	assert.Nil(t, key.RevokedAt)
+
+	assert.Nil(t, createKey(name, s.name("other-hash")), "duplicate name")
+	assert.Nil(t, createKey(s.name("other-key"), s.name("hash")), "duplicate hash")
+
+	listed := findKey()
+	require.NotNil(t, listed)
+	assert.Equal(t, key.Scopes, listed.Scopes)
+	assert.Nil(t, listed.RevokedAt)
+
+	assert.True(t, revokeKey(name))
+	assert.False(t, revokeKey(name), "already revoked")
+	assert.False(t, revokeKey(s.name("missing-key")))
+
+	listed = findKey()
+	require.NotNil(t, listed)
+	assert.NotNil(t, listed.RevokedAt)
+}
+
+func (s suite) testAudit(t *testing.T) {
+	ctx := context.Background()
+	requestID := s.name("audit-request")
+
+	require.NoError(t, s.repo.InsertAuditRecord(ctx, dto.AuditRecord{
+		Action: "first", Actor: "actor", RequestID: requestID, PayloadHash: "hash", Result: "failure", Error: "failed",
+	}))
+	s.tx(t, func(ctx context.Context, tx storage.Tx) error {
+		return s.repo.InsertAuditRecordTx(ctx, tx, dto.AuditRecord{
+			Action: "second", Actor: "actor", RequestID: requestID, PayloadHash: "hash", Result: "success",
+		})
+	})
+
+	records, err := s.repo.GetAuditRecords(ctx, dto.AuditFilter{RequestID: requestID})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "first", records[0].Action)
+	assert.Equal(t, "failed", records[0].Error)
+	assert.Equal(t, "second", records[1].Action)
+	assert.Less(t, records[0].ID, records[1].ID)
+	assert.False(t, records[1].Timestamp.IsZero())
+
+	records, err = s.repo.GetAuditRecords(ctx, dto.AuditFilter{RequestID: requestID, Action: "second"})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	records, err = s.repo.GetAuditRecords(ctx, dto.AuditFilter{RequestID: requestID, Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "second", records[0].Action)
+
+	records, err = s.repo.GetAuditRecords(ctx, dto.AuditFilter{RequestID: requestID, StartDate: time.Now().Add(time.Hour).Unix()})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func (s suite) testLedger(t *testing.T) {
+	wallet := s.name("ledger")
+	s.createWallet(t, wallet, "", 10)
+	s.tx(t, func(ctx context.Context, tx storage.Tx) error {
+		return s.repo.IncreaseWalletBalanceTx(ctx, tx, wallet, 20)
+	})
+
+	entries := s.chain(t, wallet)
+	require.Len(t, entries, 2)
+	assert.EqualValues(t, 10, entries[0].Amount)
+	assert.EqualValues(t, 20, entries[1].Amount)
+	assert.NotZero(t, entries[0].ID)
+
+	errStop := errors.New("stop")
+	calls := 0
+	err := s.repo.ScanLedger(context.Background(), func(ledger.Entry) error {
+		calls++
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+}
+
+// chain returns operations of the wallet from ScanLedger and checks they form a valid chain.
+func (s suite) chain(t *testing.T, wallet string) []ledger.Entry {
+	var entries []ledger.Entry
+	err := s.repo.ScanLedger(context.Background(), func(e ledger.Entry) error {
+		if e.Wallet == wallet {
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	prevHash := ""
+	for i, e := range entries {
+		assert.EqualValues(t, i+1, e.Seq, "seq")
+		assert.Equal(t, prevHash, e.PrevHash, "prev hash of seq %d", e.Seq)
+		assert.Equal(t, ledger.Hash(e), e.Hash, "hash of seq %d", e.Seq)
+		prevHash = e.Hash
+	}
+	return entries
+}
+
+func (s suite) testCheckpoints(t *testing.T) {
+	ctx := context.Background()
+
+	insert := func(rootHash string) (cp *dto.LedgerCheckpoint) {
+		s.tx(t, func(ctx context.Context, tx storage.Tx) (err error) {
+			cp, err = s.repo.InsertLedgerCheckpointTx(ctx, tx, dto.LedgerCheckpoint{
+				CreatedAt:  ledger.Now(),
+				Operations: 1,
+				RootHash:   rootHash,
+				Heads:      map[string]dto.LedgerHead{s.name("ledger"): {Seq: 1, Hash: "hash"}},
+			})
+			return err
+		})
+		return cp
+	}
+
+	first := insert(s.name("root-1"))
+	second := insert(s.name("root-2"))
+	require.NotNil(t, first)
+	require.NotNil(t, second)
+	assert.NotZero(t, first.ID)
+	assert.Greater(t, second.ID, first.ID)
+
+	checkpoints, err := s.repo.GetLedgerCheckpoints(ctx, 100)
+	require.NoError(t, err)
+	positions := make(map[int64]int)
+	for i, cp := range checkpoints {
+		positions[cp.ID] = i
+		if cp.ID == second.ID {
+			assert.Equal(t, second.RootHash, cp.RootHash)
+			assert.Equal(t, second.Heads, cp.Heads)
+		}
+	}
+	require.Contains(t, positions, first.ID)
+	require.Contains(t, positions, second.ID)
+	assert.Less(t, positions[second.ID], positions[first.ID], "newest first")
+
+	latest, err := s.repo.GetLatestLedgerCheckpoint(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.GreaterOrEqual(t, latest.ID, second.ID)
+}
+
+func (s suite) testImports(t *testing.T) {
+	ctx := context.Background()
+	wallet := s.name("imports")
+	keys := []string{s.name("import-key-1"), s.name("import-key-2"), s.name("import-key-3")}
+
+	var job *dto.ImportJob
+	s.tx(t, func(ctx context.Context, tx storage.Tx) (err error) {
+		rows := make([]dto.ImportRow, len(keys))
+		for i, key := range keys {
+			rows[i] = dto.ImportRow{Line: i + 2, Key: key, Wallet: wallet, Amount: uint64(i+1) * 100, Status: consts.ImportStatusPending}
+		}
+		job, err = s.repo.CreateImportJobTx(ctx, tx, dto.ImportJob{
+			Kind: consts.ImportKindDeposits, Status: consts.ImportStatusPending, FileHash: "hash", ChunkSize: 2,
+			Rows: len(rows), CreatedBy: "creator", RequestID: s.name("import-request"),
+		}, rows)
+		return err
+	})
+	require.NotNil(t, job)
+	assert.NotZero(t, job.ID)
+	assert.False(t, job.CreatedAt.IsZero())
+
+	stored, err := s.repo.GetImportJob(ctx, job.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, consts.ImportKindDeposits, stored.Kind)
+	assert.Equal(t, consts.ImportStatusPending, stored.Status)
+	assert.Equal(t, 3, stored.Rows)
+	assert.Equal(t, 2, stored.ChunkSize)
+	assert.Equal(t, "creator", stored.CreatedBy)
+	assert.Nil(t, stored.FinishedAt)
+
+	missing, err := s.repo.GetImportJob(ctx, job.ID+1000000)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	// Other pending jobs of a shared database are claimed first, they are older.
+	claim := func(lease time.Duration) *dto.ImportJob {
+		for {
+			claimed, err := s.repo.ClaimImportJob(ctx, lease)
+			require.NoError(t, err)
+			if claimed == nil || claimed.ID == job.ID {
+				return claimed
+			}
+		}
+	}
+	claimed := claim(time.Hour)
+	require.NotNil(t, claimed)
+	assert.Equal(t, consts.ImportStatusRunning, claimed.Status)
+	assert.Nil(t, claim(time.Hour), "the lease isn't expired")
+	time.Sleep(10 * time.Millisecond)
+	assert.NotNil(t, claim(time.Millisecond), "the lease is expired")
+
+	s.tx(t, func(ctx context.Context, tx storage.Tx) error {
+		rows, err := s.repo.GetPendingImportRowsTx(ctx, tx, job.ID, 2)
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, 2, rows[0].Line)
+		assert.Equal(t, 3, rows[1].Line)
+		assert.Equal(t, job.ID, rows[0].JobID)
+		assert.EqualValues(t, 100, rows[0].Amount)
+
+		rows[0].Status = consts.ImportStatusApplied
+		rows[1].Status, rows[1].Error = consts.ImportStatusFailed, "failed"
+		for _, row := range rows {
+			require.NoError(t, s.repo.UpdateImportRowTx(ctx, tx, row))
+		}
+
+		applied, err := s.repo.ImportKeyAppliedTx(ctx, tx, keys[0])
+		require.NoError(t, err)
+		assert.True(t, applied, "applied in the transaction")
+
+		rows, err = s.repo.GetPendingImportRowsTx(ctx, tx, job.ID, 2)
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Equal(t, 4, rows[0].Line)
+
+		job.Applied, job.Failed = 1, 1
+		return s.repo.UpdateImportJobTx(ctx, tx, *job)
+	})
+
+	s.tx(t, func(ctx context.Context, tx storage.Tx) error {
+		for key, want := range map[string]bool{keys[0]: true, keys[1]: false, keys[2]: false} {
+			applied, err := s.repo.ImportKeyAppliedTx(ctx, tx, key)
+			require.NoError(t, err)
+			assert.Equal(t, want, applied, key)
+		}
+
+		finishedAt := time.Now()
+		job.Status, job.FinishedAt = consts.ImportStatusDone, &finishedAt
+		return s.repo.UpdateImportJobTx(ctx, tx, *job)
+	})
+
+	stored, err = s.repo.GetImportJob(ctx, job.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, consts.ImportStatusDone, stored.Status)
+	assert.Equal(t, 1, stored.Applied)
+	assert.Equal(t, 1, stored.Failed)
+	require.NotNil(t, stored.FinishedAt)
+	assert.WithinDuration(t, *job.FinishedAt, *stored.FinishedAt, time.Second)
+
+	rows, err := s.repo.GetImportRows(ctx, job.ID)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, consts.ImportStatusApplied, rows[0].Status)
+	assert.Equal(t, consts.ImportStatusFailed, rows[1].Status)
+	assert.Equal(t, "failed", rows[1].Error)
+	assert.Equal(t, consts.ImportStatusPending, rows[2].Status)
+	assert.Equal(t, keys[2], rows[2].Key)
+}
+
+func (s suite) testStatements(t *testing.T) {
+	ctx := context.Background()
+	wallet := s.name("statements")
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	st, err := s.repo.GetStatement(ctx, wallet, "2026-09")
+	require.NoError(t, err)
+	assert.Nil(t, st)
+
+	stored := dto.Statement{
+		Period: "2026-09",
+		OperationsSummary: dto.OperationsSummary{
+			Wallet:         wallet,
+			Currency:       "USD",
+			StartDate:      start,
+			EndDate:        start.AddDate(0, 1, 0).Add(-time.Microsecond),
+			OpeningBalance: 10,
+			ClosingBalance: 12.5,
+			OperationsTotals: dto.OperationsTotals{
+				Deposits: dto.OperationsTotal{Count: 1, Amount: 2.5},
+			},
+		},
+		Entries: []dto.StatementEntry{{
+			Operation: dto.Operation{Wallet: wallet, Amount: 2.5, Type: consts.OperationTypeDeposit, Timestamp: start.Add(time.Hour)},
+			Balance:   12.5,
+		}},
+		GeneratedAt: start.AddDate(0, 1, 0),
+	}
+	require.NoError(t, s.repo.InsertStatement(ctx, stored))
+
+	// The statement of the period is stored once, a repeated one is ignored.
+	repeated := stored
+	repeated.GeneratedAt = stored.GeneratedAt.Add(time.Hour)
+	require.NoError(t, s.repo.InsertStatement(ctx, repeated))
+
+	st, err = s.repo.GetStatement(ctx, wallet, "2026-09")
+	require.NoError(t, err)
+	require.NotNil(t, st)
+	assert.Equal(t, stored, *st)
+
+	st, err = s.repo.GetStatement(ctx, wallet, "2026-10")
+	require.NoError(t, err)
+	assert.Nil(t, st)
+}
+
+func (s suite) testBalanceSnapshots(t *testing.T) {
+	ctx := context.Background()
+	wallet := s.name("snapshots")
+	day := time.Date(2026, 9, 2, 0, 0, 0, 0, time.UTC)
+
+	snapshot, err := s.repo.GetLatestBalanceSnapshot(ctx, wallet, day)
+	require.NoError(t, err)
+	assert.Nil(t, snapshot)
+
+	first := dto.BalanceSnapshot{Wallet: wallet, TakenAt: day, Balance: 1050}
+	second := dto.BalanceSnapshot{Wallet: wallet, TakenAt: day.AddDate(0, 0, 2), Balance: 2000}
+	// Snapshots may be taken in any order, a repeated one is ignored.
+	require.NoError(t, s.repo.InsertBalanceSnapshot(ctx, second))
+	require.NoError(t, s.repo.InsertBalanceSnapshot(ctx, first))
+	require.NoError(t, s.repo.InsertBalanceSnapshot(ctx, dto.BalanceSnapshot{Wallet: wallet, TakenAt: day, Balance: 1}))
+	require.NoError(t, s.repo.InsertBalanceSnapshot(ctx, dto.BalanceSnapshot{Wallet: s.name("snapshots-other"),
+		TakenAt: day.AddDate(0, 0, 1), Balance: 5}))
+
+	for _, tt := range []struct {
+		at   time.Time
+		want *dto.BalanceSnapshot
+	}{
+		{day.Add(-time.Microsecond), nil},
+		{day, &first},
+		{day.AddDate(0, 0, 1), &first},
+		{day.AddDate(0, 0, 2), &second},
+		{day.AddDate(1, 0, 0), &second},
+	} {
+		snapshot, err := s.repo.GetLatestBalanceSnapshot(ctx, wallet, tt.at)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, snapshot, tt.at)
+	}
+}
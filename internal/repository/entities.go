@@ -2,10 +2,13 @@ package repository
 
 import (
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type Wallet struct {
 	Name      string    `db:"name"`
+	Owner     string    `db:"owner"`
 	Balance   uint64    `db:"balance"`
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
@@ -18,4 +21,65 @@ type Operation struct {
 	Amount      uint64    `db:"amount"`
 	OtherWallet string    `db:"other_wallet"`
 	CreatedAt   time.Time `db:"created_at"`
+	Seq         int64     `db:"seq"`
+	PrevHash    string    `db:"prev_hash"`
+	Hash        string    `db:"hash"`
+}
+
+type APIKey struct {
+	ID        int64          `db:"id"`
+	Name      string         `db:"name"`
+	KeyHash   string         `db:"key_hash"`
+	Scopes    pq.StringArray `db:"scopes"`
+	Wallets   pq.StringArray `db:"wallets"`
+	Owners    pq.StringArray `db:"owners"`
+	CreatedAt time.Time      `db:"created_at"`
+	RevokedAt *time.Time     `db:"revoked_at"`
+}
+
+type AuditRecord struct {
+	ID          int64     `db:"id"`
+	Action      string    `db:"action"`
+	Actor       string    `db:"actor"`
+	RequestID   string    `db:"request_id"`
+	SourceIP    string    `db:"source_ip"`
+	Endpoint    string    `db:"endpoint"`
+	PayloadHash string    `db:"payload_hash"`
+	Result      string    `db:"result"`
+	Error       string    `db:"error"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+type BalanceSnapshot struct {
+	Wallet  string    `db:"wallet"`
+	TakenAt time.Time `db:"taken_at"`
+	Balance uint64    `db:"balance"`
+}
+
+type ImportJob struct {
+	ID          int64      `db:"id"`
+	Kind        string     `db:"kind"`
+	Status      string     `db:"status"`
+	FileHash    string     `db:"file_hash"`
+	ChunkSize   int        `db:"chunk_size"`
+	TotalRows   int        `db:"total_rows"`
+	AppliedRows int        `db:"applied_rows"`
+	SkippedRows int        `db:"skipped_rows"`
+	FailedRows  int        `db:"failed_rows"`
+	CreatedBy   string     `db:"created_by"`
+	RequestID   string     `db:"request_id"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+	FinishedAt  *time.Time `db:"finished_at"`
+}
+
+type ImportRow struct {
+	JobID    int64  `db:"job_id"`
+	Line     int    `db:"line"`
+	Key      string `db:"key"`
+	Wallet   string `db:"wallet"`
+	WalletTo string `db:"wallet_to"`
+	Amount   uint64 `db:"amount"`
+	Status   string `db:"status"`
+	Error    string `db:"error"`
 }
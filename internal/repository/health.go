@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/lib/pq"
+
+	"github.com/ezhdanovskiy/wallets/internal/config"
+)
+
+// Ping checks that the database is reachable.
+func (r *Repo) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Stats returns statistics of the connection pool.
+func (r *Repo) Stats() sql.DBStats {
+	return r.db.Stats()
+}
+
+// MigrationVersion returns the version of the applied migrations and whether the last migration failed.
+// The version is 0 if migrations were never applied.
+func (r *Repo) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	var row struct {
+		Version uint `db:"version"`
+		Dirty   bool `db:"dirty"`
+	}
+	err := get(ctx, r.db, &row, `SELECT version, dirty FROM `+postgres.DefaultMigrationsTable+` LIMIT 1`)
+	if err != nil {
+		var pqErr *pq.Error
+		if err == sql.ErrNoRows || errors.As(err, &pqErr) && pqErr.Code == "42P01" { // undefined_table
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("select migration version: %w", err)
+	}
+	return row.Version, row.Dirty, nil
+}
+
+// LatestMigrationVersion returns the version of the last migration in the source from config.
+func LatestMigrationVersion(cfg config.DB) (uint, error) {
+	src, err := openMigrations(cfg)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("first migration: %w", err)
+	}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("next migration: %w", err)
+		}
+		version = next
+	}
+}
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
+)
+
+// importRowsBatch is the number of rows inserted by a statement, the parameters of a batch stay below
+// the limit of the protocol.
+const importRowsBatch = 1000
+
+// CreateImportJobTx stores the job with its rows using transaction and returns the job with assigned id.
+func (r *Repo) CreateImportJobTx(ctx context.Context, tx storage.Tx, job dto.ImportJob, rows []dto.ImportRow) (*dto.ImportJob, error) {
+	logging.FromContext(ctx, r.log).With("kind", job.Kind, "rows", len(rows)).Debug("CreateImportJob")
+	const query = `
+INSERT INTO import_jobs (kind, status, file_hash, chunk_size, total_rows, created_by, request_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *
+`
+
+	var dbJob ImportJob
+	err := get(ctx, sqlTx(tx), &dbJob, query, job.Kind, job.Status, job.FileHash, job.ChunkSize, job.Rows,
+		job.CreatedBy, job.RequestID)
+	if err != nil {
+		return nil, fmt.Errorf("insert import_jobs: %w", err)
+	}
+
+	for start := 0; start < len(rows); start += importRowsBatch {
+		batch := rows[start:min(start+importRowsBatch, len(rows))]
+		values := make([]string, len(batch))
+		args := make([]interface{}, 0, len(batch)*7)
+		for i, row := range batch {
+			n := len(args)
+			values[i] = fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7)
+			args = append(args, dbJob.ID, row.Line, row.Key, row.Wallet, row.WalletTo, row.Amount, row.Status)
+		}
+
+		query := "INSERT INTO import_rows (job_id, line, key, wallet, wallet_to, amount, status) VALUES " +
+			strings.Join(values, ", ")
+		if _, err := exec(ctx, sqlTx(tx), query, args...); err != nil {
+			return nil, fmt.Errorf("insert import_rows: %w", err)
+		}
+	}
+
+	return convertImportJob(dbJob), nil
+}
+
+// GetImportJob selects the job by id, it returns nil if the job doesn't exist.
+// The job is read from the replica unless ctx requires strong consistency.
+func (r *Repo) GetImportJob(ctx context.Context, id int64) (*dto.ImportJob, error) {
+	logging.FromContext(ctx, r.log).With("id", id).Debug("GetImportJob")
+	const query = `
+SELECT *
+FROM import_jobs
+WHERE id = $1
+`
+
+	var dbJob ImportJob
+	err := r.read(ctx, func(db *sqlx.DB) error {
+		return get(ctx, db, &dbJob, query, id)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select: %w", err)
+	}
+
+	return convertImportJob(dbJob), nil
+}
+
+// GetImportRows selects rows of the job ordered by line.
+// They are read from the replica unless ctx requires strong consistency.
+func (r *Repo) GetImportRows(ctx context.Context, jobID int64) ([]dto.ImportRow, error) {
+	logging.FromContext(ctx, r.log).With("job_id", jobID).Debug("GetImportRows")
+	const query = `
+SELECT *
+FROM import_rows
+WHERE job_id = $1
+ORDER BY line
+`
+
+	var dbRows []ImportRow
+	err := r.read(ctx, func(db *sqlx.DB) error {
+		dbRows = nil
+		return selectx(ctx, db, &dbRows, query, jobID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("select: %w", err)
+	}
+
+	return convertImportRows(dbRows), nil
+}
+
+// ClaimImportJob marks the oldest pending job as running and returns it, a running job is claimed again
+// if it isn't updated during the lease. Jobs locked by other instances are skipped, nil is returned
+// if there are no jobs to run.
+func (r *Repo) ClaimImportJob(ctx context.Context, lease time.Duration) (*dto.ImportJob, error) {
+	const query = `
+UPDATE import_jobs
+SET status = $1, updated_at = now()
+WHERE id = (
+    SELECT id
+    FROM import_jobs
+    WHERE status = $2 OR status = $1 AND updated_at < now() - $3 * interval '1 second'
+    ORDER BY id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *
+`
+
+	var dbJob ImportJob
+	err := get(ctx, r.db, &dbJob, query, consts.ImportStatusRunning, consts.ImportStatusPending, lease.Seconds())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("update import_jobs: %w", err)
+	}
+
+	logging.FromContext(ctx, r.log).With("id", dbJob.ID).Debug("ClaimImportJob")
+	return convertImportJob(dbJob), nil
+}
+
+// GetPendingImportRowsTx selects at most limit pending rows of the job ordered by line using transaction.
+func (r *Repo) GetPendingImportRowsTx(ctx context.Context, tx storage.Tx, jobID int64, limit int) ([]dto.ImportRow, error) {
+	logging.FromContext(ctx, r.log).With("job_id", jobID, "limit", limit).Debug("GetPendingImportRowsTx")
+	const query = `
+SELECT *
+FROM import_rows
+WHERE job_id = $1 AND status = $2
+ORDER BY line
+LIMIT $3
+`
+
+	var dbRows []ImportRow
+	err := selectx(ctx, sqlTx(tx), &dbRows, query, jobID, consts.ImportStatusPending, limit)
+	if err != nil {
+		return nil, fmt.Errorf("select: %w", err)
+	}
+
+	return convertImportRows(dbRows), nil
+}
+
+// ImportKeyAppliedTx reports whether a row with the idempotency key is applied using transaction.
+func (r *Repo) ImportKeyAppliedTx(ctx context.Context, tx storage.Tx, key string) (bool, error) {
+	const query = `
+SELECT EXISTS (SELECT 1 FROM import_rows WHERE key = $1 AND status = $2)
+`
+
+	var applied bool
+	err := get(ctx, sqlTx(tx), &applied, query, key, consts.ImportStatusApplied)
+	if err != nil {
+		return false, fmt.Errorf("select: %w", err)
+	}
+	return applied, nil
+}
+
+// UpdateImportRowTx sets the status and the error of the row using transaction.
+func (r *Repo) UpdateImportRowTx(ctx context.Context, tx storage.Tx, row dto.ImportRow) error {
+	logging.FromContext(ctx, r.log).With("job_id", row.JobID, "line", row.Line, "status", row.Status).Debug("UpdateImportRowTx")
+	const query = `
+UPDATE import_rows
+SET status = $3, error = $4
+WHERE job_id = $1 AND line = $2
+`
+
+	_, err := exec(ctx, sqlTx(tx), query, row.JobID, row.Line, row.Status, row.Error)
+	if err != nil {
+		return fmt.Errorf("update import_rows: %w", err)
+	}
+	return nil
+}
+
+// UpdateImportJobTx sets the status and the progress of the job using transaction, it renews the lease of the job.
+func (r *Repo) UpdateImportJobTx(ctx context.Context, tx storage.Tx, job dto.ImportJob) error {
+	logging.FromContext(ctx, r.log).With("id", job.ID, "status", job.Status).Debug("UpdateImportJobTx")
+	const query = `
+UPDATE import_jobs
+SET status = $2, applied_rows = $3, skipped_rows = $4, failed_rows = $5, finished_at = $6, updated_at = now()
+WHERE id = $1
+`
+
+	_, err := exec(ctx, sqlTx(tx), query, job.ID, job.Status, job.Applied, job.Skipped, job.Failed, job.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("update import_jobs: %w", err)
+	}
+	return nil
+}
+
+func convertImportJob(job ImportJob) *dto.ImportJob {
+	return &dto.ImportJob{
+		ID:         job.ID,
+		Kind:       job.Kind,
+		Status:     job.Status,
+		FileHash:   job.FileHash,
+		ChunkSize:  job.ChunkSize,
+		Rows:       job.TotalRows,
+		Applied:    job.AppliedRows,
+		Skipped:    job.SkippedRows,
+		Failed:     job.FailedRows,
+		CreatedBy:  job.CreatedBy,
+		RequestID:  job.RequestID,
+		CreatedAt:  job.CreatedAt,
+		UpdatedAt:  job.UpdatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
+
+func convertImportRows(dbRows []ImportRow) []dto.ImportRow {
+	rows := make([]dto.ImportRow, len(dbRows))
+	for i, row := range dbRows {
+		rows[i] = dto.ImportRow{
+			JobID:    row.JobID,
+			Line:     row.Line,
+			Key:      row.Key,
+			Wallet:   row.Wallet,
+			WalletTo: row.WalletTo,
+			Amount:   row.Amount,
+			Status:   row.Status,
+			Error:    row.Error,
+		}
+	}
+	return rows
+}
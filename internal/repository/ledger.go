@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/ledger"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+	"github.com/ezhdanovskiy/wallets/internal/repository/sqltrace"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
+)
+
+// ScanLedger calls f for every operation ordered by wallet and seq, it stops on the first error.
+// Rows are read with a cursor, so the whole ledger isn't loaded in memory.
+// The ledger is read from the replica unless ctx requires strong consistency.
+func (r *Repo) ScanLedger(ctx context.Context, f func(ledger.Entry) error) (err error) {
+	logging.FromContext(ctx, r.log).Debug("ScanLedger")
+	const query = `
+SELECT * 
+FROM operations 
+ORDER BY wallet, seq
+`
+
+	ctx, span := sqltrace.Postgres.StartSpan(ctx, query)
+	defer func() { sqltrace.EndSpan(span, err) }()
+
+	// Only starting the query falls back to the primary, f must not see the same operations twice.
+	var rows *sqlx.Rows
+	err = r.read(ctx, func(db *sqlx.DB) error {
+		rows, err = db.QueryxContext(ctx, query)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("select operations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var op Operation
+		if err := rows.StructScan(&op); err != nil {
+			return fmt.Errorf("scan operation: %w", err)
+		}
+
+		err := f(ledger.Entry{
+			ID:          op.ID,
+			Wallet:      op.Wallet,
+			Seq:         op.Seq,
+			Type:        op.Type,
+			Amount:      op.Amount,
+			OtherWallet: op.OtherWallet,
+			CreatedAt:   op.CreatedAt,
+			PrevHash:    op.PrevHash,
+			Hash:        op.Hash,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate operations: %w", err)
+	}
+	return nil
+}
+
+// InsertLedgerCheckpointTx stores the signed checkpoint using transaction and returns it with assigned id.
+func (r *Repo) InsertLedgerCheckpointTx(ctx context.Context, tx storage.Tx, cp dto.LedgerCheckpoint) (*dto.LedgerCheckpoint, error) {
+	logging.FromContext(ctx, r.log).With("root_hash", cp.RootHash).Debug("InsertLedgerCheckpoint")
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return nil, fmt.Errorf("marshal checkpoint: %w", err)
+	}
+
+	err = get(ctx, sqlTx(tx), &cp.ID, `INSERT INTO ledger_checkpoints (checkpoint, created_at) VALUES ($1, $2) RETURNING id`,
+		data, cp.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("insert ledger_checkpoints: %w", err)
+	}
+
+	return &cp, nil
+}
+
+// GetLedgerCheckpoints selects the latest checkpoints, newest first.
+// They are read from the replica unless ctx requires strong consistency.
+func (r *Repo) GetLedgerCheckpoints(ctx context.Context, limit int64) ([]dto.LedgerCheckpoint, error) {
+	const query = `
+SELECT id, checkpoint 
+FROM ledger_checkpoints 
+ORDER BY id DESC
+LIMIT $1
+`
+
+	var rows []struct {
+		ID         int64  `db:"id"`
+		Checkpoint []byte `db:"checkpoint"`
+	}
+	err := r.read(ctx, func(db *sqlx.DB) error {
+		rows = nil
+		return selectx(ctx, db, &rows, query, limit)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("select ledger_checkpoints: %w", err)
+	}
+
+	checkpoints := make([]dto.LedgerCheckpoint, len(rows))
+	for i, row := range rows {
+		if err := json.Unmarshal(row.Checkpoint, &checkpoints[i]); err != nil {
+			return nil, fmt.Errorf("unmarshal checkpoint %d: %w", row.ID, err)
+		}
+		checkpoints[i].ID = row.ID
+	}
+
+	return checkpoints, nil
+}
+
+// GetLatestLedgerCheckpoint selects the last checkpoint or returns nil if there are no checkpoints.
+func (r *Repo) GetLatestLedgerCheckpoint(ctx context.Context) (*dto.LedgerCheckpoint, error) {
+	checkpoints, err := r.GetLedgerCheckpoints(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(checkpoints) == 0 {
+		return nil, nil
+	}
+	return &checkpoints[0], nil
+}
@@ -0,0 +1,971 @@
+// Package memory implements the repository in memory, it is used for demos, tests and local development.
+// Data is lost when the process exits.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/ledger"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
+	"github.com/ezhdanovskiy/wallets/internal/tracing"
+)
+
+// errTxDone is returned when a transaction is used after it is committed or rolled back.
+var errTxDone = errors.New("transaction has already been committed or rolled back")
+
+// Repo keeps wallets, operations, API keys, the audit log, ledger checkpoints, import jobs, statements
+// and balance snapshots in memory.
+//
+// Transactions run one at a time, as if every transaction locked all wallets, so they are serializable
+// and never conflict. Changes of a transaction are visible to others only after it is committed.
+type Repo struct {
+	log *zap.SugaredLogger
+
+	// txLock is held by the running transaction, it is a channel to stop waiting when ctx is done.
+	txLock chan struct{}
+
+	mu          sync.RWMutex // guards the committed data below
+	wallets     map[string]dto.Wallet
+	operations  []ledger.Entry
+	walletOps   map[string][]int // indexes of operations of the wallet ordered by seq
+	apiKeys     map[string]apiKey
+	audit       []dto.AuditRecord
+	checkpoints []dto.LedgerCheckpoint
+	importJobs  map[int64]dto.ImportJob
+	importRows  map[int64][]dto.ImportRow // rows of the job ordered by line
+	importKeys  map[string]bool           // idempotency keys of applied rows
+	statements  map[statementID]dto.Statement
+	snapshots   map[string][]dto.BalanceSnapshot // snapshots of the wallet ordered by time
+
+	// Last assigned ids, like Postgres sequences they aren't reused after a rollback.
+	operationID, auditID, checkpointID, importJobID int64
+	idMu                                            sync.Mutex
+}
+
+// importRowID identifies a row of an import job.
+type importRowID struct {
+	jobID int64
+	line  int
+}
+
+// statementID identifies the statement of a wallet for a period.
+type statementID struct {
+	wallet string
+	period string
+}
+
+type apiKey struct {
+	dto.APIKey
+	hash string
+}
+
+// NewRepo creates empty repository.
+func NewRepo(logger *zap.SugaredLogger) *Repo {
+	return &Repo{
+		log:        logger,
+		txLock:     make(chan struct{}, 1),
+		wallets:    make(map[string]dto.Wallet),
+		walletOps:  make(map[string][]int),
+		apiKeys:    make(map[string]apiKey),
+		importJobs: make(map[int64]dto.ImportJob),
+		importRows: make(map[int64][]dto.ImportRow),
+		importKeys: make(map[string]bool),
+		statements: make(map[statementID]dto.Statement),
+		snapshots:  make(map[string][]dto.BalanceSnapshot),
+	}
+}
+
+// tx collects changes of a transaction until it is committed.
+type tx struct {
+	wallets     map[string]dto.Wallet
+	operations  []ledger.Entry
+	apiKeys     map[string]apiKey
+	audit       []dto.AuditRecord
+	checkpoints []dto.LedgerCheckpoint
+	importJobs  map[int64]dto.ImportJob
+	importRows  map[int64][]dto.ImportRow
+	rowUpdates  map[importRowID]dto.ImportRow
+	done        bool
+}
+
+// memTx returns the transaction behind stx, stx must be started by RunWithTransaction of Repo.
+func memTx(stx storage.Tx) (*tx, error) {
+	t := stx.(*tx)
+	if t.done {
+		return nil, errTxDone
+	}
+	return t, nil
+}
+
+// RunWithTransaction runs the given function inside a transaction, it waits while another transaction runs.
+// The changes are committed if f returns nil and discarded otherwise.
+func (r *Repo) RunWithTransaction(ctx context.Context, f func(ctx context.Context, tx storage.Tx) error) (err error) {
+	logging.FromContext(ctx, r.log).Debug("RunWithTransaction")
+
+	ctx, span := tracing.Start(ctx, "transaction")
+	defer func() { tracing.End(span, err) }()
+
+	select {
+	case r.txLock <- struct{}{}:
+	case <-ctx.Done():
+		return fmt.Errorf("begin tx: %w", ctx.Err())
+	}
+	defer func() { <-r.txLock }()
+
+	t := &tx{
+		wallets:    make(map[string]dto.Wallet),
+		apiKeys:    make(map[string]apiKey),
+		importJobs: make(map[int64]dto.ImportJob),
+		importRows: make(map[int64][]dto.ImportRow),
+		rowUpdates: make(map[importRowID]dto.ImportRow),
+	}
+	defer func() { t.done = true }()
+
+	if err := f(ctx, t); err != nil {
+		return err
+	}
+
+	r.commit(t)
+	return nil
+}
+
+func (r *Repo) commit(t *tx) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, w := range t.wallets {
+		r.wallets[name] = w
+	}
+	for _, op := range t.operations {
+		r.walletOps[op.Wallet] = append(r.walletOps[op.Wallet], len(r.operations))
+		r.operations = append(r.operations, op)
+	}
+	for name, k := range t.apiKeys {
+		r.apiKeys[name] = k
+	}
+	r.audit = append(r.audit, t.audit...)
+	r.checkpoints = append(r.checkpoints, t.checkpoints...)
+	for id, job := range t.importJobs {
+		r.importJobs[id] = job
+	}
+	for id, rows := range t.importRows {
+		r.importRows[id] = rows
+	}
+	for id, row := range t.rowUpdates {
+		rows := r.importRows[id.jobID]
+		if i, ok := slices.BinarySearchFunc(rows, id.line, compareLine); ok {
+			rows[i] = row
+		}
+		if row.Status == consts.ImportStatusApplied {
+			r.importKeys[row.Key] = true
+		}
+	}
+}
+
+func (r *Repo) nextID(id *int64) int64 {
+	r.idMu.Lock()
+	defer r.idMu.Unlock()
+	*id++
+	return *id
+}
+
+// wallet returns the wallet changed by the transaction or the committed one.
+func (r *Repo) wallet(t *tx, name string) (dto.Wallet, bool) {
+	if w, ok := t.wallets[name]; ok {
+		return w, true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	w, ok := r.wallets[name]
+	return w, ok
+}
+
+// CreateWallet creates new wallet with unique name,
+// or do nothing if wallet already exists.
+func (r *Repo) CreateWallet(ctx context.Context, walletName, owner string) error {
+	return r.RunWithTransaction(ctx, func(ctx context.Context, tx storage.Tx) error {
+		return r.CreateWalletTx(ctx, tx, walletName, owner)
+	})
+}
+
+// CreateWalletTx is CreateWallet using transaction.
+func (r *Repo) CreateWalletTx(ctx context.Context, stx storage.Tx, walletName, owner string) error {
+	logging.FromContext(ctx, r.log).With("wallet", walletName, "owner", owner).Debug("CreateWallet")
+	t, err := memTx(stx)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := r.wallet(t, walletName); !ok {
+		t.wallets[walletName] = dto.Wallet{Name: walletName, Owner: owner}
+	}
+	return nil
+}
+
+// GetWallet returns the committed wallet by name or nil if it doesn't exist.
+func (r *Repo) GetWallet(ctx context.Context, walletName string) (*dto.Wallet, error) {
+	logging.FromContext(ctx, r.log).With("wallet", walletName).Debug("GetWallet")
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.wallets[walletName]
+	if !ok {
+		return nil, nil
+	}
+	return &w, nil
+}
+
+// ListWallets returns wallets ordered by name, optionally of the owner.
+func (r *Repo) ListWallets(ctx context.Context, filter dto.WalletsFilter) ([]dto.Wallet, error) {
+	logging.FromContext(ctx, r.log).With("owner", filter.Owner).Debug("ListWallets")
+	r.mu.RLock()
+	wallets := make([]dto.Wallet, 0)
+	for _, w := range r.wallets {
+		if filter.Owner == "" || w.Owner == filter.Owner {
+			wallets = append(wallets, w)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(wallets, func(i, j int) bool { return wallets[i].Name < wallets[j].Name })
+	from, to := window(len(wallets), filter.Limit, filter.Offset)
+	return wallets[from:to], nil
+}
+
+// IncreaseWalletBalanceTx increases wallet balance and adds new operation with type deposit using transaction.
+func (r *Repo) IncreaseWalletBalanceTx(ctx context.Context, stx storage.Tx, walletName string, amount uint64) error {
+	logging.FromContext(ctx, r.log).With("wallet", walletName, "amount", amount).Debug("IncreaseWalletBalanceTx")
+	t, err := memTx(stx)
+	if err != nil {
+		return err
+	}
+
+	if err := r.increaseWalletBalance(t, walletName, amount); err != nil {
+		return err
+	}
+	r.insertOperation(t, walletName, consts.OperationTypeDeposit, amount, consts.SystemWalletName)
+	return nil
+}
+
+// GetWalletsForUpdateTx returns the existing wallets of walletNames using transaction.
+// No locks are needed, because no other transaction runs until this one finishes.
+func (r *Repo) GetWalletsForUpdateTx(ctx context.Context, stx storage.Tx, walletNames []string) ([]dto.Wallet, error) {
+	logging.FromContext(ctx, r.log).With("wallets", walletNames).Debug("GetWalletsForUpdateTx")
+	t, err := memTx(stx)
+	if err != nil {
+		return nil, err
+	}
+
+	wallets := make([]dto.Wallet, 0, len(walletNames))
+	seen := make(map[string]bool, len(walletNames))
+	for _, name := range walletNames {
+		if w, ok := r.wallet(t, name); ok && !seen[name] {
+			seen[name] = true
+			wallets = append(wallets, w)
+		}
+	}
+	sort.Slice(wallets, func(i, j int) bool { return wallets[i].Name < wallets[j].Name })
+	return wallets, nil
+}
+
+// TransferTx moves amount from walletFrom to walletTo and adds withdrawal and deposit operations using transaction.
+func (r *Repo) TransferTx(ctx context.Context, stx storage.Tx, walletFrom, walletTo string, amount uint64) error {
+	logging.FromContext(ctx, r.log).With("wallet_from", walletFrom, "wallet_to", walletTo, "amount", amount).Debug("TransferTx")
+	t, err := memTx(stx)
+	if err != nil {
+		return err
+	}
+
+	from, ok := r.wallet(t, walletFrom)
+	if !ok || from.Balance < amount {
+		return fmt.Errorf("decrease wallet balance: %s balance can't be decreased on this amount", walletFrom)
+	}
+	from.Balance -= amount
+	t.wallets[walletFrom] = from
+	r.insertOperation(t, walletFrom, consts.OperationTypeWithdrawal, amount, walletTo)
+
+	if err := r.increaseWalletBalance(t, walletTo, amount); err != nil {
+		return fmt.Errorf("increase wallet balance: %w", err)
+	}
+	r.insertOperation(t, walletTo, consts.OperationTypeDeposit, amount, walletFrom)
+	return nil
+}
+
+func (r *Repo) increaseWalletBalance(t *tx, walletName string, amount uint64) error {
+	w, ok := r.wallet(t, walletName)
+	if !ok {
+		return fmt.Errorf("wallet %s not found", walletName)
+	}
+	w.Balance += amount
+	t.wallets[walletName] = w
+	return nil
+}
+
+// insertOperation appends the operation to the wallet chain.
+func (r *Repo) insertOperation(t *tx, wallet, opType string, amount uint64, otherWallet string) {
+	entry := ledger.Entry{
+		ID:          r.nextID(&r.operationID),
+		Wallet:      wallet,
+		Seq:         1,
+		Type:        opType,
+		Amount:      amount,
+		OtherWallet: otherWallet,
+		CreatedAt:   ledger.Now(),
+	}
+	if last, ok := r.lastOperation(t, wallet); ok {
+		entry.Seq = last.Seq + 1
+		entry.PrevHash = last.Hash
+	}
+	entry.Hash = ledger.Hash(entry)
+
+	t.operations = append(t.operations, entry)
+}
+
+func (r *Repo) lastOperation(t *tx, wallet string) (ledger.Entry, bool) {
+	for i := len(t.operations) - 1; i >= 0; i-- {
+		if t.operations[i].Wallet == wallet {
+			return t.operations[i], true
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ops := r.walletOps[wallet]
+	if len(ops) == 0 {
+		return ledger.Entry{}, false
+	}
+	return r.operations[ops[len(ops)-1]], true
+}
+
+// GetOperations returns operations of the wallet using filter, ordered by (created_at, id) in the order of the filter.
+func (r *Repo) GetOperations(ctx context.Context, filter dto.OperationsFilter) ([]dto.Operation, error) {
+	logging.FromContext(ctx, r.log).With("wallet", filter.Wallet).Debug("GetOperations")
+	r.mu.RLock()
+	operations := make([]dto.Operation, 0)
+	for _, i := range r.walletOps[filter.Wallet] {
+		op := r.operations[i]
+		if !operationMatches(op, filter) {
+			continue
+		}
+		o := dto.Operation{
+			ID:          op.ID,
+			Wallet:      op.Wallet,
+			Type:        op.Type,
+			OtherWallet: op.OtherWallet,
+			Timestamp:   op.CreatedAt,
+		}
+		o.Amount.SetAmount(op.Amount)
+		operations = append(operations, o)
+	}
+	r.mu.RUnlock()
+
+	sort.SliceStable(operations, func(i, j int) bool { return operationLess(operations[i], operations[j]) })
+
+	// A page before the cursor is the page after it in the reverse order, reversed back.
+	backward := filter.Cursor != nil && filter.Cursor.Backward
+	descending := (filter.Order == consts.OperationsOrderDesc) != backward
+	if descending {
+		reverse(operations)
+	}
+	if c := filter.Cursor; c != nil {
+		position := dto.Operation{ID: c.ID, Timestamp: c.CreatedAt}
+		n := sort.Search(len(operations), func(i int) bool {
+			if descending {
+				return operationLess(operations[i], position)
+			}
+			return operationLess(position, operations[i])
+		})
+		operations = operations[n:]
+	}
+
+	from, to := window(len(operations), filter.Limit, filter.Offset)
+	operations = operations[from:to]
+	if backward {
+		reverse(operations)
+	}
+	return operations, nil
+}
+
+// ScanOperations calls f for every operation of the wallet selected by filter, it stops on the first error.
+// A backward cursor scans operations in reverse.
+func (r *Repo) ScanOperations(ctx context.Context, filter dto.OperationsFilter, f func(dto.Operation) error) error {
+	operations, err := r.GetOperations(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if filter.Cursor != nil && filter.Cursor.Backward {
+		reverse(operations)
+	}
+
+	for _, op := range operations {
+		if err := f(op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetOperationsTotals returns the number and the sum of the operations of the wallet selected by filter by type,
+// the cursor, the order and the pagination of the filter are ignored.
+func (r *Repo) GetOperationsTotals(ctx context.Context, filter dto.OperationsFilter) (*dto.OperationsTotals, error) {
+	logging.FromContext(ctx, r.log).With("wallet", filter.Wallet).Debug("GetOperationsTotals")
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var deposits, withdrawals uint64
+	var totals dto.OperationsTotals
+	for _, i := range r.walletOps[filter.Wallet] {
+		op := r.operations[i]
+		if !operationMatches(op, filter) {
+			continue
+		}
+		switch op.Type {
+		case consts.OperationTypeDeposit:
+			totals.Deposits.Count++
+			deposits += op.Amount
+		case consts.OperationTypeWithdrawal:
+			totals.Withdrawals.Count++
+			withdrawals += op.Amount
+		}
+	}
+	totals.Deposits.Cents, totals.Withdrawals.Cents = deposits, withdrawals
+	return &totals, nil
+}
+
+// operationMatches reports whether the operation passes the filters of the history other than the cursor.
+func operationMatches(op ledger.Entry, filter dto.OperationsFilter) bool {
+	if len(filter.Types) != 0 && !slices.Contains(filter.Types, op.Type) ||
+		filter.OtherWallet != "" && op.OtherWallet != filter.OtherWallet {
+		return false
+	}
+	if filter.MinAmount > 0 && op.Amount < filter.MinAmount.GetInt() ||
+		filter.MaxAmount > 0 && op.Amount > filter.MaxAmount.GetInt() {
+		return false
+	}
+	return (filter.StartDate.IsZero() || !op.CreatedAt.Before(filter.StartDate)) &&
+		(filter.EndDate.IsZero() || !op.CreatedAt.After(filter.EndDate))
+}
+
+// operationLess orders operations by (created_at, id).
+func operationLess(a, b dto.Operation) bool {
+	if !a.Timestamp.Equal(b.Timestamp) {
+		return a.Timestamp.Before(b.Timestamp)
+	}
+	return a.ID < b.ID
+}
+
+func reverse(operations []dto.Operation) {
+	for i, j := 0, len(operations)-1; i < j; i, j = i+1, j-1 {
+		operations[i], operations[j] = operations[j], operations[i]
+	}
+}
+
+// CreateAPIKeyTx stores API key with its hash using transaction.
+// It returns nil if a key with the same name or hash already exists.
+func (r *Repo) CreateAPIKeyTx(ctx context.Context, stx storage.Tx, key dto.APIKey, keyHash string) (*dto.APIKey, error) {
+	logging.FromContext(ctx, r.log).With("name", key.Name, "scopes", key.Scopes).Debug("CreateAPIKey")
+	t, err := memTx(stx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := t.apiKeys[key.Name]; ok {
+		return nil, nil
+	}
+	for _, k := range t.apiKeys {
+		if k.hash == keyHash {
+			return nil, nil
+		}
+	}
+	r.mu.RLock()
+	_, exists := r.apiKeys[key.Name]
+	for _, k := range r.apiKeys {
+		exists = exists || k.hash == keyHash
+	}
+	r.mu.RUnlock()
+	if exists {
+		return nil, nil
+	}
+
+	created := dto.APIKey{
+		Name:      key.Name,
+		Scopes:    copyList(key.Scopes),
+		Wallets:   copyList(key.Wallets),
+		Owners:    copyList(key.Owners),
+		CreatedAt: time.Now(),
+	}
+	t.apiKeys[key.Name] = apiKey{APIKey: created, hash: keyHash}
+	return &created, nil
+}
+
+// GetAPIKeyByHash returns API key by the hash of the key or nil if it doesn't exist.
+func (r *Repo) GetAPIKeyByHash(ctx context.Context, keyHash string) (*dto.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, k := range r.apiKeys {
+		if k.hash == keyHash {
+			key := k.APIKey
+			return &key, nil
+		}
+	}
+	return nil, nil
+}
+
+// ListAPIKeys returns all API keys including revoked ones ordered by name.
+func (r *Repo) ListAPIKeys(ctx context.Context) ([]dto.APIKey, error) {
+	logging.FromContext(ctx, r.log).Debug("ListAPIKeys")
+	r.mu.RLock()
+	keys := make([]dto.APIKey, 0, len(r.apiKeys))
+	for _, k := range r.apiKeys {
+		keys = append(keys, k.APIKey)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+	return keys, nil
+}
+
+// RevokeAPIKeyTx marks API key as revoked using transaction,
+// it returns false if there is no active key with the name.
+func (r *Repo) RevokeAPIKeyTx(ctx context.Context, stx storage.Tx, name string) (bool, error) {
+	logging.FromContext(ctx, r.log).With("name", name).Debug("RevokeAPIKey")
+	t, err := memTx(stx)
+	if err != nil {
+		return false, err
+	}
+
+	k, ok := t.apiKeys[name]
+	if !ok {
+		r.mu.RLock()
+		k, ok = r.apiKeys[name]
+		r.mu.RUnlock()
+	}
+	if !ok || k.RevokedAt != nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	k.RevokedAt = &now
+	t.apiKeys[name] = k
+	return true, nil
+}
+
+// InsertAuditRecordTx appends the record to the audit log using transaction,
+// so the record is committed only together with the audited changes.
+func (r *Repo) InsertAuditRecordTx(ctx context.Context, stx storage.Tx, record dto.AuditRecord) error {
+	logging.FromContext(ctx, r.log).With("action", record.Action, "actor", record.Actor, "result", record.Result).Debug("insertAuditRecord")
+	t, err := memTx(stx)
+	if err != nil {
+		return err
+	}
+
+	t.audit = append(t.audit, r.newAuditRecord(record))
+	return nil
+}
+
+// InsertAuditRecord appends the record to the audit log, it is used for failed actions
+// whose transaction has been rolled back.
+func (r *Repo) InsertAuditRecord(ctx context.Context, record dto.AuditRecord) error {
+	logging.FromContext(ctx, r.log).With("action", record.Action, "actor", record.Actor, "result", record.Result).Debug("insertAuditRecord")
+	record = r.newAuditRecord(record)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.audit = append(r.audit, record)
+	return nil
+}
+
+func (r *Repo) newAuditRecord(record dto.AuditRecord) dto.AuditRecord {
+	record.ID = r.nextID(&r.auditID)
+	record.Timestamp = time.Now()
+	return record
+}
+
+// GetAuditRecords returns audit records using filter, ordered by time.
+func (r *Repo) GetAuditRecords(ctx context.Context, filter dto.AuditFilter) ([]dto.AuditRecord, error) {
+	r.mu.RLock()
+	records := make([]dto.AuditRecord, 0)
+	for _, rec := range r.audit {
+		if filter.Actor != "" && rec.Actor != filter.Actor ||
+			filter.Action != "" && rec.Action != filter.Action ||
+			filter.RequestID != "" && rec.RequestID != filter.RequestID ||
+			!inPeriod(rec.Timestamp, filter.StartDate, filter.EndDate) {
+			continue
+		}
+		records = append(records, rec)
+	}
+	r.mu.RUnlock()
+
+	// Records of a transaction are appended at commit, so the order of ids may differ from the order of times.
+	sort.SliceStable(records, func(i, j int) bool {
+		if !records[i].Timestamp.Equal(records[j].Timestamp) {
+			return records[i].Timestamp.Before(records[j].Timestamp)
+		}
+		return records[i].ID < records[j].ID
+	})
+	from, to := window(len(records), filter.Limit, filter.Offset)
+	return records[from:to], nil
+}
+
+// ScanLedger calls f for every committed operation ordered by wallet and seq, it stops on the first error.
+func (r *Repo) ScanLedger(ctx context.Context, f func(ledger.Entry) error) error {
+	logging.FromContext(ctx, r.log).Debug("ScanLedger")
+	r.mu.RLock()
+	wallets := make([]string, 0, len(r.walletOps))
+	for wallet := range r.walletOps {
+		wallets = append(wallets, wallet)
+	}
+	entries := make([]ledger.Entry, 0, len(r.operations))
+	sort.Strings(wallets)
+	for _, wallet := range wallets {
+		for _, i := range r.walletOps[wallet] {
+			entries = append(entries, r.operations[i])
+		}
+	}
+	r.mu.RUnlock()
+
+	for _, e := range entries {
+		if err := f(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InsertLedgerCheckpointTx stores the signed checkpoint using transaction and returns it with assigned id.
+func (r *Repo) InsertLedgerCheckpointTx(ctx context.Context, stx storage.Tx, cp dto.LedgerCheckpoint) (*dto.LedgerCheckpoint, error) {
+	logging.FromContext(ctx, r.log).With("root_hash", cp.RootHash).Debug("InsertLedgerCheckpoint")
+	t, err := memTx(stx)
+	if err != nil {
+		return nil, err
+	}
+
+	cp.ID = r.nextID(&r.checkpointID)
+	t.checkpoints = append(t.checkpoints, cp)
+	return &cp, nil
+}
+
+// GetLedgerCheckpoints returns the latest checkpoints, newest first.
+func (r *Repo) GetLedgerCheckpoints(ctx context.Context, limit int64) ([]dto.LedgerCheckpoint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	checkpoints := make([]dto.LedgerCheckpoint, 0)
+	for i := len(r.checkpoints) - 1; i >= 0 && int64(len(checkpoints)) < limit; i-- {
+		checkpoints = append(checkpoints, r.checkpoints[i])
+	}
+	return checkpoints, nil
+}
+
+// GetLatestLedgerCheckpoint returns the last checkpoint or nil if there are no checkpoints.
+func (r *Repo) GetLatestLedgerCheckpoint(ctx context.Context) (*dto.LedgerCheckpoint, error) {
+	checkpoints, err := r.GetLedgerCheckpoints(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(checkpoints) == 0 {
+		return nil, nil
+	}
+	return &checkpoints[0], nil
+}
+
+// CreateImportJobTx stores the job with its rows using transaction and returns the job with assigned id.
+func (r *Repo) CreateImportJobTx(ctx context.Context, stx storage.Tx, job dto.ImportJob, rows []dto.ImportRow) (*dto.ImportJob, error) {
+	logging.FromContext(ctx, r.log).With("kind", job.Kind, "rows", len(rows)).Debug("CreateImportJob")
+	t, err := memTx(stx)
+	if err != nil {
+		return nil, err
+	}
+
+	job.ID = r.nextID(&r.importJobID)
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = job.CreatedAt
+	job.Applied, job.Skipped, job.Failed, job.FinishedAt = 0, 0, 0, nil
+
+	stored := make([]dto.ImportRow, len(rows))
+	for i, row := range rows {
+		row.JobID = job.ID
+		stored[i] = row
+	}
+	slices.SortFunc(stored, func(a, b dto.ImportRow) int { return a.Line - b.Line })
+
+	t.importJobs[job.ID] = job
+	t.importRows[job.ID] = stored
+	return &job, nil
+}
+
+// GetImportJob returns the committed job by id or nil if it doesn't exist.
+func (r *Repo) GetImportJob(ctx context.Context, id int64) (*dto.ImportJob, error) {
+	logging.FromContext(ctx, r.log).With("id", id).Debug("GetImportJob")
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, ok := r.importJobs[id]
+	if !ok {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+// GetImportRows returns committed rows of the job ordered by line.
+func (r *Repo) GetImportRows(ctx context.Context, jobID int64) ([]dto.ImportRow, error) {
+	logging.FromContext(ctx, r.log).With("job_id", jobID).Debug("GetImportRows")
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]dto.ImportRow{}, r.importRows[jobID]...), nil
+}
+
+// ClaimImportJob marks the oldest pending job as running and returns it, a running job is claimed again
+// if it isn't updated during the lease. It returns nil if there are no jobs to run.
+func (r *Repo) ClaimImportJob(ctx context.Context, lease time.Duration) (*dto.ImportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var claimed *dto.ImportJob
+	for _, job := range r.importJobs {
+		if job.Status != consts.ImportStatusPending &&
+			(job.Status != consts.ImportStatusRunning || !job.UpdatedAt.Before(now.Add(-lease))) {
+			continue
+		}
+		if claimed == nil || job.ID < claimed.ID {
+			claimed = &job
+		}
+	}
+	if claimed == nil {
+		return nil, nil
+	}
+
+	claimed.Status = consts.ImportStatusRunning
+	claimed.UpdatedAt = now
+	r.importJobs[claimed.ID] = *claimed
+
+	logging.FromContext(ctx, r.log).With("id", claimed.ID).Debug("ClaimImportJob")
+	return claimed, nil
+}
+
+// GetPendingImportRowsTx returns at most limit pending rows of the job ordered by line using transaction.
+func (r *Repo) GetPendingImportRowsTx(ctx context.Context, stx storage.Tx, jobID int64, limit int) ([]dto.ImportRow, error) {
+	logging.FromContext(ctx, r.log).With("job_id", jobID, "limit", limit).Debug("GetPendingImportRowsTx")
+	t, err := memTx(stx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, ok := t.importRows[jobID]
+	if !ok {
+		r.mu.RLock()
+		rows = r.importRows[jobID]
+		r.mu.RUnlock()
+	}
+
+	pending := make([]dto.ImportRow, 0)
+	for _, row := range rows {
+		if len(pending) >= limit {
+			break
+		}
+		if updated, ok := t.rowUpdates[importRowID{jobID: jobID, line: row.Line}]; ok {
+			row = updated
+		}
+		if row.Status == consts.ImportStatusPending {
+			pending = append(pending, row)
+		}
+	}
+	return pending, nil
+}
+
+// ImportKeyAppliedTx reports whether a row with the idempotency key is applied using transaction.
+func (r *Repo) ImportKeyAppliedTx(ctx context.Context, stx storage.Tx, key string) (bool, error) {
+	t, err := memTx(stx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, row := range t.rowUpdates {
+		if row.Key == key && row.Status == consts.ImportStatusApplied {
+			return true, nil
+		}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.importKeys[key], nil
+}
+
+// UpdateImportRowTx sets the status and the error of the row using transaction.
+func (r *Repo) UpdateImportRowTx(ctx context.Context, stx storage.Tx, row dto.ImportRow) error {
+	logging.FromContext(ctx, r.log).With("job_id", row.JobID, "line", row.Line, "status", row.Status).Debug("UpdateImportRowTx")
+	t, err := memTx(stx)
+	if err != nil {
+		return err
+	}
+
+	id := importRowID{jobID: row.JobID, line: row.Line}
+	stored, ok := t.rowUpdates[id]
+	if !ok {
+		rows, created := t.importRows[row.JobID]
+		if !created {
+			r.mu.RLock()
+			rows = r.importRows[row.JobID]
+			r.mu.RUnlock()
+		}
+		i, found := slices.BinarySearchFunc(rows, row.Line, compareLine)
+		if !found {
+			return nil
+		}
+		stored = rows[i]
+	}
+
+	stored.Status, stored.Error = row.Status, row.Error
+	t.rowUpdates[id] = stored
+	return nil
+}
+
+// UpdateImportJobTx sets the status and the progress of the job using transaction, it renews the lease of the job.
+func (r *Repo) UpdateImportJobTx(ctx context.Context, stx storage.Tx, job dto.ImportJob) error {
+	logging.FromContext(ctx, r.log).With("id", job.ID, "status", job.Status).Debug("UpdateImportJobTx")
+	t, err := memTx(stx)
+	if err != nil {
+		return err
+	}
+
+	stored, ok := t.importJobs[job.ID]
+	if !ok {
+		r.mu.RLock()
+		stored, ok = r.importJobs[job.ID]
+		r.mu.RUnlock()
+	}
+	if !ok {
+		return nil
+	}
+
+	stored.Status = job.Status
+	stored.Applied, stored.Skipped, stored.Failed = job.Applied, job.Skipped, job.Failed
+	stored.FinishedAt = job.FinishedAt
+	stored.UpdatedAt = time.Now()
+	t.importJobs[job.ID] = stored
+	return nil
+}
+
+// compareLine compares the line of the row with the line, rows of a job are ordered by line.
+func compareLine(row dto.ImportRow, line int) int {
+	return row.Line - line
+}
+
+// Ping always succeeds, the repository is in the process.
+func (r *Repo) Ping(ctx context.Context) error {
+	return nil
+}
+
+// MigrationVersion returns 0, the repository has no schema.
+func (r *Repo) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	return 0, false, nil
+}
+
+// Stats returns empty statistics, there is no connection pool.
+func (r *Repo) Stats() sql.DBStats {
+	return sql.DBStats{}
+}
+
+// Close does nothing, data is kept until the process exits.
+func (r *Repo) Close() error {
+	return nil
+}
+
+// inPeriod reports whether t is within the period of unix times, 0 means the bound isn't set.
+func inPeriod(t time.Time, start, end int64) bool {
+	seconds := float64(t.UnixNano()) / float64(time.Second)
+	return (start <= 0 || seconds >= float64(start)) && (end <= 0 || seconds <= float64(end))
+}
+
+// window returns bounds of the page of n items, a limit not greater than 0 means no limit.
+func window(n int, limit, offset int64) (from, to int) {
+	if offset >= int64(n) {
+		return n, n
+	}
+	if offset > 0 {
+		from = int(offset)
+	}
+	to = n
+	if limit > 0 && int64(from)+limit < int64(n) {
+		to = from + int(limit)
+	}
+	return from, to
+}
+
+func copyList(list []string) []string {
+	return append([]string{}, list...)
+}
+
+// InsertStatement stores the statement of the closed month. A stored statement of the same wallet and period
+// is kept, it has the same operations.
+func (r *Repo) InsertStatement(ctx context.Context, st dto.Statement) error {
+	logging.FromContext(ctx, r.log).With("wallet", st.Wallet, "period", st.Period).Debug("InsertStatement")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := statementID{wallet: st.Wallet, period: st.Period}
+	if _, ok := r.statements[id]; !ok {
+		st.Entries = slices.Clone(st.Entries)
+		r.statements[id] = st
+	}
+	return nil
+}
+
+// GetStatement returns the stored statement of the wallet for the period, it returns nil if it isn't stored.
+func (r *Repo) GetStatement(ctx context.Context, walletName, period string) (*dto.Statement, error) {
+	logging.FromContext(ctx, r.log).With("wallet", walletName, "period", period).Debug("GetStatement")
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	st, ok := r.statements[statementID{wallet: walletName, period: period}]
+	if !ok {
+		return nil, nil
+	}
+	st.Entries = slices.Clone(st.Entries)
+	return &st, nil
+}
+
+// InsertBalanceSnapshot stores the snapshot, a stored snapshot of the wallet at the same time is kept.
+func (r *Repo) InsertBalanceSnapshot(ctx context.Context, snapshot dto.BalanceSnapshot) error {
+	logging.FromContext(ctx, r.log).With("wallet", snapshot.Wallet, "taken_at", snapshot.TakenAt).Debug("InsertBalanceSnapshot")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshots := r.snapshots[snapshot.Wallet]
+	i := sort.Search(len(snapshots), func(i int) bool { return !snapshots[i].TakenAt.Before(snapshot.TakenAt) })
+	if i < len(snapshots) && snapshots[i].TakenAt.Equal(snapshot.TakenAt) {
+		return nil
+	}
+	r.snapshots[snapshot.Wallet] = slices.Insert(snapshots, i, snapshot)
+	return nil
+}
+
+// GetLatestBalanceSnapshot returns the latest snapshot of the wallet taken at or before the time,
+// it returns nil if there is no such snapshot.
+func (r *Repo) GetLatestBalanceSnapshot(ctx context.Context, walletName string, at time.Time) (*dto.BalanceSnapshot, error) {
+	logging.FromContext(ctx, r.log).With("wallet", walletName, "at", at).Debug("GetLatestBalanceSnapshot")
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshots := r.snapshots[walletName]
+	i := sort.Search(len(snapshots), func(i int) bool { return snapshots[i].TakenAt.After(at) })
+	if i == 0 {
+		return nil, nil
+	}
+	snapshot := snapshots[i-1]
+	return &snapshot, nil
+}
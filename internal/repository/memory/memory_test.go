@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/ezhdanovskiy/wallets/internal/repository/conformance"
+)
+
+func TestRepo_Conformance(t *testing.T) {
+	conformance.Run(t, NewRepo(zap.NewNop().Sugar()))
+}
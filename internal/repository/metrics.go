@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	transactionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wallets_db_transactions_total",
+		Help: "Transactions run by RunWithTransaction by result: commit or rollback.",
+	}, []string{"result"})
+
+	transactionRetriesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "wallets_db_transaction_retries_total",
+		Help: "Transactions restarted after serialization failures or deadlocks.",
+	})
+
+	replicaFallbacksTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "wallets_db_replica_fallbacks_total",
+		Help: "Read queries repeated on the primary after the replica failed.",
+	})
+
+	lockWaitSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "wallets_db_lock_wait_seconds",
+		Help:    "Time spent locking wallets for update.",
+		Buckets: prometheus.ExponentialBuckets(0.0005, 4, 10),
+	})
+)
+
+// Results of transactions used as the result label.
+const (
+	txResultCommit   = "commit"
+	txResultRollback = "rollback"
+)
+
+// StatsCollector returns collector of the connection pool statistics.
+func (r *Repo) StatsCollector() prometheus.Collector {
+	return NewStatsCollector(r.db)
+}
+
+// NewStatsCollector returns collector of statistics of the connection pool of db.
+func NewStatsCollector(db *sqlx.DB) prometheus.Collector {
+	return &dbStatsCollector{db: db}
+}
+
+var (
+	dbMaxOpenDesc = prometheus.NewDesc("wallets_db_max_open_connections",
+		"Maximum number of open connections to the database.", nil, nil)
+	dbOpenDesc = prometheus.NewDesc("wallets_db_open_connections",
+		"The number of established connections both in use and idle.", nil, nil)
+	dbInUseDesc = prometheus.NewDesc("wallets_db_in_use_connections",
+		"The number of connections currently in use.", nil, nil)
+	dbIdleDesc = prometheus.NewDesc("wallets_db_idle_connections",
+		"The number of idle connections.", nil, nil)
+	dbWaitCountDesc = prometheus.NewDesc("wallets_db_wait_count_total",
+		"The total number of connections waited for.", nil, nil)
+	dbWaitDurationDesc = prometheus.NewDesc("wallets_db_wait_duration_seconds_total",
+		"The total time blocked waiting for a new connection.", nil, nil)
+)
+
+// dbStatsCollector exports sql.DBStats on every scrape.
+type dbStatsCollector struct {
+	db *sqlx.DB
+}
+
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dbMaxOpenDesc
+	ch <- dbOpenDesc
+	ch <- dbInUseDesc
+	ch <- dbIdleDesc
+	ch <- dbWaitCountDesc
+	ch <- dbWaitDurationDesc
+}
+
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.db.Stats()
+	ch <- prometheus.MustNewConstMetric(dbMaxOpenDesc, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(dbOpenDesc, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(dbInUseDesc, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(dbIdleDesc, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(dbWaitCountDesc, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(dbWaitDurationDesc, prometheus.CounterValue, stats.WaitDuration.Seconds())
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/httpfs"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/ezhdanovskiy/wallets/internal/config"
+	"github.com/ezhdanovskiy/wallets/migrations"
+)
+
+// migrateLockID is the key of the advisory lock held while migrations are changed.
+// It differs from the lock golang-migrate takes on its own connection, otherwise they would deadlock.
+const migrateLockID int64 = 0x77616c6c657473 // "wallets"
+
+// Errors of the schema that the application can't work with.
+var (
+	ErrSchemaDirty  = errors.New("database schema is dirty")
+	ErrSchemaBehind = errors.New("database schema is behind the migrations")
+)
+
+// Migrator applies migrations from the configured source to the database.
+type Migrator struct {
+	log *zap.SugaredLogger
+	db  *sqlx.DB
+	m   *migrate.Migrate
+	cfg config.DB
+}
+
+// MigrationStatus describes the schema of the database.
+type MigrationStatus struct {
+	Version uint // 0 if no migrations are applied
+	Dirty   bool // the last migration failed, the schema must be fixed manually and the version forced
+	Latest  uint // the version of the last migration in the source
+}
+
+// NewMigrator creates migrator for the migrations source from config.
+// The database is closed by Close of the migrator. The advisory lock and golang-migrate hold
+// separate connections, so the pool must allow at least two of them.
+func NewMigrator(logger *zap.SugaredLogger, db *sqlx.DB, cfg config.DB) (*Migrator, error) {
+	if db.Stats().MaxOpenConnections == 1 {
+		return nil, errors.New("migrations need at least 2 open database connections")
+	}
+
+	driver, err := postgres.WithInstance(db.DB, &postgres.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("migrate postgres driver: %w", err)
+	}
+	src, err := openMigrations(cfg)
+	if err != nil {
+		return nil, err
+	}
+	m, err := migrate.NewWithInstance(cfg.MigrationsSource, src, "postgres", driver)
+	if err != nil {
+		return nil, fmt.Errorf("migrate NewWithInstance: %w", err)
+	}
+	m.Log = migrateLogger{log: logger}
+
+	return &Migrator{log: logger, db: db, m: m, cfg: cfg}, nil
+}
+
+// Up applies all migrations that aren't applied yet.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.locked(ctx, func() error {
+		if err := m.m.Up(); err != nil && err != migrate.ErrNoChange {
+			return fmt.Errorf("migrate up: %w", err)
+		}
+		return nil
+	})
+}
+
+// Down rolls back the last steps migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.locked(ctx, func() error {
+		if err := m.m.Steps(-steps); err != nil && err != migrate.ErrNoChange {
+			return fmt.Errorf("migrate down: %w", err)
+		}
+		return nil
+	})
+}
+
+// Force sets the version without running migrations and clears the dirty flag.
+// It is used after the schema of a failed migration is fixed manually.
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	return m.locked(ctx, func() error {
+		if err := m.m.Force(version); err != nil {
+			return fmt.Errorf("migrate force: %w", err)
+		}
+		return nil
+	})
+}
+
+// locked calls f holding the advisory lock, so instances starting together apply migrations one by one:
+// the first applies them, the others wait and find nothing to apply. ctx limits the wait for the lock.
+func (m *Migrator) locked(ctx context.Context, f func() error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("migration lock connection: %w", err)
+	}
+	defer conn.Close()
+
+	m.log.Debug("Acquiring migration lock")
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrateLockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrateLockID); err != nil {
+			m.log.With("error", err).Warn("Failed to release migration lock")
+		}
+	}()
+
+	return f()
+}
+
+// Status returns the applied and the latest versions.
+func (m *Migrator) Status() (MigrationStatus, error) {
+	var status MigrationStatus
+
+	version, dirty, err := m.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return status, fmt.Errorf("migrate version: %w", err)
+	}
+	status.Version, status.Dirty = version, dirty
+
+	status.Latest, err = LatestMigrationVersion(m.cfg)
+	if err != nil {
+		return status, err
+	}
+	return status, nil
+}
+
+// Close closes the migrations source and the database.
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	if srcErr != nil {
+		return fmt.Errorf("close migrations: %w", srcErr)
+	}
+	if dbErr != nil {
+		return fmt.Errorf("close database: %w", dbErr)
+	}
+	return nil
+}
+
+// ConnectMigrator connects a separate connection pool for migrations, it is closed by Close of the migrator.
+// The pool allows at least 2 connections for the advisory lock and golang-migrate.
+func ConnectMigrator(logger *zap.SugaredLogger, cfg config.DB) (*Migrator, error) {
+	if cfg.MaxOpenConns == 1 {
+		cfg.MaxOpenConns = 2
+	}
+	db, err := Connect(logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+	migrator, err := NewMigrator(logger, db, cfg)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return migrator, nil
+}
+
+// migrateOnStart applies migrations using a separate connection pool, which is closed afterwards.
+func migrateOnStart(logger *zap.SugaredLogger, cfg config.DB) error {
+	migrator, err := ConnectMigrator(logger, cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = migrator.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.MigrateLockTimeout)
+	defer cancel()
+	if err := migrator.Up(ctx); err != nil {
+		return err
+	}
+
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	logger.With("version", status.Version, "dirty", status.Dirty).Info("Migrations applied")
+	return nil
+}
+
+// checkSchema refuses to work with the schema that is dirty or behind the migrations.
+// A newer schema is allowed, so the previous version keeps working during a rolling update.
+func (r *Repo) checkSchema(ctx context.Context, cfg config.DB) error {
+	latest, err := LatestMigrationVersion(cfg)
+	if err != nil {
+		return err
+	}
+	version, dirty, err := r.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case dirty:
+		return fmt.Errorf("%w at version %d, fix the failed migration and run \"wallets migrate force VERSION\"",
+			ErrSchemaDirty, version)
+	case version < latest:
+		return fmt.Errorf("%w: version %d, latest %d, run \"wallets migrate up\" or enable MIGRATE_ON_START",
+			ErrSchemaBehind, version, latest)
+	case version > latest:
+		r.log.With("version", version, "latest", latest).Warn("Database schema is newer than the migrations")
+	}
+	return nil
+}
+
+// openMigrations opens the source of migrations from config.
+func openMigrations(cfg config.DB) (source.Driver, error) {
+	if cfg.MigrationsSource == config.MigrationsSourceEmbed {
+		src, err := httpfs.New(http.FS(migrations.FS), ".")
+		if err != nil {
+			return nil, fmt.Errorf("open embedded migrations: %w", err)
+		}
+		return src, nil
+	}
+
+	src, err := (&file.File{}).Open("file://" + cfg.MigrationsPath)
+	if err != nil {
+		return nil, fmt.Errorf("open migrations: %w", err)
+	}
+	return src, nil
+}
+
+// migrateLogger writes the progress of migrations to the application log.
+type migrateLogger struct {
+	log *zap.SugaredLogger
+}
+
+func (l migrateLogger) Printf(format string, v ...interface{}) {
+	l.log.Info(strings.TrimSuffix(fmt.Sprintf(format, v...), "\n"))
+}
+
+func (l migrateLogger) Verbose() bool {
+	return true
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+	"github.com/ezhdanovskiy/wallets/internal/ratelimit"
+)
+
+// rateLimitSweepPeriod is the period of removing idle rate limit buckets.
+const rateLimitSweepPeriod = time.Minute
+
+// TakeRateLimitToken takes a token from the bucket stored in the database, so the limit is shared by replicas.
+// The bucket row stays locked until the token is taken, time is taken from the database to not depend
+// on clocks of replicas.
+func (r *Repo) TakeRateLimitToken(ctx context.Context, key string, limit ratelimit.Limit) (bool, time.Duration, error) {
+	r.sweepRateLimitBuckets(ctx)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	const lockQuery = `
+INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+VALUES ($1, $2, now())
+ON CONFLICT (key) DO UPDATE SET key = EXCLUDED.key
+RETURNING tokens, updated_at, now() AS now
+`
+	var row struct {
+		Tokens    float64   `db:"tokens"`
+		UpdatedAt time.Time `db:"updated_at"`
+		Now       time.Time `db:"now"`
+	}
+	err = get(ctx, tx, &row, lockQuery, key, float64(limit.Burst))
+	if err != nil {
+		return false, 0, fmt.Errorf("lock bucket: %w", err)
+	}
+
+	bucket := ratelimit.Bucket{Tokens: row.Tokens, UpdatedAt: row.UpdatedAt}
+	allowed, retryAfter := bucket.Take(row.Now, limit)
+
+	_, err = exec(ctx, tx, `UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3 WHERE key = $1`,
+		key, bucket.Tokens, bucket.UpdatedAt)
+	if err != nil {
+		return false, 0, fmt.Errorf("update bucket: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, 0, fmt.Errorf("commit tx: %w", err)
+	}
+	return allowed, retryAfter, nil
+}
+
+// sweepRateLimitBuckets removes idle buckets, it runs at most once per rateLimitSweepPeriod in the process.
+func (r *Repo) sweepRateLimitBuckets(ctx context.Context) {
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&r.rateLimitSweptAt)
+	if now-last < int64(rateLimitSweepPeriod) || !atomic.CompareAndSwapInt64(&r.rateLimitSweptAt, last, now) {
+		return
+	}
+
+	_, err := exec(ctx, r.db, `DELETE FROM rate_limit_buckets WHERE updated_at < now() - $1 * interval '1 second'`,
+		ratelimit.IdleTimeout.Seconds())
+	if err != nil {
+		logging.FromContext(ctx, r.log).With("error", err).Warn("Failed to remove idle rate limit buckets")
+	}
+}
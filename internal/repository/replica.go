@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ezhdanovskiy/wallets/internal/consistency"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+)
+
+// replicaRetryInterval is the time reads go to the primary after a replica query failed.
+const replicaRetryInterval = 10 * time.Second
+
+// replica is an optional read-only database for queries that tolerate replication lag.
+type replica struct {
+	db       *sqlx.DB
+	failedAt int64 // unix nanoseconds, accessed atomically
+}
+
+// healthy reports whether the replica didn't fail during the last replicaRetryInterval.
+func (r *replica) healthy() bool {
+	return time.Now().UnixNano()-atomic.LoadInt64(&r.failedAt) > int64(replicaRetryInterval)
+}
+
+func (r *replica) fail() {
+	atomic.StoreInt64(&r.failedAt, time.Now().UnixNano())
+}
+
+// read runs the read-only query f with the replica unless ctx requires strong consistency or the replica is unhealthy.
+// If the replica fails, f is run again with the primary.
+func (r *Repo) read(ctx context.Context, f func(db *sqlx.DB) error) error {
+	if r.replica != nil && !consistency.IsStrong(ctx) && r.replica.healthy() {
+		err := f(r.replica.db)
+		if err == nil || errors.Is(err, sql.ErrNoRows) || ctx.Err() != nil {
+			return err
+		}
+
+		r.replica.fail()
+		replicaFallbacksTotal.Inc()
+		logging.FromContext(ctx, r.log).With("error", err).Warn("Replica query failed, reading from primary")
+	}
+	return f(r.db)
+}
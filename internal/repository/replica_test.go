@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/ezhdanovskiy/wallets/internal/consistency"
+)
+
+// newReplicaTestRepo creates the repository with a replica, pools aren't connected until they are queried.
+func newReplicaTestRepo(t *testing.T) *Repo {
+	open := func() *sqlx.DB {
+		db, err := sqlx.Open("postgres", "host=127.0.0.1 port=1 connect_timeout=1 sslmode=disable")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		return db
+	}
+	return &Repo{log: zap.NewNop().Sugar(), db: open(), replica: &replica{db: open()}}
+}
+
+// readFrom runs read and returns the pools f was called with.
+func readFrom(ctx context.Context, r *Repo, errs ...error) ([]*sqlx.DB, error) {
+	var used []*sqlx.DB
+	err := r.read(ctx, func(db *sqlx.DB) error {
+		used = append(used, db)
+		if len(errs) == 0 {
+			return nil
+		}
+		err := errs[0]
+		errs = errs[1:]
+		return err
+	})
+	return used, err
+}
+
+func TestRepo_read(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("replica", func(t *testing.T) {
+		r := newReplicaTestRepo(t)
+		used, err := readFrom(ctx, r)
+		require.NoError(t, err)
+		assert.Equal(t, []*sqlx.DB{r.replica.db}, used)
+	})
+
+	t.Run("no replica", func(t *testing.T) {
+		r := newReplicaTestRepo(t)
+		r.replica = nil
+		used, err := readFrom(ctx, r)
+		require.NoError(t, err)
+		assert.Equal(t, []*sqlx.DB{r.db}, used)
+	})
+
+	t.Run("strong consistency", func(t *testing.T) {
+		r := newReplicaTestRepo(t)
+		used, err := readFrom(consistency.Strong(ctx), r)
+		require.NoError(t, err)
+		assert.Equal(t, []*sqlx.DB{r.db}, used)
+	})
+
+	t.Run("no rows isn't a failure", func(t *testing.T) {
+		r := newReplicaTestRepo(t)
+		used, err := readFrom(ctx, r, sql.ErrNoRows)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Equal(t, []*sqlx.DB{r.replica.db}, used)
+		assert.True(t, r.replica.healthy())
+	})
+
+	t.Run("canceled context isn't a failure", func(t *testing.T) {
+		r := newReplicaTestRepo(t)
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		used, err := readFrom(ctx, r, context.Canceled)
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, []*sqlx.DB{r.replica.db}, used)
+		assert.True(t, r.replica.healthy())
+	})
+
+	t.Run("fallback to primary", func(t *testing.T) {
+		r := newReplicaTestRepo(t)
+		used, err := readFrom(ctx, r, errors.New("replica is down"))
+		require.NoError(t, err)
+		assert.Equal(t, []*sqlx.DB{r.replica.db, r.db}, used)
+		assert.False(t, r.replica.healthy())
+
+		// The primary error is returned as is.
+		_, err = readFrom(ctx, r, errors.New("primary is down"))
+		assert.EqualError(t, err, "primary is down")
+	})
+
+	t.Run("unhealthy replica is skipped during the retry interval", func(t *testing.T) {
+		r := newReplicaTestRepo(t)
+		r.replica.fail()
+		used, err := readFrom(ctx, r)
+		require.NoError(t, err)
+		assert.Equal(t, []*sqlx.DB{r.db}, used)
+
+		r.replica.failedAt = time.Now().Add(-replicaRetryInterval - time.Second).UnixNano()
+		used, err = readFrom(ctx, r)
+		require.NoError(t, err)
+		assert.Equal(t, []*sqlx.DB{r.replica.db}, used)
+	})
+
+	t.Run("unreachable replica", func(t *testing.T) {
+		r := newReplicaTestRepo(t)
+		var primaryQueried bool
+		err := r.read(ctx, func(db *sqlx.DB) error {
+			if db == r.db {
+				primaryQueried = true
+				return nil
+			}
+			var one int
+			return db.GetContext(ctx, &one, `SELECT 1`)
+		})
+		require.NoError(t, err)
+		assert.True(t, primaryQueried)
+		assert.False(t, r.replica.healthy())
+	})
+}
@@ -4,65 +4,132 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"strings"
+	"time"
 
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 
 	"github.com/ezhdanovskiy/wallets/internal/config"
 	"github.com/ezhdanovskiy/wallets/internal/consts"
 	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/ledger"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+	"github.com/ezhdanovskiy/wallets/internal/repository/sqltrace"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
+	"github.com/ezhdanovskiy/wallets/internal/tracing"
 )
 
 // Repo performs database operations.
 type Repo struct {
 	log *zap.SugaredLogger
 	db  *sqlx.DB
+	// replica serves read-only queries, it is nil when no replica is configured.
+	replica *replica
+
+	rateLimitSweptAt int64 // unix nanoseconds, accessed atomically
 }
 
-// NewRepo creates instance of repository using config and applies migrations.
+// Delays between attempts of the first connection to the database.
+const (
+	connectRetryMinDelay = 250 * time.Millisecond
+	connectRetryMaxDelay = 5 * time.Second
+)
+
+// NewRepo creates instance of repository using config, applies migrations if MigrateOnStart is set
+// and checks that the schema is up to date.
 func NewRepo(logger *zap.SugaredLogger, cfg config.DB) (*Repo, error) {
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName)
+	if cfg.MigrateOnStart {
+		if err := migrateOnStart(logger, cfg); err != nil {
+			return nil, err
+		}
+	}
 
-	db, err := sqlx.Connect("postgres", dsn)
+	db, err := Connect(logger, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("connect database: %w", err)
+		return nil, err
 	}
 
-	err = MigrateUp(logger, db, "file://"+cfg.MigrationsPath)
+	repo, err := NewRepoWithDB(logger, db)
 	if err != nil {
+		_ = db.Close()
 		return nil, err
 	}
 
-	return NewRepoWithDB(logger, db)
+	if err := repo.checkSchema(context.Background(), cfg); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	if cfg.ReplicaURL != "" {
+		// The replica isn't connected eagerly, reads fall back to the primary while it is unavailable.
+		replicaDB, err := sqlx.Open("postgres", cfg.ReplicaURL)
+		if err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("open replica: %w", err)
+		}
+		configurePool(replicaDB, cfg)
+		repo.replica = &replica{db: replicaDB}
+	}
+
+	return repo, nil
 }
 
-// MigrateUp applies migrations to DB.
-func MigrateUp(logger *zap.SugaredLogger, db *sqlx.DB, path string) error {
-	driver, err := postgres.WithInstance(db.DB, &postgres.Config{})
-	m, err := migrate.NewWithDatabaseInstance(path, "postgres", driver)
+// Connect connects to the primary database and configures the connection pool.
+func Connect(logger *zap.SugaredLogger, cfg config.DB) (*sqlx.DB, error) {
+	db, err := connect(logger, cfg.DSN(), cfg.ConnectRetryTimeout)
 	if err != nil {
-		return fmt.Errorf("migrate NewWithDatabaseInstance: %w", err)
+		return nil, fmt.Errorf("connect database: %w", err)
 	}
 
-	err = m.Up()
-	if err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("migrate Up: %w", err)
-	}
+	configurePool(db, cfg)
+	return db, nil
+}
+
+func configurePool(db *sqlx.DB, cfg config.DB) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+}
+
+// connect connects to the database, retrying with exponential backoff until retryTimeout elapses,
+// so the application can start together with the database, e.g. in docker-compose.
+func connect(logger *zap.SugaredLogger, dsn string, retryTimeout time.Duration) (*sqlx.DB, error) {
+	deadline := time.Now().Add(retryTimeout)
+	delay := connectRetryMinDelay
+	for attempt := 1; ; attempt++ {
+		db, err := sqlx.Connect("postgres", dsn)
+		if err == nil {
+			return db, nil
+		}
+		if !isConnectRetryable(err) || time.Now().Add(delay).After(deadline) {
+			return nil, err
+		}
 
-	version, dirty, err := m.Version()
-	if err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("migrate version: %w", err)
+		logger.With("error", err, "attempt", attempt, "retry_in", delay).Warn("Failed to connect database, retrying")
+		time.Sleep(delay)
+		if delay *= 2; delay > connectRetryMaxDelay {
+			delay = connectRetryMaxDelay
+		}
 	}
-	logger.With("version", version, "dirty", dirty).Info("Migrations applied")
+}
 
-	return nil
+// isConnectRetryable reports whether the connection may succeed later: network errors and
+// the database that is starting up are retried, authentication or configuration errors are not.
+func isConnectRetryable(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "57P03" // cannot_connect_now
+	}
+	// Not net.Error: *url.Error of a malformed DSN implements it too.
+	var opErr *net.OpError
+	return errors.As(err, &opErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
 }
 
 // NewRepoWithDB creates instance of repository using existing DB.
@@ -73,17 +140,36 @@ func NewRepoWithDB(logger *zap.SugaredLogger, db *sqlx.DB) (*Repo, error) {
 	}, nil
 }
 
+// Close closes the connection pools, it waits for the started queries to finish.
+func (r *Repo) Close() error {
+	if r.replica != nil {
+		if err := r.replica.db.Close(); err != nil {
+			return fmt.Errorf("close replica: %w", err)
+		}
+	}
+	return r.db.Close()
+}
+
 // CreateWallet creates new wallet with unique name,
 // or do nothing if wallet already exists.
-func (r *Repo) CreateWallet(walletName string) error {
-	r.log.With("wallet", walletName).Debug("CreateWallet")
+func (r *Repo) CreateWallet(ctx context.Context, walletName, owner string) error {
+	return r.createWallet(ctx, r.db, walletName, owner)
+}
+
+// CreateWalletTx is CreateWallet using transaction.
+func (r *Repo) CreateWalletTx(ctx context.Context, tx storage.Tx, walletName, owner string) error {
+	return r.createWallet(ctx, sqlTx(tx), walletName, owner)
+}
+
+func (r *Repo) createWallet(ctx context.Context, db sqlx.ExecerContext, walletName, owner string) error {
+	logging.FromContext(ctx, r.log).With("wallet", walletName, "owner", owner).Debug("CreateWallet")
 	const query = `
-INSERT INTO wallets (name) 
-VALUES ($1) 
+INSERT INTO wallets (name, owner) 
+VALUES ($1, $2) 
 ON CONFLICT DO NOTHING
 `
 
-	_, err := r.db.Exec(query, walletName)
+	_, err := exec(ctx, db, query, walletName, owner)
 	if err != nil {
 		return fmt.Errorf("insert wallets: %w", err)
 	}
@@ -91,9 +177,9 @@ ON CONFLICT DO NOTHING
 	return nil
 }
 
-// GetWallet selects wallet by name.
-func (r *Repo) GetWallet(walletName string) (*dto.Wallet, error) {
-	r.log.With("wallet", walletName).Debug("GetWallet")
+// GetWallet selects wallet by name, it reads from the replica unless ctx requires strong consistency.
+func (r *Repo) GetWallet(ctx context.Context, walletName string) (*dto.Wallet, error) {
+	logging.FromContext(ctx, r.log).With("wallet", walletName).Debug("GetWallet")
 	const query = `
 SELECT * 
 FROM wallets 
@@ -101,7 +187,9 @@ WHERE name = $1
 `
 
 	var dbWallet Wallet
-	err := r.db.Get(&dbWallet, query, walletName)
+	err := r.read(ctx, func(db *sqlx.DB) error {
+		return get(ctx, db, &dbWallet, query, walletName)
+	})
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -111,54 +199,142 @@ WHERE name = $1
 
 	return &dto.Wallet{
 		Name:    dbWallet.Name,
+		Owner:   dbWallet.Owner,
 		Balance: dbWallet.Balance,
 	}, nil
 }
 
+// ListWallets selects wallets ordered by name, optionally of the owner.
+// They are read from the replica unless ctx requires strong consistency.
+func (r *Repo) ListWallets(ctx context.Context, filter dto.WalletsFilter) ([]dto.Wallet, error) {
+	logging.FromContext(ctx, r.log).With("owner", filter.Owner).Debug("ListWallets")
+	const query = `
+SELECT * 
+FROM wallets 
+WHERE $1 = '' OR owner = $1
+ORDER BY name
+LIMIT $2 OFFSET $3
+`
+
+	var dbWallets []Wallet
+	err := r.read(ctx, func(db *sqlx.DB) error {
+		dbWallets = nil
+		return selectx(ctx, db, &dbWallets, query, filter.Owner, filter.Limit, filter.Offset)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("select: %w", err)
+	}
+
+	wallets := make([]dto.Wallet, len(dbWallets))
+	for i, w := range dbWallets {
+		wallets[i] = dto.Wallet{
+			Name:    w.Name,
+			Owner:   w.Owner,
+			Balance: w.Balance,
+		}
+	}
+	return wallets, nil
+}
+
 // IncreaseWalletBalance runs two operations in transaction:
 // 	- increases wallet balance;
 // 	- add new operation with type deposit.
-func (r *Repo) IncreaseWalletBalance(walletName string, amount uint64) error {
-	r.log.With("wallet_name", walletName, "amount", amount).Debug("IncreaseWalletBalance")
+func (r *Repo) IncreaseWalletBalance(ctx context.Context, walletName string, amount uint64) error {
+	logging.FromContext(ctx, r.log).With("wallet_name", walletName, "amount", amount).Debug("IncreaseWalletBalance")
 
-	return r.RunWithTransaction(func(tx *sqlx.Tx) error {
-		err := r.increaseWalletBalanceTx(tx, walletName, amount)
-		if err != nil {
+	return r.RunWithTransaction(ctx, func(ctx context.Context, tx storage.Tx) error {
+		return r.IncreaseWalletBalanceTx(ctx, tx, walletName, amount)
+	})
+}
+
+// IncreaseWalletBalanceTx is IncreaseWalletBalance using transaction.
+func (r *Repo) IncreaseWalletBalanceTx(ctx context.Context, tx storage.Tx, walletName string, amount uint64) error {
+	err := r.increaseWalletBalanceTx(ctx, sqlTx(tx), walletName, amount)
+	if err != nil {
+		return err
+	}
+
+	return r.insertOperation(ctx, sqlTx(tx), walletName, consts.OperationTypeDeposit, amount, consts.SystemWalletName)
+}
+
+const (
+	// txMaxAttempts limits the number of attempts to run a transaction failed with a serialization failure or a deadlock.
+	txMaxAttempts = 3
+	// txRetryBackoff is the pause before the second attempt, it grows with every next one.
+	txRetryBackoff = 10 * time.Millisecond
+)
+
+// RunWithTransaction runs the given function inside a transaction, ctx passed to f carries the transaction span.
+// The transaction is restarted if it conflicts with a concurrent one, so f must not have side effects outside tx.
+func (r *Repo) RunWithTransaction(ctx context.Context, f func(ctx context.Context, tx storage.Tx) error) error {
+	logging.FromContext(ctx, r.log).Debug("RunWithTransaction")
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = r.runTransaction(ctx, f)
+		if err == nil || !isRetryable(err) || attempt == txMaxAttempts {
 			return err
 		}
+		transactionRetriesTotal.Inc()
+		logging.FromContext(ctx, r.log).With("attempt", attempt, "error", err).Debug("Retry transaction")
 
-		return r.insertOperation(tx, walletName, consts.OperationTypeDeposit, amount, consts.SystemWalletName)
-	})
+		select {
+		case <-time.After(time.Duration(attempt) * txRetryBackoff):
+		case <-ctx.Done():
+			return err
+		}
+	}
 }
 
-// RunWithTransaction runs the given function inside a transaction.
-func (r *Repo) RunWithTransaction(f func(tx *sqlx.Tx) error) error {
-	r.log.Debug("RunWithTransaction")
+func (r *Repo) runTransaction(ctx context.Context, f func(ctx context.Context, tx storage.Tx) error) (err error) {
+	ctx, span := tracing.Start(ctx, "transaction")
+	defer func() { tracing.End(span, err) }()
 
-	tx, err := r.db.BeginTxx(context.Background(), &sql.TxOptions{Isolation: sql.LevelSerializable})
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
 	if err != nil {
 		return fmt.Errorf("begin tx: %w", err)
 	}
 
-	fErr := f(tx)
+	fErr := f(ctx, tx)
 	if fErr != nil {
+		transactionsTotal.WithLabelValues(txResultRollback).Inc()
 		if err = tx.Rollback(); err != nil {
-			r.log.Errorf("rollback: %s", err)
+			logging.FromContext(ctx, r.log).Errorf("rollback: %s", err)
 		}
 		return fErr
 	}
 
-	if err = tx.Commit(); err != nil {
+	_, commitSpan := tracing.Start(ctx, "COMMIT")
+	err = tx.Commit()
+	tracing.End(commitSpan, err)
+	if err != nil {
+		transactionsTotal.WithLabelValues(txResultRollback).Inc()
 		return fmt.Errorf("commit tx: %w", err)
 	}
+	transactionsTotal.WithLabelValues(txResultCommit).Inc()
 
 	return nil
 }
 
+// sqlTx returns the Postgres transaction behind tx, tx must be started by RunWithTransaction of Repo.
+func sqlTx(tx storage.Tx) *sqlx.Tx {
+	return tx.(*sqlx.Tx)
+}
+
+// isRetryable reports whether the transaction failed because of a conflict with a concurrent transaction,
+// either in f or on commit.
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "40001" || pqErr.Code == "40P01" // serialization_failure, deadlock_detected
+}
+
 // GetWalletsForUpdateTx selects wallets and obtains a lock for them at the database level using transaction.
 // It will wait if some of the required wallets already locked in another goroutine.
-func (r *Repo) GetWalletsForUpdateTx(tx *sqlx.Tx, walletNames []string) ([]dto.Wallet, error) {
-	r.log.With("wallets", walletNames).Debug("GetWalletsForUpdateTx")
+func (r *Repo) GetWalletsForUpdateTx(ctx context.Context, tx storage.Tx, walletNames []string) ([]dto.Wallet, error) {
+	logging.FromContext(ctx, r.log).With("wallets", walletNames).Debug("GetWalletsForUpdateTx")
 
 	const querySrc = `
 SELECT * 
@@ -173,7 +349,9 @@ FOR UPDATE
 	}
 
 	dbWallets := make([]Wallet, 0)
-	err = tx.Select(&dbWallets, r.db.Rebind(query), args...)
+	start := time.Now()
+	err = selectx(ctx, sqlTx(tx), &dbWallets, r.db.Rebind(query), args...)
+	lockWaitSeconds.Observe(time.Since(start).Seconds())
 	if err != nil {
 		return nil, fmt.Errorf("select for update: %w", err)
 	}
@@ -181,6 +359,7 @@ FOR UPDATE
 	wallets := make([]dto.Wallet, len(dbWallets))
 	for i := range dbWallets {
 		wallets[i].Name = dbWallets[i].Name
+		wallets[i].Owner = dbWallets[i].Owner
 		wallets[i].Balance = dbWallets[i].Balance
 	}
 
@@ -192,25 +371,26 @@ FOR UPDATE
 // 	- add new operation with type withdrawal for wallet_from;
 // 	- increases balance of wallet_to;
 // 	- add new operation with type deposit for wallet_to.
-func (r *Repo) TransferTx(tx *sqlx.Tx, walletFrom, walletTo string, amount uint64) error {
-	r.log.With("wallet_from", walletFrom, "wallet_to", walletTo, "amount", amount).Debug("TransferTx")
+func (r *Repo) TransferTx(ctx context.Context, stx storage.Tx, walletFrom, walletTo string, amount uint64) error {
+	logging.FromContext(ctx, r.log).With("wallet_from", walletFrom, "wallet_to", walletTo, "amount", amount).Debug("TransferTx")
+	tx := sqlTx(stx)
 
-	err := r.decreaseWalletBalanceTx(tx, walletFrom, amount)
+	err := r.decreaseWalletBalanceTx(ctx, tx, walletFrom, amount)
 	if err != nil {
 		return fmt.Errorf("decrease wallet balance: %w", err)
 	}
 
-	err = r.insertOperation(tx, walletFrom, consts.OperationTypeWithdrawal, amount, walletTo)
+	err = r.insertOperation(ctx, tx, walletFrom, consts.OperationTypeWithdrawal, amount, walletTo)
 	if err != nil {
 		return fmt.Errorf("insert operation: %w", err)
 	}
 
-	err = r.increaseWalletBalanceTx(tx, walletTo, amount)
+	err = r.increaseWalletBalanceTx(ctx, tx, walletTo, amount)
 	if err != nil {
 		return fmt.Errorf("increase wallet balance: %w", err)
 	}
 
-	err = r.insertOperation(tx, walletTo, consts.OperationTypeDeposit, amount, walletFrom)
+	err = r.insertOperation(ctx, tx, walletTo, consts.OperationTypeDeposit, amount, walletFrom)
 	if err != nil {
 		return fmt.Errorf("insert operation: %w", err)
 	}
@@ -218,15 +398,15 @@ func (r *Repo) TransferTx(tx *sqlx.Tx, walletFrom, walletTo string, amount uint6
 	return nil
 }
 
-func (r *Repo) decreaseWalletBalanceTx(tx *sqlx.Tx, walletName string, amount uint64) error {
-	r.log.With("wallet", walletName, "amount", amount).Debug("decreaseWalletBalanceTx")
+func (r *Repo) decreaseWalletBalanceTx(ctx context.Context, tx *sqlx.Tx, walletName string, amount uint64) error {
+	logging.FromContext(ctx, r.log).With("wallet", walletName, "amount", amount).Debug("decreaseWalletBalanceTx")
 	const query = `
 UPDATE wallets
 SET balance = balance - $2, updated_at = now()
 WHERE name = $1 AND balance >= $2
 `
 
-	res, err := tx.Exec(query, walletName, amount)
+	res, err := exec(ctx, tx, query, walletName, amount)
 	if err != nil {
 		return fmt.Errorf("update wallets: %w", err)
 	}
@@ -243,15 +423,15 @@ WHERE name = $1 AND balance >= $2
 	return nil
 }
 
-func (r *Repo) increaseWalletBalanceTx(tx *sqlx.Tx, walletName string, amount uint64) error {
-	r.log.With("wallet", walletName, "amount", amount).Debug("increaseWalletBalanceTx")
+func (r *Repo) increaseWalletBalanceTx(ctx context.Context, tx *sqlx.Tx, walletName string, amount uint64) error {
+	logging.FromContext(ctx, r.log).With("wallet", walletName, "amount", amount).Debug("increaseWalletBalanceTx")
 	const query = `
 UPDATE wallets
 SET balance = balance + $2, updated_at = now()
 WHERE name = $1
 `
 
-	_, err := tx.Exec(query, walletName, amount)
+	_, err := exec(ctx, tx, query, walletName, amount)
 	if err != nil {
 		return fmt.Errorf("update wallets: %w", err)
 	}
@@ -259,53 +439,178 @@ WHERE name = $1
 	return nil
 }
 
-func (r *Repo) insertOperation(tx *sqlx.Tx, wallet, opType string, amount uint64, otherWallet string) error {
-	r.log.With("wallet", wallet, "type", opType, "amount", amount, "other", otherWallet).Debug("insertOperation")
+// insertOperation appends the operation to the wallet chain.
+// The wallet row is already locked by the balance update, so the last operation of the wallet can't change.
+func (r *Repo) insertOperation(ctx context.Context, tx *sqlx.Tx, wallet, opType string, amount uint64, otherWallet string) error {
+	logging.FromContext(ctx, r.log).With("wallet", wallet, "type", opType, "amount", amount, "other", otherWallet).Debug("insertOperation")
+
+	entry := ledger.Entry{
+		Wallet:      wallet,
+		Seq:         1,
+		Type:        opType,
+		Amount:      amount,
+		OtherWallet: otherWallet,
+		CreatedAt:   ledger.Now(),
+	}
+
+	var last Operation
+	err := get(ctx, tx, &last, `SELECT seq, hash FROM operations WHERE wallet = $1 ORDER BY seq DESC LIMIT 1`, wallet)
+	switch {
+	case err == nil:
+		entry.Seq = last.Seq + 1
+		entry.PrevHash = last.Hash
+	case err != sql.ErrNoRows:
+		return fmt.Errorf("select last operation: %w", err)
+	}
+	entry.Hash = ledger.Hash(entry)
 
 	const query = `
-INSERT INTO operations (wallet, type, amount, other_wallet)
-VALUES ($1, $2, $3, $4)
+INSERT INTO operations (wallet, type, amount, other_wallet, created_at, seq, prev_hash, hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 `
 
-	_, err := tx.Exec(query, wallet, opType, amount, otherWallet)
+	_, err = exec(ctx, tx, query, entry.Wallet, entry.Type, entry.Amount, entry.OtherWallet, entry.CreatedAt,
+		entry.Seq, entry.PrevHash, entry.Hash)
 	if err != nil {
-		return fmt.Errorf("update wallets: %w", err)
+		return fmt.Errorf("insert operation: %w", err)
 	}
 
 	return nil
 }
 
 // GetOperations selects operations for specified wallet using filter.
-// Operations ordered by time.
-func (r *Repo) GetOperations(filter dto.OperationsFilter) ([]dto.Operation, error) {
-	r.log.With("wallet", filter.Wallet).Debug("GetOperations")
+// Operations ordered by (created_at, id) in the order of the filter,
+// they are read from the replica unless ctx requires strong consistency.
+func (r *Repo) GetOperations(ctx context.Context, filter dto.OperationsFilter) ([]dto.Operation, error) {
+	logging.FromContext(ctx, r.log).With("wallet", filter.Wallet).Debug("GetOperations")
+
+	query, args, err := r.operationsQuery(filter)
+	if err != nil {
+		return nil, err
+	}
 
+	logging.FromContext(ctx, r.log).With("query", query, "args", args).Debug("select operations")
+	var dbOperations []Operation
+	err = r.read(ctx, func(db *sqlx.DB) error {
+		dbOperations = make([]Operation, 0)
+		return selectx(ctx, db, &dbOperations, query, args...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("select for update: %w", err)
+	}
+
+	// A page before the cursor is selected in the reverse order starting from the cursor and reversed back.
+	backward := filter.Cursor != nil && filter.Cursor.Backward
+	operations := make([]dto.Operation, len(dbOperations))
+	for i := range dbOperations {
+		j := i
+		if backward {
+			j = len(dbOperations) - 1 - i
+		}
+		operations[j] = convertOperation(dbOperations[i])
+	}
+
+	return operations, nil
+}
+
+// ScanOperations calls f for every operation of the wallet selected by filter, it stops on the first error.
+// Rows are read with a cursor, so all operations aren't loaded in memory, a backward cursor scans them in reverse.
+// Operations are read from the replica unless ctx requires strong consistency.
+func (r *Repo) ScanOperations(ctx context.Context, filter dto.OperationsFilter, f func(dto.Operation) error) (err error) {
+	logging.FromContext(ctx, r.log).With("wallet", filter.Wallet).Debug("ScanOperations")
+
+	query, args, err := r.operationsQuery(filter)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := sqltrace.Postgres.StartSpan(ctx, query)
+	defer func() { sqltrace.EndSpan(span, err) }()
+
+	// Only starting the query falls back to the primary, f must not see the same operations twice.
+	var rows *sqlx.Rows
+	err = r.read(ctx, func(db *sqlx.DB) error {
+		rows, err = db.QueryxContext(ctx, query, args...)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("select operations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var op Operation
+		if err := rows.StructScan(&op); err != nil {
+			return fmt.Errorf("scan operation: %w", err)
+		}
+		if err := f(convertOperation(op)); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate operations: %w", err)
+	}
+	return nil
+}
+
+// GetOperationsTotals returns the number and the sum of the operations of the wallet selected by filter by type,
+// the cursor, the order and the pagination of the filter are ignored.
+// Operations are read from the replica unless ctx requires strong consistency.
+func (r *Repo) GetOperationsTotals(ctx context.Context, filter dto.OperationsFilter) (*dto.OperationsTotals, error) {
+	logging.FromContext(ctx, r.log).With("wallet", filter.Wallet).Debug("GetOperationsTotals")
+
+	whereParts, namedArgs := operationsConditions(filter)
+	query, args, err := sqlx.Named(`
+SELECT type, COUNT(*) AS count, COALESCE(SUM(amount), 0)::bigint AS amount
+FROM operations
+WHERE `+strings.Join(whereParts, " AND ")+`
+GROUP BY type
+`, namedArgs)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx named: %w", err)
+	}
+	query = r.db.Rebind(query)
+
+	var rows []operationsTotal
+	err = r.read(ctx, func(db *sqlx.DB) error {
+		rows = make([]operationsTotal, 0)
+		return selectx(ctx, db, &rows, query, args...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("select operations totals: %w", err)
+	}
+
+	return convertOperationsTotals(rows), nil
+}
+
+// operationsQuery builds the query selecting operations by filter.
+func (r *Repo) operationsQuery(filter dto.OperationsFilter) (string, []interface{}, error) {
 	queryTempl := `
 SELECT * 
 FROM operations 
 WHERE %s
-ORDER BY created_at
+ORDER BY %s
 `
 
-	namedArgs := make(map[string]interface{}) // Prepare named parameters.
-	var whereParts []string                   // Generate where clause.
+	whereParts, namedArgs := operationsConditions(filter)
 
-	whereParts = append(whereParts, "wallet = :wallet")
-	namedArgs["wallet"] = filter.Wallet
-
-	if len(filter.Type) != 0 {
-		whereParts = append(whereParts, "type = :type")
-		namedArgs["type"] = filter.Type
-	}
-
-	if filter.StartDate > 0 {
-		whereParts = append(whereParts, "EXTRACT(EPOCH FROM created_at) >= :start_date")
-		namedArgs["start_date"] = filter.StartDate
+	// A page before the cursor is selected in the reverse order starting from the cursor.
+	backward := filter.Cursor != nil && filter.Cursor.Backward
+	descending := (filter.Order == consts.OperationsOrderDesc) != backward
+	orderBy := "created_at, id"
+	if descending {
+		orderBy = "created_at DESC, id DESC"
 	}
 
-	if filter.EndDate > 0 {
-		whereParts = append(whereParts, "EXTRACT(EPOCH FROM created_at) <= :end_date")
-		namedArgs["end_date"] = filter.EndDate
+	if filter.Cursor != nil {
+		if descending {
+			whereParts = append(whereParts, "(created_at, id) < (:cursor_created_at, :cursor_id)")
+		} else {
+			whereParts = append(whereParts, "(created_at, id) > (:cursor_created_at, :cursor_id)")
+		}
+		namedArgs["cursor_created_at"] = filter.Cursor.CreatedAt
+		namedArgs["cursor_id"] = filter.Cursor.ID
 	}
 
 	if filter.Limit > 0 {
@@ -319,30 +624,93 @@ ORDER BY created_at
 	}
 
 	where := strings.Join(whereParts, " AND ")
-	query := fmt.Sprintf(queryTempl, where)
+	query := fmt.Sprintf(queryTempl, where, orderBy)
 
 	query, args, err := sqlx.Named(query, namedArgs)
 	if err != nil {
-		return nil, fmt.Errorf("sqlx named: %w", err)
+		return "", nil, fmt.Errorf("sqlx named: %w", err)
 	}
 
 	query = r.db.Rebind(query)
+	return query, args, nil
+}
 
-	r.log.With("query", query, "args", args).Debug("select operations")
-	dbOperations := make([]Operation, 0)
-	err = r.db.Select(&dbOperations, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("select for update: %w", err)
+// operationsConditions builds the where clause parts and their named parameters for the filters
+// of the history other than the cursor.
+func operationsConditions(filter dto.OperationsFilter) ([]string, map[string]interface{}) {
+	namedArgs := make(map[string]interface{}) // Prepare named parameters.
+	var whereParts []string                   // Generate where clause.
+
+	whereParts = append(whereParts, "wallet = :wallet")
+	namedArgs["wallet"] = filter.Wallet
+
+	if len(filter.Types) != 0 {
+		typeParams := make([]string, len(filter.Types))
+		for i, t := range filter.Types {
+			typeParams[i] = fmt.Sprintf(":type%d", i)
+			namedArgs[fmt.Sprintf("type%d", i)] = t
+		}
+		whereParts = append(whereParts, "type IN ("+strings.Join(typeParams, ", ")+")")
 	}
 
-	operations := make([]dto.Operation, len(dbOperations))
-	for i := range dbOperations {
-		operations[i].Wallet = dbOperations[i].Wallet
-		operations[i].Type = dbOperations[i].Type
-		operations[i].Amount.SetAmount(dbOperations[i].Amount)
-		operations[i].OtherWallet = dbOperations[i].OtherWallet
-		operations[i].Timestamp = dbOperations[i].CreatedAt
+	if filter.OtherWallet != "" {
+		whereParts = append(whereParts, "other_wallet = :other_wallet")
+		namedArgs["other_wallet"] = filter.OtherWallet
 	}
 
-	return operations, nil
+	if filter.MinAmount > 0 {
+		whereParts = append(whereParts, "amount >= :min_amount")
+		namedArgs["min_amount"] = filter.MinAmount.GetInt()
+	}
+
+	if filter.MaxAmount > 0 {
+		whereParts = append(whereParts, "amount <= :max_amount")
+		namedArgs["max_amount"] = filter.MaxAmount.GetInt()
+	}
+
+	// Plain comparisons of created_at use the index, unlike expressions of the column.
+	if !filter.StartDate.IsZero() {
+		whereParts = append(whereParts, "created_at >= :start_date")
+		namedArgs["start_date"] = filter.StartDate
+	}
+
+	if !filter.EndDate.IsZero() {
+		whereParts = append(whereParts, "created_at <= :end_date")
+		namedArgs["end_date"] = filter.EndDate
+	}
+
+	return whereParts, namedArgs
+}
+
+// operationsTotal is a row of the totals of operations grouped by type.
+type operationsTotal struct {
+	Type   string `db:"type"`
+	Count  int64  `db:"count"`
+	Amount uint64 `db:"amount"`
+}
+
+func convertOperationsTotals(rows []operationsTotal) *dto.OperationsTotals {
+	var totals dto.OperationsTotals
+	for _, row := range rows {
+		total := dto.OperationsTotal{Count: row.Count, Cents: row.Amount}
+		switch row.Type {
+		case consts.OperationTypeDeposit:
+			totals.Deposits = total
+		case consts.OperationTypeWithdrawal:
+			totals.Withdrawals = total
+		}
+	}
+	return &totals
+}
+
+func convertOperation(op Operation) dto.Operation {
+	o := dto.Operation{
+		ID:          op.ID,
+		Wallet:      op.Wallet,
+		Type:        op.Type,
+		OtherWallet: op.OtherWallet,
+		Timestamp:   op.CreatedAt,
+	}
+	o.Amount.SetAmount(op.Amount)
+	return o
 }
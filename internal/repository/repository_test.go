@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestIsConnectRetryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, true},
+		{"host not resolved", &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host"}}, true},
+		{"connection closed", io.EOF, true},
+		{"database starting up", &pq.Error{Code: "57P03"}, true},
+		{"authentication failed", &pq.Error{Code: "28P01"}, false},
+		{"missing certificate", &fs.PathError{Op: "open", Path: "/root.crt", Err: fs.ErrNotExist}, false},
+		{"configuration", errors.New(`pq: unsupported sslmode "bogus"`), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.retryable, isConnectRetryable(tt.err))
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"serialization failure", &pq.Error{Code: "40001"}, true},
+		{"deadlock", &pq.Error{Code: "40P01"}, true},
+		{"serialization failure on commit", fmt.Errorf("commit tx: %w", &pq.Error{Code: "40001"}), true},
+		{"unique violation", &pq.Error{Code: "23505"}, false},
+		{"not a database error", errors.New("not enough money"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.retryable, isRetryable(tt.err))
+		})
+	}
+}
+
+func TestConnect_malformedDSN(t *testing.T) {
+	start := time.Now()
+	_, err := connect(zap.NewNop().Sugar(), "postgres://%zz", time.Minute)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second, "malformed DSN must not be retried")
+}
@@ -0,0 +1,125 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
+)
+
+// InsertAuditRecordTx appends the record to the audit log using transaction,
+// so the record is committed only together with the audited changes.
+func (r *Repo) InsertAuditRecordTx(ctx context.Context, tx storage.Tx, record dto.AuditRecord) error {
+	return r.insertAuditRecord(ctx, sqlTx(tx), record)
+}
+
+// InsertAuditRecord appends the record to the audit log, it is used for failed actions
+// whose transaction has been rolled back.
+func (r *Repo) InsertAuditRecord(ctx context.Context, record dto.AuditRecord) error {
+	return r.insertAuditRecord(ctx, r.db, record)
+}
+
+func (r *Repo) insertAuditRecord(ctx context.Context, db sqlx.ExecerContext, record dto.AuditRecord) error {
+	logging.FromContext(ctx, r.log).With("action", record.Action, "actor", record.Actor, "result", record.Result).Debug("insertAuditRecord")
+	const query = `
+INSERT INTO audit_log (action, actor, request_id, source_ip, endpoint, payload_hash, result, error, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+	_, err := exec(ctx, db, query, record.Action, record.Actor, record.RequestID, record.SourceIP, record.Endpoint,
+		record.PayloadHash, record.Result, record.Error, newTimestamp(time.Now()))
+	if err != nil {
+		return fmt.Errorf("insert audit_log: %w", err)
+	}
+
+	return nil
+}
+
+// GetAuditRecords selects audit records using filter, records ordered by time.
+func (r *Repo) GetAuditRecords(ctx context.Context, filter dto.AuditFilter) ([]dto.AuditRecord, error) {
+	queryTempl := `
+SELECT *
+FROM audit_log
+WHERE %s
+ORDER BY created_at, id
+`
+
+	namedArgs := make(map[string]interface{})
+	whereParts := []string{"TRUE"}
+
+	if filter.Actor != "" {
+		whereParts = append(whereParts, "actor = :actor")
+		namedArgs["actor"] = filter.Actor
+	}
+
+	if filter.Action != "" {
+		whereParts = append(whereParts, "action = :action")
+		namedArgs["action"] = filter.Action
+	}
+
+	if filter.RequestID != "" {
+		whereParts = append(whereParts, "request_id = :request_id")
+		namedArgs["request_id"] = filter.RequestID
+	}
+
+	if filter.StartDate > 0 {
+		whereParts = append(whereParts, "created_at >= :start_date")
+		namedArgs["start_date"] = unixTimestamp(filter.StartDate)
+	}
+
+	if filter.EndDate > 0 {
+		whereParts = append(whereParts, "created_at <= :end_date")
+		namedArgs["end_date"] = unixTimestamp(filter.EndDate)
+	}
+
+	if filter.Limit > 0 {
+		queryTempl += "LIMIT :limit\n"
+		namedArgs["limit"] = filter.Limit
+	}
+
+	if filter.Offset > 0 {
+		if filter.Limit <= 0 {
+			queryTempl += "LIMIT -1\n" // SQLite doesn't allow OFFSET without LIMIT
+		}
+		queryTempl += "OFFSET :offset\n"
+		namedArgs["offset"] = filter.Offset
+	}
+
+	query := fmt.Sprintf(queryTempl, strings.Join(whereParts, " AND "))
+
+	query, args, err := sqlx.Named(query, namedArgs)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx named: %w", err)
+	}
+
+	logging.FromContext(ctx, r.log).With("query", query, "args", args).Debug("select audit records")
+	dbRecords := make([]AuditRecord, 0)
+	err = selectx(ctx, r.db, &dbRecords, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("select: %w", err)
+	}
+
+	records := make([]dto.AuditRecord, len(dbRecords))
+	for i, rec := range dbRecords {
+		records[i] = dto.AuditRecord{
+			ID:          rec.ID,
+			Action:      rec.Action,
+			Actor:       rec.Actor,
+			RequestID:   rec.RequestID,
+			SourceIP:    rec.SourceIP,
+			Endpoint:    rec.Endpoint,
+			PayloadHash: rec.PayloadHash,
+			Result:      rec.Result,
+			Error:       rec.Error,
+			Timestamp:   rec.CreatedAt.Time(),
+		}
+	}
+
+	return records, nil
+}
@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type Wallet struct {
+	Name      string    `db:"name"`
+	Owner     string    `db:"owner"`
+	Balance   uint64    `db:"balance"`
+	CreatedAt timestamp `db:"created_at"`
+	UpdatedAt timestamp `db:"updated_at"`
+}
+
+type Operation struct {
+	ID          int64     `db:"id"`
+	Wallet      string    `db:"wallet"`
+	Type        string    `db:"type"`
+	Amount      uint64    `db:"amount"`
+	OtherWallet string    `db:"other_wallet"`
+	CreatedAt   timestamp `db:"created_at"`
+	Seq         int64     `db:"seq"`
+	PrevHash    string    `db:"prev_hash"`
+	Hash        string    `db:"hash"`
+}
+
+type APIKey struct {
+	ID        int64      `db:"id"`
+	Name      string     `db:"name"`
+	KeyHash   string     `db:"key_hash"`
+	Scopes    stringList `db:"scopes"`
+	Wallets   stringList `db:"wallets"`
+	Owners    stringList `db:"owners"`
+	CreatedAt timestamp  `db:"created_at"`
+	RevokedAt *timestamp `db:"revoked_at"`
+}
+
+type AuditRecord struct {
+	ID          int64     `db:"id"`
+	Action      string    `db:"action"`
+	Actor       string    `db:"actor"`
+	RequestID   string    `db:"request_id"`
+	SourceIP    string    `db:"source_ip"`
+	Endpoint    string    `db:"endpoint"`
+	PayloadHash string    `db:"payload_hash"`
+	Result      string    `db:"result"`
+	Error       string    `db:"error"`
+	CreatedAt   timestamp `db:"created_at"`
+}
+
+type BalanceSnapshot struct {
+	Wallet  string    `db:"wallet"`
+	TakenAt timestamp `db:"taken_at"`
+	Balance uint64    `db:"balance"`
+}
+
+type ImportJob struct {
+	ID          int64      `db:"id"`
+	Kind        string     `db:"kind"`
+	Status      string     `db:"status"`
+	FileHash    string     `db:"file_hash"`
+	ChunkSize   int        `db:"chunk_size"`
+	TotalRows   int        `db:"total_rows"`
+	AppliedRows int        `db:"applied_rows"`
+	SkippedRows int        `db:"skipped_rows"`
+	FailedRows  int        `db:"failed_rows"`
+	CreatedBy   string     `db:"created_by"`
+	RequestID   string     `db:"request_id"`
+	CreatedAt   timestamp  `db:"created_at"`
+	UpdatedAt   timestamp  `db:"updated_at"`
+	FinishedAt  *timestamp `db:"finished_at"`
+}
+
+type ImportRow struct {
+	JobID    int64  `db:"job_id"`
+	Line     int    `db:"line"`
+	Key      string `db:"key"`
+	Wallet   string `db:"wallet"`
+	WalletTo string `db:"wallet_to"`
+	Amount   uint64 `db:"amount"`
+	Status   string `db:"status"`
+	Error    string `db:"error"`
+}
+
+// timestamp is time stored as unix microseconds, the precision of timestamptz in Postgres,
+// so the hashes of operations are the same in both databases.
+type timestamp int64
+
+func newTimestamp(t time.Time) timestamp {
+	return timestamp(t.UnixMicro())
+}
+
+// unixTimestamp converts unix seconds of filters.
+func unixTimestamp(seconds int64) timestamp {
+	return timestamp(seconds * int64(time.Second/time.Microsecond))
+}
+
+func (ts timestamp) Time() time.Time {
+	return time.UnixMicro(int64(ts)).UTC()
+}
+
+// stringList is stored as JSON array, SQLite has no arrays.
+type stringList []string
+
+func (l stringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]string(l))
+	return string(data), err
+}
+
+func (l *stringList) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("scan %T into string list", src)
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
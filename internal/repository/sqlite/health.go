@@ -0,0 +1,23 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Ping checks that the database is reachable.
+func (r *Repo) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Stats returns statistics of the connection pool.
+func (r *Repo) Stats() sql.DBStats {
+	return r.db.Stats()
+}
+
+// MigrationVersion returns the version of the applied migrations, it is 0 if migrations were never applied.
+// The schema is never dirty, migrations are transactional in SQLite.
+func (r *Repo) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	version, err := migrationVersion(ctx, r.db)
+	return version, false, err
+}
@@ -0,0 +1,237 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
+)
+
+// importRowsBatch is the number of rows inserted by a statement, the parameters of a batch stay below
+// the limit of SQLite.
+const importRowsBatch = 1000
+
+// CreateImportJobTx stores the job with its rows using transaction and returns the job with assigned id.
+func (r *Repo) CreateImportJobTx(ctx context.Context, tx storage.Tx, job dto.ImportJob, rows []dto.ImportRow) (*dto.ImportJob, error) {
+	logging.FromContext(ctx, r.log).With("kind", job.Kind, "rows", len(rows)).Debug("CreateImportJob")
+	const query = `
+INSERT INTO import_jobs (kind, status, file_hash, chunk_size, total_rows, created_by, request_id, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *
+`
+
+	now := newTimestamp(time.Now())
+	var dbJob ImportJob
+	err := get(ctx, sqlTx(tx), &dbJob, query, job.Kind, job.Status, job.FileHash, job.ChunkSize, job.Rows,
+		job.CreatedBy, job.RequestID, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("insert import_jobs: %w", err)
+	}
+
+	for start := 0; start < len(rows); start += importRowsBatch {
+		batch := rows[start:min(start+importRowsBatch, len(rows))]
+		values := make([]string, len(batch))
+		args := make([]interface{}, 0, len(batch)*7)
+		for i, row := range batch {
+			values[i] = "(?, ?, ?, ?, ?, ?, ?)"
+			args = append(args, dbJob.ID, row.Line, row.Key, row.Wallet, row.WalletTo, row.Amount, row.Status)
+		}
+
+		query := "INSERT INTO import_rows (job_id, line, key, wallet, wallet_to, amount, status) VALUES " +
+			strings.Join(values, ", ")
+		if _, err := exec(ctx, sqlTx(tx), query, args...); err != nil {
+			return nil, fmt.Errorf("insert import_rows: %w", err)
+		}
+	}
+
+	return convertImportJob(dbJob), nil
+}
+
+// GetImportJob selects the job by id, it returns nil if the job doesn't exist.
+func (r *Repo) GetImportJob(ctx context.Context, id int64) (*dto.ImportJob, error) {
+	logging.FromContext(ctx, r.log).With("id", id).Debug("GetImportJob")
+	const query = `
+SELECT *
+FROM import_jobs
+WHERE id = ?
+`
+
+	var dbJob ImportJob
+	err := get(ctx, r.db, &dbJob, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select: %w", err)
+	}
+
+	return convertImportJob(dbJob), nil
+}
+
+// GetImportRows selects rows of the job ordered by line.
+func (r *Repo) GetImportRows(ctx context.Context, jobID int64) ([]dto.ImportRow, error) {
+	logging.FromContext(ctx, r.log).With("job_id", jobID).Debug("GetImportRows")
+	const query = `
+SELECT *
+FROM import_rows
+WHERE job_id = ?
+ORDER BY line
+`
+
+	var dbRows []ImportRow
+	if err := selectx(ctx, r.db, &dbRows, query, jobID); err != nil {
+		return nil, fmt.Errorf("select: %w", err)
+	}
+
+	return convertImportRows(dbRows), nil
+}
+
+// ClaimImportJob marks the oldest pending job as running and returns it, a running job is claimed again
+// if it isn't updated during the lease. It returns nil if there are no jobs to run.
+// The statement holds the write lock, so a job is claimed by one process.
+func (r *Repo) ClaimImportJob(ctx context.Context, lease time.Duration) (*dto.ImportJob, error) {
+	const query = `
+UPDATE import_jobs
+SET status = ?, updated_at = ?
+WHERE id = (
+    SELECT id
+    FROM import_jobs
+    WHERE status = ? OR status = ? AND updated_at < ?
+    ORDER BY id
+    LIMIT 1
+)
+RETURNING *
+`
+
+	now := time.Now()
+	var dbJob ImportJob
+	err := get(ctx, r.db, &dbJob, query, consts.ImportStatusRunning, newTimestamp(now),
+		consts.ImportStatusPending, consts.ImportStatusRunning, newTimestamp(now.Add(-lease)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("update import_jobs: %w", err)
+	}
+
+	logging.FromContext(ctx, r.log).With("id", dbJob.ID).Debug("ClaimImportJob")
+	return convertImportJob(dbJob), nil
+}
+
+// GetPendingImportRowsTx selects at most limit pending rows of the job ordered by line using transaction.
+func (r *Repo) GetPendingImportRowsTx(ctx context.Context, tx storage.Tx, jobID int64, limit int) ([]dto.ImportRow, error) {
+	logging.FromContext(ctx, r.log).With("job_id", jobID, "limit", limit).Debug("GetPendingImportRowsTx")
+	const query = `
+SELECT *
+FROM import_rows
+WHERE job_id = ? AND status = ?
+ORDER BY line
+LIMIT ?
+`
+
+	var dbRows []ImportRow
+	err := selectx(ctx, sqlTx(tx), &dbRows, query, jobID, consts.ImportStatusPending, limit)
+	if err != nil {
+		return nil, fmt.Errorf("select: %w", err)
+	}
+
+	return convertImportRows(dbRows), nil
+}
+
+// ImportKeyAppliedTx reports whether a row with the idempotency key is applied using transaction.
+func (r *Repo) ImportKeyAppliedTx(ctx context.Context, tx storage.Tx, key string) (bool, error) {
+	const query = `
+SELECT EXISTS (SELECT 1 FROM import_rows WHERE key = ? AND status = ?)
+`
+
+	var applied bool
+	err := get(ctx, sqlTx(tx), &applied, query, key, consts.ImportStatusApplied)
+	if err != nil {
+		return false, fmt.Errorf("select: %w", err)
+	}
+	return applied, nil
+}
+
+// UpdateImportRowTx sets the status and the error of the row using transaction.
+func (r *Repo) UpdateImportRowTx(ctx context.Context, tx storage.Tx, row dto.ImportRow) error {
+	logging.FromContext(ctx, r.log).With("job_id", row.JobID, "line", row.Line, "status", row.Status).Debug("UpdateImportRowTx")
+	const query = `
+UPDATE import_rows
+SET status = ?, error = ?
+WHERE job_id = ? AND line = ?
+`
+
+	_, err := exec(ctx, sqlTx(tx), query, row.Status, row.Error, row.JobID, row.Line)
+	if err != nil {
+		return fmt.Errorf("update import_rows: %w", err)
+	}
+	return nil
+}
+
+// UpdateImportJobTx sets the status and the progress of the job using transaction, it renews the lease of the job.
+func (r *Repo) UpdateImportJobTx(ctx context.Context, tx storage.Tx, job dto.ImportJob) error {
+	logging.FromContext(ctx, r.log).With("id", job.ID, "status", job.Status).Debug("UpdateImportJobTx")
+	const query = `
+UPDATE import_jobs
+SET status = ?, applied_rows = ?, skipped_rows = ?, failed_rows = ?, finished_at = ?, updated_at = ?
+WHERE id = ?
+`
+
+	var finishedAt *timestamp
+	if job.FinishedAt != nil {
+		ts := newTimestamp(*job.FinishedAt)
+		finishedAt = &ts
+	}
+	_, err := exec(ctx, sqlTx(tx), query, job.Status, job.Applied, job.Skipped, job.Failed, finishedAt,
+		newTimestamp(time.Now()), job.ID)
+	if err != nil {
+		return fmt.Errorf("update import_jobs: %w", err)
+	}
+	return nil
+}
+
+func convertImportJob(dbJob ImportJob) *dto.ImportJob {
+	job := &dto.ImportJob{
+		ID:        dbJob.ID,
+		Kind:      dbJob.Kind,
+		Status:    dbJob.Status,
+		FileHash:  dbJob.FileHash,
+		ChunkSize: dbJob.ChunkSize,
+		Rows:      dbJob.TotalRows,
+		Applied:   dbJob.AppliedRows,
+		Skipped:   dbJob.SkippedRows,
+		Failed:    dbJob.FailedRows,
+		CreatedBy: dbJob.CreatedBy,
+		RequestID: dbJob.RequestID,
+		CreatedAt: dbJob.CreatedAt.Time(),
+		UpdatedAt: dbJob.UpdatedAt.Time(),
+	}
+	if dbJob.FinishedAt != nil {
+		finishedAt := dbJob.FinishedAt.Time()
+		job.FinishedAt = &finishedAt
+	}
+	return job
+}
+
+func convertImportRows(dbRows []ImportRow) []dto.ImportRow {
+	rows := make([]dto.ImportRow, len(dbRows))
+	for i, row := range dbRows {
+		rows[i] = dto.ImportRow{
+			JobID:    row.JobID,
+			Line:     row.Line,
+			Key:      row.Key,
+			Wallet:   row.Wallet,
+			WalletTo: row.WalletTo,
+			Amount:   row.Amount,
+			Status:   row.Status,
+			Error:    row.Error,
+		}
+	}
+	return rows
+}
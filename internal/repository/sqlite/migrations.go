@@ -0,0 +1,232 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/httpfs"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ezhdanovskiy/wallets/internal/repository"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
+	"github.com/ezhdanovskiy/wallets/migrations"
+)
+
+// migrationsTable keeps the version of the schema, like the table of golang-migrate in Postgres.
+// There is no dirty flag, a migration is applied together with its version in a transaction.
+const migrationsTable = "schema_migrations"
+
+// MigrateUp applies the embedded migrations that aren't applied yet.
+// Every migration runs in its own transaction holding the write lock, so concurrent processes don't apply it twice.
+func (r *Repo) MigrateUp(ctx context.Context) error {
+	src, err := openMigrations()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	for {
+		var applied bool
+		err := r.RunWithTransaction(ctx, func(ctx context.Context, stx storage.Tx) error {
+			tx := sqlTx(stx)
+			version, err := migrationVersion(ctx, tx)
+			if err != nil {
+				return err
+			}
+
+			next, err := src.First()
+			if version > 0 {
+				next, err = src.Next(version)
+			}
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("next migration: %w", err)
+			}
+
+			r.log.With("version", next).Info("Apply migration")
+			if err := runMigration(ctx, tx, src.ReadUp, next); err != nil {
+				return err
+			}
+			applied = true
+			return setMigrationVersion(ctx, tx, next)
+		})
+		if err != nil || !applied {
+			return err
+		}
+	}
+}
+
+// MigrateDown rolls back the last steps migrations.
+func (r *Repo) MigrateDown(ctx context.Context, steps int) error {
+	src, err := openMigrations()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	for i := 0; i < steps; i++ {
+		var rolledBack bool
+		err := r.RunWithTransaction(ctx, func(ctx context.Context, stx storage.Tx) error {
+			tx := sqlTx(stx)
+			version, err := migrationVersion(ctx, tx)
+			if err != nil || version == 0 {
+				return err
+			}
+
+			prev, err := src.Prev(version)
+			if errors.Is(err, os.ErrNotExist) {
+				prev = 0
+			} else if err != nil {
+				return fmt.Errorf("previous migration: %w", err)
+			}
+
+			r.log.With("version", version).Info("Roll back migration")
+			if err := runMigration(ctx, tx, src.ReadDown, version); err != nil {
+				return err
+			}
+			rolledBack = true
+			return setMigrationVersion(ctx, tx, prev)
+		})
+		if err != nil || !rolledBack {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrationStatus returns the version of the applied migrations and the latest one.
+func (r *Repo) MigrationStatus(ctx context.Context) (repository.MigrationStatus, error) {
+	version, _, err := r.MigrationVersion(ctx)
+	if err != nil {
+		return repository.MigrationStatus{}, err
+	}
+	latest, err := LatestMigrationVersion()
+	if err != nil {
+		return repository.MigrationStatus{}, err
+	}
+	return repository.MigrationStatus{Version: version, Latest: latest}, nil
+}
+
+// checkSchema refuses to work with the schema that is behind the migrations.
+// A newer schema is allowed, so the previous version keeps working during an update.
+func (r *Repo) checkSchema(ctx context.Context) error {
+	latest, err := LatestMigrationVersion()
+	if err != nil {
+		return err
+	}
+	version, _, err := r.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case version < latest:
+		return fmt.Errorf("%w: version %d, latest %d, run \"wallets migrate up\" or enable MIGRATE_ON_START",
+			repository.ErrSchemaBehind, version, latest)
+	case version > latest:
+		r.log.With("version", version, "latest", latest).Warn("Database schema is newer than the migrations")
+	}
+	return nil
+}
+
+// LatestMigrationVersion returns the version of the last embedded SQLite migration.
+func LatestMigrationVersion() (uint, error) {
+	src, err := openMigrations()
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("first migration: %w", err)
+	}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("next migration: %w", err)
+		}
+		version = next
+	}
+}
+
+// migrationVersion returns the version of the applied migrations, 0 if migrations were never applied.
+func migrationVersion(ctx context.Context, db sqlx.QueryerContext) (uint, error) {
+	var tables int
+	err := get(ctx, db, &tables, `SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, migrationsTable)
+	if err != nil {
+		return 0, fmt.Errorf("select migrations table: %w", err)
+	}
+	if tables == 0 {
+		return 0, nil
+	}
+
+	var version uint
+	err = get(ctx, db, &version, `SELECT version FROM `+migrationsTable+` LIMIT 1`)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("select migration version: %w", err)
+	}
+	return version, nil
+}
+
+func setMigrationVersion(ctx context.Context, tx *sqlx.Tx, version uint) error {
+	for _, query := range []string{
+		`CREATE TABLE IF NOT EXISTS ` + migrationsTable + ` (version INTEGER NOT NULL)`,
+		`DELETE FROM ` + migrationsTable,
+	} {
+		if _, err := exec(ctx, tx, query); err != nil {
+			return fmt.Errorf("reset migration version: %w", err)
+		}
+	}
+	if version == 0 {
+		return nil
+	}
+
+	if _, err := exec(ctx, tx, `INSERT INTO `+migrationsTable+` (version) VALUES (?)`, version); err != nil {
+		return fmt.Errorf("insert migration version: %w", err)
+	}
+	return nil
+}
+
+// runMigration executes all statements of the migration read by read, e.g. ReadUp of the source.
+func runMigration(ctx context.Context, tx *sqlx.Tx, read func(uint) (io.ReadCloser, string, error), version uint) error {
+	r, _, err := read(version)
+	if err != nil {
+		return fmt.Errorf("read migration %d: %w", version, err)
+	}
+	defer r.Close()
+
+	query, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("read migration %d: %w", version, err)
+	}
+	if _, err := exec(ctx, tx, string(query)); err != nil {
+		return fmt.Errorf("migration %d: %w", version, err)
+	}
+	return nil
+}
+
+// openMigrations opens the embedded SQLite migrations.
+func openMigrations() (source.Driver, error) {
+	dir, err := fs.Sub(migrations.SQLite, "sqlite")
+	if err != nil {
+		return nil, fmt.Errorf("open embedded migrations: %w", err)
+	}
+	src, err := httpfs.New(http.FS(dir), ".")
+	if err != nil {
+		return nil, fmt.Errorf("open embedded migrations: %w", err)
+	}
+	return src, nil
+}
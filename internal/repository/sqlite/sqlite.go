@@ -0,0 +1,610 @@
+// Package sqlite implements the repository in a SQLite database file, it is used by single instance deployments.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite" // registers the pure Go "sqlite" driver, the binary is built without cgo
+
+	"github.com/ezhdanovskiy/wallets/internal/config"
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/ledger"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+	"github.com/ezhdanovskiy/wallets/internal/repository"
+	"github.com/ezhdanovskiy/wallets/internal/repository/sqltrace"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
+	"github.com/ezhdanovskiy/wallets/internal/tracing"
+)
+
+// Repo performs database operations in SQLite.
+//
+// Every transaction starts with BEGIN IMMEDIATE and holds the write lock of the database until it ends,
+// so transactions changing data run one at a time and never conflict, while readers aren't blocked in WAL mode.
+type Repo struct {
+	log *zap.SugaredLogger
+	db  *sqlx.DB
+}
+
+// NewRepo opens the database from config, applies migrations if migrateOnStart is set
+// and checks that the schema is up to date.
+func NewRepo(logger *zap.SugaredLogger, cfg config.SQLite, migrateOnStart bool) (*Repo, error) {
+	repo, err := Open(logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if migrateOnStart {
+		err = repo.MigrateUp(context.Background())
+	} else {
+		err = repo.checkSchema(context.Background())
+	}
+	if err != nil {
+		_ = repo.Close()
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// Open opens the database from config, the file is created if it doesn't exist. The schema isn't checked.
+func Open(logger *zap.SugaredLogger, cfg config.SQLite) (*Repo, error) {
+	db, err := sqlx.Open("sqlite", dsn(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("open sqlite %s: %w", cfg.Path, err)
+	}
+
+	return &Repo{
+		log: logger,
+		db:  db,
+	}, nil
+}
+
+// dsn returns the connection string of the modernc.org/sqlite driver, pragmas are applied to every connection.
+func dsn(cfg config.SQLite) string {
+	params := url.Values{}
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", cfg.BusyTimeout.Milliseconds()))
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Set("_txlock", "immediate")
+	return "file:" + cfg.Path + "?" + params.Encode()
+}
+
+// Close closes the database, it waits for the started queries to finish.
+func (r *Repo) Close() error {
+	return r.db.Close()
+}
+
+// StatsCollector returns collector of the connection pool statistics.
+func (r *Repo) StatsCollector() prometheus.Collector {
+	return repository.NewStatsCollector(r.db)
+}
+
+// CreateWallet creates new wallet with unique name,
+// or do nothing if wallet already exists.
+func (r *Repo) CreateWallet(ctx context.Context, walletName, owner string) error {
+	return r.createWallet(ctx, r.db, walletName, owner)
+}
+
+// CreateWalletTx is CreateWallet using transaction.
+func (r *Repo) CreateWalletTx(ctx context.Context, tx storage.Tx, walletName, owner string) error {
+	return r.createWallet(ctx, sqlTx(tx), walletName, owner)
+}
+
+func (r *Repo) createWallet(ctx context.Context, db sqlx.ExecerContext, walletName, owner string) error {
+	logging.FromContext(ctx, r.log).With("wallet", walletName, "owner", owner).Debug("CreateWallet")
+	const query = `
+INSERT INTO wallets (name, owner, created_at, updated_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT DO NOTHING
+`
+
+	now := newTimestamp(time.Now())
+	_, err := exec(ctx, db, query, walletName, owner, now, now)
+	if err != nil {
+		return fmt.Errorf("insert wallets: %w", err)
+	}
+
+	return nil
+}
+
+// GetWallet selects wallet by name.
+func (r *Repo) GetWallet(ctx context.Context, walletName string) (*dto.Wallet, error) {
+	logging.FromContext(ctx, r.log).With("wallet", walletName).Debug("GetWallet")
+	const query = `
+SELECT *
+FROM wallets
+WHERE name = ?
+`
+
+	var dbWallet Wallet
+	err := get(ctx, r.db, &dbWallet, query, walletName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select: %w", err)
+	}
+
+	return &dto.Wallet{
+		Name:    dbWallet.Name,
+		Owner:   dbWallet.Owner,
+		Balance: dbWallet.Balance,
+	}, nil
+}
+
+// ListWallets selects wallets ordered by name, optionally of the owner.
+func (r *Repo) ListWallets(ctx context.Context, filter dto.WalletsFilter) ([]dto.Wallet, error) {
+	logging.FromContext(ctx, r.log).With("owner", filter.Owner).Debug("ListWallets")
+	const query = `
+SELECT *
+FROM wallets
+WHERE ?1 = '' OR owner = ?1
+ORDER BY name
+LIMIT ?2 OFFSET ?3
+`
+
+	var dbWallets []Wallet
+	err := selectx(ctx, r.db, &dbWallets, query, filter.Owner, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("select: %w", err)
+	}
+
+	wallets := make([]dto.Wallet, len(dbWallets))
+	for i, w := range dbWallets {
+		wallets[i] = dto.Wallet{
+			Name:    w.Name,
+			Owner:   w.Owner,
+			Balance: w.Balance,
+		}
+	}
+	return wallets, nil
+}
+
+// IncreaseWalletBalance runs two operations in transaction:
+//   - increases wallet balance;
+//   - add new operation with type deposit.
+func (r *Repo) IncreaseWalletBalance(ctx context.Context, walletName string, amount uint64) error {
+	logging.FromContext(ctx, r.log).With("wallet_name", walletName, "amount", amount).Debug("IncreaseWalletBalance")
+
+	return r.RunWithTransaction(ctx, func(ctx context.Context, tx storage.Tx) error {
+		return r.IncreaseWalletBalanceTx(ctx, tx, walletName, amount)
+	})
+}
+
+// IncreaseWalletBalanceTx is IncreaseWalletBalance using transaction.
+func (r *Repo) IncreaseWalletBalanceTx(ctx context.Context, tx storage.Tx, walletName string, amount uint64) error {
+	err := r.increaseWalletBalanceTx(ctx, sqlTx(tx), walletName, amount)
+	if err != nil {
+		return err
+	}
+
+	return r.insertOperation(ctx, sqlTx(tx), walletName, consts.OperationTypeDeposit, amount, consts.SystemWalletName)
+}
+
+// RunWithTransaction runs the given function inside a transaction, ctx passed to f carries the transaction span.
+// The transaction waits for the write lock at most the busy timeout, it never conflicts with a concurrent one
+// once started, so it isn't restarted.
+func (r *Repo) RunWithTransaction(ctx context.Context, f func(ctx context.Context, tx storage.Tx) error) (err error) {
+	logging.FromContext(ctx, r.log).Debug("RunWithTransaction")
+
+	ctx, span := tracing.Start(ctx, "transaction")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+
+	fErr := f(ctx, tx)
+	if fErr != nil {
+		if err = tx.Rollback(); err != nil {
+			logging.FromContext(ctx, r.log).Errorf("rollback: %s", err)
+		}
+		return fErr
+	}
+
+	_, commitSpan := tracing.Start(ctx, "COMMIT")
+	err = tx.Commit()
+	tracing.End(commitSpan, err)
+	if err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	return nil
+}
+
+// sqlTx returns the SQLite transaction behind tx, tx must be started by RunWithTransaction of Repo.
+func sqlTx(tx storage.Tx) *sqlx.Tx {
+	return tx.(*sqlx.Tx)
+}
+
+// GetWalletsForUpdateTx selects wallets using transaction. SQLite has no row locks,
+// the wallets can't be changed by others because the transaction holds the write lock of the database.
+func (r *Repo) GetWalletsForUpdateTx(ctx context.Context, tx storage.Tx, walletNames []string) ([]dto.Wallet, error) {
+	logging.FromContext(ctx, r.log).With("wallets", walletNames).Debug("GetWalletsForUpdateTx")
+
+	const querySrc = `
+SELECT *
+FROM wallets
+WHERE name IN (?)
+`
+
+	query, args, err := sqlx.In(querySrc, walletNames)
+	if err != nil {
+		return nil, fmt.Errorf("select for update: %w", err)
+	}
+
+	dbWallets := make([]Wallet, 0)
+	err = selectx(ctx, sqlTx(tx), &dbWallets, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("select for update: %w", err)
+	}
+
+	wallets := make([]dto.Wallet, len(dbWallets))
+	for i := range dbWallets {
+		wallets[i].Name = dbWallets[i].Name
+		wallets[i].Owner = dbWallets[i].Owner
+		wallets[i].Balance = dbWallets[i].Balance
+	}
+
+	return wallets, nil
+}
+
+// TransferTx runs four operations using transaction:
+//   - decreases balance of wallet_from if there is enough money;
+//   - add new operation with type withdrawal for wallet_from;
+//   - increases balance of wallet_to;
+//   - add new operation with type deposit for wallet_to.
+func (r *Repo) TransferTx(ctx context.Context, stx storage.Tx, walletFrom, walletTo string, amount uint64) error {
+	logging.FromContext(ctx, r.log).With("wallet_from", walletFrom, "wallet_to", walletTo, "amount", amount).Debug("TransferTx")
+	tx := sqlTx(stx)
+
+	err := r.decreaseWalletBalanceTx(ctx, tx, walletFrom, amount)
+	if err != nil {
+		return fmt.Errorf("decrease wallet balance: %w", err)
+	}
+
+	err = r.insertOperation(ctx, tx, walletFrom, consts.OperationTypeWithdrawal, amount, walletTo)
+	if err != nil {
+		return fmt.Errorf("insert operation: %w", err)
+	}
+
+	err = r.increaseWalletBalanceTx(ctx, tx, walletTo, amount)
+	if err != nil {
+		return fmt.Errorf("increase wallet balance: %w", err)
+	}
+
+	err = r.insertOperation(ctx, tx, walletTo, consts.OperationTypeDeposit, amount, walletFrom)
+	if err != nil {
+		return fmt.Errorf("insert operation: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repo) decreaseWalletBalanceTx(ctx context.Context, tx *sqlx.Tx, walletName string, amount uint64) error {
+	logging.FromContext(ctx, r.log).With("wallet", walletName, "amount", amount).Debug("decreaseWalletBalanceTx")
+	const query = `
+UPDATE wallets
+SET balance = balance - ?2, updated_at = ?3
+WHERE name = ?1 AND balance >= ?2
+`
+
+	res, err := exec(ctx, tx, query, walletName, amount, newTimestamp(time.Now()))
+	if err != nil {
+		return fmt.Errorf("update wallets: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rowsAffected != 1 {
+		return fmt.Errorf("%s balance can't be decreased on this amount", walletName)
+	}
+
+	return nil
+}
+
+func (r *Repo) increaseWalletBalanceTx(ctx context.Context, tx *sqlx.Tx, walletName string, amount uint64) error {
+	logging.FromContext(ctx, r.log).With("wallet", walletName, "amount", amount).Debug("increaseWalletBalanceTx")
+	const query = `
+UPDATE wallets
+SET balance = balance + ?2, updated_at = ?3
+WHERE name = ?1
+`
+
+	_, err := exec(ctx, tx, query, walletName, amount, newTimestamp(time.Now()))
+	if err != nil {
+		return fmt.Errorf("update wallets: %w", err)
+	}
+
+	return nil
+}
+
+// insertOperation appends the operation to the wallet chain.
+// The transaction holds the write lock, so the last operation of the wallet can't change.
+func (r *Repo) insertOperation(ctx context.Context, tx *sqlx.Tx, wallet, opType string, amount uint64, otherWallet string) error {
+	logging.FromContext(ctx, r.log).With("wallet", wallet, "type", opType, "amount", amount, "other", otherWallet).Debug("insertOperation")
+
+	entry := ledger.Entry{
+		Wallet:      wallet,
+		Seq:         1,
+		Type:        opType,
+		Amount:      amount,
+		OtherWallet: otherWallet,
+		CreatedAt:   ledger.Now(),
+	}
+
+	var last Operation
+	err := get(ctx, tx, &last, `SELECT seq, hash FROM operations WHERE wallet = ? ORDER BY seq DESC LIMIT 1`, wallet)
+	switch {
+	case err == nil:
+		entry.Seq = last.Seq + 1
+		entry.PrevHash = last.Hash
+	case err != sql.ErrNoRows:
+		return fmt.Errorf("select last operation: %w", err)
+	}
+	entry.Hash = ledger.Hash(entry)
+
+	const query = `
+INSERT INTO operations (wallet, type, amount, other_wallet, created_at, seq, prev_hash, hash)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+	_, err = exec(ctx, tx, query, entry.Wallet, entry.Type, entry.Amount, entry.OtherWallet, newTimestamp(entry.CreatedAt),
+		entry.Seq, entry.PrevHash, entry.Hash)
+	if err != nil {
+		return fmt.Errorf("insert operation: %w", err)
+	}
+
+	return nil
+}
+
+// GetOperations selects operations for specified wallet using filter,
+// operations ordered by (created_at, id) in the order of the filter.
+func (r *Repo) GetOperations(ctx context.Context, filter dto.OperationsFilter) ([]dto.Operation, error) {
+	logging.FromContext(ctx, r.log).With("wallet", filter.Wallet).Debug("GetOperations")
+
+	query, args, err := operationsQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx, r.log).With("query", query, "args", args).Debug("select operations")
+	dbOperations := make([]Operation, 0)
+	err = selectx(ctx, r.db, &dbOperations, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("select operations: %w", err)
+	}
+
+	// A page before the cursor is selected in the reverse order starting from the cursor and reversed back.
+	backward := filter.Cursor != nil && filter.Cursor.Backward
+	operations := make([]dto.Operation, len(dbOperations))
+	for i := range dbOperations {
+		j := i
+		if backward {
+			j = len(dbOperations) - 1 - i
+		}
+		operations[j] = convertOperation(dbOperations[i])
+	}
+
+	return operations, nil
+}
+
+// ScanOperations calls f for every operation of the wallet selected by filter, it stops on the first error.
+// Rows are read with a cursor, so all operations aren't loaded in memory, a backward cursor scans them in reverse.
+func (r *Repo) ScanOperations(ctx context.Context, filter dto.OperationsFilter, f func(dto.Operation) error) (err error) {
+	logging.FromContext(ctx, r.log).With("wallet", filter.Wallet).Debug("ScanOperations")
+
+	query, args, err := operationsQuery(filter)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := sqltrace.SQLite.StartSpan(ctx, query)
+	defer func() { sqltrace.EndSpan(span, err) }()
+
+	rows, err := r.db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("select operations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var op Operation
+		if err := rows.StructScan(&op); err != nil {
+			return fmt.Errorf("scan operation: %w", err)
+		}
+		if err := f(convertOperation(op)); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate operations: %w", err)
+	}
+	return nil
+}
+
+// GetOperationsTotals returns the number and the sum of the operations of the wallet selected by filter by type,
+// the cursor, the order and the pagination of the filter are ignored.
+func (r *Repo) GetOperationsTotals(ctx context.Context, filter dto.OperationsFilter) (*dto.OperationsTotals, error) {
+	logging.FromContext(ctx, r.log).With("wallet", filter.Wallet).Debug("GetOperationsTotals")
+
+	whereParts, namedArgs := operationsConditions(filter)
+	query, args, err := sqlx.Named(`
+SELECT type, COUNT(*) AS count, COALESCE(SUM(amount), 0) AS amount
+FROM operations
+WHERE `+strings.Join(whereParts, " AND ")+`
+GROUP BY type
+`, namedArgs)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx named: %w", err)
+	}
+
+	rows := make([]operationsTotal, 0)
+	if err := selectx(ctx, r.db, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("select operations totals: %w", err)
+	}
+
+	return convertOperationsTotals(rows), nil
+}
+
+// operationsQuery builds the query selecting operations by filter.
+func operationsQuery(filter dto.OperationsFilter) (string, []interface{}, error) {
+	queryTempl := `
+SELECT *
+FROM operations
+WHERE %s
+ORDER BY %s
+`
+
+	whereParts, namedArgs := operationsConditions(filter)
+
+	// A page before the cursor is selected in the reverse order starting from the cursor.
+	backward := filter.Cursor != nil && filter.Cursor.Backward
+	descending := (filter.Order == consts.OperationsOrderDesc) != backward
+	orderBy := "created_at, id"
+	if descending {
+		orderBy = "created_at DESC, id DESC"
+	}
+
+	if filter.Cursor != nil {
+		if descending {
+			whereParts = append(whereParts, "(created_at, id) < (:cursor_created_at, :cursor_id)")
+		} else {
+			whereParts = append(whereParts, "(created_at, id) > (:cursor_created_at, :cursor_id)")
+		}
+		namedArgs["cursor_created_at"] = newTimestamp(filter.Cursor.CreatedAt)
+		namedArgs["cursor_id"] = filter.Cursor.ID
+	}
+
+	if filter.Limit > 0 {
+		queryTempl += "LIMIT :limit\n"
+		namedArgs["limit"] = filter.Limit
+	}
+
+	if filter.Offset > 0 {
+		if filter.Limit <= 0 {
+			queryTempl += "LIMIT -1\n" // SQLite doesn't allow OFFSET without LIMIT
+		}
+		queryTempl += "OFFSET :offset\n"
+		namedArgs["offset"] = filter.Offset
+	}
+
+	query := fmt.Sprintf(queryTempl, strings.Join(whereParts, " AND "), orderBy)
+
+	query, args, err := sqlx.Named(query, namedArgs)
+	if err != nil {
+		return "", nil, fmt.Errorf("sqlx named: %w", err)
+	}
+	return query, args, nil
+}
+
+// operationsConditions builds the where clause parts and their named parameters for the filters
+// of the history other than the cursor.
+func operationsConditions(filter dto.OperationsFilter) ([]string, map[string]interface{}) {
+	namedArgs := make(map[string]interface{}) // Prepare named parameters.
+	var whereParts []string                   // Generate where clause.
+
+	whereParts = append(whereParts, "wallet = :wallet")
+	namedArgs["wallet"] = filter.Wallet
+
+	if len(filter.Types) != 0 {
+		typeParams := make([]string, len(filter.Types))
+		for i, t := range filter.Types {
+			typeParams[i] = fmt.Sprintf(":type%d", i)
+			namedArgs[fmt.Sprintf("type%d", i)] = t
+		}
+		whereParts = append(whereParts, "type IN ("+strings.Join(typeParams, ", ")+")")
+	}
+
+	if filter.OtherWallet != "" {
+		whereParts = append(whereParts, "other_wallet = :other_wallet")
+		namedArgs["other_wallet"] = filter.OtherWallet
+	}
+
+	if filter.MinAmount > 0 {
+		whereParts = append(whereParts, "amount >= :min_amount")
+		namedArgs["min_amount"] = filter.MinAmount.GetInt()
+	}
+
+	if filter.MaxAmount > 0 {
+		whereParts = append(whereParts, "amount <= :max_amount")
+		namedArgs["max_amount"] = filter.MaxAmount.GetInt()
+	}
+
+	// Plain comparisons of created_at use the index, unlike expressions of the column.
+	if !filter.StartDate.IsZero() {
+		whereParts = append(whereParts, "created_at >= :start_date")
+		namedArgs["start_date"] = newTimestamp(filter.StartDate)
+	}
+
+	if !filter.EndDate.IsZero() {
+		whereParts = append(whereParts, "created_at <= :end_date")
+		namedArgs["end_date"] = newTimestamp(filter.EndDate)
+	}
+
+	return whereParts, namedArgs
+}
+
+// operationsTotal is a row of the totals of operations grouped by type.
+type operationsTotal struct {
+	Type   string `db:"type"`
+	Count  int64  `db:"count"`
+	Amount uint64 `db:"amount"`
+}
+
+func convertOperationsTotals(rows []operationsTotal) *dto.OperationsTotals {
+	var totals dto.OperationsTotals
+	for _, row := range rows {
+		total := dto.OperationsTotal{Count: row.Count, Cents: row.Amount}
+		switch row.Type {
+		case consts.OperationTypeDeposit:
+			totals.Deposits = total
+		case consts.OperationTypeWithdrawal:
+			totals.Withdrawals = total
+		}
+	}
+	return &totals
+}
+
+func convertOperation(op Operation) dto.Operation {
+	o := dto.Operation{
+		ID:          op.ID,
+		Wallet:      op.Wallet,
+		Type:        op.Type,
+		OtherWallet: op.OtherWallet,
+		Timestamp:   op.CreatedAt.Time(),
+	}
+	o.Amount.SetAmount(op.Amount)
+	return o
+}
+
+// The helpers below run queries on DB or transaction in a span named by the operation and the table.
+
+func get(ctx context.Context, db sqlx.QueryerContext, dest interface{}, query string, args ...interface{}) error {
+	return sqltrace.SQLite.Get(ctx, db, dest, query, args...)
+}
+
+func selectx(ctx context.Context, db sqlx.QueryerContext, dest interface{}, query string, args ...interface{}) error {
+	return sqltrace.SQLite.Select(ctx, db, dest, query, args...)
+}
+
+func exec(ctx context.Context, db sqlx.ExecerContext, query string, args ...interface{}) (sql.Result, error) {
+	return sqltrace.SQLite.Exec(ctx, db, query, args...)
+}
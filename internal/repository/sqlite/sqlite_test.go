@@ -0,0 +1,24 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/ezhdanovskiy/wallets/internal/config"
+	"github.com/ezhdanovskiy/wallets/internal/repository/conformance"
+)
+
+func TestRepo_Conformance(t *testing.T) {
+	repo, err := NewRepo(zap.NewNop().Sugar(), config.SQLite{
+		Path:        filepath.Join(t.TempDir(), "wallets.db"),
+		BusyTimeout: 5 * time.Second,
+	}, true)
+	require.NoError(t, err)
+	defer func() { _ = repo.Close() }()
+
+	conformance.Run(t, repo)
+}
@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+)
+
+// InsertStatement stores the statement of the closed month. A stored statement of the same wallet and period
+// is kept, it has the same operations.
+func (r *Repo) InsertStatement(ctx context.Context, st dto.Statement) error {
+	logging.FromContext(ctx, r.log).With("wallet", st.Wallet, "period", st.Period).Debug("InsertStatement")
+	const query = `
+INSERT INTO statements (wallet, period, statement, created_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (wallet, period) DO NOTHING
+`
+
+	data, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("marshal statement: %w", err)
+	}
+
+	if _, err := exec(ctx, r.db, query, st.Wallet, st.Period, string(data), newTimestamp(time.Now())); err != nil {
+		return fmt.Errorf("insert statements: %w", err)
+	}
+	return nil
+}
+
+// GetStatement selects the stored statement of the wallet for the period, it returns nil if it isn't stored.
+func (r *Repo) GetStatement(ctx context.Context, walletName, period string) (*dto.Statement, error) {
+	logging.FromContext(ctx, r.log).With("wallet", walletName, "period", period).Debug("GetStatement")
+	const query = `
+SELECT statement
+FROM statements
+WHERE wallet = ? AND period = ?
+`
+
+	var data string
+	err := get(ctx, r.db, &data, query, walletName, period)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select statements: %w", err)
+	}
+
+	var st dto.Statement
+	if err := json.Unmarshal([]byte(data), &st); err != nil {
+		return nil, fmt.Errorf("unmarshal statement: %w", err)
+	}
+	return &st, nil
+}
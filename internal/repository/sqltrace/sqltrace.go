@@ -0,0 +1,97 @@
+// Package sqltrace runs queries on DB or transaction in a span named by the operation and the table.
+package sqltrace
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ezhdanovskiy/wallets/internal/tracing"
+)
+
+// Tracer creates spans of queries to the database system, e.g. semconv.DBSystemPostgreSQL.
+type Tracer struct {
+	system attribute.KeyValue
+}
+
+// New creates Tracer for the database system.
+func New(system attribute.KeyValue) Tracer {
+	return Tracer{system: system}
+}
+
+// Postgres and SQLite are tracers of the supported databases.
+var (
+	Postgres = New(semconv.DBSystemPostgreSQL)
+	SQLite   = New(semconv.DBSystemSqlite)
+)
+
+// Get is sqlx.GetContext in a span.
+func (t Tracer) Get(ctx context.Context, db sqlx.QueryerContext, dest interface{}, query string, args ...interface{}) error {
+	ctx, span := t.StartSpan(ctx, query)
+	err := sqlx.GetContext(ctx, db, dest, query, args...)
+	EndSpan(span, err)
+	return err
+}
+
+// Select is sqlx.SelectContext in a span.
+func (t Tracer) Select(ctx context.Context, db sqlx.QueryerContext, dest interface{}, query string, args ...interface{}) error {
+	ctx, span := t.StartSpan(ctx, query)
+	err := sqlx.SelectContext(ctx, db, dest, query, args...)
+	EndSpan(span, err)
+	return err
+}
+
+// Exec is ExecContext of db in a span.
+func (t Tracer) Exec(ctx context.Context, db sqlx.ExecerContext, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := t.StartSpan(ctx, query)
+	res, err := db.ExecContext(ctx, query, args...)
+	EndSpan(span, err)
+	return res, err
+}
+
+// StartSpan starts the span of the query, it is used for queries that read rows with a cursor.
+func (t Tracer) StartSpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation, table := parseQuery(query)
+	name := operation
+	if table != "" {
+		name += " " + table
+	}
+
+	return tracing.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		t.system,
+		semconv.DBOperationName(operation),
+		semconv.DBCollectionName(table),
+		semconv.DBQueryText(strings.Join(strings.Fields(query), " ")),
+	))
+}
+
+// EndSpan ends the span, no rows isn't a failure of the query.
+func EndSpan(span trace.Span, err error) {
+	if err == sql.ErrNoRows {
+		span.SetAttributes(attribute.Bool("db.no_rows", true))
+		err = nil
+	}
+	tracing.End(span, err)
+}
+
+// parseQuery returns the operation of the query and the table the operation is applied to.
+func parseQuery(query string) (operation, table string) {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "", ""
+	}
+
+	operation = strings.ToUpper(fields[0])
+	for i := 0; i < len(fields)-1; i++ {
+		switch strings.ToUpper(fields[i]) {
+		case "FROM", "INTO", "UPDATE":
+			return operation, fields[i+1]
+		}
+	}
+	return operation, ""
+}
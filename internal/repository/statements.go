@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+)
+
+// InsertStatement stores the statement of the closed month. A stored statement of the same wallet and period
+// is kept, it has the same operations.
+func (r *Repo) InsertStatement(ctx context.Context, st dto.Statement) error {
+	logging.FromContext(ctx, r.log).With("wallet", st.Wallet, "period", st.Period).Debug("InsertStatement")
+	const query = `
+INSERT INTO statements (wallet, period, statement)
+VALUES ($1, $2, $3)
+ON CONFLICT (wallet, period) DO NOTHING
+`
+
+	data, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("marshal statement: %w", err)
+	}
+
+	if _, err := exec(ctx, r.db, query, st.Wallet, st.Period, data); err != nil {
+		return fmt.Errorf("insert statements: %w", err)
+	}
+	return nil
+}
+
+// GetStatement selects the stored statement of the wallet for the period, it returns nil if it isn't stored.
+// The statement is read from the replica unless ctx requires strong consistency.
+func (r *Repo) GetStatement(ctx context.Context, walletName, period string) (*dto.Statement, error) {
+	logging.FromContext(ctx, r.log).With("wallet", walletName, "period", period).Debug("GetStatement")
+	const query = `
+SELECT statement
+FROM statements
+WHERE wallet = $1 AND period = $2
+`
+
+	var data []byte
+	err := r.read(ctx, func(db *sqlx.DB) error {
+		return get(ctx, db, &data, query, walletName, period)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select statements: %w", err)
+	}
+
+	var st dto.Statement
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("unmarshal statement: %w", err)
+	}
+	return &st, nil
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ezhdanovskiy/wallets/internal/repository/sqltrace"
+)
+
+// The helpers below run queries on DB or transaction in a span named by the operation and the table.
+
+func get(ctx context.Context, db sqlx.QueryerContext, dest interface{}, query string, args ...interface{}) error {
+	return sqltrace.Postgres.Get(ctx, db, dest, query, args...)
+}
+
+func selectx(ctx context.Context, db sqlx.QueryerContext, dest interface{}, query string, args ...interface{}) error {
+	return sqltrace.Postgres.Select(ctx, db, dest, query, args...)
+}
+
+func exec(ctx context.Context, db sqlx.ExecerContext, query string, args ...interface{}) (sql.Result, error) {
+	return sqltrace.Postgres.Exec(ctx, db, query, args...)
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ezhdanovskiy/wallets/internal/audit"
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
+	"github.com/ezhdanovskiy/wallets/internal/tracing"
+)
+
+// CreateAPIKey generates new API key and stores its hash.
+// The plain key is returned only here, it can't be restored later.
+func (s *Service) CreateAPIKey(ctx context.Context, req dto.CreateAPIKeyRequest) (_ *dto.CreatedAPIKey, err error) {
+	ctx, span := tracing.Start(ctx, "Service.CreateAPIKey")
+	defer func() { tracing.End(span, err) }()
+
+	if err := checkCreateAPIKeyRequest(req); err != nil {
+		return nil, s.auditRejected(ctx, audit.ActionCreateAPIKey, req, err)
+	}
+
+	var created *dto.CreatedAPIKey
+	err = s.audited(ctx, audit.ActionCreateAPIKey, req, func(ctx context.Context, tx storage.Tx) error {
+		if err := authorizeScope(ctx, auth.ScopeAdmin); err != nil {
+			return err
+		}
+
+		key, err := auth.GenerateAPIKey()
+		if err != nil {
+			return ErrInternal.Wrap(err)
+		}
+
+		stored, err := s.repo.CreateAPIKeyTx(ctx, tx, dto.APIKey{
+			Name:    req.Name,
+			Scopes:  req.Scopes,
+			Wallets: nonNil(req.Wallets),
+			Owners:  nonNil(req.Owners),
+		}, auth.HashAPIKey(key))
+		if err != nil {
+			return ErrDatabase.Wrap(err)
+		}
+		if stored == nil {
+			return ErrAPIKeyAlreadyExists
+		}
+
+		created = &dto.CreatedAPIKey{APIKey: *stored, Key: key}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx, s.log).With("name", created.Name, "scopes", created.Scopes).Info("API key created")
+	return created, nil
+}
+
+// checkCreateAPIKeyRequest validates the name and the scopes of the API key.
+func checkCreateAPIKeyRequest(req dto.CreateAPIKeyRequest) error {
+	if req.Name == "" {
+		return ErrEmptyAPIKeyName
+	}
+	if len(req.Scopes) == 0 {
+		return ErrEmptyScopes
+	}
+	for _, scope := range req.Scopes {
+		if !auth.ValidScope(scope) {
+			return ErrUnsupportedScope
+		}
+	}
+	return nil
+}
+
+// ListAPIKeys provides all API keys without the keys themselves.
+func (s *Service) ListAPIKeys(ctx context.Context) (_ []dto.APIKey, err error) {
+	ctx, span := tracing.Start(ctx, "Service.ListAPIKeys")
+	defer func() { tracing.End(span, err) }()
+
+	if err := authorizeScope(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.repo.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey disables the API key, revoked keys stay in the list.
+func (s *Service) RevokeAPIKey(ctx context.Context, name string) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.RevokeAPIKey")
+	defer func() { tracing.End(span, err) }()
+
+	if name == "" {
+		return s.auditRejected(ctx, audit.ActionRevokeAPIKey, name, ErrEmptyAPIKeyName)
+	}
+
+	err = s.audited(ctx, audit.ActionRevokeAPIKey, name, func(ctx context.Context, tx storage.Tx) error {
+		if err := authorizeScope(ctx, auth.ScopeAdmin); err != nil {
+			return err
+		}
+
+		revoked, err := s.repo.RevokeAPIKeyTx(ctx, tx, name)
+		if err != nil {
+			return ErrDatabase.Wrap(err)
+		}
+		if !revoked {
+			return ErrAPIKeyNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx, s.log).With("name", name).Info("API key revoked")
+	return nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
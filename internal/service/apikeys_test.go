@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ezhdanovskiy/wallets/internal/audit"
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
+)
+
+func TestService_CreateAPIKey(t *testing.T) {
+	req := dto.CreateAPIKeyRequest{
+		Name:    "payouts",
+		Scopes:  []string{auth.ScopeTransfer},
+		Wallets: []string{testWalletName01},
+	}
+
+	t.Run("not admin", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.expectTx()
+		ts.expectAudit(audit.ActionCreateAPIKey, audit.ResultError)
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeTransfer}})
+		key, err := ts.svc.CreateAPIKey(ctx, req)
+		assert.Nil(t, key)
+		assert.Equal(t, ErrPermissionDenied, err)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+		ts.expectAudit(audit.ActionCreateAPIKey, audit.ResultError)
+
+		_, err := ts.svc.CreateAPIKey(context.Background(), dto.CreateAPIKeyRequest{Scopes: req.Scopes})
+		assert.Equal(t, ErrEmptyAPIKeyName, err)
+	})
+
+	t.Run("empty scopes", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+		ts.expectAudit(audit.ActionCreateAPIKey, audit.ResultError)
+
+		_, err := ts.svc.CreateAPIKey(context.Background(), dto.CreateAPIKeyRequest{Name: req.Name})
+		assert.Equal(t, ErrEmptyScopes, err)
+	})
+
+	t.Run("unsupported scope", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+		ts.expectAudit(audit.ActionCreateAPIKey, audit.ResultError)
+
+		_, err := ts.svc.CreateAPIKey(context.Background(), dto.CreateAPIKeyRequest{Name: req.Name, Scopes: []string{"root"}})
+		assert.Equal(t, ErrUnsupportedScope, err)
+	})
+
+	t.Run("already exists", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.expectTx()
+		ts.mockRepo.EXPECT().CreateAPIKeyTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+		ts.expectAudit(audit.ActionCreateAPIKey, audit.ResultError)
+
+		_, err := ts.svc.CreateAPIKey(context.Background(), req)
+		assert.Equal(t, ErrAPIKeyAlreadyExists, err)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.expectTx()
+		ts.mockRepo.EXPECT().CreateAPIKeyTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, sql.ErrConnDone)
+		ts.expectAudit(audit.ActionCreateAPIKey, audit.ResultError)
+
+		_, err := ts.svc.CreateAPIKey(context.Background(), req)
+		assert.Equal(t, ErrDatabase.Wrap(sql.ErrConnDone), err)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		var storedHash string
+		ts.expectTx()
+		ts.mockRepo.EXPECT().CreateAPIKeyTx(gomock.Any(), gomock.Any(), dto.APIKey{
+			Name:    req.Name,
+			Scopes:  req.Scopes,
+			Wallets: req.Wallets,
+			Owners:  []string{},
+		}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ storage.Tx, key dto.APIKey, keyHash string) (*dto.APIKey, error) {
+				storedHash = keyHash
+				return &key, nil
+			})
+		ts.expectAudit(audit.ActionCreateAPIKey, audit.ResultOK)
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeAdmin}})
+		key, err := ts.svc.CreateAPIKey(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, req.Name, key.Name)
+		assert.Equal(t, auth.HashAPIKey(key.Key), storedHash)
+	})
+}
+
+func TestService_ListAPIKeys(t *testing.T) {
+	t.Run("not admin", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeRead}})
+		_, err := ts.svc.ListAPIKeys(ctx)
+		assert.Equal(t, ErrPermissionDenied, err)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().ListAPIKeys(gomock.Any()).Return([]dto.APIKey{{Name: "payouts"}}, nil)
+
+		keys, err := ts.svc.ListAPIKeys(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, keys, 1)
+	})
+}
+
+func TestService_RevokeAPIKey(t *testing.T) {
+	t.Run("empty name", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+		ts.expectAudit(audit.ActionRevokeAPIKey, audit.ResultError)
+
+		assert.Equal(t, ErrEmptyAPIKeyName, ts.svc.RevokeAPIKey(context.Background(), ""))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.expectTx()
+		ts.mockRepo.EXPECT().RevokeAPIKeyTx(gomock.Any(), gomock.Any(), "payouts").Return(false, nil)
+		ts.expectAudit(audit.ActionRevokeAPIKey, audit.ResultError)
+
+		assert.Equal(t, ErrAPIKeyNotFound, ts.svc.RevokeAPIKey(context.Background(), "payouts"))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.expectTx()
+		ts.mockRepo.EXPECT().RevokeAPIKeyTx(gomock.Any(), gomock.Any(), "payouts").Return(true, nil)
+		ts.expectAudit(audit.ActionRevokeAPIKey, audit.ResultOK)
+
+		assert.NoError(t, ts.svc.RevokeAPIKey(context.Background(), "payouts"))
+	})
+}
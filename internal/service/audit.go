@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ezhdanovskiy/wallets/internal/audit"
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
+	"github.com/ezhdanovskiy/wallets/internal/tracing"
+)
+
+// audited runs f in a transaction and appends the audit record in the same transaction.
+// If f fails, the transaction is rolled back and the failure is recorded separately.
+func (s *Service) audited(ctx context.Context, action string, payload interface{}, f func(ctx context.Context, tx storage.Tx) error) error {
+	record := newAuditRecord(ctx, action, payload)
+
+	err := s.repo.RunWithTransaction(ctx, func(ctx context.Context, tx storage.Tx) error {
+		if err := f(ctx, tx); err != nil {
+			return err
+		}
+
+		record.Result = audit.ResultOK
+		if err := s.repo.InsertAuditRecordTx(ctx, tx, record); err != nil {
+			return ErrDatabase.Wrap(fmt.Errorf("insert audit record: %w", err))
+		}
+		return nil
+	})
+	if err != nil {
+		s.insertAuditFailure(ctx, record, err)
+	}
+	return err
+}
+
+// auditRejected records the request rejected before audited runs, e.g. an invalid or denied one, and returns err.
+func (s *Service) auditRejected(ctx context.Context, action string, payload interface{}, err error) error {
+	s.insertAuditFailure(ctx, newAuditRecord(ctx, action, payload), err)
+	return err
+}
+
+// insertAuditFailure records the failed request outside of its transaction, the failure is only logged
+// if the record can't be inserted.
+func (s *Service) insertAuditFailure(ctx context.Context, record dto.AuditRecord, err error) {
+	record.Result = audit.ResultError
+	record.Error = err.Error()
+	// The failure is recorded even if the request is canceled.
+	if auditErr := s.repo.InsertAuditRecord(context.WithoutCancel(ctx), record); auditErr != nil {
+		logging.FromContext(ctx, s.log).With("action", record.Action, "request_id", record.RequestID, "error", auditErr).
+			Error("Failed to insert audit record")
+	}
+}
+
+func newAuditRecord(ctx context.Context, action string, payload interface{}) dto.AuditRecord {
+	source := audit.FromContext(ctx)
+	record := dto.AuditRecord{
+		Action:      action,
+		RequestID:   source.RequestID,
+		SourceIP:    source.IP,
+		Endpoint:    source.Endpoint,
+		PayloadHash: audit.PayloadHash(payload),
+	}
+	if caller, ok := auth.FromContext(ctx); ok {
+		record.Actor = caller.ID
+	}
+	return record
+}
+
+// GetAuditRecords provides audit records according to filtering parameters.
+func (s *Service) GetAuditRecords(ctx context.Context, filter dto.AuditFilter) (_ []dto.AuditRecord, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetAuditRecords")
+	defer func() { tracing.End(span, err) }()
+
+	if err := authorizeScope(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+	if filter.StartDate < 0 {
+		return nil, ErrNegativeStartDate
+	}
+	if filter.EndDate < 0 {
+		return nil, ErrNegativeEndDate
+	}
+	if filter.Limit < 0 {
+		return nil, ErrNotPositiveLimit
+	}
+	if filter.Limit == 0 {
+		filter.Limit = consts.OperationsLimitDefault
+	}
+	if filter.Offset < 0 {
+		return nil, ErrNegativeOffset
+	}
+
+	records, err := s.repo.GetAuditRecords(ctx, filter)
+	if err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+	return records, nil
+}
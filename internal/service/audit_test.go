@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ezhdanovskiy/wallets/internal/audit"
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+func TestService_audited(t *testing.T) {
+	req := dto.CreateWalletRequest{Name: testWalletName01}
+	source := audit.Source{RequestID: "req-1", IP: "10.0.0.1", Endpoint: "POST /v1/wallets"}
+	ctx := audit.NewContext(context.Background(), source)
+	ctx = auth.NewContext(ctx, &auth.Caller{ID: "api_key:payouts", Scopes: []string{auth.ScopeAdmin}})
+
+	t.Run("record in the same transaction", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.expectTx()
+		ts.mockRepo.EXPECT().CreateWalletTx(gomock.Any(), gomock.Any(), testWalletName01, "").Return(nil)
+		ts.mockRepo.EXPECT().InsertAuditRecordTx(gomock.Any(), gomock.Any(), dto.AuditRecord{
+			Action:      audit.ActionCreateWallet,
+			Actor:       "api_key:payouts",
+			RequestID:   source.RequestID,
+			SourceIP:    source.IP,
+			Endpoint:    source.Endpoint,
+			PayloadHash: audit.PayloadHash(req),
+			Result:      audit.ResultOK,
+		}).Return(nil)
+
+		assert.NoError(t, ts.svc.CreateWallet(ctx, req))
+	})
+
+	t.Run("audit insert error rolls back the action", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.expectTx()
+		ts.mockRepo.EXPECT().CreateWalletTx(gomock.Any(), gomock.Any(), testWalletName01, "").Return(nil)
+		ts.mockRepo.EXPECT().InsertAuditRecordTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(sql.ErrConnDone)
+		ts.expectAudit(audit.ActionCreateWallet, audit.ResultError)
+
+		err := ts.svc.CreateWallet(ctx, req)
+		assert.Equal(t, ErrDatabase.Wrap(fmt.Errorf("insert audit record: %w", sql.ErrConnDone)), err)
+	})
+
+	t.Run("failure recorded with error", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.expectTx()
+		ts.mockRepo.EXPECT().CreateWalletTx(gomock.Any(), gomock.Any(), testWalletName01, "").Return(sql.ErrConnDone)
+		ts.mockRepo.EXPECT().InsertAuditRecord(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, record dto.AuditRecord) error {
+				assert.Equal(t, audit.ResultError, record.Result)
+				assert.Equal(t, ErrDatabase.Wrap(sql.ErrConnDone).Error(), record.Error)
+				return sql.ErrConnDone
+			})
+
+		assert.Equal(t, ErrDatabase.Wrap(sql.ErrConnDone), ts.svc.CreateWallet(ctx, req))
+	})
+
+	t.Run("invalid request recorded", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		invalid := dto.CreateWalletRequest{Owner: "alice"}
+		ts.mockRepo.EXPECT().InsertAuditRecord(gomock.Any(), dto.AuditRecord{
+			Action:      audit.ActionCreateWallet,
+			Actor:       "api_key:payouts",
+			RequestID:   source.RequestID,
+			SourceIP:    source.IP,
+			Endpoint:    source.Endpoint,
+			PayloadHash: audit.PayloadHash(invalid),
+			Result:      audit.ResultError,
+			Error:       ErrEmptyWalletName.Error(),
+		}).Return(nil)
+
+		assert.Equal(t, ErrEmptyWalletName, ts.svc.CreateWallet(ctx, invalid))
+	})
+
+	t.Run("denied request recorded", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		reader := auth.NewContext(ctx, &auth.Caller{ID: "api_key:reports", Scopes: []string{auth.ScopeRead}})
+		ts.expectTx()
+		ts.mockRepo.EXPECT().InsertAuditRecord(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, record dto.AuditRecord) error {
+				assert.Equal(t, "api_key:reports", record.Actor)
+				assert.Equal(t, audit.ResultError, record.Result)
+				assert.Equal(t, ErrPermissionDenied.Error(), record.Error)
+				return nil
+			})
+
+		assert.Equal(t, ErrPermissionDenied, ts.svc.CreateWallet(reader, req))
+	})
+}
+
+func TestService_GetAuditRecords(t *testing.T) {
+	t.Run("not admin", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeRead}})
+		records, err := ts.svc.GetAuditRecords(ctx, dto.AuditFilter{})
+		assert.Nil(t, records)
+		assert.Equal(t, ErrPermissionDenied, err)
+	})
+
+	t.Run("negative offset", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		_, err := ts.svc.GetAuditRecords(context.Background(), dto.AuditFilter{Offset: -1})
+		assert.Equal(t, ErrNegativeOffset, err)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetAuditRecords(gomock.Any(), dto.AuditFilter{
+			Actor: "api_key:payouts",
+			Limit: consts.OperationsLimitDefault,
+		}).Return([]dto.AuditRecord{{Action: audit.ActionTransfer}}, nil)
+
+		records, err := ts.svc.GetAuditRecords(context.Background(), dto.AuditFilter{Actor: "api_key:payouts"})
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, audit.ActionTransfer, records[0].Action)
+	})
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/consistency"
+)
+
+// authorizeScope checks that the caller has at least one of the scopes.
+// Calls without a caller in the context are trusted.
+func authorizeScope(ctx context.Context, scopes ...string) error {
+	caller, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if !caller.HasScope(scopes...) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// authorize checks that the caller has at least one of the scopes and may access the wallet.
+func authorize(ctx context.Context, walletName, owner string, scopes ...string) error {
+	if err := authorizeScope(ctx, scopes...); err != nil {
+		return err
+	}
+	caller, ok := auth.FromContext(ctx)
+	if ok && !caller.CanAccessWallet(walletName, owner) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// authorizeWallet is like authorize, but it loads the wallet owner when the caller allow-list depends on it.
+// Unknown wallets are reported as forbidden to not disclose which wallets exist. The owner is read from
+// the primary database, so the wallet created by the previous request is accessible.
+func (s *Service) authorizeWallet(ctx context.Context, walletName string, scopes ...string) error {
+	if err := authorizeScope(ctx, scopes...); err != nil {
+		return err
+	}
+	caller, ok := auth.FromContext(ctx)
+	if !ok || caller.CanAccessWallet(walletName, "") {
+		return nil
+	}
+	if !caller.RestrictedByOwner() {
+		return ErrPermissionDenied
+	}
+
+	wallet, err := s.repo.GetWallet(consistency.Strong(ctx), walletName)
+	if err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+	if wallet == nil || !caller.CanAccessWallet(wallet.Name, wallet.Owner) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// walletNotFound returns notFound for the missing wallet if the caller may access the wallet by its name,
+// otherwise it returns ErrPermissionDenied like for an existing wallet, so which wallets exist isn't disclosed.
+func walletNotFound(ctx context.Context, walletName string, notFound error, scopes ...string) error {
+	if err := authorize(ctx, walletName, "", scopes...); err != nil {
+		return err
+	}
+	return notFound
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ezhdanovskiy/wallets/internal/audit"
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/consistency"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/httperr"
+)
+
+func TestService_Transfer_authorization(t *testing.T) {
+	transfer := dto.Transfer{
+		WalletFrom: testWalletName01,
+		WalletTo:   testWalletName02,
+		Amount:     testAmount,
+	}
+	wallets := []dto.Wallet{
+		{Name: testWalletName01, Owner: "alice", Balance: testAmount.GetInt()},
+		{Name: testWalletName02, Owner: "bob"},
+	}
+
+	runTx := func(ts TestService) {
+		ts.expectTx()
+		ts.mockRepo.EXPECT().GetWalletsForUpdateTx(gomock.Any(), gomock.Any(), []string{testWalletName01, testWalletName02}).
+			Return(wallets, nil)
+	}
+
+	t.Run("no transfer scope", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.expectTx()
+		ts.expectAudit(audit.ActionTransfer, audit.ResultError)
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeRead}, Wallets: []string{auth.AllWallets}})
+		assert.Equal(t, ErrPermissionDenied, ts.svc.Transfer(ctx, transfer))
+	})
+
+	t.Run("wallet_from not allowed", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+		runTx(ts)
+		ts.expectAudit(audit.ActionTransfer, audit.ResultError)
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeTransfer}, Wallets: []string{testWalletName02}})
+		assert.Equal(t, ErrPermissionDenied, ts.svc.Transfer(ctx, transfer))
+	})
+
+	t.Run("allowed by owner", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+		runTx(ts)
+		ts.mockRepo.EXPECT().TransferTx(gomock.Any(), gomock.Any(), testWalletName01, testWalletName02, testAmount.GetInt()).Return(nil)
+		ts.expectAudit(audit.ActionTransfer, audit.ResultOK)
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeTransfer}, Owners: []string{"alice"}})
+		assert.NoError(t, ts.svc.Transfer(ctx, transfer))
+	})
+
+	// A caller not allowed to debit the sender can't tell whether the sender or the receiver exists.
+	for name, found := range map[string][]dto.Wallet{
+		"both missing not disclosed":     nil,
+		"receiver missing not disclosed": wallets[:1],
+		"sender missing not disclosed":   wallets[1:],
+	} {
+		t.Run(name, func(t *testing.T) {
+			ts := newTestService(t)
+			defer ts.Finish()
+			ts.expectTx()
+			ts.mockRepo.EXPECT().GetWalletsForUpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(found, nil)
+			ts.expectAudit(audit.ActionTransfer, audit.ResultError)
+
+			ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeTransfer}, Owners: []string{"bob"}})
+			assert.Equal(t, ErrPermissionDenied, ts.svc.Transfer(ctx, transfer))
+		})
+	}
+
+	t.Run("missing receiver reported to sender", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+		ts.expectTx()
+		ts.mockRepo.EXPECT().GetWalletsForUpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(wallets[:1], nil)
+		ts.expectAudit(audit.ActionTransfer, audit.ResultError)
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeTransfer}, Owners: []string{"alice"}})
+		err := ts.svc.Transfer(ctx, transfer)
+		assert.Equal(t, httperr.New(http.StatusNotFound, "%s not found", testWalletName02), err)
+	})
+}
+
+func TestService_GetOperations_authorization(t *testing.T) {
+	filter := dto.OperationsFilter{Wallet: testWalletName01}
+
+	t.Run("no read scope", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeDeposit}, Wallets: []string{auth.AllWallets}})
+		_, err := ts.svc.GetOperations(ctx, filter)
+		assert.Equal(t, ErrPermissionDenied, err)
+	})
+
+	t.Run("wallet not allowed", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeRead}, Wallets: []string{testWalletName02}})
+		_, err := ts.svc.GetOperations(ctx, filter)
+		assert.Equal(t, ErrPermissionDenied, err)
+	})
+
+	t.Run("owner not allowed", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).Return(&dto.Wallet{Name: testWalletName01, Owner: "bob"}, nil)
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeRead}, Owners: []string{"alice"}})
+		_, err := ts.svc.GetOperations(ctx, filter)
+		assert.Equal(t, ErrPermissionDenied, err)
+	})
+
+	t.Run("allowed by owner", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		// The owner is read from the primary, the wallet may be just created.
+		strongCtx := gomock.Cond(func(x any) bool { return consistency.IsStrong(x.(context.Context)) })
+		ts.mockRepo.EXPECT().GetWallet(strongCtx, testWalletName01).Return(&dto.Wallet{Name: testWalletName01, Owner: "alice"}, nil)
+		ts.mockRepo.EXPECT().GetOperations(gomock.Any(), gomock.Any()).Return([]dto.Operation{}, nil)
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeRead}, Owners: []string{"alice"}})
+		_, err := ts.svc.GetOperations(ctx, filter)
+		assert.NoError(t, err)
+	})
+}
+
+func TestService_IncreaseWalletBalance_authorization(t *testing.T) {
+	ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeDeposit}, Owners: []string{"alice"}})
+	deposit := dto.Deposit{Wallet: testWalletName01, Amount: testAmount}
+
+	t.Run("owner not allowed", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.expectTx()
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).Return(&dto.Wallet{Name: testWalletName01, Owner: "bob"}, nil)
+		ts.expectAudit(audit.ActionDeposit, audit.ResultError)
+
+		assert.Equal(t, ErrPermissionDenied, ts.svc.IncreaseWalletBalance(ctx, deposit))
+	})
+
+	t.Run("missing wallet not disclosed", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.expectTx()
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).Return(nil, nil)
+		ts.expectAudit(audit.ActionDeposit, audit.ResultError)
+
+		assert.Equal(t, ErrPermissionDenied, ts.svc.IncreaseWalletBalance(ctx, deposit))
+	})
+}
+
+func TestService_GetWallet_authorization(t *testing.T) {
+	t.Run("missing wallet not disclosed", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).Return(nil, nil)
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeRead}, Owners: []string{"alice"}})
+		_, err := ts.svc.GetWallet(ctx, testWalletName01)
+		assert.Equal(t, ErrPermissionDenied, err)
+	})
+
+	t.Run("missing wallet of the allow-list", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).Return(nil, nil)
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeRead}, Wallets: []string{testWalletName01}})
+		_, err := ts.svc.GetWallet(ctx, testWalletName01)
+		assert.Equal(t, ErrWalletNotFound, err)
+	})
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/consistency"
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+	"github.com/ezhdanovskiy/wallets/internal/tracing"
+)
+
+// GetBalance computes the balance of the wallet including all operations created until at, zero at is now.
+func (s *Service) GetBalance(ctx context.Context, walletName string, at time.Time) (_ *dto.Balance, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetBalance")
+	defer func() { tracing.End(span, err) }()
+
+	if walletName == "" {
+		return nil, ErrEmptyWalletName
+	}
+	now := time.Now().UTC()
+	if at.IsZero() {
+		at = now.Truncate(time.Microsecond)
+	}
+	if at.After(now) {
+		return nil, ErrFutureBalanceTime
+	}
+	if err := s.authorizeWallet(ctx, walletName, auth.ScopeRead); err != nil {
+		return nil, err
+	}
+	if err := s.checkWalletExists(ctx, walletName); err != nil {
+		return nil, err
+	}
+
+	balance, _, err := s.balanceAt(ctx, walletName, at)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.Balance{Wallet: walletName, At: at, Balance: dto.Amount(balance) / 100}, nil
+}
+
+// GetDailyBalances provides the closing balances of the wallet for each day of the filter.
+func (s *Service) GetDailyBalances(ctx context.Context, filter dto.DailyBalancesFilter) (_ []dto.DailyBalance, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetDailyBalances")
+	defer func() { tracing.End(span, err) }()
+
+	if filter.Wallet == "" {
+		return nil, ErrEmptyWalletName
+	}
+	loc := filter.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	today := startOfDay(time.Now(), loc)
+	end := today
+	if !filter.EndDate.IsZero() {
+		end = startOfDay(filter.EndDate, loc)
+	}
+	if end.After(today) {
+		return nil, ErrFutureEndDate
+	}
+	start := end.AddDate(0, 0, 1-consts.BalanceDaysDefault)
+	if !filter.StartDate.IsZero() {
+		start = startOfDay(filter.StartDate, loc)
+	}
+	if start.After(end) {
+		return nil, ErrWrongDateRange
+	}
+	// Days are rounded, a day with a daylight saving time transition is not 24 hours long.
+	if days := int(math.Round(end.Sub(start).Hours()/24)) + 1; days > consts.BalanceDaysMax {
+		return nil, ErrTooManyBalanceDays
+	}
+	if err := s.authorizeWallet(ctx, filter.Wallet, auth.ScopeRead); err != nil {
+		return nil, err
+	}
+	if err := s.checkWalletExists(ctx, filter.Wallet); err != nil {
+		return nil, err
+	}
+
+	balance, _, err := s.balanceAt(ctx, filter.Wallet, start.Add(-time.Microsecond))
+	if err != nil {
+		return nil, err
+	}
+
+	series := []dto.DailyBalance{}
+	day, next := start, start.AddDate(0, 0, 1)
+	closeDay := func() {
+		series = append(series, dto.DailyBalance{Date: day.Format(time.DateOnly), Balance: dto.Amount(balance) / 100})
+		day, next = next, next.AddDate(0, 0, 1)
+	}
+	operations := dto.OperationsFilter{Wallet: filter.Wallet, StartDate: start, EndDate: end.AddDate(0, 0, 1).Add(-time.Microsecond)}
+	err = s.repo.ScanOperations(ctx, operations, func(op dto.Operation) error {
+		for !op.Timestamp.Before(next) {
+			closeDay()
+		}
+		if op.Type == consts.OperationTypeWithdrawal {
+			balance -= cents(op.Amount)
+		} else {
+			balance += cents(op.Amount)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+	for !day.After(end) {
+		closeDay()
+	}
+	return series, nil
+}
+
+// TakeBalanceSnapshots stores the balances of all wallets at the time, so balances after it are computed
+// from the snapshots instead of the whole history. The time must be at least an hour in the past,
+// wallets without operations since the previous snapshot are skipped, the number of taken snapshots is returned.
+func (s *Service) TakeBalanceSnapshots(ctx context.Context, at time.Time) (n int, err error) {
+	ctx, span := tracing.Start(ctx, "Service.TakeBalanceSnapshots")
+	defer func() { tracing.End(span, err) }()
+
+	if err := authorizeScope(ctx, auth.ScopeAdmin); err != nil {
+		return 0, err
+	}
+	if at.After(dto.BalanceSnapshotTime(time.Now())) {
+		return 0, ErrRecentBalanceSnapshot
+	}
+	// Snapshots are never updated, they must include all committed operations, a replica may lag behind.
+	ctx = consistency.Strong(ctx)
+
+	for offset := int64(0); ; offset += consts.WalletsLimitDefault {
+		wallets, err := s.repo.ListWallets(ctx, dto.WalletsFilter{Limit: consts.WalletsLimitDefault, Offset: offset})
+		if err != nil {
+			return n, ErrDatabase.Wrap(err)
+		}
+
+		for _, wallet := range wallets {
+			if ctx.Err() != nil {
+				return n, ctx.Err()
+			}
+			balance, operations, err := s.balanceAt(ctx, wallet.Name, at)
+			if err != nil {
+				return n, fmt.Errorf("wallet %s: %w", wallet.Name, err)
+			}
+			if operations == 0 {
+				continue
+			}
+			snapshot := dto.BalanceSnapshot{Wallet: wallet.Name, TakenAt: at, Balance: uint64(balance)}
+			if err := s.repo.InsertBalanceSnapshot(ctx, snapshot); err != nil {
+				return n, ErrDatabase.Wrap(err)
+			}
+			n++
+		}
+
+		if len(wallets) < consts.WalletsLimitDefault {
+			logging.FromContext(ctx, s.log).With("at", at, "taken", n).Info("Balance snapshots taken")
+			return n, nil
+		}
+	}
+}
+
+// balanceAt computes the balance of the wallet in cents including all operations created until t,
+// zero t is the whole history. Operations before the latest snapshot aren't read, their number isn't counted
+// in the returned number of operations.
+func (s *Service) balanceAt(ctx context.Context, walletName string, t time.Time) (balance, operations int64, err error) {
+	filter := dto.OperationsFilter{Wallet: walletName, EndDate: t}
+	if !t.IsZero() {
+		snapshot, err := s.repo.GetLatestBalanceSnapshot(ctx, walletName, t)
+		if err != nil {
+			return 0, 0, ErrDatabase.Wrap(err)
+		}
+		if snapshot != nil {
+			balance = int64(snapshot.Balance)
+			filter.StartDate = snapshot.TakenAt.Add(time.Microsecond)
+		}
+	}
+
+	totals, err := s.repo.GetOperationsTotals(ctx, filter)
+	if err != nil {
+		return 0, 0, ErrDatabase.Wrap(err)
+	}
+	return balance + totals.NetCents(), totals.Deposits.Count + totals.Withdrawals.Count, nil
+}
+
+// checkWalletExists returns ErrWalletNotFound if there is no such wallet.
+func (s *Service) checkWalletExists(ctx context.Context, walletName string) error {
+	wallet, err := s.repo.GetWallet(ctx, walletName)
+	if err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+	if wallet == nil {
+		return ErrWalletNotFound
+	}
+	return nil
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
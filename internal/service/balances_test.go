@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+func TestService_GetBalance(t *testing.T) {
+	at := time.Date(2026, 9, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("from snapshot", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		takenAt := time.Date(2026, 9, 10, 0, 0, 0, 0, time.UTC)
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).Return(&dto.Wallet{Name: testWalletName01}, nil)
+		ts.mockRepo.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), testWalletName01, at).
+			Return(&dto.BalanceSnapshot{Wallet: testWalletName01, TakenAt: takenAt, Balance: 1029}, nil)
+		ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), dto.OperationsFilter{Wallet: testWalletName01,
+			StartDate: takenAt.Add(time.Microsecond), EndDate: at}).Return(&dto.OperationsTotals{
+			Deposits:    dto.OperationsTotal{Count: 1, Cents: 29},
+			Withdrawals: dto.OperationsTotal{Count: 1, Cents: 58},
+		}, nil)
+
+		balance, err := ts.svc.GetBalance(context.Background(), testWalletName01, at)
+		require.NoError(t, err)
+		assert.Equal(t, &dto.Balance{Wallet: testWalletName01, At: at, Balance: 10}, balance)
+	})
+
+	t.Run("without snapshot", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).Return(&dto.Wallet{Name: testWalletName01}, nil)
+		ts.mockRepo.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), testWalletName01, gomock.Any()).Return(nil, nil)
+		ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, filter dto.OperationsFilter) (*dto.OperationsTotals, error) {
+				assert.True(t, filter.StartDate.IsZero())
+				return &dto.OperationsTotals{Deposits: dto.OperationsTotal{Count: 1, Cents: 500}}, nil
+			})
+
+		balance, err := ts.svc.GetBalance(context.Background(), testWalletName01, time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, dto.Amount(5), balance.Balance)
+		assert.WithinDuration(t, time.Now(), balance.At, time.Minute)
+	})
+
+	t.Run("unknown wallet", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).Return(nil, nil)
+
+		_, err := ts.svc.GetBalance(context.Background(), testWalletName01, at)
+		assert.Equal(t, ErrWalletNotFound, err)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{ID: "reader", Scopes: []string{auth.ScopeRead},
+			Wallets: []string{testWalletName02}})
+		for _, tt := range []struct {
+			wallet string
+			at     time.Time
+			want   error
+		}{
+			{"", at, ErrEmptyWalletName},
+			{testWalletName01, time.Now().Add(time.Hour), ErrFutureBalanceTime},
+			{testWalletName01, at, ErrPermissionDenied},
+		} {
+			_, err := ts.svc.GetBalance(ctx, tt.wallet, tt.at)
+			assert.Equal(t, tt.want, err, tt.wallet)
+		}
+	})
+}
+
+func TestService_GetDailyBalances(t *testing.T) {
+	t.Run("series", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		loc := time.FixedZone("UTC+3", 3*60*60)
+		start := time.Date(2026, 9, 1, 0, 0, 0, 0, loc)
+		end := time.Date(2026, 9, 4, 0, 0, 0, 0, loc)
+		operations := []dto.Operation{
+			{ID: 1, Wallet: testWalletName01, Amount: 0.29, Type: consts.OperationTypeDeposit, Timestamp: start.Add(time.Hour)},
+			{ID: 2, Wallet: testWalletName01, Amount: 0.29, Type: consts.OperationTypeDeposit, Timestamp: start.Add(2 * time.Hour)},
+			{ID: 3, Wallet: testWalletName01, Amount: 0.58, Type: consts.OperationTypeWithdrawal,
+				Timestamp: start.AddDate(0, 0, 2).Add(23 * time.Hour)},
+		}
+
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).Return(&dto.Wallet{Name: testWalletName01}, nil)
+		ts.mockRepo.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), testWalletName01, start.Add(-time.Microsecond)).
+			Return(nil, nil)
+		ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), dto.OperationsFilter{Wallet: testWalletName01,
+			EndDate: start.Add(-time.Microsecond)}).Return(&dto.OperationsTotals{Deposits: dto.OperationsTotal{Count: 1, Cents: 1000}}, nil)
+		ts.mockRepo.EXPECT().ScanOperations(gomock.Any(), dto.OperationsFilter{Wallet: testWalletName01,
+			StartDate: start, EndDate: end.AddDate(0, 0, 1).Add(-time.Microsecond)}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ dto.OperationsFilter, f func(dto.Operation) error) error {
+				for _, op := range operations {
+					if err := f(op); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+		// Bounds are days in the location whatever their time is.
+		series, err := ts.svc.GetDailyBalances(context.Background(), dto.DailyBalancesFilter{Wallet: testWalletName01,
+			StartDate: start.Add(5 * time.Hour), EndDate: end.Add(20 * time.Hour), Location: loc})
+		require.NoError(t, err)
+		assert.Equal(t, []dto.DailyBalance{
+			{Date: "2026-09-01", Balance: 10.58},
+			{Date: "2026-09-02", Balance: 10.58},
+			{Date: "2026-09-03", Balance: 10},
+			{Date: "2026-09-04", Balance: 10},
+		}, series)
+	})
+
+	t.Run("default period", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).Return(&dto.Wallet{Name: testWalletName01}, nil)
+		ts.mockRepo.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), testWalletName01, gomock.Any()).Return(nil, nil)
+		ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), gomock.Any()).Return(&dto.OperationsTotals{}, nil)
+		ts.mockRepo.EXPECT().ScanOperations(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+		series, err := ts.svc.GetDailyBalances(context.Background(), dto.DailyBalancesFilter{Wallet: testWalletName01})
+		require.NoError(t, err)
+		require.Len(t, series, consts.BalanceDaysDefault)
+		assert.Equal(t, time.Now().UTC().Format(time.DateOnly), series[len(series)-1].Date)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+		for _, tt := range []struct {
+			name   string
+			filter dto.DailyBalancesFilter
+			want   error
+		}{
+			{"empty wallet", dto.DailyBalancesFilter{}, ErrEmptyWalletName},
+			{"future", dto.DailyBalancesFilter{Wallet: testWalletName01, EndDate: time.Now().AddDate(0, 0, 2)}, ErrFutureEndDate},
+			{"reversed", dto.DailyBalancesFilter{Wallet: testWalletName01, StartDate: day, EndDate: day.AddDate(0, 0, -1)},
+				ErrWrongDateRange},
+			{"too long", dto.DailyBalancesFilter{Wallet: testWalletName01, StartDate: day.AddDate(0, 0, -consts.BalanceDaysMax),
+				EndDate: day}, ErrTooManyBalanceDays},
+		} {
+			_, err := ts.svc.GetDailyBalances(context.Background(), tt.filter)
+			assert.Equal(t, tt.want, err, tt.name)
+		}
+	})
+}
+
+func TestService_TakeBalanceSnapshots(t *testing.T) {
+	at := time.Date(2026, 9, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("take", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().ListWallets(gomock.Any(), dto.WalletsFilter{Limit: consts.WalletsLimitDefault}).
+			Return([]dto.Wallet{{Name: testWalletName01}, {Name: testWalletName02}}, nil)
+		ts.mockRepo.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), testWalletName01, at).Return(nil, nil)
+		ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), dto.OperationsFilter{Wallet: testWalletName01, EndDate: at}).
+			Return(&dto.OperationsTotals{Deposits: dto.OperationsTotal{Count: 2, Cents: 1058}}, nil)
+		ts.mockRepo.EXPECT().InsertBalanceSnapshot(gomock.Any(),
+			dto.BalanceSnapshot{Wallet: testWalletName01, TakenAt: at, Balance: 1058}).Return(nil)
+		// The second wallet has no operations since its snapshot, the snapshot is still valid.
+		takenAt := at.AddDate(0, 0, -1)
+		ts.mockRepo.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), testWalletName02, at).
+			Return(&dto.BalanceSnapshot{Wallet: testWalletName02, TakenAt: takenAt, Balance: 500}, nil)
+		ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), dto.OperationsFilter{Wallet: testWalletName02,
+			StartDate: takenAt.Add(time.Microsecond), EndDate: at}).Return(&dto.OperationsTotals{}, nil)
+
+		n, err := ts.svc.TakeBalanceSnapshots(context.Background(), at)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("recent", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		_, err := ts.svc.TakeBalanceSnapshots(context.Background(), time.Now().Add(-time.Minute))
+		assert.Equal(t, ErrRecentBalanceSnapshot, err)
+	})
+
+	t.Run("not admin", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{ID: "reader", Scopes: []string{auth.ScopeRead}})
+		_, err := ts.svc.TakeBalanceSnapshots(ctx, at)
+		assert.Equal(t, ErrPermissionDenied, err)
+	})
+}
@@ -1,21 +1,55 @@
 package service
 
 import (
-	"github.com/jmoiron/sqlx"
+	"context"
+	"time"
 
 	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/ledger"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
 )
 
 // Repository describes the repository methods required for the service.
 type Repository interface {
-	CreateWallet(walletName string) error
-	GetWallet(walletName string) (*dto.Wallet, error)
-	IncreaseWalletBalance(walletName string, amount uint64) error
-	GetOperations(dto.OperationsFilter) ([]dto.Operation, error)
-
-	RunWithTransaction(func(tx *sqlx.Tx) error) error
-	GetWalletsForUpdateTx(tx *sqlx.Tx, walletNames []string) ([]dto.Wallet, error)
-	TransferTx(tx *sqlx.Tx, walletFrom, walletTo string, amount uint64) error
+	GetWallet(ctx context.Context, walletName string) (*dto.Wallet, error)
+	ListWallets(ctx context.Context, filter dto.WalletsFilter) ([]dto.Wallet, error)
+	GetOperations(ctx context.Context, filter dto.OperationsFilter) ([]dto.Operation, error)
+	ScanOperations(ctx context.Context, filter dto.OperationsFilter, f func(dto.Operation) error) error
+	GetOperationsTotals(ctx context.Context, filter dto.OperationsFilter) (*dto.OperationsTotals, error)
+
+	RunWithTransaction(ctx context.Context, f func(ctx context.Context, tx storage.Tx) error) error
+	CreateWalletTx(ctx context.Context, tx storage.Tx, walletName, owner string) error
+	IncreaseWalletBalanceTx(ctx context.Context, tx storage.Tx, walletName string, amount uint64) error
+	GetWalletsForUpdateTx(ctx context.Context, tx storage.Tx, walletNames []string) ([]dto.Wallet, error)
+	TransferTx(ctx context.Context, tx storage.Tx, walletFrom, walletTo string, amount uint64) error
+
+	CreateAPIKeyTx(ctx context.Context, tx storage.Tx, key dto.APIKey, keyHash string) (*dto.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]dto.APIKey, error)
+	RevokeAPIKeyTx(ctx context.Context, tx storage.Tx, name string) (bool, error)
+
+	InsertAuditRecordTx(ctx context.Context, tx storage.Tx, record dto.AuditRecord) error
+	InsertAuditRecord(ctx context.Context, record dto.AuditRecord) error
+	GetAuditRecords(ctx context.Context, filter dto.AuditFilter) ([]dto.AuditRecord, error)
+
+	ScanLedger(ctx context.Context, f func(ledger.Entry) error) error
+	InsertLedgerCheckpointTx(ctx context.Context, tx storage.Tx, cp dto.LedgerCheckpoint) (*dto.LedgerCheckpoint, error)
+	GetLedgerCheckpoints(ctx context.Context, limit int64) ([]dto.LedgerCheckpoint, error)
+	GetLatestLedgerCheckpoint(ctx context.Context) (*dto.LedgerCheckpoint, error)
+
+	CreateImportJobTx(ctx context.Context, tx storage.Tx, job dto.ImportJob, rows []dto.ImportRow) (*dto.ImportJob, error)
+	GetImportJob(ctx context.Context, id int64) (*dto.ImportJob, error)
+	GetImportRows(ctx context.Context, jobID int64) ([]dto.ImportRow, error)
+	ClaimImportJob(ctx context.Context, lease time.Duration) (*dto.ImportJob, error)
+	GetPendingImportRowsTx(ctx context.Context, tx storage.Tx, jobID int64, limit int) ([]dto.ImportRow, error)
+	ImportKeyAppliedTx(ctx context.Context, tx storage.Tx, key string) (bool, error)
+	UpdateImportRowTx(ctx context.Context, tx storage.Tx, row dto.ImportRow) error
+	UpdateImportJobTx(ctx context.Context, tx storage.Tx, job dto.ImportJob) error
+
+	InsertStatement(ctx context.Context, st dto.Statement) error
+	GetStatement(ctx context.Context, walletName, period string) (*dto.Statement, error)
+
+	InsertBalanceSnapshot(ctx context.Context, snapshot dto.BalanceSnapshot) error
+	GetLatestBalanceSnapshot(ctx context.Context, walletName string, at time.Time) (*dto.BalanceSnapshot, error)
 }
 
 //go:generate mockgen -destination=./mocks/repository_mock.go -package=mocks . Repository
@@ -3,20 +3,50 @@ package service
 import (
 	"net/http"
 
+	"github.com/ezhdanovskiy/wallets/internal/consts"
 	"github.com/ezhdanovskiy/wallets/internal/httperr"
 )
 
 var (
+	ErrAPIKeyAlreadyExists      = httperr.New(http.StatusConflict, "api key already exists")
+	ErrAPIKeyNotFound           = httperr.New(http.StatusNotFound, "api key not found")
+	ErrCursorWithOffset         = httperr.New(http.StatusBadRequest, "cursor can't be combined with offset")
 	ErrDatabase                 = httperr.New(http.StatusInternalServerError, "database error")
+	ErrEmptyAPIKeyName          = httperr.New(http.StatusBadRequest, "empty api key name")
+	ErrEmptyScopes              = httperr.New(http.StatusBadRequest, "empty scopes")
 	ErrEmptyWalletFrom          = httperr.New(http.StatusBadRequest, "empty wallet_from")
 	ErrEmptyWalletName          = httperr.New(http.StatusBadRequest, "empty wallet name")
 	ErrEmptyWalletTo            = httperr.New(http.StatusBadRequest, "empty wallet_to")
+	ErrFutureBalanceTime        = httperr.New(http.StatusBadRequest, "at can't be in the future")
+	ErrFutureEndDate            = httperr.New(http.StatusBadRequest, "end_date can't be in the future")
+	ErrFutureStatementPeriod    = httperr.New(http.StatusBadRequest, "period can't be in the future")
+	ErrImportNotFound           = httperr.New(http.StatusNotFound, "import not found")
+	ErrInternal                 = httperr.New(http.StatusInternalServerError, "internal error")
+	ErrLedgerBroken             = httperr.New(http.StatusConflict, "ledger hash chain is broken")
+	ErrLedgerSigningDisabled    = httperr.New(http.StatusNotImplemented, "ledger signing key is not configured")
+	ErrOpenStatementPeriod      = httperr.New(http.StatusBadRequest, "statements are generated only for months ended at least an hour ago")
+	ErrPermissionDenied         = httperr.New(http.StatusForbidden, "permission denied")
+	ErrRecentBalanceSnapshot    = httperr.New(http.StatusBadRequest, "balance snapshots are taken only for days ended at least an hour ago")
 	ErrSameWallets              = httperr.New(http.StatusBadRequest, "same wallets")
 	ErrNegativeEndDate          = httperr.New(http.StatusBadRequest, "end_date can't be negative")
+	ErrNegativeMaxAmount        = httperr.New(http.StatusBadRequest, "max_amount can't be negative")
+	ErrNegativeMinAmount        = httperr.New(http.StatusBadRequest, "min_amount can't be negative")
 	ErrNegativeOffset           = httperr.New(http.StatusBadRequest, "offset can't be negative")
 	ErrNegativeStartDate        = httperr.New(http.StatusBadRequest, "start_date can't be negative")
 	ErrNotPositiveAmount        = httperr.New(http.StatusBadRequest, "amount must be positive")
 	ErrNotPositiveLimit         = httperr.New(http.StatusBadRequest, "limit must be positive")
+	ErrTooManyBalanceDays       = httperr.New(http.StatusBadRequest, "too many days, at most %d are allowed", consts.BalanceDaysMax)
+	ErrTooManyImportRows        = httperr.New(http.StatusBadRequest, "too many rows in the import file, at most %d are allowed", consts.ImportRowsMax)
+	ErrUnsupportedImportKind    = httperr.New(http.StatusBadRequest, "unsupported kind of import, it have to be deposits or transfers")
 	ErrUnsupportedOperationType = httperr.New(http.StatusBadRequest, "unsupported operation type")
+	ErrUnsupportedOrder         = httperr.New(http.StatusBadRequest, "unsupported order, it have to be asc or desc")
+	ErrUnsupportedScope         = httperr.New(http.StatusBadRequest, "unsupported scope")
 	ErrWalletNotFound           = httperr.New(http.StatusBadRequest, "wallet not found")
+	ErrWrongChunkSize           = httperr.New(http.StatusBadRequest, "wrong chunk_size, it have to be in [1, %d]", consts.ImportChunkSizeMax)
+	ErrWrongAmount              = httperr.New(http.StatusBadRequest, "wrong amount, it have to be a number up to 92233720368547758.07")
+	ErrWrongAmountRange         = httperr.New(http.StatusBadRequest, "min_amount can't be greater than max_amount")
+	ErrWrongMaxAmount           = httperr.New(http.StatusBadRequest, "wrong max_amount, it have to be a number up to 92233720368547758.07")
+	ErrWrongMinAmount           = httperr.New(http.StatusBadRequest, "wrong min_amount, it have to be a number up to 92233720368547758.07")
+	ErrWrongDateRange           = httperr.New(http.StatusBadRequest, "start_date can't be after end_date")
+	ErrWrongStatementPeriod     = httperr.New(http.StatusBadRequest, "wrong period, it have to be a month in YYYY-MM format")
 )
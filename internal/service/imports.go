@@ -0,0 +1,408 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/audit"
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/consistency"
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/httperr"
+	"github.com/ezhdanovskiy/wallets/internal/imports"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
+	"github.com/ezhdanovskiy/wallets/internal/tracing"
+)
+
+// importLease is the time after which a running import whose progress isn't updated is taken over,
+// e.g. when the instance applying it is stopped.
+const importLease = time.Minute
+
+// CreateImport validates every row of the import file and creates the job applying them, rows are applied
+// by ProcessImportJobs. The job isn't created for a dry run or if the file has errors, they are reported by lines.
+// The caller must be allowed to deposit to or to transfer from the wallets of the rows.
+func (s *Service) CreateImport(ctx context.Context, req dto.ImportRequest) (_ *dto.ImportResult, err error) {
+	ctx, span := tracing.Start(ctx, "Service.CreateImport")
+	defer func() { tracing.End(span, err) }()
+
+	// The file hash is known once the file is parsed, the requests rejected before have only the kind.
+	payload := struct {
+		Kind     string `json:"kind"`
+		FileHash string `json:"file_hash"`
+	}{Kind: req.Kind}
+
+	scope, ok := map[string]string{
+		consts.ImportKindDeposits:  auth.ScopeDeposit,
+		consts.ImportKindTransfers: auth.ScopeTransfer,
+	}[req.Kind]
+	if !ok {
+		return nil, s.auditRejected(ctx, audit.ActionCreateImport, payload, ErrUnsupportedImportKind)
+	}
+	if req.ChunkSize == 0 {
+		req.ChunkSize = consts.ImportChunkSizeDefault
+	}
+	if req.ChunkSize < 0 || req.ChunkSize > consts.ImportChunkSizeMax {
+		return nil, s.auditRejected(ctx, audit.ActionCreateImport, payload, ErrWrongChunkSize)
+	}
+	if err := authorizeScope(ctx, scope); err != nil {
+		return nil, s.auditRejected(ctx, audit.ActionCreateImport, payload, err)
+	}
+
+	file, err := imports.Parse(req.File, req.Kind, req.Delimiter)
+	if err != nil {
+		return nil, ErrInternal.Wrap(err)
+	}
+	payload.FileHash = file.Hash
+	if file.Total > consts.ImportRowsMax {
+		return nil, s.auditRejected(ctx, audit.ActionCreateImport, payload, ErrTooManyImportRows)
+	}
+
+	report := dto.ImportReport{Kind: req.Kind, Rows: file.Total, Errors: file.Errors}
+	if err := s.checkImportWallets(ctx, req.Kind, scope, file.Rows, &report); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(report.Errors, func(i, j int) bool { return report.Errors[i].Line < report.Errors[j].Line })
+	if len(report.Errors) > consts.ImportErrorsMax {
+		report.Errors = report.Errors[:consts.ImportErrorsMax]
+		report.Truncated = true
+	}
+	if report.Errors == nil {
+		report.Errors = []dto.ImportError{}
+	}
+
+	result := &dto.ImportResult{Report: report}
+	if req.DryRun || len(report.Errors) > 0 {
+		return result, nil
+	}
+
+	job := dto.ImportJob{
+		Kind:      req.Kind,
+		Status:    consts.ImportStatusPending,
+		FileHash:  file.Hash,
+		ChunkSize: req.ChunkSize,
+		Rows:      len(file.Rows),
+		RequestID: audit.FromContext(ctx).RequestID,
+	}
+	if caller, ok := auth.FromContext(ctx); ok {
+		job.CreatedBy = caller.ID
+	}
+	err = s.audited(ctx, audit.ActionCreateImport, payload, func(ctx context.Context, tx storage.Tx) error {
+		created, err := s.repo.CreateImportJobTx(ctx, tx, job, file.Rows)
+		if err != nil {
+			return ErrDatabase.Wrap(err)
+		}
+		result.Job = created
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx, s.log).With("id", result.Job.ID, "kind", job.Kind, "rows", job.Rows).Info("Import created")
+	return result, nil
+}
+
+// checkImportWallets reports rows with unknown wallets or wallets the caller isn't allowed to use.
+// The receiving wallet of a transfer only has to exist.
+func (s *Service) checkImportWallets(ctx context.Context, kind, scope string, rows []dto.ImportRow, report *dto.ImportReport) error {
+	// Wallets may be created right before the import, a replica may not have them yet.
+	strongCtx := consistency.Strong(ctx)
+	wallets := make(map[string]*dto.Wallet)
+	wallet := func(name string) (*dto.Wallet, error) {
+		if w, ok := wallets[name]; ok {
+			return w, nil
+		}
+		w, err := s.repo.GetWallet(strongCtx, name)
+		if err != nil {
+			return nil, ErrDatabase.Wrap(err)
+		}
+		wallets[name] = w
+		return w, nil
+	}
+
+	for _, row := range rows {
+		names := []string{row.Wallet}
+		if kind == consts.ImportKindTransfers {
+			names = append(names, row.WalletTo)
+		}
+
+		for i, name := range names {
+			w, err := wallet(name)
+			if err != nil {
+				return err
+			}
+			if w == nil {
+				report.Errors = append(report.Errors, dto.ImportError{Line: row.Line, Error: fmt.Sprintf("wallet %s not found", name)})
+				break
+			}
+			if i == 0 && authorize(ctx, w.Name, w.Owner, scope) != nil {
+				report.Errors = append(report.Errors, dto.ImportError{Line: row.Line, Error: fmt.Sprintf("permission denied for wallet %s", name)})
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// GetImportJob provides the status of the import, it is available to its creator and admins.
+func (s *Service) GetImportJob(ctx context.Context, id int64) (_ *dto.ImportJob, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetImportJob")
+	defer func() { tracing.End(span, err) }()
+
+	return s.getImportJob(ctx, id)
+}
+
+// GetImportResult provides the import with its rows ordered by lines, the rows have the statuses of applying.
+func (s *Service) GetImportResult(ctx context.Context, id int64) (_ *dto.ImportJob, _ []dto.ImportRow, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetImportResult")
+	defer func() { tracing.End(span, err) }()
+
+	job, err := s.getImportJob(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := s.repo.GetImportRows(consistency.Strong(ctx), id)
+	if err != nil {
+		return nil, nil, ErrDatabase.Wrap(err)
+	}
+	return job, rows, nil
+}
+
+func (s *Service) getImportJob(ctx context.Context, id int64) (*dto.ImportJob, error) {
+	// The progress is polled right after the import is created and while it is applied.
+	job, err := s.repo.GetImportJob(consistency.Strong(ctx), id)
+	if err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+	// Imports of others are reported as missing to not disclose them.
+	caller, ok := auth.FromContext(ctx)
+	if job == nil || ok && job.CreatedBy != caller.ID && !caller.HasScope(auth.ScopeAdmin) {
+		return nil, ErrImportNotFound
+	}
+	return job, nil
+}
+
+// ProcessImportJobs applies pending imports until there are none left or ctx is done, it returns the number
+// of finished imports. Rows are applied in chunks, every chunk in a transaction together with the progress,
+// so a stopped import continues from the first pending row when its lease expires.
+func (s *Service) ProcessImportJobs(ctx context.Context) (n int, err error) {
+	ctx, span := tracing.Start(ctx, "Service.ProcessImportJobs")
+	defer func() { tracing.End(span, err) }()
+
+	for ctx.Err() == nil {
+		job, err := s.repo.ClaimImportJob(ctx, importLease)
+		if err != nil {
+			return n, ErrDatabase.Wrap(err)
+		}
+		if job == nil {
+			return n, nil
+		}
+
+		for job.Status != consts.ImportStatusDone {
+			if err := s.processImportChunk(ctx, job); err != nil {
+				return n, fmt.Errorf("import %d: %w", job.ID, err)
+			}
+		}
+		n++
+		logging.FromContext(ctx, s.log).With("id", job.ID, "applied", job.Applied, "skipped", job.Skipped,
+			"failed", job.Failed).Info("Import finished")
+	}
+	return n, ctx.Err()
+}
+
+// processImportChunk applies the next chunk of pending rows of the job and updates its progress.
+// The rows are applied on behalf of the creator, whose access is checked again for every chunk.
+// Database errors, e.g. a serialization failure caused by a concurrent transfer, don't fail the rows:
+// the transaction is restarted by the repository and the chunk is applied again.
+func (s *Service) processImportChunk(ctx context.Context, job *dto.ImportJob) error {
+	creator, err := s.importCreator(ctx, job)
+	if err != nil {
+		return err
+	}
+	if creator != nil {
+		ctx = auth.NewContext(ctx, creator)
+	}
+
+	var updated dto.ImportJob
+	err = s.repo.RunWithTransaction(ctx, func(ctx context.Context, tx storage.Tx) error {
+		// The job is updated only after the commit, a failed chunk is applied again from the same progress.
+		updated = *job
+
+		rows, err := s.repo.GetPendingImportRowsTx(ctx, tx, job.ID, job.ChunkSize)
+		if err != nil {
+			return ErrDatabase.Wrap(err)
+		}
+
+		for _, row := range rows {
+			if err := s.applyImportRow(ctx, tx, job, &row); err != nil {
+				return err
+			}
+			switch row.Status {
+			case consts.ImportStatusApplied:
+				updated.Applied++
+			case consts.ImportStatusSkipped:
+				updated.Skipped++
+			default:
+				updated.Failed++
+			}
+			if err := s.repo.UpdateImportRowTx(ctx, tx, row); err != nil {
+				return ErrDatabase.Wrap(err)
+			}
+		}
+
+		updated.Status = consts.ImportStatusRunning
+		if len(rows) < job.ChunkSize {
+			now := time.Now()
+			updated.Status = consts.ImportStatusDone
+			updated.FinishedAt = &now
+		}
+		if err := s.repo.UpdateImportJobTx(ctx, tx, updated); err != nil {
+			return ErrDatabase.Wrap(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*job = updated
+	return nil
+}
+
+// importCreator loads the current permissions of the API key that created the job, a revoked key has none,
+// so the remaining rows fail. It returns nil for imports created by trusted calls or with JWTs,
+// their permissions are known only when the token is presented and can't be checked again.
+func (s *Service) importCreator(ctx context.Context, job *dto.ImportJob) (*auth.Caller, error) {
+	if job.CreatedBy == "" {
+		return nil, nil
+	}
+
+	// The key may be revoked right before the chunk, a replica may not have it yet.
+	keys, err := s.repo.ListAPIKeys(consistency.Strong(ctx))
+	if err != nil {
+		return nil, ErrDatabase.Wrap(fmt.Errorf("list api keys: %w", err))
+	}
+	for _, key := range keys {
+		if key.Name != job.CreatedBy {
+			continue
+		}
+		if key.RevokedAt != nil {
+			return &auth.Caller{ID: key.Name}, nil
+		}
+		return &auth.Caller{ID: key.Name, Scopes: key.Scopes, Wallets: key.Wallets, Owners: key.Owners}, nil
+	}
+	return nil, nil
+}
+
+// applyImportRow applies the row using transaction and sets its status, the row is skipped if its key is applied.
+// Problems of the row, like not enough money, fail only the row, other errors are returned.
+// Every applied or failed row is recorded in the audit log on behalf of the creator of the import.
+func (s *Service) applyImportRow(ctx context.Context, tx storage.Tx, job *dto.ImportJob, row *dto.ImportRow) error {
+	applied, err := s.repo.ImportKeyAppliedTx(ctx, tx, row.Key)
+	if err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+	if applied {
+		row.Status = consts.ImportStatusSkipped
+		row.Error = "idempotency key is already applied"
+		return nil
+	}
+
+	var amount dto.Amount
+	amount.SetAmount(row.Amount)
+	record := dto.AuditRecord{
+		Actor:     job.CreatedBy,
+		RequestID: job.RequestID,
+		Endpoint:  fmt.Sprintf("import %d", job.ID),
+		Result:    audit.ResultOK,
+	}
+
+	var rowErr error
+	if job.Kind == consts.ImportKindDeposits {
+		deposit := dto.Deposit{Wallet: row.Wallet, Amount: amount}
+		record.Action, record.PayloadHash = audit.ActionDeposit, audit.PayloadHash(deposit)
+		rowErr = s.authorizeImportRow(ctx, tx, row.Wallet, auth.ScopeDeposit)
+		if rowErr == nil {
+			if rowErr = s.repo.IncreaseWalletBalanceTx(ctx, tx, row.Wallet, row.Amount); rowErr != nil {
+				rowErr = ErrDatabase.Wrap(rowErr)
+			}
+		}
+		observeDeposit(amount, rowErr)
+	} else {
+		transfer := dto.Transfer{WalletFrom: row.Wallet, WalletTo: row.WalletTo, Amount: amount}
+		record.Action, record.PayloadHash = audit.ActionTransfer, audit.PayloadHash(transfer)
+		rowErr = s.authorizeImportRow(ctx, tx, row.Wallet, auth.ScopeTransfer)
+		if rowErr == nil {
+			rowErr = s.importTransfer(ctx, tx, *row)
+		}
+		observeTransfer(amount, rowErr)
+	}
+
+	var httpErr *httperr.Error
+	switch {
+	case rowErr == nil:
+		row.Status = consts.ImportStatusApplied
+	case errors.As(rowErr, &httpErr) && httpErr.StatusCode < http.StatusInternalServerError:
+		row.Status, row.Error = consts.ImportStatusFailed, rowErr.Error()
+		record.Result, record.Error = audit.ResultError, rowErr.Error()
+	default:
+		return rowErr
+	}
+
+	if err := s.repo.InsertAuditRecordTx(ctx, tx, record); err != nil {
+		return ErrDatabase.Wrap(fmt.Errorf("insert audit record: %w", err))
+	}
+	return nil
+}
+
+// authorizeImportRow is like authorizeWallet for the creator of the import, the owner is read using transaction.
+func (s *Service) authorizeImportRow(ctx context.Context, tx storage.Tx, walletName, scope string) error {
+	if err := authorizeScope(ctx, scope); err != nil {
+		return err
+	}
+	caller, ok := auth.FromContext(ctx)
+	if !ok || caller.CanAccessWallet(walletName, "") {
+		return nil
+	}
+	if !caller.RestrictedByOwner() {
+		return ErrPermissionDenied
+	}
+
+	wallets, err := s.repo.GetWalletsForUpdateTx(ctx, tx, []string{walletName})
+	if err != nil {
+		return ErrDatabase.Wrap(fmt.Errorf("get wallets for update: %w", err))
+	}
+	if len(wallets) == 0 || !caller.CanAccessWallet(wallets[0].Name, wallets[0].Owner) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// importTransfer transfers the amount of the row, the balance is checked before it changes,
+// so a failed row leaves the transaction usable for the next rows.
+func (s *Service) importTransfer(ctx context.Context, tx storage.Tx, row dto.ImportRow) error {
+	wallets, err := s.repo.GetWalletsForUpdateTx(ctx, tx, []string{row.Wallet, row.WalletTo})
+	if err != nil {
+		return ErrDatabase.Wrap(fmt.Errorf("get wallets for update: %w", err))
+	}
+	if len(wallets) < 2 {
+		return httperr.New(http.StatusNotFound, "wallets not found")
+	}
+	for _, w := range wallets {
+		if w.Name == row.Wallet && w.Balance < row.Amount {
+			return httperr.New(http.StatusUnprocessableEntity, "not enough money")
+		}
+	}
+
+	if err := s.repo.TransferTx(ctx, tx, row.Wallet, row.WalletTo, row.Amount); err != nil {
+		return ErrDatabase.Wrap(fmt.Errorf("transfer: %w", err))
+	}
+	return nil
+}
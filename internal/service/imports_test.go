@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ezhdanovskiy/wallets/internal/audit"
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+func TestService_CreateImport(t *testing.T) {
+	deposits := dto.ImportRequest{
+		Kind: consts.ImportKindDeposits,
+		File: []byte("wallet,amount\n" + testWalletName01 + ",10.50\n" + testWalletName02 + ",1\n"),
+	}
+
+	t.Run("unsupported kind", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+		ts.expectAudit(audit.ActionCreateImport, audit.ResultError)
+
+		_, err := ts.svc.CreateImport(context.Background(), dto.ImportRequest{Kind: "withdrawals"})
+		assert.Equal(t, ErrUnsupportedImportKind, err)
+	})
+
+	t.Run("wrong chunk size", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+		ts.expectAudit(audit.ActionCreateImport, audit.ResultError)
+
+		req := deposits
+		req.ChunkSize = consts.ImportChunkSizeMax + 1
+		_, err := ts.svc.CreateImport(context.Background(), req)
+		assert.Equal(t, ErrWrongChunkSize, err)
+	})
+
+	t.Run("permission denied", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+		ts.expectAudit(audit.ActionCreateImport, audit.ResultError)
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeTransfer}, Wallets: []string{auth.AllWallets}})
+		_, err := ts.svc.CreateImport(ctx, deposits)
+		assert.Equal(t, ErrPermissionDenied, err)
+	})
+
+	t.Run("errors by lines", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).Return(&dto.Wallet{Name: testWalletName01}, nil)
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName02).Return(nil, nil)
+
+		req := dto.ImportRequest{
+			Kind: consts.ImportKindDeposits,
+			File: []byte("wallet,amount\n" + testWalletName02 + ",1\n" + testWalletName01 + ",-1\n" + testWalletName01 + ",2\n"),
+		}
+		result, err := ts.svc.CreateImport(context.Background(), req)
+		require.NoError(t, err)
+		assert.Nil(t, result.Job)
+		assert.Equal(t, 3, result.Report.Rows)
+		assert.Equal(t, []dto.ImportError{
+			{Line: 2, Error: "wallet " + testWalletName02 + " not found"},
+			{Line: 3, Error: `amount "-1" must be a number with at most two decimals`},
+		}, result.Report.Errors)
+	})
+
+	t.Run("not allowed wallet", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).Return(&dto.Wallet{Name: testWalletName01}, nil)
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName02).Return(&dto.Wallet{Name: testWalletName02}, nil)
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeDeposit}, Wallets: []string{testWalletName01}})
+		result, err := ts.svc.CreateImport(ctx, deposits)
+		require.NoError(t, err)
+		assert.Nil(t, result.Job)
+		assert.Equal(t, []dto.ImportError{{Line: 3, Error: "permission denied for wallet " + testWalletName02}}, result.Report.Errors)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, name string) (*dto.Wallet, error) { return &dto.Wallet{Name: name}, nil }).
+			Times(2)
+
+		req := deposits
+		req.DryRun = true
+		result, err := ts.svc.CreateImport(context.Background(), req)
+		require.NoError(t, err)
+		assert.Nil(t, result.Job)
+		assert.Equal(t, dto.ImportReport{Kind: consts.ImportKindDeposits, Rows: 2, Errors: []dto.ImportError{}}, result.Report)
+	})
+
+	t.Run("database connection error", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).Return(nil, sql.ErrConnDone)
+
+		_, err := ts.svc.CreateImport(context.Background(), deposits)
+		assert.Equal(t, ErrDatabase.Wrap(sql.ErrConnDone), err)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, name string) (*dto.Wallet, error) { return &dto.Wallet{Name: name}, nil }).
+			Times(2)
+		ts.expectTx()
+		ts.mockRepo.EXPECT().CreateImportJobTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ any, job dto.ImportJob, rows []dto.ImportRow) (*dto.ImportJob, error) {
+				assert.Equal(t, consts.ImportStatusPending, job.Status)
+				assert.Equal(t, consts.ImportChunkSizeDefault, job.ChunkSize)
+				assert.Equal(t, "alice", job.CreatedBy)
+				require.Len(t, rows, 2)
+				assert.Equal(t, dto.ImportRow{
+					Line: 2, Key: job.FileHash + ":2", Wallet: testWalletName01, Amount: 1050, Status: consts.ImportStatusPending,
+				}, rows[0])
+				job.ID = 7
+				return &job, nil
+			})
+		ts.expectAudit(audit.ActionCreateImport, audit.ResultOK)
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{ID: "alice", Scopes: []string{auth.ScopeDeposit}, Wallets: []string{auth.AllWallets}})
+		result, err := ts.svc.CreateImport(ctx, deposits)
+		require.NoError(t, err)
+		require.NotNil(t, result.Job)
+		assert.EqualValues(t, 7, result.Job.ID)
+		assert.Equal(t, 2, result.Job.Rows)
+		assert.Empty(t, result.Report.Errors)
+	})
+}
+
+func TestService_GetImportJob(t *testing.T) {
+	job := &dto.ImportJob{ID: 7, Kind: consts.ImportKindDeposits, CreatedBy: "alice"}
+
+	t.Run("not found", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetImportJob(gomock.Any(), int64(7)).Return(nil, nil)
+
+		_, err := ts.svc.GetImportJob(context.Background(), 7)
+		assert.Equal(t, ErrImportNotFound, err)
+	})
+
+	t.Run("import of another caller", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetImportJob(gomock.Any(), int64(7)).Return(job, nil)
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{ID: "bob", Scopes: []string{auth.ScopeDeposit}})
+		_, err := ts.svc.GetImportJob(ctx, 7)
+		assert.Equal(t, ErrImportNotFound, err)
+	})
+
+	t.Run("admin", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetImportJob(gomock.Any(), int64(7)).Return(job, nil)
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{ID: "bob", Scopes: []string{auth.ScopeAdmin}})
+		got, err := ts.svc.GetImportJob(ctx, 7)
+		require.NoError(t, err)
+		assert.Equal(t, job, got)
+	})
+
+	t.Run("result", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		rows := []dto.ImportRow{{JobID: 7, Line: 2, Status: consts.ImportStatusApplied}}
+		ts.mockRepo.EXPECT().GetImportJob(gomock.Any(), int64(7)).Return(job, nil)
+		ts.mockRepo.EXPECT().GetImportRows(gomock.Any(), int64(7)).Return(rows, nil)
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{ID: "alice", Scopes: []string{auth.ScopeDeposit}})
+		gotJob, gotRows, err := ts.svc.GetImportResult(ctx, 7)
+		require.NoError(t, err)
+		assert.Equal(t, job, gotJob)
+		assert.Equal(t, rows, gotRows)
+	})
+}
+
+func TestService_ProcessImportJobs(t *testing.T) {
+	t.Run("database connection error", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().ClaimImportJob(gomock.Any(), importLease).Return(nil, sql.ErrConnDone)
+
+		n, err := ts.svc.ProcessImportJobs(context.Background())
+		assert.Zero(t, n)
+		assert.Equal(t, ErrDatabase.Wrap(sql.ErrConnDone), err)
+	})
+
+	t.Run("deposits", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		job := &dto.ImportJob{ID: 7, Kind: consts.ImportKindDeposits, Status: consts.ImportStatusRunning, ChunkSize: 2, Rows: 2}
+		rows := []dto.ImportRow{
+			{JobID: 7, Line: 2, Key: "applied", Wallet: testWalletName01, Amount: 100, Status: consts.ImportStatusPending},
+			{JobID: 7, Line: 3, Key: "new", Wallet: testWalletName01, Amount: 250, Status: consts.ImportStatusPending},
+		}
+		gomock.InOrder(
+			ts.mockRepo.EXPECT().ClaimImportJob(gomock.Any(), importLease).Return(job, nil),
+			ts.mockRepo.EXPECT().ClaimImportJob(gomock.Any(), importLease).Return(nil, nil),
+		)
+		ts.expectTx()
+		ts.expectTx()
+		gomock.InOrder(
+			ts.mockRepo.EXPECT().GetPendingImportRowsTx(gomock.Any(), gomock.Any(), int64(7), 2).Return(rows, nil),
+			ts.mockRepo.EXPECT().GetPendingImportRowsTx(gomock.Any(), gomock.Any(), int64(7), 2).Return(nil, nil),
+		)
+		ts.mockRepo.EXPECT().ImportKeyAppliedTx(gomock.Any(), gomock.Any(), "applied").Return(true, nil)
+		ts.mockRepo.EXPECT().ImportKeyAppliedTx(gomock.Any(), gomock.Any(), "new").Return(false, nil)
+		ts.mockRepo.EXPECT().IncreaseWalletBalanceTx(gomock.Any(), gomock.Any(), testWalletName01, uint64(250)).Return(nil)
+		ts.expectAudit(audit.ActionDeposit, audit.ResultOK)
+
+		skipped, applied := rows[0], rows[1]
+		skipped.Status, skipped.Error = consts.ImportStatusSkipped, "idempotency key is already applied"
+		applied.Status = consts.ImportStatusApplied
+		ts.mockRepo.EXPECT().UpdateImportRowTx(gomock.Any(), gomock.Any(), skipped).Return(nil)
+		ts.mockRepo.EXPECT().UpdateImportRowTx(gomock.Any(), gomock.Any(), applied).Return(nil)
+
+		gomock.InOrder(
+			ts.mockRepo.EXPECT().UpdateImportJobTx(gomock.Any(), gomock.Any(), gomock.Cond(func(x any) bool {
+				job := x.(dto.ImportJob)
+				return job.Status == consts.ImportStatusRunning && job.Applied == 1 && job.Skipped == 1
+			})).Return(nil),
+			ts.mockRepo.EXPECT().UpdateImportJobTx(gomock.Any(), gomock.Any(), gomock.Cond(func(x any) bool {
+				job := x.(dto.ImportJob)
+				return job.Status == consts.ImportStatusDone && job.FinishedAt != nil && job.Applied == 1
+			})).Return(nil),
+		)
+
+		n, err := ts.svc.ProcessImportJobs(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("not enough money", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		job := &dto.ImportJob{ID: 7, Kind: consts.ImportKindTransfers, Status: consts.ImportStatusRunning, ChunkSize: 2, Rows: 1}
+		row := dto.ImportRow{JobID: 7, Line: 2, Key: "key", Wallet: testWalletName01, WalletTo: testWalletName02, Amount: 500,
+			Status: consts.ImportStatusPending}
+		gomock.InOrder(
+			ts.mockRepo.EXPECT().ClaimImportJob(gomock.Any(), importLease).Return(job, nil),
+			ts.mockRepo.EXPECT().ClaimImportJob(gomock.Any(), importLease).Return(nil, nil),
+		)
+		ts.expectTx()
+		ts.mockRepo.EXPECT().GetPendingImportRowsTx(gomock.Any(), gomock.Any(), int64(7), 2).Return([]dto.ImportRow{row}, nil)
+		ts.mockRepo.EXPECT().ImportKeyAppliedTx(gomock.Any(), gomock.Any(), "key").Return(false, nil)
+		ts.mockRepo.EXPECT().GetWalletsForUpdateTx(gomock.Any(), gomock.Any(), []string{testWalletName01, testWalletName02}).
+			Return([]dto.Wallet{{Name: testWalletName01, Balance: 100}, {Name: testWalletName02}}, nil)
+		ts.mockRepo.EXPECT().InsertAuditRecordTx(gomock.Any(), gomock.Any(), gomock.Cond(func(x any) bool {
+			record := x.(dto.AuditRecord)
+			return record.Action == audit.ActionTransfer && record.Result == audit.ResultError && record.Endpoint == "import 7"
+		})).Return(nil)
+
+		failed := row
+		failed.Status, failed.Error = consts.ImportStatusFailed, "not enough money"
+		ts.mockRepo.EXPECT().UpdateImportRowTx(gomock.Any(), gomock.Any(), failed).Return(nil)
+		ts.mockRepo.EXPECT().UpdateImportJobTx(gomock.Any(), gomock.Any(), gomock.Cond(func(x any) bool {
+			job := x.(dto.ImportJob)
+			return job.Status == consts.ImportStatusDone && job.Failed == 1
+		})).Return(nil)
+
+		n, err := ts.svc.ProcessImportJobs(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("creator lost access", func(t *testing.T) {
+		revokedAt := time.Now()
+		tests := map[string]struct {
+			key     dto.APIKey
+			wallets []dto.Wallet
+		}{
+			"revoked key": {
+				key: dto.APIKey{Name: "payouts", Scopes: []string{auth.ScopeDeposit}, Wallets: []string{auth.AllWallets},
+					RevokedAt: &revokedAt},
+			},
+			"scope removed": {
+				key: dto.APIKey{Name: "payouts", Scopes: []string{auth.ScopeRead}, Wallets: []string{auth.AllWallets}},
+			},
+			"owner changed": {
+				key:     dto.APIKey{Name: "payouts", Scopes: []string{auth.ScopeDeposit}, Owners: []string{"acme"}},
+				wallets: []dto.Wallet{{Name: testWalletName01, Owner: "other"}},
+			},
+		}
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				ts := newTestService(t)
+				defer ts.Finish()
+
+				job := &dto.ImportJob{ID: 7, Kind: consts.ImportKindDeposits, Status: consts.ImportStatusRunning, ChunkSize: 2,
+					Rows: 1, CreatedBy: "payouts"}
+				row := dto.ImportRow{JobID: 7, Line: 2, Key: "key", Wallet: testWalletName01, Amount: 100,
+					Status: consts.ImportStatusPending}
+				gomock.InOrder(
+					ts.mockRepo.EXPECT().ClaimImportJob(gomock.Any(), importLease).Return(job, nil),
+					ts.mockRepo.EXPECT().ClaimImportJob(gomock.Any(), importLease).Return(nil, nil),
+				)
+				ts.mockRepo.EXPECT().ListAPIKeys(gomock.Any()).Return([]dto.APIKey{{Name: "other"}, tt.key}, nil)
+				ts.expectTx()
+				ts.mockRepo.EXPECT().GetPendingImportRowsTx(gomock.Any(), gomock.Any(), int64(7), 2).Return([]dto.ImportRow{row}, nil)
+				ts.mockRepo.EXPECT().ImportKeyAppliedTx(gomock.Any(), gomock.Any(), "key").Return(false, nil)
+				if tt.wallets != nil {
+					ts.mockRepo.EXPECT().GetWalletsForUpdateTx(gomock.Any(), gomock.Any(), []string{testWalletName01}).
+						Return(tt.wallets, nil)
+				}
+				ts.mockRepo.EXPECT().InsertAuditRecordTx(gomock.Any(), gomock.Any(), gomock.Cond(func(x any) bool {
+					record := x.(dto.AuditRecord)
+					return record.Actor == "payouts" && record.Result == audit.ResultError && record.Error == ErrPermissionDenied.Error()
+				})).Return(nil)
+
+				failed := row
+				failed.Status, failed.Error = consts.ImportStatusFailed, ErrPermissionDenied.Error()
+				ts.mockRepo.EXPECT().UpdateImportRowTx(gomock.Any(), gomock.Any(), failed).Return(nil)
+				ts.mockRepo.EXPECT().UpdateImportJobTx(gomock.Any(), gomock.Any(), gomock.Cond(func(x any) bool {
+					job := x.(dto.ImportJob)
+					return job.Status == consts.ImportStatusDone && job.Failed == 1
+				})).Return(nil)
+
+				n, err := ts.svc.ProcessImportJobs(context.Background())
+				require.NoError(t, err)
+				assert.Equal(t, 1, n)
+			})
+		}
+	})
+
+	t.Run("serialization failure", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		// The row isn't failed, the error aborts the transaction, so the repository restarts the chunk.
+		job := &dto.ImportJob{ID: 7, Kind: consts.ImportKindDeposits, Status: consts.ImportStatusRunning, ChunkSize: 2, Rows: 1}
+		row := dto.ImportRow{JobID: 7, Line: 2, Key: "key", Wallet: testWalletName01, Amount: 100, Status: consts.ImportStatusPending}
+		conflict := &pq.Error{Code: "40001"}
+		ts.mockRepo.EXPECT().ClaimImportJob(gomock.Any(), importLease).Return(job, nil)
+		ts.expectTx()
+		ts.mockRepo.EXPECT().GetPendingImportRowsTx(gomock.Any(), gomock.Any(), int64(7), 2).Return([]dto.ImportRow{row}, nil)
+		ts.mockRepo.EXPECT().ImportKeyAppliedTx(gomock.Any(), gomock.Any(), "key").Return(false, nil)
+		ts.mockRepo.EXPECT().IncreaseWalletBalanceTx(gomock.Any(), gomock.Any(), testWalletName01, uint64(100)).Return(conflict)
+
+		n, err := ts.svc.ProcessImportJobs(context.Background())
+		assert.Zero(t, n)
+		var pqErr *pq.Error
+		require.ErrorAs(t, err, &pqErr)
+		assert.Equal(t, conflict, pqErr)
+		assert.Equal(t, consts.ImportStatusRunning, job.Status)
+	})
+}
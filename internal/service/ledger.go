@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ezhdanovskiy/wallets/internal/audit"
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/consistency"
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/ledger"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
+	"github.com/ezhdanovskiy/wallets/internal/tracing"
+)
+
+// errStopScan stops the ledger scan after the first broken link.
+var errStopScan = errors.New("stop scan")
+
+// VerifyLedger walks the hash chain of every wallet and reports the first broken link.
+// If checkpoint is nil, the latest stored checkpoint is used to detect removed operations.
+func (s *Service) VerifyLedger(ctx context.Context, checkpoint *dto.LedgerCheckpoint) (_ *dto.LedgerReport, err error) {
+	ctx, span := tracing.Start(ctx, "Service.VerifyLedger")
+	defer func() { tracing.End(span, err) }()
+
+	if err := authorizeScope(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	if checkpoint == nil {
+		var err error
+		checkpoint, err = s.repo.GetLatestLedgerCheckpoint(ctx)
+		if err != nil {
+			return nil, ErrDatabase.Wrap(err)
+		}
+	}
+
+	var checkpointErr error
+	if checkpoint != nil && s.signer != nil {
+		checkpointErr = ledger.VerifyCheckpoint(s.signer.PublicKey(), checkpoint)
+		if checkpointErr != nil {
+			checkpoint = nil
+		}
+	}
+
+	verifier, err := s.scanLedger(ctx, checkpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	report := verifier.Report()
+	if checkpointErr != nil {
+		report.OK = false
+		report.CheckpointError = checkpointErr.Error()
+	}
+	if !report.OK {
+		logging.FromContext(ctx, s.log).With("report", report).Error("Ledger verification failed")
+	}
+	return &report, nil
+}
+
+// CreateLedgerCheckpoint verifies the ledger and stores signed heads of all wallet chains.
+func (s *Service) CreateLedgerCheckpoint(ctx context.Context) (_ *dto.LedgerCheckpoint, err error) {
+	ctx, span := tracing.Start(ctx, "Service.CreateLedgerCheckpoint")
+	defer func() { tracing.End(span, err) }()
+
+	var created *dto.LedgerCheckpoint
+	err = s.audited(ctx, audit.ActionCreateLedgerCheckpoint, nil, func(ctx context.Context, tx storage.Tx) error {
+		if err := authorizeScope(ctx, auth.ScopeAdmin); err != nil {
+			return err
+		}
+		if s.signer == nil {
+			return ErrLedgerSigningDisabled
+		}
+		// The checkpoint must cover all committed operations, a replica may lag behind.
+		ctx = consistency.Strong(ctx)
+
+		previous, err := s.repo.GetLatestLedgerCheckpoint(ctx)
+		if err != nil {
+			return ErrDatabase.Wrap(err)
+		}
+		if previous != nil {
+			if err := ledger.VerifyCheckpoint(s.signer.PublicKey(), previous); err != nil {
+				return ErrLedgerBroken.Wrap(fmt.Errorf("checkpoint %d: %w", previous.ID, err))
+			}
+		}
+
+		verifier, err := s.scanLedger(ctx, previous)
+		if err != nil {
+			return err
+		}
+		report := verifier.Report()
+		if !report.OK {
+			return ErrLedgerBroken.Wrap(fmt.Errorf("wallet %s seq %d: %s",
+				report.Broken.Wallet, report.Broken.Seq, report.Broken.Reason))
+		}
+
+		cp, err := s.signer.NewCheckpoint(verifier.Heads(), report.Operations, verifier.LastOperationID())
+		if err != nil {
+			return ErrInternal.Wrap(err)
+		}
+
+		created, err = s.repo.InsertLedgerCheckpointTx(ctx, tx, *cp)
+		if err != nil {
+			return ErrDatabase.Wrap(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx, s.log).With("id", created.ID, "root_hash", created.RootHash, "operations", created.Operations).
+		Info("Ledger checkpoint created")
+	return created, nil
+}
+
+// GetLedgerCheckpoints provides the latest checkpoints, newest first.
+func (s *Service) GetLedgerCheckpoints(ctx context.Context, limit int64) (_ []dto.LedgerCheckpoint, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetLedgerCheckpoints")
+	defer func() { tracing.End(span, err) }()
+
+	if err := authorizeScope(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+	if limit < 0 {
+		return nil, ErrNotPositiveLimit
+	}
+	if limit == 0 {
+		limit = consts.OperationsLimitDefault
+	}
+
+	checkpoints, err := s.repo.GetLedgerCheckpoints(ctx, limit)
+	if err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+	return checkpoints, nil
+}
+
+func (s *Service) scanLedger(ctx context.Context, checkpoint *dto.LedgerCheckpoint) (*ledger.Verifier, error) {
+	verifier := ledger.NewVerifier(checkpoint)
+	err := s.repo.ScanLedger(ctx, func(e ledger.Entry) error {
+		if !verifier.Add(e) {
+			return errStopScan
+		}
+		return nil
+	})
+	if err != nil && err != errStopScan {
+		return nil, ErrDatabase.Wrap(err)
+	}
+	return verifier, nil
+}
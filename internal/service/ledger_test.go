@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ezhdanovskiy/wallets/internal/audit"
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/httperr"
+	"github.com/ezhdanovskiy/wallets/internal/ledger"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
+)
+
+func testLedger() []ledger.Entry {
+	var entries []ledger.Entry
+	prev := ""
+	for seq := int64(1); seq <= 3; seq++ {
+		e := ledger.Entry{
+			ID:          seq,
+			Wallet:      testWalletName01,
+			Seq:         seq,
+			Type:        "deposit",
+			Amount:      testAmount.GetInt(),
+			OtherWallet: "system",
+			CreatedAt:   time.Unix(1234567890+seq, 0),
+			PrevHash:    prev,
+		}
+		e.Hash = ledger.Hash(e)
+		prev = e.Hash
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func (ts *TestService) expectScanLedger(entries []ledger.Entry) {
+	ts.mockRepo.EXPECT().ScanLedger(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, f func(ledger.Entry) error) error {
+			for _, e := range entries {
+				if err := f(e); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+}
+
+func TestService_VerifyLedger(t *testing.T) {
+	t.Run("not admin", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeRead}})
+		_, err := ts.svc.VerifyLedger(ctx, nil)
+		assert.Equal(t, ErrPermissionDenied, err)
+	})
+
+	t.Run("intact", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetLatestLedgerCheckpoint(gomock.Any()).Return(nil, nil)
+		ts.expectScanLedger(testLedger())
+
+		report, err := ts.svc.VerifyLedger(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, &dto.LedgerReport{OK: true, Wallets: 1, Operations: 3}, report)
+	})
+
+	t.Run("broken", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		entries := testLedger()
+		entries[1].Amount++
+		ts.mockRepo.EXPECT().GetLatestLedgerCheckpoint(gomock.Any()).Return(nil, nil)
+		ts.expectScanLedger(entries)
+
+		report, err := ts.svc.VerifyLedger(context.Background(), nil)
+		require.NoError(t, err)
+		assert.False(t, report.OK)
+		assert.Equal(t, &dto.LedgerBrokenLink{
+			Wallet:      testWalletName01,
+			Seq:         2,
+			OperationID: 2,
+			Reason:      ledger.ReasonHashMismatch,
+		}, report.Broken)
+	})
+
+	t.Run("invalid checkpoint signature", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+		ts.svc.signer = newTestSigner(t)
+
+		ts.mockRepo.EXPECT().GetLatestLedgerCheckpoint(gomock.Any()).
+			Return(&dto.LedgerCheckpoint{ID: 1, KeyID: "unknown"}, nil)
+		ts.expectScanLedger(testLedger())
+
+		report, err := ts.svc.VerifyLedger(context.Background(), nil)
+		require.NoError(t, err)
+		assert.False(t, report.OK)
+		assert.Zero(t, report.CheckpointID)
+		assert.NotEmpty(t, report.CheckpointError)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetLatestLedgerCheckpoint(gomock.Any()).Return(nil, nil)
+		ts.mockRepo.EXPECT().ScanLedger(gomock.Any(), gomock.Any()).Return(sql.ErrConnDone)
+
+		_, err := ts.svc.VerifyLedger(context.Background(), nil)
+		assert.Equal(t, ErrDatabase.Wrap(sql.ErrConnDone), err)
+	})
+}
+
+func TestService_CreateLedgerCheckpoint(t *testing.T) {
+	t.Run("signing disabled", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.expectTx()
+		ts.expectAudit(audit.ActionCreateLedgerCheckpoint, audit.ResultError)
+
+		_, err := ts.svc.CreateLedgerCheckpoint(context.Background())
+		assert.Equal(t, ErrLedgerSigningDisabled, err)
+	})
+
+	t.Run("broken ledger", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+		ts.svc.signer = newTestSigner(t)
+
+		entries := testLedger()
+		entries[2].PrevHash = ""
+		ts.expectTx()
+		ts.mockRepo.EXPECT().GetLatestLedgerCheckpoint(gomock.Any()).Return(nil, nil)
+		ts.expectScanLedger(entries)
+		ts.expectAudit(audit.ActionCreateLedgerCheckpoint, audit.ResultError)
+
+		_, err := ts.svc.CreateLedgerCheckpoint(context.Background())
+		var httpErr *httperr.Error
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, ErrLedgerBroken.Message, httpErr.Message)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+		signer := newTestSigner(t)
+		ts.svc.signer = signer
+
+		entries := testLedger()
+		ts.expectTx()
+		ts.mockRepo.EXPECT().GetLatestLedgerCheckpoint(gomock.Any()).Return(nil, nil)
+		ts.expectScanLedger(entries)
+		ts.mockRepo.EXPECT().InsertLedgerCheckpointTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ storage.Tx, cp dto.LedgerCheckpoint) (*dto.LedgerCheckpoint, error) {
+				cp.ID = 1
+				return &cp, nil
+			})
+		ts.expectAudit(audit.ActionCreateLedgerCheckpoint, audit.ResultOK)
+
+		cp, err := ts.svc.CreateLedgerCheckpoint(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), cp.ID)
+		assert.Equal(t, int64(3), cp.Operations)
+		assert.Equal(t, int64(3), cp.LastOperationID)
+		assert.Equal(t, dto.LedgerHead{Seq: 3, Hash: entries[2].Hash}, cp.Heads[testWalletName01])
+		assert.NoError(t, ledger.VerifyCheckpoint(signer.PublicKey(), cp))
+	})
+}
+
+func newTestSigner(t *testing.T) *ledger.Signer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return ledger.NewSigner(key)
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/httperr"
+)
+
+// Outcomes of money movements used as the result label.
+const (
+	outcomeSuccess           = "success"
+	outcomeInvalid           = "invalid"
+	outcomeDenied            = "denied"
+	outcomeNotFound          = "not_found"
+	outcomeInsufficientFunds = "insufficient_funds"
+	outcomeError             = "error"
+)
+
+var (
+	depositsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wallets_deposits_total",
+		Help: "Deposits by result: success, invalid, denied, not_found or error.",
+	}, []string{"result"})
+
+	transfersTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wallets_transfers_total",
+		Help: "Transfers by result: success, invalid, denied, not_found, insufficient_funds or error.",
+	}, []string{"result"})
+
+	depositAmount = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "wallets_deposit_amount",
+		Help:    "Amounts of successful deposits.",
+		Buckets: prometheus.ExponentialBuckets(1, 10, 8),
+	})
+
+	transferAmount = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "wallets_transfer_amount",
+		Help:    "Amounts of successful transfers.",
+		Buckets: prometheus.ExponentialBuckets(1, 10, 8),
+	})
+)
+
+// outcome maps the error returned by the service to the result label.
+func outcome(err error) string {
+	if err == nil {
+		return outcomeSuccess
+	}
+	// The wallet not found error has the bad request status for backward compatibility.
+	if errors.Is(err, ErrWalletNotFound) {
+		return outcomeNotFound
+	}
+
+	var httpErr *httperr.Error
+	if !errors.As(err, &httpErr) {
+		return outcomeError
+	}
+
+	switch httpErr.StatusCode {
+	case http.StatusBadRequest:
+		return outcomeInvalid
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return outcomeDenied
+	case http.StatusNotFound:
+		return outcomeNotFound
+	case http.StatusUnprocessableEntity:
+		return outcomeInsufficientFunds
+	default:
+		return outcomeError
+	}
+}
+
+func observeDeposit(amount dto.Amount, err error) {
+	depositsTotal.WithLabelValues(outcome(err)).Inc()
+	if err == nil {
+		depositAmount.Observe(float64(amount))
+	}
+}
+
+func observeTransfer(amount dto.Amount, err error) {
+	transfersTotal.WithLabelValues(outcome(err)).Inc()
+	if err == nil {
+		transferAmount.Observe(float64(amount))
+	}
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ezhdanovskiy/wallets/internal/httperr"
+)
+
+func TestOutcome(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{name: "success", expected: outcomeSuccess},
+		{name: "invalid", err: ErrNotPositiveAmount, expected: outcomeInvalid},
+		{name: "wallet not found", err: ErrWalletNotFound, expected: outcomeNotFound},
+		{name: "not found message", err: httperr.New(http.StatusBadRequest, ErrWalletNotFound.Message), expected: outcomeInvalid},
+		{name: "wallets not found", err: httperr.New(http.StatusNotFound, "w2 not found"), expected: outcomeNotFound},
+		{name: "denied", err: ErrPermissionDenied, expected: outcomeDenied},
+		{name: "not enough money", err: httperr.New(http.StatusUnprocessableEntity, "not enough money"), expected: outcomeInsufficientFunds},
+		{name: "database", err: ErrDatabase.Wrap(errors.New("connection refused")), expected: outcomeError},
+		{name: "wrapped", err: fmt.Errorf("audited: %w", ErrPermissionDenied), expected: outcomeDenied},
+		{name: "unknown", err: errors.New("unknown"), expected: outcomeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, outcome(tt.err))
+		})
+	}
+}
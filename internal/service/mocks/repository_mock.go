@@ -10,10 +10,13 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	dto "github.com/ezhdanovskiy/wallets/internal/dto"
-	sqlx "github.com/jmoiron/sqlx"
+	ledger "github.com/ezhdanovskiy/wallets/internal/ledger"
+	storage "github.com/ezhdanovskiy/wallets/internal/storage"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,103 +44,470 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
-// CreateWallet mocks base method.
-func (m *MockRepository) CreateWallet(walletName string) error {
+// ClaimImportJob mocks base method.
+func (m *MockRepository) ClaimImportJob(ctx context.Context, lease time.Duration) (*dto.ImportJob, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateWallet", walletName)
+	ret := m.ctrl.Call(m, "ClaimImportJob", ctx, lease)
+	ret0, _ := ret[0].(*dto.ImportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimImportJob indicates an expected call of ClaimImportJob.
+func (mr *MockRepositoryMockRecorder) ClaimImportJob(ctx, lease any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimImportJob", reflect.TypeOf((*MockRepository)(nil).ClaimImportJob), ctx, lease)
+}
+
+// CreateAPIKeyTx mocks base method.
+func (m *MockRepository) CreateAPIKeyTx(ctx context.Context, tx storage.Tx, key dto.APIKey, keyHash string) (*dto.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKeyTx", ctx, tx, key, keyHash)
+	ret0, _ := ret[0].(*dto.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKeyTx indicates an expected call of CreateAPIKeyTx.
+func (mr *MockRepositoryMockRecorder) CreateAPIKeyTx(ctx, tx, key, keyHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKeyTx", reflect.TypeOf((*MockRepository)(nil).CreateAPIKeyTx), ctx, tx, key, keyHash)
+}
+
+// CreateImportJobTx mocks base method.
+func (m *MockRepository) CreateImportJobTx(ctx context.Context, tx storage.Tx, job dto.ImportJob, rows []dto.ImportRow) (*dto.ImportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateImportJobTx", ctx, tx, job, rows)
+	ret0, _ := ret[0].(*dto.ImportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateImportJobTx indicates an expected call of CreateImportJobTx.
+func (mr *MockRepositoryMockRecorder) CreateImportJobTx(ctx, tx, job, rows any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateImportJobTx", reflect.TypeOf((*MockRepository)(nil).CreateImportJobTx), ctx, tx, job, rows)
+}
+
+// CreateWalletTx mocks base method.
+func (m *MockRepository) CreateWalletTx(ctx context.Context, tx storage.Tx, walletName, owner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWalletTx", ctx, tx, walletName, owner)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// CreateWallet indicates an expected call of CreateWallet.
-func (mr *MockRepositoryMockRecorder) CreateWallet(walletName any) *gomock.Call {
+// CreateWalletTx indicates an expected call of CreateWalletTx.
+func (mr *MockRepositoryMockRecorder) CreateWalletTx(ctx, tx, walletName, owner any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockRepository)(nil).CreateWallet), walletName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWalletTx", reflect.TypeOf((*MockRepository)(nil).CreateWalletTx), ctx, tx, walletName, owner)
+}
+
+// GetAuditRecords mocks base method.
+func (m *MockRepository) GetAuditRecords(ctx context.Context, filter dto.AuditFilter) ([]dto.AuditRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditRecords", ctx, filter)
+	ret0, _ := ret[0].([]dto.AuditRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditRecords indicates an expected call of GetAuditRecords.
+func (mr *MockRepositoryMockRecorder) GetAuditRecords(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditRecords", reflect.TypeOf((*MockRepository)(nil).GetAuditRecords), ctx, filter)
+}
+
+// GetImportJob mocks base method.
+func (m *MockRepository) GetImportJob(ctx context.Context, id int64) (*dto.ImportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImportJob", ctx, id)
+	ret0, _ := ret[0].(*dto.ImportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImportJob indicates an expected call of GetImportJob.
+func (mr *MockRepositoryMockRecorder) GetImportJob(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImportJob", reflect.TypeOf((*MockRepository)(nil).GetImportJob), ctx, id)
+}
+
+// GetImportRows mocks base method.
+func (m *MockRepository) GetImportRows(ctx context.Context, jobID int64) ([]dto.ImportRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImportRows", ctx, jobID)
+	ret0, _ := ret[0].([]dto.ImportRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImportRows indicates an expected call of GetImportRows.
+func (mr *MockRepositoryMockRecorder) GetImportRows(ctx, jobID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImportRows", reflect.TypeOf((*MockRepository)(nil).GetImportRows), ctx, jobID)
+}
+
+// GetLatestBalanceSnapshot mocks base method.
+func (m *MockRepository) GetLatestBalanceSnapshot(ctx context.Context, walletName string, at time.Time) (*dto.BalanceSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestBalanceSnapshot", ctx, walletName, at)
+	ret0, _ := ret[0].(*dto.BalanceSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestBalanceSnapshot indicates an expected call of GetLatestBalanceSnapshot.
+func (mr *MockRepositoryMockRecorder) GetLatestBalanceSnapshot(ctx, walletName, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestBalanceSnapshot", reflect.TypeOf((*MockRepository)(nil).GetLatestBalanceSnapshot), ctx, walletName, at)
+}
+
+// GetLatestLedgerCheckpoint mocks base method.
+func (m *MockRepository) GetLatestLedgerCheckpoint(ctx context.Context) (*dto.LedgerCheckpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestLedgerCheckpoint", ctx)
+	ret0, _ := ret[0].(*dto.LedgerCheckpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestLedgerCheckpoint indicates an expected call of GetLatestLedgerCheckpoint.
+func (mr *MockRepositoryMockRecorder) GetLatestLedgerCheckpoint(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestLedgerCheckpoint", reflect.TypeOf((*MockRepository)(nil).GetLatestLedgerCheckpoint), ctx)
+}
+
+// GetLedgerCheckpoints mocks base method.
+func (m *MockRepository) GetLedgerCheckpoints(ctx context.Context, limit int64) ([]dto.LedgerCheckpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLedgerCheckpoints", ctx, limit)
+	ret0, _ := ret[0].([]dto.LedgerCheckpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLedgerCheckpoints indicates an expected call of GetLedgerCheckpoints.
+func (mr *MockRepositoryMockRecorder) GetLedgerCheckpoints(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLedgerCheckpoints", reflect.TypeOf((*MockRepository)(nil).GetLedgerCheckpoints), ctx, limit)
 }
 
 // GetOperations mocks base method.
-func (m *MockRepository) GetOperations(arg0 dto.OperationsFilter) ([]dto.Operation, error) {
+func (m *MockRepository) GetOperations(ctx context.Context, filter dto.OperationsFilter) ([]dto.Operation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOperations", arg0)
+	ret := m.ctrl.Call(m, "GetOperations", ctx, filter)
 	ret0, _ := ret[0].([]dto.Operation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOperations indicates an expected call of GetOperations.
-func (mr *MockRepositoryMockRecorder) GetOperations(arg0 any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetOperations(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOperations", reflect.TypeOf((*MockRepository)(nil).GetOperations), ctx, filter)
+}
+
+// GetOperationsTotals mocks base method.
+func (m *MockRepository) GetOperationsTotals(ctx context.Context, filter dto.OperationsFilter) (*dto.OperationsTotals, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOperationsTotals", ctx, filter)
+	ret0, _ := ret[0].(*dto.OperationsTotals)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOperationsTotals indicates an expected call of GetOperationsTotals.
+func (mr *MockRepositoryMockRecorder) GetOperationsTotals(ctx, filter any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOperations", reflect.TypeOf((*MockRepository)(nil).GetOperations), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOperationsTotals", reflect.TypeOf((*MockRepository)(nil).GetOperationsTotals), ctx, filter)
+}
+
+// GetPendingImportRowsTx mocks base method.
+func (m *MockRepository) GetPendingImportRowsTx(ctx context.Context, tx storage.Tx, jobID int64, limit int) ([]dto.ImportRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingImportRowsTx", ctx, tx, jobID, limit)
+	ret0, _ := ret[0].([]dto.ImportRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingImportRowsTx indicates an expected call of GetPendingImportRowsTx.
+func (mr *MockRepositoryMockRecorder) GetPendingImportRowsTx(ctx, tx, jobID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingImportRowsTx", reflect.TypeOf((*MockRepository)(nil).GetPendingImportRowsTx), ctx, tx, jobID, limit)
+}
+
+// GetStatement mocks base method.
+func (m *MockRepository) GetStatement(ctx context.Context, walletName, period string) (*dto.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatement", ctx, walletName, period)
+	ret0, _ := ret[0].(*dto.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatement indicates an expected call of GetStatement.
+func (mr *MockRepositoryMockRecorder) GetStatement(ctx, walletName, period any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatement", reflect.TypeOf((*MockRepository)(nil).GetStatement), ctx, walletName, period)
 }
 
 // GetWallet mocks base method.
-func (m *MockRepository) GetWallet(walletName string) (*dto.Wallet, error) {
+func (m *MockRepository) GetWallet(ctx context.Context, walletName string) (*dto.Wallet, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWallet", walletName)
+	ret := m.ctrl.Call(m, "GetWallet", ctx, walletName)
 	ret0, _ := ret[0].(*dto.Wallet)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetWallet indicates an expected call of GetWallet.
-func (mr *MockRepositoryMockRecorder) GetWallet(walletName any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetWallet(ctx, walletName any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockRepository)(nil).GetWallet), walletName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockRepository)(nil).GetWallet), ctx, walletName)
 }
 
 // GetWalletsForUpdateTx mocks base method.
-func (m *MockRepository) GetWalletsForUpdateTx(tx *sqlx.Tx, walletNames []string) ([]dto.Wallet, error) {
+func (m *MockRepository) GetWalletsForUpdateTx(ctx context.Context, tx storage.Tx, walletNames []string) ([]dto.Wallet, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWalletsForUpdateTx", tx, walletNames)
+	ret := m.ctrl.Call(m, "GetWalletsForUpdateTx", ctx, tx, walletNames)
 	ret0, _ := ret[0].([]dto.Wallet)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetWalletsForUpdateTx indicates an expected call of GetWalletsForUpdateTx.
-func (mr *MockRepositoryMockRecorder) GetWalletsForUpdateTx(tx, walletNames any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetWalletsForUpdateTx(ctx, tx, walletNames any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletsForUpdateTx", reflect.TypeOf((*MockRepository)(nil).GetWalletsForUpdateTx), tx, walletNames)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletsForUpdateTx", reflect.TypeOf((*MockRepository)(nil).GetWalletsForUpdateTx), ctx, tx, walletNames)
 }
 
-// IncreaseWalletBalance mocks base method.
-func (m *MockRepository) IncreaseWalletBalance(walletName string, amount uint64) error {
+// ImportKeyAppliedTx mocks base method.
+func (m *MockRepository) ImportKeyAppliedTx(ctx context.Context, tx storage.Tx, key string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IncreaseWalletBalance", walletName, amount)
+	ret := m.ctrl.Call(m, "ImportKeyAppliedTx", ctx, tx, key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportKeyAppliedTx indicates an expected call of ImportKeyAppliedTx.
+func (mr *MockRepositoryMockRecorder) ImportKeyAppliedTx(ctx, tx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportKeyAppliedTx", reflect.TypeOf((*MockRepository)(nil).ImportKeyAppliedTx), ctx, tx, key)
+}
+
+// IncreaseWalletBalanceTx mocks base method.
+func (m *MockRepository) IncreaseWalletBalanceTx(ctx context.Context, tx storage.Tx, walletName string, amount uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncreaseWalletBalanceTx", ctx, tx, walletName, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncreaseWalletBalanceTx indicates an expected call of IncreaseWalletBalanceTx.
+func (mr *MockRepositoryMockRecorder) IncreaseWalletBalanceTx(ctx, tx, walletName, amount any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseWalletBalanceTx", reflect.TypeOf((*MockRepository)(nil).IncreaseWalletBalanceTx), ctx, tx, walletName, amount)
+}
+
+// InsertAuditRecord mocks base method.
+func (m *MockRepository) InsertAuditRecord(ctx context.Context, record dto.AuditRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertAuditRecord", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertAuditRecord indicates an expected call of InsertAuditRecord.
+func (mr *MockRepositoryMockRecorder) InsertAuditRecord(ctx, record any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAuditRecord", reflect.TypeOf((*MockRepository)(nil).InsertAuditRecord), ctx, record)
+}
+
+// InsertAuditRecordTx mocks base method.
+func (m *MockRepository) InsertAuditRecordTx(ctx context.Context, tx storage.Tx, record dto.AuditRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertAuditRecordTx", ctx, tx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertAuditRecordTx indicates an expected call of InsertAuditRecordTx.
+func (mr *MockRepositoryMockRecorder) InsertAuditRecordTx(ctx, tx, record any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAuditRecordTx", reflect.TypeOf((*MockRepository)(nil).InsertAuditRecordTx), ctx, tx, record)
+}
+
+// InsertBalanceSnapshot mocks base method.
+func (m *MockRepository) InsertBalanceSnapshot(ctx context.Context, snapshot dto.BalanceSnapshot) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertBalanceSnapshot", ctx, snapshot)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// IncreaseWalletBalance indicates an expected call of IncreaseWalletBalance.
-func (mr *MockRepositoryMockRecorder) IncreaseWalletBalance(walletName, amount any) *gomock.Call {
+// InsertBalanceSnapshot indicates an expected call of InsertBalanceSnapshot.
+func (mr *MockRepositoryMockRecorder) InsertBalanceSnapshot(ctx, snapshot any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBalanceSnapshot", reflect.TypeOf((*MockRepository)(nil).InsertBalanceSnapshot), ctx, snapshot)
+}
+
+// InsertLedgerCheckpointTx mocks base method.
+func (m *MockRepository) InsertLedgerCheckpointTx(ctx context.Context, tx storage.Tx, cp dto.LedgerCheckpoint) (*dto.LedgerCheckpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertLedgerCheckpointTx", ctx, tx, cp)
+	ret0, _ := ret[0].(*dto.LedgerCheckpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertLedgerCheckpointTx indicates an expected call of InsertLedgerCheckpointTx.
+func (mr *MockRepositoryMockRecorder) InsertLedgerCheckpointTx(ctx, tx, cp any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertLedgerCheckpointTx", reflect.TypeOf((*MockRepository)(nil).InsertLedgerCheckpointTx), ctx, tx, cp)
+}
+
+// InsertStatement mocks base method.
+func (m *MockRepository) InsertStatement(ctx context.Context, st dto.Statement) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertStatement", ctx, st)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertStatement indicates an expected call of InsertStatement.
+func (mr *MockRepositoryMockRecorder) InsertStatement(ctx, st any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertStatement", reflect.TypeOf((*MockRepository)(nil).InsertStatement), ctx, st)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockRepository) ListAPIKeys(ctx context.Context) ([]dto.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx)
+	ret0, _ := ret[0].([]dto.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockRepositoryMockRecorder) ListAPIKeys(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseWalletBalance", reflect.TypeOf((*MockRepository)(nil).IncreaseWalletBalance), walletName, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockRepository)(nil).ListAPIKeys), ctx)
+}
+
+// ListWallets mocks base method.
+func (m *MockRepository) ListWallets(ctx context.Context, filter dto.WalletsFilter) ([]dto.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWallets", ctx, filter)
+	ret0, _ := ret[0].([]dto.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWallets indicates an expected call of ListWallets.
+func (mr *MockRepositoryMockRecorder) ListWallets(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWallets", reflect.TypeOf((*MockRepository)(nil).ListWallets), ctx, filter)
+}
+
+// RevokeAPIKeyTx mocks base method.
+func (m *MockRepository) RevokeAPIKeyTx(ctx context.Context, tx storage.Tx, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKeyTx", ctx, tx, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAPIKeyTx indicates an expected call of RevokeAPIKeyTx.
+func (mr *MockRepositoryMockRecorder) RevokeAPIKeyTx(ctx, tx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKeyTx", reflect.TypeOf((*MockRepository)(nil).RevokeAPIKeyTx), ctx, tx, name)
 }
 
 // RunWithTransaction mocks base method.
-func (m *MockRepository) RunWithTransaction(arg0 func(*sqlx.Tx) error) error {
+func (m *MockRepository) RunWithTransaction(ctx context.Context, f func(context.Context, storage.Tx) error) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RunWithTransaction", arg0)
+	ret := m.ctrl.Call(m, "RunWithTransaction", ctx, f)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // RunWithTransaction indicates an expected call of RunWithTransaction.
-func (mr *MockRepositoryMockRecorder) RunWithTransaction(arg0 any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) RunWithTransaction(ctx, f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunWithTransaction", reflect.TypeOf((*MockRepository)(nil).RunWithTransaction), ctx, f)
+}
+
+// ScanLedger mocks base method.
+func (m *MockRepository) ScanLedger(ctx context.Context, f func(ledger.Entry) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanLedger", ctx, f)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScanLedger indicates an expected call of ScanLedger.
+func (mr *MockRepositoryMockRecorder) ScanLedger(ctx, f any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunWithTransaction", reflect.TypeOf((*MockRepository)(nil).RunWithTransaction), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanLedger", reflect.TypeOf((*MockRepository)(nil).ScanLedger), ctx, f)
+}
+
+// ScanOperations mocks base method.
+func (m *MockRepository) ScanOperations(ctx context.Context, filter dto.OperationsFilter, f func(dto.Operation) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanOperations", ctx, filter, f)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScanOperations indicates an expected call of ScanOperations.
+func (mr *MockRepositoryMockRecorder) ScanOperations(ctx, filter, f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanOperations", reflect.TypeOf((*MockRepository)(nil).ScanOperations), ctx, filter, f)
 }
 
 // TransferTx mocks base method.
-func (m *MockRepository) TransferTx(tx *sqlx.Tx, walletFrom, walletTo string, amount uint64) error {
+func (m *MockRepository) TransferTx(ctx context.Context, tx storage.Tx, walletFrom, walletTo string, amount uint64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "TransferTx", tx, walletFrom, walletTo, amount)
+	ret := m.ctrl.Call(m, "TransferTx", ctx, tx, walletFrom, walletTo, amount)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // TransferTx indicates an expected call of TransferTx.
-func (mr *MockRepositoryMockRecorder) TransferTx(tx, walletFrom, walletTo, amount any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) TransferTx(ctx, tx, walletFrom, walletTo, amount any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferTx", reflect.TypeOf((*MockRepository)(nil).TransferTx), ctx, tx, walletFrom, walletTo, amount)
+}
+
+// UpdateImportJobTx mocks base method.
+func (m *MockRepository) UpdateImportJobTx(ctx context.Context, tx storage.Tx, job dto.ImportJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateImportJobTx", ctx, tx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateImportJobTx indicates an expected call of UpdateImportJobTx.
+func (mr *MockRepositoryMockRecorder) UpdateImportJobTx(ctx, tx, job any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateImportJobTx", reflect.TypeOf((*MockRepository)(nil).UpdateImportJobTx), ctx, tx, job)
+}
+
+// UpdateImportRowTx mocks base method.
+func (m *MockRepository) UpdateImportRowTx(ctx context.Context, tx storage.Tx, row dto.ImportRow) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateImportRowTx", ctx, tx, row)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateImportRowTx indicates an expected call of UpdateImportRowTx.
+func (mr *MockRepositoryMockRecorder) UpdateImportRowTx(ctx, tx, row any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferTx", reflect.TypeOf((*MockRepository)(nil).TransferTx), tx, walletFrom, walletTo, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateImportRowTx", reflect.TypeOf((*MockRepository)(nil).UpdateImportRowTx), ctx, tx, row)
 }
@@ -2,101 +2,191 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"time"
 
-	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 
+	"github.com/ezhdanovskiy/wallets/internal/audit"
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/consistency"
 	"github.com/ezhdanovskiy/wallets/internal/consts"
 	"github.com/ezhdanovskiy/wallets/internal/dto"
 	"github.com/ezhdanovskiy/wallets/internal/httperr"
+	"github.com/ezhdanovskiy/wallets/internal/ledger"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
+	"github.com/ezhdanovskiy/wallets/internal/tracing"
 )
 
 // Service implements the business logic for wallets application.
 type Service struct {
-	log  *zap.SugaredLogger
-	repo Repository
+	log      *zap.SugaredLogger
+	repo     Repository
+	signer   *ledger.Signer
+	currency string
 }
 
-// NewService creates a service instance.
-func NewService(logger *zap.SugaredLogger, repo Repository) *Service {
+// NewService creates a service instance, ledger checkpoints are disabled if signer is nil.
+// The currency is the ISO 4217 code of wallet amounts reported in statements.
+func NewService(logger *zap.SugaredLogger, repo Repository, signer *ledger.Signer, currency string) *Service {
 	return &Service{
-		log:  logger,
-		repo: repo,
+		log:      logger,
+		repo:     repo,
+		signer:   signer,
+		currency: currency,
 	}
 }
 
 // CreateWallet creates new wallet.
-func (s *Service) CreateWallet(wallet dto.CreateWalletRequest) error {
+func (s *Service) CreateWallet(ctx context.Context, wallet dto.CreateWalletRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.CreateWallet")
+	defer func() { tracing.End(span, err) }()
+
 	if wallet.Name == "" {
-		return ErrEmptyWalletName
+		return s.auditRejected(ctx, audit.ActionCreateWallet, wallet, ErrEmptyWalletName)
 	}
 
-	err := s.repo.CreateWallet(wallet.Name)
-	if err != nil {
-		return ErrDatabase.Wrap(err)
-	}
-	return nil
+	return s.audited(ctx, audit.ActionCreateWallet, wallet, func(ctx context.Context, tx storage.Tx) error {
+		if err := authorize(ctx, wallet.Name, wallet.Owner, auth.ScopeDeposit, auth.ScopeTransfer); err != nil {
+			return err
+		}
+
+		err := s.repo.CreateWalletTx(ctx, tx, wallet.Name, wallet.Owner)
+		if err != nil {
+			return ErrDatabase.Wrap(err)
+		}
+		return nil
+	})
 }
 
-// IncreaseWalletBalance increases wallet balance.
-func (s *Service) IncreaseWalletBalance(deposit dto.Deposit) error {
-	if deposit.Wallet == "" {
-		return ErrEmptyWalletName
+// GetWallet provides the wallet with its current balance.
+func (s *Service) GetWallet(ctx context.Context, walletName string) (_ *dto.Wallet, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetWallet")
+	defer func() { tracing.End(span, err) }()
+
+	if walletName == "" {
+		return nil, ErrEmptyWalletName
 	}
-	if deposit.Amount <= 0 {
-		return ErrNotPositiveAmount
+	if err := authorizeScope(ctx, auth.ScopeRead); err != nil {
+		return nil, err
 	}
 
-	wallet, err := s.repo.GetWallet(deposit.Wallet)
+	wallet, err := s.repo.GetWallet(ctx, walletName)
 	if err != nil {
-		return ErrDatabase.Wrap(err)
+		return nil, ErrDatabase.Wrap(err)
 	}
 	if wallet == nil {
-		return ErrWalletNotFound
+		return nil, walletNotFound(ctx, walletName, ErrWalletNotFound, auth.ScopeRead)
 	}
-
-	err = s.repo.IncreaseWalletBalance(deposit.Wallet, deposit.Amount.GetInt())
-	if err != nil {
-		return ErrDatabase.Wrap(err)
+	if err := authorize(ctx, wallet.Name, wallet.Owner, auth.ScopeRead); err != nil {
+		return nil, err
 	}
-	return nil
+	return wallet, nil
 }
 
-// Transfer transfers money from one wallet to another.
-func (s *Service) Transfer(transfer dto.Transfer) error {
-	if transfer.WalletFrom == "" {
-		return ErrEmptyWalletFrom
+// ListWallets provides wallets ordered by name, it requires admin scope.
+func (s *Service) ListWallets(ctx context.Context, filter dto.WalletsFilter) (_ []dto.Wallet, err error) {
+	ctx, span := tracing.Start(ctx, "Service.ListWallets")
+	defer func() { tracing.End(span, err) }()
+
+	if filter.Limit < 0 {
+		return nil, ErrNotPositiveLimit
 	}
-	if transfer.WalletTo == "" {
-		return ErrEmptyWalletTo
+	if filter.Limit == 0 {
+		filter.Limit = consts.WalletsLimitDefault
 	}
-	if transfer.WalletFrom == transfer.WalletTo {
-		return ErrSameWallets
+	if filter.Offset < 0 {
+		return nil, ErrNegativeOffset
 	}
-	if transfer.Amount <= 0 {
-		return ErrNotPositiveAmount
+	if err := authorizeScope(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	wallets, err := s.repo.ListWallets(ctx, filter)
+	if err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+	return wallets, nil
+}
+
+// IncreaseWalletBalance increases wallet balance.
+func (s *Service) IncreaseWalletBalance(ctx context.Context, deposit dto.Deposit) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.IncreaseWalletBalance")
+	defer func() { tracing.End(span, err) }()
+	defer func() { observeDeposit(deposit.Amount, err) }()
+
+	if err := checkDeposit(deposit); err != nil {
+		return s.auditRejected(ctx, audit.ActionDeposit, deposit, err)
 	}
 
-	return s.repo.RunWithTransaction(func(tx *sqlx.Tx) error {
-		wallets, err := s.repo.GetWalletsForUpdateTx(tx, []string{transfer.WalletFrom, transfer.WalletTo})
+	return s.audited(ctx, audit.ActionDeposit, deposit, func(ctx context.Context, tx storage.Tx) error {
+		if err := authorizeScope(ctx, auth.ScopeDeposit); err != nil {
+			return err
+		}
+
+		// The wallet may be created right before the deposit, a replica may not have it yet.
+		wallet, err := s.repo.GetWallet(consistency.Strong(ctx), deposit.Wallet)
+		if err != nil {
+			return ErrDatabase.Wrap(err)
+		}
+		if wallet == nil {
+			return walletNotFound(ctx, deposit.Wallet, ErrWalletNotFound, auth.ScopeDeposit)
+		}
+		if err := authorize(ctx, wallet.Name, wallet.Owner, auth.ScopeDeposit); err != nil {
+			return err
+		}
+
+		err = s.repo.IncreaseWalletBalanceTx(ctx, tx, deposit.Wallet, deposit.Amount.GetInt())
+		if err != nil {
+			return ErrDatabase.Wrap(err)
+		}
+		return nil
+	})
+}
+
+// Transfer transfers money from one wallet to another.
+// The caller must be allowed to debit WalletFrom, any wallet can be credited.
+func (s *Service) Transfer(ctx context.Context, transfer dto.Transfer) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.Transfer")
+	defer func() { tracing.End(span, err) }()
+	defer func() { observeTransfer(transfer.Amount, err) }()
+
+	if err := checkTransfer(transfer); err != nil {
+		return s.auditRejected(ctx, audit.ActionTransfer, transfer, err)
+	}
+
+	return s.audited(ctx, audit.ActionTransfer, transfer, func(ctx context.Context, tx storage.Tx) error {
+		if err := authorizeScope(ctx, auth.ScopeTransfer); err != nil {
+			return err
+		}
+
+		wallets, err := s.repo.GetWalletsForUpdateTx(ctx, tx, []string{transfer.WalletFrom, transfer.WalletTo})
 		if err != nil {
 			return ErrDatabase.Wrap(fmt.Errorf("get wallets for update: %w", err))
 		}
 
 		if len(wallets) < 2 {
-			if len(wallets) == 0 {
-				return httperr.New(http.StatusNotFound, "wallets not found")
-			}
-			if wallets[0].Name == transfer.WalletFrom {
+			// The sender is authorized first, a missing wallet is reported only to callers allowed to debit it.
+			if len(wallets) == 1 && wallets[0].Name == transfer.WalletFrom {
+				if err := authorize(ctx, wallets[0].Name, wallets[0].Owner, auth.ScopeTransfer); err != nil {
+					return err
+				}
 				return httperr.New(http.StatusNotFound, "%s not found", transfer.WalletTo)
 			}
-			return httperr.New(http.StatusNotFound, "%s not found", transfer.WalletFrom)
+			notFound := httperr.New(http.StatusNotFound, "%s not found", transfer.WalletFrom)
+			if len(wallets) == 0 {
+				notFound = httperr.New(http.StatusNotFound, "wallets not found")
+			}
+			return walletNotFound(ctx, transfer.WalletFrom, notFound, auth.ScopeTransfer)
 		}
 
 		for _, w := range wallets {
 			if w.Name == transfer.WalletFrom {
+				if err := authorize(ctx, w.Name, w.Owner, auth.ScopeTransfer); err != nil {
+					return err
+				}
 				if w.Balance < transfer.Amount.GetInt() {
 					return httperr.New(http.StatusUnprocessableEntity, "not enough money")
 				}
@@ -104,7 +194,7 @@ func (s *Service) Transfer(transfer dto.Transfer) error {
 			}
 		}
 
-		err = s.repo.TransferTx(tx, transfer.WalletFrom, transfer.WalletTo, transfer.Amount.GetInt())
+		err = s.repo.TransferTx(ctx, tx, transfer.WalletFrom, transfer.WalletTo, transfer.Amount.GetInt())
 		if err != nil {
 			return ErrDatabase.Wrap(fmt.Errorf("transfer: %w", err))
 		}
@@ -113,33 +203,234 @@ func (s *Service) Transfer(transfer dto.Transfer) error {
 	})
 }
 
-// GetOperations provides operations for the specified wallet according to filtering parameters.
-func (s *Service) GetOperations(filter dto.OperationsFilter) ([]dto.Operation, error) {
-	if filter.Wallet == "" {
-		return nil, ErrEmptyWalletName
+// checkDeposit validates the deposit request.
+func checkDeposit(deposit dto.Deposit) error {
+	if deposit.Wallet == "" {
+		return ErrEmptyWalletName
+	}
+	if deposit.Amount <= 0 {
+		return ErrNotPositiveAmount
+	}
+	// NaN passes the check above, so do infinity and amounts whose cents overflow a bigint.
+	if !deposit.Amount.InRange() {
+		return ErrWrongAmount
+	}
+	return nil
+}
+
+// checkTransfer validates the transfer request.
+func checkTransfer(transfer dto.Transfer) error {
+	if transfer.WalletFrom == "" {
+		return ErrEmptyWalletFrom
 	}
-	if filter.Type != "" && filter.Type != consts.OperationTypeDeposit && filter.Type != consts.OperationTypeWithdrawal {
-		return nil, ErrUnsupportedOperationType
+	if transfer.WalletTo == "" {
+		return ErrEmptyWalletTo
 	}
-	if filter.StartDate < 0 {
-		return nil, ErrNegativeStartDate
+	if transfer.WalletFrom == transfer.WalletTo {
+		return ErrSameWallets
 	}
-	if filter.EndDate < 0 {
-		return nil, ErrNegativeEndDate
+	if transfer.Amount <= 0 {
+		return ErrNotPositiveAmount
 	}
-	if filter.Limit < 0 {
-		return nil, ErrNotPositiveLimit
+	// NaN passes the check above, so do infinity and amounts whose cents overflow a bigint.
+	if !transfer.Amount.InRange() {
+		return ErrWrongAmount
 	}
+	return nil
+}
+
+// GetOperations provides operations for the specified wallet according to filtering parameters.
+func (s *Service) GetOperations(ctx context.Context, filter dto.OperationsFilter) (_ []dto.Operation, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetOperations")
+	defer func() { tracing.End(span, err) }()
+
+	return s.getOperations(ctx, filter)
+}
+
+// GetOperationsPage provides a page of operations for the specified wallet according to filtering parameters
+// with cursors of the next and the previous pages.
+func (s *Service) GetOperationsPage(ctx context.Context, filter dto.OperationsFilter) (_ *dto.OperationsPage, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetOperationsPage")
+	defer func() { tracing.End(span, err) }()
+
 	if filter.Limit == 0 {
 		filter.Limit = consts.OperationsLimitDefault
 	}
-	if filter.Offset < 0 {
-		return nil, ErrNegativeOffset
+	limit := filter.Limit
+	// One more operation tells whether there is a page beyond this one.
+	if limit > 0 {
+		filter.Limit++
+	}
+
+	operations, err := s.getOperations(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	backward := filter.Cursor != nil && filter.Cursor.Backward
+	more := int64(len(operations)) > limit
+	if more && backward {
+		operations = operations[1:]
+	} else if more {
+		operations = operations[:limit]
+	}
+
+	page := &dto.OperationsPage{Operations: operations}
+	if len(operations) == 0 {
+		return page, nil
+	}
+	if backward || more {
+		last := operations[len(operations)-1]
+		page.NextCursor = dto.OperationsCursor{CreatedAt: last.Timestamp, ID: last.ID}.Encode()
+	}
+	if backward && more || !backward && (filter.Cursor != nil || filter.Offset > 0) {
+		first := operations[0]
+		page.PrevCursor = dto.OperationsCursor{CreatedAt: first.Timestamp, ID: first.ID, Backward: true}.Encode()
+	}
+	return page, nil
+}
+
+// ScanOperations calls f for every operation selected by filter, all of them if the limit is 0.
+// Operations are streamed from the database, so any number of them can be exported.
+// The error of f is returned as is, the scan stops on it.
+func (s *Service) ScanOperations(ctx context.Context, filter dto.OperationsFilter, f func(dto.Operation) error) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.ScanOperations")
+	defer func() { tracing.End(span, err) }()
+
+	if err := s.checkOperationsFilter(ctx, filter); err != nil {
+		return err
+	}
+
+	var fErr error
+	err = s.repo.ScanOperations(ctx, filter, func(op dto.Operation) error {
+		fErr = f(op)
+		return fErr
+	})
+	if fErr != nil {
+		return fErr
+	}
+	if err != nil {
+		return ErrDatabase.Wrap(err)
+	}
+	return nil
+}
+
+// GetOperationsSummary provides the balances of the wallet at the bounds of the filter period
+// and the totals of the operations selected by filter, like a bank statement does.
+// The balances include all operations of the wallet, other filters apply only to the totals.
+func (s *Service) GetOperationsSummary(ctx context.Context, filter dto.OperationsFilter) (_ *dto.OperationsSummary, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetOperationsSummary")
+	defer func() { tracing.End(span, err) }()
+
+	if err := s.checkOperationsFilter(ctx, filter); err != nil {
+		return nil, err
+	}
+	summary, _, err := s.operationsSummary(ctx, filter)
+	return summary, err
+}
+
+// operationsSummary computes the summary of the valid filter allowed to read. Balances and totals are counted
+// in cents and converted to amounts only in the summary, the opening balance is returned in cents too.
+func (s *Service) operationsSummary(ctx context.Context, filter dto.OperationsFilter) (_ *dto.OperationsSummary, opening int64, err error) {
+	summary := &dto.OperationsSummary{
+		Wallet:    filter.Wallet,
+		Currency:  s.currency,
+		StartDate: filter.StartDate,
+		EndDate:   filter.EndDate,
+	}
+	totals, err := s.repo.GetOperationsTotals(ctx, dto.OperationsFilter{
+		Wallet:      filter.Wallet,
+		Types:       filter.Types,
+		OtherWallet: filter.OtherWallet,
+		MinAmount:   filter.MinAmount,
+		MaxAmount:   filter.MaxAmount,
+		StartDate:   filter.StartDate,
+		EndDate:     filter.EndDate,
+	})
+	if err != nil {
+		return nil, 0, ErrDatabase.Wrap(err)
+	}
+	summary.OperationsTotals = *totals
+	summary.SetAmounts()
+
+	if !filter.StartDate.IsZero() {
+		// Bounds are inclusive, the opening balance is the balance right before the start.
+		opening, _, err = s.balanceAt(ctx, filter.Wallet, filter.StartDate.Add(-time.Microsecond))
+		if err != nil {
+			return nil, 0, err
+		}
+		summary.OpeningBalance = dto.Amount(opening) / 100
+	}
+
+	closing, _, err := s.balanceAt(ctx, filter.Wallet, filter.EndDate)
+	if err != nil {
+		return nil, 0, err
+	}
+	summary.ClosingBalance = dto.Amount(closing) / 100
+
+	return summary, opening, nil
+}
+
+func (s *Service) getOperations(ctx context.Context, filter dto.OperationsFilter) ([]dto.Operation, error) {
+	if err := s.checkOperationsFilter(ctx, filter); err != nil {
+		return nil, err
+	}
+	if filter.Limit == 0 {
+		filter.Limit = consts.OperationsLimitDefault
 	}
 
-	operations, err := s.repo.GetOperations(filter)
+	operations, err := s.repo.GetOperations(ctx, filter)
 	if err != nil {
 		return nil, ErrDatabase.Wrap(err)
 	}
 	return operations, nil
 }
+
+// checkOperationsFilter validates the filter and checks the wallet is allowed to read.
+func (s *Service) checkOperationsFilter(ctx context.Context, filter dto.OperationsFilter) error {
+	if filter.Wallet == "" {
+		return ErrEmptyWalletName
+	}
+	for _, t := range filter.Types {
+		if t != consts.OperationTypeDeposit && t != consts.OperationTypeWithdrawal {
+			return ErrUnsupportedOperationType
+		}
+	}
+	if filter.MinAmount < 0 {
+		return ErrNegativeMinAmount
+	}
+	if filter.MaxAmount < 0 {
+		return ErrNegativeMaxAmount
+	}
+	if !filter.MinAmount.InRange() {
+		return ErrWrongMinAmount
+	}
+	if !filter.MaxAmount.InRange() {
+		return ErrWrongMaxAmount
+	}
+	if filter.MinAmount > 0 && filter.MaxAmount > 0 && filter.MinAmount > filter.MaxAmount {
+		return ErrWrongAmountRange
+	}
+	if !filter.StartDate.IsZero() && filter.StartDate.Unix() < 0 {
+		return ErrNegativeStartDate
+	}
+	if !filter.EndDate.IsZero() && filter.EndDate.Unix() < 0 {
+		return ErrNegativeEndDate
+	}
+	if !filter.StartDate.IsZero() && !filter.EndDate.IsZero() && filter.StartDate.After(filter.EndDate) {
+		return ErrWrongDateRange
+	}
+	if filter.Limit < 0 {
+		return ErrNotPositiveLimit
+	}
+	if filter.Offset < 0 {
+		return ErrNegativeOffset
+	}
+	if filter.Order != "" && filter.Order != consts.OperationsOrderAsc && filter.Order != consts.OperationsOrderDesc {
+		return ErrUnsupportedOrder
+	}
+	if filter.Cursor != nil && filter.Offset > 0 {
+		return ErrCursorWithOffset
+	}
+	return s.authorizeWallet(ctx, filter.Wallet, auth.ScopeRead)
+}
@@ -1,17 +1,26 @@
 package service
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
 
+	"github.com/ezhdanovskiy/wallets/internal/audit"
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/consistency"
 	"github.com/ezhdanovskiy/wallets/internal/consts"
 	"github.com/ezhdanovskiy/wallets/internal/dto"
 	"github.com/ezhdanovskiy/wallets/internal/service/mocks"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
 )
 
 const logsEnabled = false
@@ -26,9 +35,10 @@ func TestService_CreateWallet(t *testing.T) {
 	t.Run("empty wallet name", func(t *testing.T) {
 		ts := newTestService(t)
 		defer ts.Finish()
+		ts.expectAudit(audit.ActionCreateWallet, audit.ResultError)
 
 		req := dto.CreateWalletRequest{Name: ""}
-		err := ts.svc.CreateWallet(req)
+		err := ts.svc.CreateWallet(context.Background(), req)
 		assert.Equal(t, ErrEmptyWalletName, err)
 	})
 
@@ -36,11 +46,13 @@ func TestService_CreateWallet(t *testing.T) {
 		ts := newTestService(t)
 		defer ts.Finish()
 
-		ts.mockRepo.EXPECT().CreateWallet(testWalletName01).
+		ts.expectTx()
+		ts.mockRepo.EXPECT().CreateWalletTx(gomock.Any(), gomock.Any(), testWalletName01, "").
 			Return(sql.ErrConnDone)
+		ts.expectAudit(audit.ActionCreateWallet, audit.ResultError)
 
 		req := dto.CreateWalletRequest{Name: testWalletName01}
-		err := ts.svc.CreateWallet(req)
+		err := ts.svc.CreateWallet(context.Background(), req)
 		assert.Equal(t, ErrDatabase.Wrap(sql.ErrConnDone), err)
 	})
 
@@ -48,52 +60,162 @@ func TestService_CreateWallet(t *testing.T) {
 		ts := newTestService(t)
 		defer ts.Finish()
 
-		ts.mockRepo.EXPECT().CreateWallet(testWalletName01).
+		ts.expectTx()
+		ts.mockRepo.EXPECT().CreateWalletTx(gomock.Any(), gomock.Any(), testWalletName01, "").
 			Return(nil)
+		ts.expectAudit(audit.ActionCreateWallet, audit.ResultOK)
 
 		req := dto.CreateWalletRequest{Name: testWalletName01}
-		err := ts.svc.CreateWallet(req)
+		err := ts.svc.CreateWallet(context.Background(), req)
 		assert.NoError(t, err)
 	})
 }
 
+func TestService_GetWallet(t *testing.T) {
+	t.Run("empty wallet name", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		wallet, err := ts.svc.GetWallet(context.Background(), "")
+		assert.Nil(t, wallet)
+		assert.Equal(t, ErrEmptyWalletName, err)
+	})
+
+	t.Run("database connection error", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).
+			Return(nil, sql.ErrConnDone)
+
+		wallet, err := ts.svc.GetWallet(context.Background(), testWalletName01)
+		assert.Nil(t, wallet)
+		assert.Equal(t, ErrDatabase.Wrap(sql.ErrConnDone), err)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).
+			Return(nil, nil)
+
+		wallet, err := ts.svc.GetWallet(context.Background(), testWalletName01)
+		assert.Nil(t, wallet)
+		assert.Equal(t, ErrWalletNotFound, err)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).
+			Return(&dto.Wallet{
+				Name:    testWalletName01,
+				Balance: testAmount.GetInt(),
+			}, nil)
+
+		wallet, err := ts.svc.GetWallet(context.Background(), testWalletName01)
+		require.NoError(t, err)
+		require.NotNil(t, wallet)
+		assert.Equal(t, testAmount.GetInt(), wallet.Balance)
+	})
+}
+
+func TestService_ListWallets(t *testing.T) {
+	t.Run("negative limit", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		_, err := ts.svc.ListWallets(context.Background(), dto.WalletsFilter{Limit: -1})
+		assert.Equal(t, ErrNotPositiveLimit, err)
+	})
+
+	t.Run("not admin", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{Scopes: []string{auth.ScopeRead}, Wallets: []string{auth.AllWallets}})
+		_, err := ts.svc.ListWallets(ctx, dto.WalletsFilter{})
+		assert.Equal(t, ErrPermissionDenied, err)
+	})
+
+	t.Run("database connection error", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().ListWallets(gomock.Any(), gomock.Any()).Return(nil, sql.ErrConnDone)
+
+		_, err := ts.svc.ListWallets(context.Background(), dto.WalletsFilter{})
+		assert.Equal(t, ErrDatabase.Wrap(sql.ErrConnDone), err)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		wallets := []dto.Wallet{{Name: testWalletName01, Owner: "alice"}, {Name: testWalletName02, Owner: "alice"}}
+		ts.mockRepo.EXPECT().ListWallets(gomock.Any(), dto.WalletsFilter{Owner: "alice", Limit: consts.WalletsLimitDefault}).
+			Return(wallets, nil)
+
+		got, err := ts.svc.ListWallets(context.Background(), dto.WalletsFilter{Owner: "alice"})
+		require.NoError(t, err)
+		assert.Equal(t, wallets, got)
+	})
+}
+
 func TestService_IncreaseWalletBalance(t *testing.T) {
 	t.Run("empty wallet name", func(t *testing.T) {
 		ts := newTestService(t)
 		defer ts.Finish()
+		ts.expectAudit(audit.ActionDeposit, audit.ResultError)
 
 		deposit := dto.Deposit{
 			Wallet: "",
 			Amount: testAmount,
 		}
-		err := ts.svc.IncreaseWalletBalance(deposit)
+		err := ts.svc.IncreaseWalletBalance(context.Background(), deposit)
 		assert.Equal(t, ErrEmptyWalletName, err)
 	})
 
 	t.Run("negative amount", func(t *testing.T) {
 		ts := newTestService(t)
 		defer ts.Finish()
+		ts.expectAudit(audit.ActionDeposit, audit.ResultError)
 
 		deposit := dto.Deposit{
 			Wallet: testWalletName01,
 			Amount: -1,
 		}
-		err := ts.svc.IncreaseWalletBalance(deposit)
+		err := ts.svc.IncreaseWalletBalance(context.Background(), deposit)
 		assert.Equal(t, ErrNotPositiveAmount, err)
 	})
 
+	t.Run("wrong amount", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		for _, amount := range []float64{math.NaN(), math.Inf(1), 1e17} {
+			ts.expectAudit(audit.ActionDeposit, audit.ResultError)
+			err := ts.svc.IncreaseWalletBalance(context.Background(), dto.Deposit{Wallet: testWalletName01, Amount: dto.Amount(amount)})
+			assert.Equal(t, ErrWrongAmount, err, amount)
+		}
+	})
+
 	t.Run("database connection error", func(t *testing.T) {
 		ts := newTestService(t)
 		defer ts.Finish()
 
-		ts.mockRepo.EXPECT().GetWallet(testWalletName01).
+		ts.expectTx()
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).
 			Return(nil, sql.ErrConnDone)
+		ts.expectAudit(audit.ActionDeposit, audit.ResultError)
 
 		deposit := dto.Deposit{
 			Wallet: testWalletName01,
 			Amount: testAmount,
 		}
-		err := ts.svc.IncreaseWalletBalance(deposit)
+		err := ts.svc.IncreaseWalletBalance(context.Background(), deposit)
 		assert.Equal(t, ErrDatabase.Wrap(sql.ErrConnDone), err)
 	})
 
@@ -101,14 +223,16 @@ func TestService_IncreaseWalletBalance(t *testing.T) {
 		ts := newTestService(t)
 		defer ts.Finish()
 
-		ts.mockRepo.EXPECT().GetWallet(testWalletName01).
+		ts.expectTx()
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).
 			Return(nil, nil)
+		ts.expectAudit(audit.ActionDeposit, audit.ResultError)
 
 		deposit := dto.Deposit{
 			Wallet: testWalletName01,
 			Amount: testAmount,
 		}
-		err := ts.svc.IncreaseWalletBalance(deposit)
+		err := ts.svc.IncreaseWalletBalance(context.Background(), deposit)
 		assert.Equal(t, ErrWalletNotFound, err)
 	})
 
@@ -116,19 +240,22 @@ func TestService_IncreaseWalletBalance(t *testing.T) {
 		ts := newTestService(t)
 		defer ts.Finish()
 
-		ts.mockRepo.EXPECT().GetWallet(testWalletName01).
+		ts.expectTx()
+		strongCtx := gomock.Cond(func(x any) bool { return consistency.IsStrong(x.(context.Context)) })
+		ts.mockRepo.EXPECT().GetWallet(strongCtx, testWalletName01).
 			Return(&dto.Wallet{
 				Name:    testWalletName01,
 				Balance: testAmount.GetInt(),
 			}, nil)
-		ts.mockRepo.EXPECT().IncreaseWalletBalance(testWalletName01, testAmount.GetInt()).
+		ts.mockRepo.EXPECT().IncreaseWalletBalanceTx(gomock.Any(), gomock.Any(), testWalletName01, testAmount.GetInt()).
 			Return(nil)
+		ts.expectAudit(audit.ActionDeposit, audit.ResultOK)
 
 		deposit := dto.Deposit{
 			Wallet: testWalletName01,
 			Amount: testAmount,
 		}
-		err := ts.svc.IncreaseWalletBalance(deposit)
+		err := ts.svc.IncreaseWalletBalance(context.Background(), deposit)
 		require.NoError(t, err)
 	})
 }
@@ -137,54 +264,70 @@ func TestService_Transfer(t *testing.T) {
 	t.Run("empty wallet_from", func(t *testing.T) {
 		ts := newTestService(t)
 		defer ts.Finish()
+		ts.expectAudit(audit.ActionTransfer, audit.ResultError)
 
 		transfer := dto.Transfer{
 			WalletFrom: "",
 			WalletTo:   testWalletName02,
 			Amount:     testAmount,
 		}
-		err := ts.svc.Transfer(transfer)
+		err := ts.svc.Transfer(context.Background(), transfer)
 		assert.Equal(t, ErrEmptyWalletFrom, err)
 	})
 
 	t.Run("empty wallet_to", func(t *testing.T) {
 		ts := newTestService(t)
 		defer ts.Finish()
+		ts.expectAudit(audit.ActionTransfer, audit.ResultError)
 
 		transfer := dto.Transfer{
 			WalletFrom: testWalletName01,
 			WalletTo:   "",
 			Amount:     testAmount,
 		}
-		err := ts.svc.Transfer(transfer)
+		err := ts.svc.Transfer(context.Background(), transfer)
 		assert.Equal(t, ErrEmptyWalletTo, err)
 	})
 
 	t.Run("same wallets", func(t *testing.T) {
 		ts := newTestService(t)
 		defer ts.Finish()
+		ts.expectAudit(audit.ActionTransfer, audit.ResultError)
 
 		transfer := dto.Transfer{
 			WalletFrom: testWalletName01,
 			WalletTo:   testWalletName01,
 			Amount:     testAmount,
 		}
-		err := ts.svc.Transfer(transfer)
+		err := ts.svc.Transfer(context.Background(), transfer)
 		assert.Equal(t, ErrSameWallets, err)
 	})
 
 	t.Run("negative amount", func(t *testing.T) {
 		ts := newTestService(t)
 		defer ts.Finish()
+		ts.expectAudit(audit.ActionTransfer, audit.ResultError)
 
 		transfer := dto.Transfer{
 			WalletFrom: testWalletName01,
 			WalletTo:   testWalletName02,
 			Amount:     -1,
 		}
-		err := ts.svc.Transfer(transfer)
+		err := ts.svc.Transfer(context.Background(), transfer)
 		assert.Equal(t, ErrNotPositiveAmount, err)
 	})
+
+	t.Run("wrong amount", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		for _, amount := range []float64{math.NaN(), math.Inf(1), 1e17} {
+			ts.expectAudit(audit.ActionTransfer, audit.ResultError)
+			err := ts.svc.Transfer(context.Background(), dto.Transfer{WalletFrom: testWalletName01, WalletTo: testWalletName02,
+				Amount: dto.Amount(amount)})
+			assert.Equal(t, ErrWrongAmount, err, amount)
+		}
+	})
 }
 
 func TestService_GetOperations(t *testing.T) {
@@ -192,7 +335,7 @@ func TestService_GetOperations(t *testing.T) {
 		ts := newTestService(t)
 		defer ts.Finish()
 
-		ops, err := ts.svc.GetOperations(dto.OperationsFilter{})
+		ops, err := ts.svc.GetOperations(context.Background(), dto.OperationsFilter{})
 		assert.Nil(t, ops)
 		assert.Equal(t, ErrEmptyWalletName, err)
 	})
@@ -203,9 +346,9 @@ func TestService_GetOperations(t *testing.T) {
 
 		filter := dto.OperationsFilter{
 			Wallet: testWalletName01,
-			Type:   "123",
+			Types:  []string{consts.OperationTypeDeposit, "123"},
 		}
-		ops, err := ts.svc.GetOperations(filter)
+		ops, err := ts.svc.GetOperations(context.Background(), filter)
 		assert.Nil(t, ops)
 		assert.Equal(t, ErrUnsupportedOperationType, err)
 	})
@@ -216,9 +359,9 @@ func TestService_GetOperations(t *testing.T) {
 
 		filter := dto.OperationsFilter{
 			Wallet:    testWalletName01,
-			StartDate: -1,
+			StartDate: time.Unix(-1, 0),
 		}
-		ops, err := ts.svc.GetOperations(filter)
+		ops, err := ts.svc.GetOperations(context.Background(), filter)
 		assert.Nil(t, ops)
 		assert.Equal(t, ErrNegativeStartDate, err)
 	})
@@ -229,9 +372,9 @@ func TestService_GetOperations(t *testing.T) {
 
 		filter := dto.OperationsFilter{
 			Wallet:  testWalletName01,
-			EndDate: -1,
+			EndDate: time.Unix(-1, 0),
 		}
-		ops, err := ts.svc.GetOperations(filter)
+		ops, err := ts.svc.GetOperations(context.Background(), filter)
 		assert.Nil(t, ops)
 		assert.Equal(t, ErrNegativeEndDate, err)
 	})
@@ -244,7 +387,7 @@ func TestService_GetOperations(t *testing.T) {
 			Wallet: testWalletName01,
 			Limit:  -1,
 		}
-		ops, err := ts.svc.GetOperations(filter)
+		ops, err := ts.svc.GetOperations(context.Background(), filter)
 		assert.Nil(t, ops)
 		assert.Equal(t, ErrNotPositiveLimit, err)
 	})
@@ -257,22 +400,101 @@ func TestService_GetOperations(t *testing.T) {
 			Wallet: testWalletName01,
 			Offset: -1,
 		}
-		ops, err := ts.svc.GetOperations(filter)
+		ops, err := ts.svc.GetOperations(context.Background(), filter)
 		assert.Nil(t, ops)
 		assert.Equal(t, ErrNegativeOffset, err)
 	})
 
+	for _, tc := range []struct {
+		name    string
+		filter  dto.OperationsFilter
+		wantErr error
+	}{
+		{
+			name:    "negative min_amount",
+			filter:  dto.OperationsFilter{MinAmount: -1},
+			wantErr: ErrNegativeMinAmount,
+		},
+		{
+			name:    "negative max_amount",
+			filter:  dto.OperationsFilter{MaxAmount: -1},
+			wantErr: ErrNegativeMaxAmount,
+		},
+		{
+			name:    "min_amount is NaN",
+			filter:  dto.OperationsFilter{MinAmount: dto.Amount(math.NaN())},
+			wantErr: ErrWrongMinAmount,
+		},
+		{
+			name:    "infinite max_amount",
+			filter:  dto.OperationsFilter{MaxAmount: dto.Amount(math.Inf(1))},
+			wantErr: ErrWrongMaxAmount,
+		},
+		{
+			name:    "max_amount out of bigint cents",
+			filter:  dto.OperationsFilter{MaxAmount: 1e17},
+			wantErr: ErrWrongMaxAmount,
+		},
+		{
+			name:    "min_amount greater than max_amount",
+			filter:  dto.OperationsFilter{MinAmount: 10, MaxAmount: 5},
+			wantErr: ErrWrongAmountRange,
+		},
+		{
+			name:    "start_date after end_date",
+			filter:  dto.OperationsFilter{StartDate: time.Unix(200, 0), EndDate: time.Unix(100, 0)},
+			wantErr: ErrWrongDateRange,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := newTestService(t)
+			defer ts.Finish()
+
+			tc.filter.Wallet = testWalletName01
+			ops, err := ts.svc.GetOperations(context.Background(), tc.filter)
+			assert.Nil(t, ops)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+
+	t.Run("unsupported order", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		filter := dto.OperationsFilter{
+			Wallet: testWalletName01,
+			Order:  "random",
+		}
+		ops, err := ts.svc.GetOperations(context.Background(), filter)
+		assert.Nil(t, ops)
+		assert.Equal(t, ErrUnsupportedOrder, err)
+	})
+
+	t.Run("cursor with offset", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		filter := dto.OperationsFilter{
+			Wallet: testWalletName01,
+			Offset: 10,
+			Cursor: &dto.OperationsCursor{CreatedAt: time.Now(), ID: 1},
+		}
+		ops, err := ts.svc.GetOperations(context.Background(), filter)
+		assert.Nil(t, ops)
+		assert.Equal(t, ErrCursorWithOffset, err)
+	})
+
 	t.Run("database connection error", func(t *testing.T) {
 		ts := newTestService(t)
 		defer ts.Finish()
 
-		ts.mockRepo.EXPECT().GetOperations(dto.OperationsFilter{
+		ts.mockRepo.EXPECT().GetOperations(gomock.Any(), dto.OperationsFilter{
 			Wallet: testWalletName01,
 			Limit:  consts.OperationsLimitDefault,
 		}).
 			Return(nil, sql.ErrConnDone)
 
-		ops, err := ts.svc.GetOperations(dto.OperationsFilter{
+		ops, err := ts.svc.GetOperations(context.Background(), dto.OperationsFilter{
 			Wallet: testWalletName01,
 		})
 		assert.Nil(t, ops)
@@ -283,7 +505,7 @@ func TestService_GetOperations(t *testing.T) {
 		ts := newTestService(t)
 		defer ts.Finish()
 
-		ts.mockRepo.EXPECT().GetOperations(dto.OperationsFilter{
+		ts.mockRepo.EXPECT().GetOperations(gomock.Any(), dto.OperationsFilter{
 			Wallet: testWalletName01,
 			Limit:  consts.OperationsLimitDefault,
 		}).
@@ -293,7 +515,7 @@ func TestService_GetOperations(t *testing.T) {
 				Type:   consts.OperationTypeDeposit,
 			}}, nil)
 
-		ops, err := ts.svc.GetOperations(dto.OperationsFilter{
+		ops, err := ts.svc.GetOperations(context.Background(), dto.OperationsFilter{
 			Wallet: testWalletName01,
 		})
 		assert.NoError(t, err)
@@ -302,6 +524,251 @@ func TestService_GetOperations(t *testing.T) {
 	})
 }
 
+func TestService_ScanOperations(t *testing.T) {
+	op := dto.Operation{ID: 1, Wallet: testWalletName01, Amount: testAmount, Type: consts.OperationTypeDeposit}
+	errWrite := errors.New("write failed")
+
+	tests := []struct {
+		name      string
+		filter    dto.OperationsFilter
+		repoErr   error
+		writeErr  error
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "success",
+			filter:    dto.OperationsFilter{Wallet: testWalletName01},
+			wantCalls: 2,
+		},
+		{
+			name:    "invalid filter",
+			filter:  dto.OperationsFilter{Wallet: testWalletName01, Types: []string{"123"}},
+			wantErr: ErrUnsupportedOperationType,
+		},
+		{
+			name:      "database error",
+			filter:    dto.OperationsFilter{Wallet: testWalletName01},
+			repoErr:   sql.ErrConnDone,
+			wantErr:   ErrDatabase.Wrap(sql.ErrConnDone),
+			wantCalls: 2,
+		},
+		{
+			name:      "write error",
+			filter:    dto.OperationsFilter{Wallet: testWalletName01},
+			writeErr:  errWrite,
+			wantErr:   errWrite,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t)
+			defer ts.Finish()
+
+			if tt.wantErr != ErrUnsupportedOperationType {
+				// The limit isn't defaulted, all operations are scanned.
+				ts.mockRepo.EXPECT().ScanOperations(gomock.Any(), tt.filter, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ dto.OperationsFilter, f func(dto.Operation) error) error {
+						for i := 0; i < 2; i++ {
+							if err := f(op); err != nil {
+								return fmt.Errorf("wrapped: %w", err)
+							}
+						}
+						return tt.repoErr
+					})
+			}
+
+			var calls int
+			err := ts.svc.ScanOperations(context.Background(), tt.filter, func(dto.Operation) error {
+				calls++
+				return tt.writeErr
+			})
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestService_GetOperationsSummary(t *testing.T) {
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 9, 30, 23, 59, 59, 999999000, time.UTC)
+	period := dto.OperationsTotals{
+		Deposits:    dto.OperationsTotal{Count: 2, Cents: 3000},
+		Withdrawals: dto.OperationsTotal{Count: 1, Cents: 500},
+	}
+	before := dto.OperationsTotals{Deposits: dto.OperationsTotal{Count: 1, Cents: 10000}}
+	untilEnd := dto.OperationsTotals{
+		Deposits:    dto.OperationsTotal{Count: 4, Cents: 15000},
+		Withdrawals: dto.OperationsTotal{Count: 1, Cents: 500},
+	}
+
+	t.Run("period", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		filter := dto.OperationsFilter{Wallet: testWalletName01, Types: []string{consts.OperationTypeDeposit},
+			StartDate: start, EndDate: end, Order: consts.OperationsOrderDesc, Limit: 10}
+		gomock.InOrder(
+			ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), dto.OperationsFilter{Wallet: testWalletName01,
+				Types: []string{consts.OperationTypeDeposit}, StartDate: start, EndDate: end}).Return(&period, nil),
+			ts.mockRepo.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), testWalletName01, start.Add(-time.Microsecond)).
+				Return(nil, nil),
+			ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), dto.OperationsFilter{Wallet: testWalletName01,
+				EndDate: start.Add(-time.Microsecond)}).Return(&before, nil),
+			ts.mockRepo.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), testWalletName01, end).Return(nil, nil),
+			ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), dto.OperationsFilter{Wallet: testWalletName01,
+				EndDate: end}).Return(&untilEnd, nil),
+		)
+
+		summary, err := ts.svc.GetOperationsSummary(context.Background(), filter)
+		require.NoError(t, err)
+		assert.Equal(t, &dto.OperationsSummary{
+			Wallet:         testWalletName01,
+			Currency:       "EUR",
+			StartDate:      start,
+			EndDate:        end,
+			OpeningBalance: 100,
+			ClosingBalance: 145,
+			OperationsTotals: dto.OperationsTotals{
+				Deposits:    dto.OperationsTotal{Count: 2, Amount: 30, Cents: 3000},
+				Withdrawals: dto.OperationsTotal{Count: 1, Amount: 5, Cents: 500},
+			},
+		}, summary)
+	})
+
+	t.Run("whole history", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		filter := dto.OperationsFilter{Wallet: testWalletName01}
+		ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), filter).Return(&untilEnd, nil).Times(2)
+
+		summary, err := ts.svc.GetOperationsSummary(context.Background(), filter)
+		require.NoError(t, err)
+		assert.Equal(t, dto.Amount(0), summary.OpeningBalance)
+		assert.Equal(t, dto.Amount(145), summary.ClosingBalance)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		_, err := ts.svc.GetOperationsSummary(context.Background(), dto.OperationsFilter{Wallet: testWalletName01,
+			StartDate: end, EndDate: start})
+		assert.Equal(t, ErrWrongDateRange, err)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), gomock.Any()).Return(nil, sql.ErrConnDone)
+
+		_, err := ts.svc.GetOperationsSummary(context.Background(), dto.OperationsFilter{Wallet: testWalletName01})
+		assert.Equal(t, ErrDatabase.Wrap(sql.ErrConnDone), err)
+	})
+}
+
+func TestService_GetOperationsPage(t *testing.T) {
+	operations := func(ids ...int64) []dto.Operation {
+		ops := make([]dto.Operation, len(ids))
+		for i, id := range ids {
+			ops[i] = dto.Operation{ID: id, Wallet: testWalletName01, Timestamp: time.Unix(id, 0).UTC()}
+		}
+		return ops
+	}
+	cursor := func(id int64, backward bool) string {
+		return dto.OperationsCursor{CreatedAt: time.Unix(id, 0).UTC(), ID: id, Backward: backward}.Encode()
+	}
+
+	tests := []struct {
+		name     string
+		filter   dto.OperationsFilter
+		repoOps  []dto.Operation
+		wantIDs  []int64
+		wantNext string
+		wantPrev string
+	}{
+		{
+			name:    "single page",
+			filter:  dto.OperationsFilter{Wallet: testWalletName01, Limit: 3},
+			repoOps: operations(1, 2),
+			wantIDs: []int64{1, 2},
+		},
+		{
+			name:     "first page",
+			filter:   dto.OperationsFilter{Wallet: testWalletName01, Limit: 2},
+			repoOps:  operations(1, 2, 3),
+			wantIDs:  []int64{1, 2},
+			wantNext: cursor(2, false),
+		},
+		{
+			name: "middle page",
+			filter: dto.OperationsFilter{Wallet: testWalletName01, Limit: 2,
+				Cursor: &dto.OperationsCursor{CreatedAt: time.Unix(2, 0).UTC(), ID: 2}},
+			repoOps:  operations(3, 4, 5),
+			wantIDs:  []int64{3, 4},
+			wantNext: cursor(4, false),
+			wantPrev: cursor(3, true),
+		},
+		{
+			name: "last page",
+			filter: dto.OperationsFilter{Wallet: testWalletName01, Limit: 2,
+				Cursor: &dto.OperationsCursor{CreatedAt: time.Unix(4, 0).UTC(), ID: 4}},
+			repoOps:  operations(5),
+			wantIDs:  []int64{5},
+			wantPrev: cursor(5, true),
+		},
+		{
+			name: "previous page",
+			filter: dto.OperationsFilter{Wallet: testWalletName01, Limit: 2,
+				Cursor: &dto.OperationsCursor{CreatedAt: time.Unix(5, 0).UTC(), ID: 5, Backward: true}},
+			repoOps:  operations(2, 3, 4),
+			wantIDs:  []int64{3, 4},
+			wantNext: cursor(4, false),
+			wantPrev: cursor(3, true),
+		},
+		{
+			name: "first page backward",
+			filter: dto.OperationsFilter{Wallet: testWalletName01, Limit: 2,
+				Cursor: &dto.OperationsCursor{CreatedAt: time.Unix(3, 0).UTC(), ID: 3, Backward: true}},
+			repoOps:  operations(1, 2),
+			wantIDs:  []int64{1, 2},
+			wantNext: cursor(2, false),
+		},
+		{
+			name:     "offset",
+			filter:   dto.OperationsFilter{Wallet: testWalletName01, Limit: 2, Offset: 2},
+			repoOps:  operations(3),
+			wantIDs:  []int64{3},
+			wantPrev: cursor(3, true),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t)
+			defer ts.Finish()
+
+			repoFilter := tt.filter
+			repoFilter.Limit++
+			ts.mockRepo.EXPECT().GetOperations(gomock.Any(), repoFilter).Return(tt.repoOps, nil)
+
+			page, err := ts.svc.GetOperationsPage(context.Background(), tt.filter)
+			require.NoError(t, err)
+			ids := make([]int64, len(page.Operations))
+			for i, op := range page.Operations {
+				ids[i] = op.ID
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+			assert.Equal(t, tt.wantNext, page.NextCursor)
+			assert.Equal(t, tt.wantPrev, page.PrevCursor)
+		})
+	}
+}
+
 // TestService ---------------------------------------------------------------------------------------------------------
 type TestService struct {
 	t        *testing.T
@@ -327,11 +794,30 @@ func newTestService(t *testing.T) TestService {
 		ts.log = zap.NewNop().Sugar()
 	}
 
-	ts.svc = NewService(ts.log, ts.mockRepo)
+	ts.svc = NewService(ts.log, ts.mockRepo, nil, "EUR")
 
 	return ts
 }
 
+// expectTx makes the mocked RunWithTransaction call the function without a real transaction.
+func (ts *TestService) expectTx() {
+	ts.mockRepo.EXPECT().RunWithTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, f func(context.Context, storage.Tx) error) error { return f(ctx, nil) })
+}
+
+// expectAudit expects the audit record with the action and result.
+func (ts *TestService) expectAudit(action, result string) {
+	matcher := gomock.Cond(func(x any) bool {
+		record, ok := x.(dto.AuditRecord)
+		return ok && record.Action == action && record.Result == result && record.PayloadHash != ""
+	})
+	if result == audit.ResultOK {
+		ts.mockRepo.EXPECT().InsertAuditRecordTx(gomock.Any(), gomock.Any(), matcher).Return(nil)
+	} else {
+		ts.mockRepo.EXPECT().InsertAuditRecord(gomock.Any(), matcher).Return(nil)
+	}
+}
+
 func (ts *TestService) Finish() {
 	ts.mockCtrl.Finish()
 }
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/consistency"
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/logging"
+	"github.com/ezhdanovskiy/wallets/internal/tracing"
+)
+
+// GetStatement provides the statement of the wallet for the month in YYYY-MM, the current month ends now.
+// Statements stored by GenerateStatements are served as is once the month is settled, others are computed
+// from operations.
+func (s *Service) GetStatement(ctx context.Context, walletName, period string) (_ *dto.Statement, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetStatement")
+	defer func() { tracing.End(span, err) }()
+
+	if walletName == "" {
+		return nil, ErrEmptyWalletName
+	}
+	start, end, err := dto.ParseStatementPeriod(period)
+	if err != nil {
+		return nil, ErrWrongStatementPeriod
+	}
+	now := time.Now().UTC()
+	if start.After(now) {
+		return nil, ErrFutureStatementPeriod
+	}
+	if err := s.authorizeWallet(ctx, walletName, auth.ScopeRead); err != nil {
+		return nil, err
+	}
+
+	if dto.StatementSettled(end, now) {
+		stored, err := s.repo.GetStatement(ctx, walletName, period)
+		if err != nil {
+			return nil, ErrDatabase.Wrap(err)
+		}
+		if stored != nil {
+			return stored, nil
+		}
+	} else if end.After(now) {
+		end = now.Truncate(time.Microsecond)
+	}
+
+	return s.buildStatement(ctx, walletName, period, start, end)
+}
+
+// GenerateStatements stores the statements of all wallets for the settled month in YYYY-MM,
+// so GetStatement serves them without reading operations. Stored statements are kept as is,
+// the number of generated ones is returned.
+func (s *Service) GenerateStatements(ctx context.Context, period string) (n int, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GenerateStatements")
+	defer func() { tracing.End(span, err) }()
+
+	if err := authorizeScope(ctx, auth.ScopeAdmin); err != nil {
+		return 0, err
+	}
+	start, end, err := dto.ParseStatementPeriod(period)
+	if err != nil {
+		return 0, ErrWrongStatementPeriod
+	}
+	if !dto.StatementSettled(end, time.Now()) {
+		return 0, ErrOpenStatementPeriod
+	}
+	// Stored statements are never updated, they must include all committed operations, a replica may lag behind.
+	ctx = consistency.Strong(ctx)
+
+	for offset := int64(0); ; offset += consts.WalletsLimitDefault {
+		wallets, err := s.repo.ListWallets(ctx, dto.WalletsFilter{Limit: consts.WalletsLimitDefault, Offset: offset})
+		if err != nil {
+			return n, ErrDatabase.Wrap(err)
+		}
+
+		for _, wallet := range wallets {
+			if ctx.Err() != nil {
+				return n, ctx.Err()
+			}
+			stored, err := s.repo.GetStatement(ctx, wallet.Name, period)
+			if err != nil {
+				return n, ErrDatabase.Wrap(err)
+			}
+			if stored != nil {
+				continue
+			}
+
+			st, err := s.buildStatement(ctx, wallet.Name, period, start, end)
+			if err != nil {
+				return n, fmt.Errorf("wallet %s: %w", wallet.Name, err)
+			}
+			if err := s.repo.InsertStatement(ctx, *st); err != nil {
+				return n, ErrDatabase.Wrap(err)
+			}
+			n++
+		}
+
+		if len(wallets) < consts.WalletsLimitDefault {
+			logging.FromContext(ctx, s.log).With("period", period, "generated", n).Info("Statements generated")
+			return n, nil
+		}
+	}
+}
+
+// buildStatement computes the statement of the wallet for the period from its operations.
+func (s *Service) buildStatement(ctx context.Context, walletName, period string, start, end time.Time) (*dto.Statement, error) {
+	wallet, err := s.repo.GetWallet(ctx, walletName)
+	if err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+	if wallet == nil {
+		return nil, ErrWalletNotFound
+	}
+
+	filter := dto.OperationsFilter{Wallet: walletName, StartDate: start, EndDate: end}
+	summary, opening, err := s.operationsSummary(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &dto.Statement{
+		Period:            period,
+		OperationsSummary: *summary,
+		Entries:           []dto.StatementEntry{},
+		GeneratedAt:       time.Now().UTC(),
+	}
+	// The balance is counted in cents, sums of float amounts drift.
+	balance := opening
+	err = s.repo.ScanOperations(ctx, filter, func(op dto.Operation) error {
+		if op.Type == consts.OperationTypeWithdrawal {
+			balance -= cents(op.Amount)
+		} else {
+			balance += cents(op.Amount)
+		}
+		st.Entries = append(st.Entries, dto.StatementEntry{Operation: op, Balance: dto.Amount(balance) / 100})
+		return nil
+	})
+	if err != nil {
+		return nil, ErrDatabase.Wrap(err)
+	}
+	return st, nil
+}
+
+func cents(a dto.Amount) int64 {
+	return int64(math.Round(float64(a) * 100))
+}
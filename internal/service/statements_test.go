@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/ezhdanovskiy/wallets/internal/auth"
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+// expectStatementOperations mocks the operations of the statement of testWalletName01 for September 2026:
+// the opening balance is 10, deposits are 0.29 and 0.29, the withdrawal is 0.58.
+func (ts *TestService) expectStatementOperations(start, end time.Time) []dto.Operation {
+	operations := []dto.Operation{
+		{ID: 1, Wallet: testWalletName01, Amount: 0.29, Type: consts.OperationTypeDeposit, Timestamp: start.Add(time.Hour)},
+		{ID: 2, Wallet: testWalletName01, Amount: 0.29, Type: consts.OperationTypeDeposit, Timestamp: start.Add(2 * time.Hour)},
+		{ID: 3, Wallet: testWalletName01, Amount: 0.58, Type: consts.OperationTypeWithdrawal, OtherWallet: testWalletName02,
+			Timestamp: start.Add(3 * time.Hour)},
+	}
+	filter := dto.OperationsFilter{Wallet: testWalletName01, StartDate: start, EndDate: end}
+
+	ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).Return(&dto.Wallet{Name: testWalletName01}, nil)
+	ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), filter).Return(&dto.OperationsTotals{
+		Deposits:    dto.OperationsTotal{Count: 2, Cents: 58},
+		Withdrawals: dto.OperationsTotal{Count: 1, Cents: 58},
+	}, nil)
+	ts.mockRepo.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), testWalletName01, gomock.Any()).Return(nil, nil).Times(2)
+	ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), dto.OperationsFilter{Wallet: testWalletName01,
+		EndDate: start.Add(-time.Microsecond)}).Return(&dto.OperationsTotals{Deposits: dto.OperationsTotal{Count: 1, Cents: 1000}}, nil)
+	ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), dto.OperationsFilter{Wallet: testWalletName01, EndDate: end}).
+		Return(&dto.OperationsTotals{
+			Deposits:    dto.OperationsTotal{Count: 3, Cents: 1058},
+			Withdrawals: dto.OperationsTotal{Count: 1, Cents: 58},
+		}, nil)
+	ts.mockRepo.EXPECT().ScanOperations(gomock.Any(), filter, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ dto.OperationsFilter, f func(dto.Operation) error) error {
+			for _, op := range operations {
+				if err := f(op); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	return operations
+}
+
+func TestService_GetStatement(t *testing.T) {
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 9, 30, 23, 59, 59, 999999000, time.UTC)
+
+	t.Run("computed", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetStatement(gomock.Any(), testWalletName01, "2026-09").Return(nil, nil)
+		operations := ts.expectStatementOperations(start, end)
+
+		st, err := ts.svc.GetStatement(context.Background(), testWalletName01, "2026-09")
+		require.NoError(t, err)
+		assert.Equal(t, "2026-09", st.Period)
+		assert.Equal(t, "EUR", st.Currency)
+		assert.Equal(t, start, st.StartDate)
+		assert.Equal(t, end, st.EndDate)
+		assert.Equal(t, dto.Amount(10), st.OpeningBalance)
+		assert.Equal(t, dto.Amount(10), st.ClosingBalance)
+		assert.Equal(t, []dto.StatementEntry{
+			{Operation: operations[0], Balance: 10.29},
+			{Operation: operations[1], Balance: 10.58},
+			{Operation: operations[2], Balance: 10},
+		}, st.Entries)
+		assert.False(t, st.GeneratedAt.IsZero())
+	})
+
+	t.Run("many small operations", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		// Sums of 0.1 in floats drift, e.g. a thousand of them add up to 99.9999999999986.
+		const n = 1000
+		operations := make([]dto.Operation, n)
+		for i := range operations {
+			operations[i] = dto.Operation{ID: int64(i + 1), Wallet: testWalletName01, Type: consts.OperationTypeDeposit,
+				Timestamp: start.Add(time.Duration(i) * time.Minute)}
+			operations[i].Amount.SetAmount(10)
+		}
+		filter := dto.OperationsFilter{Wallet: testWalletName01, StartDate: start, EndDate: end}
+		ts.mockRepo.EXPECT().GetStatement(gomock.Any(), testWalletName01, "2026-09").Return(nil, nil)
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).Return(&dto.Wallet{Name: testWalletName01}, nil)
+		ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), filter).
+			Return(&dto.OperationsTotals{Deposits: dto.OperationsTotal{Count: n, Cents: n * 10}}, nil)
+		ts.mockRepo.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), testWalletName01, gomock.Any()).Return(nil, nil).Times(2)
+		ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), dto.OperationsFilter{Wallet: testWalletName01,
+			EndDate: start.Add(-time.Microsecond)}).Return(&dto.OperationsTotals{Deposits: dto.OperationsTotal{Count: 1, Cents: 1}}, nil)
+		ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), dto.OperationsFilter{Wallet: testWalletName01, EndDate: end}).
+			Return(&dto.OperationsTotals{Deposits: dto.OperationsTotal{Count: n + 1, Cents: n*10 + 1}}, nil)
+		ts.mockRepo.EXPECT().ScanOperations(gomock.Any(), filter, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ dto.OperationsFilter, f func(dto.Operation) error) error {
+				for _, op := range operations {
+					if err := f(op); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+		st, err := ts.svc.GetStatement(context.Background(), testWalletName01, "2026-09")
+		require.NoError(t, err)
+		assert.Equal(t, dto.Amount(0.01), st.OpeningBalance)
+		assert.Equal(t, dto.Amount(100.01), st.ClosingBalance)
+		assert.Equal(t, dto.Amount(100), st.Deposits.Amount)
+		require.Len(t, st.Entries, n)
+		for i, entry := range st.Entries {
+			assert.Equal(t, dto.Amount(1+10*(i+1))/100, entry.Balance, i)
+		}
+		assert.Equal(t, dto.Amount(100.01), st.Entries[n-1].Balance)
+	})
+
+	t.Run("stored", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		stored := &dto.Statement{Period: "2026-09", OperationsSummary: dto.OperationsSummary{Wallet: testWalletName01}}
+		ts.mockRepo.EXPECT().GetStatement(gomock.Any(), testWalletName01, "2026-09").Return(stored, nil)
+
+		st, err := ts.svc.GetStatement(context.Background(), testWalletName01, "2026-09")
+		require.NoError(t, err)
+		assert.Equal(t, stored, st)
+	})
+
+	t.Run("current month", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		period := time.Now().UTC().Format(dto.StatementPeriodLayout)
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).Return(&dto.Wallet{Name: testWalletName01}, nil)
+		ts.mockRepo.EXPECT().GetOperationsTotals(gomock.Any(), gomock.Any()).Return(&dto.OperationsTotals{}, nil).Times(3)
+		ts.mockRepo.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), testWalletName01, gomock.Any()).Return(nil, nil).Times(2)
+		ts.mockRepo.EXPECT().ScanOperations(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+		st, err := ts.svc.GetStatement(context.Background(), testWalletName01, period)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), st.EndDate, time.Minute)
+		assert.Equal(t, []dto.StatementEntry{}, st.Entries)
+	})
+
+	t.Run("unknown wallet", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().GetStatement(gomock.Any(), testWalletName01, "2026-09").Return(nil, nil)
+		ts.mockRepo.EXPECT().GetWallet(gomock.Any(), testWalletName01).Return(nil, nil)
+
+		_, err := ts.svc.GetStatement(context.Background(), testWalletName01, "2026-09")
+		assert.Equal(t, ErrWalletNotFound, err)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{ID: "reader", Scopes: []string{auth.ScopeRead},
+			Wallets: []string{testWalletName02}})
+		for _, tt := range []struct {
+			wallet, period string
+			want           error
+		}{
+			{"", "2026-09", ErrEmptyWalletName},
+			{testWalletName01, "2026-13", ErrWrongStatementPeriod},
+			{testWalletName01, "2026-09-01", ErrWrongStatementPeriod},
+			{testWalletName01, "2999-01", ErrFutureStatementPeriod},
+			{testWalletName01, "2026-09", ErrPermissionDenied},
+		} {
+			_, err := ts.svc.GetStatement(ctx, tt.wallet, tt.period)
+			assert.Equal(t, tt.want, err, tt.wallet+" "+tt.period)
+		}
+	})
+}
+
+func TestService_GenerateStatements(t *testing.T) {
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 9, 30, 23, 59, 59, 999999000, time.UTC)
+
+	t.Run("generate", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ts.mockRepo.EXPECT().ListWallets(gomock.Any(), dto.WalletsFilter{Limit: consts.WalletsLimitDefault}).
+			Return([]dto.Wallet{{Name: testWalletName01}, {Name: testWalletName02}}, nil)
+		ts.mockRepo.EXPECT().GetStatement(gomock.Any(), testWalletName01, "2026-09").Return(nil, nil)
+		ts.mockRepo.EXPECT().GetStatement(gomock.Any(), testWalletName02, "2026-09").Return(&dto.Statement{}, nil)
+		ts.expectStatementOperations(start, end)
+		ts.mockRepo.EXPECT().InsertStatement(gomock.Any(), gomock.Any()).Do(func(_ context.Context, st dto.Statement) {
+			assert.Equal(t, testWalletName01, st.Wallet)
+			assert.Equal(t, "2026-09", st.Period)
+			assert.Len(t, st.Entries, 3)
+		})
+
+		n, err := ts.svc.GenerateStatements(context.Background(), "2026-09")
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("open period", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		_, err := ts.svc.GenerateStatements(context.Background(), time.Now().UTC().Format(dto.StatementPeriodLayout))
+		assert.Equal(t, ErrOpenStatementPeriod, err)
+	})
+
+	t.Run("not admin", func(t *testing.T) {
+		ts := newTestService(t)
+		defer ts.Finish()
+
+		ctx := auth.NewContext(context.Background(), &auth.Caller{ID: "reader", Scopes: []string{auth.ScopeRead}})
+		_, err := ts.svc.GenerateStatements(ctx, "2026-09")
+		assert.Equal(t, ErrPermissionDenied, err)
+	})
+}
@@ -0,0 +1,53 @@
+package statements
+
+import (
+	"html/template"
+	"io"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/export"
+)
+
+var htmlTemplate = template.Must(template.New("statement").Funcs(template.FuncMap{
+	"amount": export.FormatAmount,
+	"time":   export.FormatTime,
+	"signed": signedAmount,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Statement of {{.Wallet}} for {{.Period}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+.amount { text-align: right; font-variant-numeric: tabular-nums; }
+</style>
+</head>
+<body>
+<h1>Statement of {{.Wallet}} for {{.Period}}</h1>
+<table>
+<tr><th>Period</th><td>{{time .StartDate}} &ndash; {{time .EndDate}}</td></tr>
+<tr><th>Currency</th><td>{{.Currency}}</td></tr>
+<tr><th>Opening balance</th><td class="amount">{{amount .OpeningBalance}}</td></tr>
+<tr><th>Deposits ({{.Deposits.Count}})</th><td class="amount">{{amount .Deposits.Amount}}</td></tr>
+<tr><th>Withdrawals ({{.Withdrawals.Count}})</th><td class="amount">{{amount .Withdrawals.Amount}}</td></tr>
+<tr><th>Closing balance</th><td class="amount">{{amount .ClosingBalance}}</td></tr>
+</table>
+<h2>Operations</h2>
+<table>
+<tr><th>Timestamp</th><th>Type</th><th>Other wallet</th><th class="amount">Amount</th><th class="amount">Balance</th></tr>
+{{- range .Entries}}
+<tr><td>{{time .Timestamp}}</td><td>{{.Type}}</td><td>{{.OtherWallet}}</td><td class="amount">{{amount (signed .Operation)}}</td><td class="amount">{{amount .Balance}}</td></tr>
+{{- else}}
+<tr><td colspan="5">No operations</td></tr>
+{{- end}}
+</table>
+<p>Generated at {{time .GeneratedAt}}</p>
+</body>
+</html>
+`))
+
+func writeHTML(w io.Writer, st *dto.Statement) error {
+	return htmlTemplate.Execute(w, st)
+}
@@ -0,0 +1,84 @@
+// Package statements renders account statements of wallets in file formats.
+package statements
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+
+	"github.com/ezhdanovskiy/wallets/internal/consts"
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+	"github.com/ezhdanovskiy/wallets/internal/export"
+)
+
+// Format describes a statement format.
+type Format struct {
+	// Name selects the format by the format parameter, e.g. csv.
+	Name string
+	// ContentType is the Content-Type of the response.
+	ContentType string
+	// Extension is the file name extension without the dot.
+	Extension string
+	// Write renders the statement to w.
+	Write func(w io.Writer, st *dto.Statement) error
+}
+
+var (
+	// CSV writes the opening balance, a row per operation and the closing balance. Withdrawals are negative,
+	// the balance column is the balance after the row.
+	CSV = Format{Name: "csv", ContentType: "text/csv; charset=utf-8", Extension: "csv", Write: writeCSV}
+	// JSON writes dto.Statement as is.
+	JSON = Format{Name: "json", ContentType: "application/json; charset=utf-8", Extension: "json", Write: writeJSON}
+	// HTML writes a printable page, browsers save it as PDF.
+	HTML = Format{Name: "html", ContentType: "text/html; charset=utf-8", Extension: "html", Write: writeHTML}
+)
+
+// formats are the supported formats, the first one is the default.
+var formats = []Format{CSV, JSON, HTML}
+
+// Lookup returns the format by name.
+func Lookup(name string) (Format, bool) {
+	for _, f := range formats {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// Names returns the names of the supported formats.
+func Names() []string {
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// signedAmount returns the amount of the operation, negative for withdrawals.
+func signedAmount(op dto.Operation) dto.Amount {
+	if op.Type == consts.OperationTypeWithdrawal {
+		return -op.Amount
+	}
+	return op.Amount
+}
+
+func writeCSV(w io.Writer, st *dto.Statement) error {
+	records := make([][]string, 0, len(st.Entries)+3)
+	records = append(records,
+		[]string{"timestamp", "type", "other_wallet", "amount", "balance"},
+		[]string{export.FormatTime(st.StartDate), "opening_balance", "", "", export.FormatAmount(st.OpeningBalance)})
+	for _, e := range st.Entries {
+		records = append(records, []string{export.FormatTime(e.Timestamp), e.Type, e.OtherWallet,
+			export.FormatAmount(signedAmount(e.Operation)), export.FormatAmount(e.Balance)})
+	}
+	records = append(records,
+		[]string{export.FormatTime(st.EndDate), "closing_balance", "", "", export.FormatAmount(st.ClosingBalance)})
+	return csv.NewWriter(w).WriteAll(records)
+}
+
+func writeJSON(w io.Writer, st *dto.Statement) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(st)
+}
@@ -0,0 +1,76 @@
+package statements
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ezhdanovskiy/wallets/internal/dto"
+)
+
+var testStatement = &dto.Statement{
+	Period: "2026-09",
+	OperationsSummary: dto.OperationsSummary{
+		Wallet:         "wallet1",
+		Currency:       "USD",
+		StartDate:      time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:        time.Date(2026, 9, 30, 23, 59, 59, 999999000, time.UTC),
+		OpeningBalance: 10,
+		ClosingBalance: 8.29,
+		OperationsTotals: dto.OperationsTotals{
+			Deposits:    dto.OperationsTotal{Count: 1, Amount: 0.29},
+			Withdrawals: dto.OperationsTotal{Count: 1, Amount: 2},
+		},
+	},
+	Entries: []dto.StatementEntry{
+		{Operation: dto.Operation{Wallet: "wallet1", Amount: 0.29, Type: "deposit",
+			Timestamp: time.Date(2026, 9, 1, 12, 30, 0, 0, time.UTC)}, Balance: 10.29},
+		{Operation: dto.Operation{Wallet: "wallet1", Amount: 2, Type: "withdrawal", OtherWallet: "<shop>",
+			Timestamp: time.Date(2026, 9, 2, 5, 0, 0, 0, time.UTC)}, Balance: 8.29},
+	},
+	GeneratedAt: time.Date(2026, 10, 1, 3, 0, 0, 0, time.UTC),
+}
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, CSV.Write(&buf, testStatement))
+	assert.Equal(t, "timestamp,type,other_wallet,amount,balance\n"+
+		"2026-09-01T00:00:00Z,opening_balance,,,10.00\n"+
+		"2026-09-01T12:30:00Z,deposit,,0.29,10.29\n"+
+		"2026-09-02T05:00:00Z,withdrawal,<shop>,-2.00,8.29\n"+
+		"2026-09-30T23:59:59.999999Z,closing_balance,,,8.29\n", buf.String())
+}
+
+func TestJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, JSON.Write(&buf, testStatement))
+
+	var got dto.Statement
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, *testStatement, got)
+	assert.Contains(t, buf.String(), `"opening_balance": 10,`)
+}
+
+func TestHTML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, HTML.Write(&buf, testStatement))
+	page := buf.String()
+	assert.Contains(t, page, "<title>Statement of wallet1 for 2026-09</title>")
+	assert.Contains(t, page, `<td class="amount">10.00</td>`)
+	assert.Contains(t, page, `<td>withdrawal</td><td>&lt;shop&gt;</td><td class="amount">-2.00</td><td class="amount">8.29</td>`)
+	assert.NotContains(t, page, "No operations")
+}
+
+func TestLookup(t *testing.T) {
+	f, ok := Lookup("html")
+	assert.True(t, ok)
+	assert.Equal(t, "html", f.Extension)
+
+	_, ok = Lookup("pdf")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"csv", "json", "html"}, Names())
+}
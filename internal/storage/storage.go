@@ -0,0 +1,6 @@
+// Package storage contains the types shared by the storage backends of the repository.
+package storage
+
+// Tx is a transaction started by RunWithTransaction of a repository.
+// It is opaque for callers, a backend accepts only the transactions it has started.
+type Tx interface{}
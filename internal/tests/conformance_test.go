@@ -0,0 +1,16 @@
+// +build integration
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/ezhdanovskiy/wallets/internal/repository/conformance"
+)
+
+func TestRepo_Conformance(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	conformance.Run(t, ts.repo)
+}
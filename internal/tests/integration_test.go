@@ -4,8 +4,10 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -17,11 +19,14 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/ezhdanovskiy/wallets/internal/config"
 	"github.com/ezhdanovskiy/wallets/internal/consts"
 	"github.com/ezhdanovskiy/wallets/internal/dto"
 	httpsrv "github.com/ezhdanovskiy/wallets/internal/http"
+	"github.com/ezhdanovskiy/wallets/internal/ledger"
 	"github.com/ezhdanovskiy/wallets/internal/repository"
 	"github.com/ezhdanovskiy/wallets/internal/service"
+	"github.com/ezhdanovskiy/wallets/internal/storage"
 )
 
 const logsEnabled = false
@@ -36,7 +41,7 @@ func TestCreateWallet(t *testing.T) {
 	assert.Equal(t, http.StatusOK, code)
 	assert.Empty(t, body)
 
-	wallet, err := ts.repo.GetWallet(testWalletName)
+	wallet, err := ts.repo.GetWallet(context.Background(), testWalletName)
 	require.NoError(t, err)
 	require.NotNil(t, wallet)
 	assert.EqualValues(t, 0, wallet.Balance)
@@ -64,12 +69,12 @@ func TestDeposit(t *testing.T) {
 	assert.Equal(t, http.StatusOK, code)
 	assert.Empty(t, body)
 
-	wallet, err := ts.repo.GetWallet(testWalletName)
+	wallet, err := ts.repo.GetWallet(context.Background(), testWalletName)
 	require.NoError(t, err)
 	require.NotNil(t, wallet)
 	assert.EqualValues(t, testAmount.GetInt(), wallet.Balance)
 
-	operations, err := ts.repo.GetOperations(dto.OperationsFilter{Wallet: testWalletName})
+	operations, err := ts.repo.GetOperations(context.Background(), dto.OperationsFilter{Wallet: testWalletName})
 	require.NoError(t, err)
 	require.Len(t, operations, 1)
 	assert.Equal(t, testWalletName, operations[0].Wallet)
@@ -91,9 +96,9 @@ func TestTransfer(t *testing.T) {
 	)
 	ts.cleanWallets(testWalletName01, testWalletName02)
 
-	require.NoError(t, ts.repo.CreateWallet(testWalletName01))
-	require.NoError(t, ts.repo.IncreaseWalletBalance(testWalletName01, testAmount.GetInt()))
-	require.NoError(t, ts.repo.CreateWallet(testWalletName02))
+	require.NoError(t, ts.repo.CreateWallet(context.Background(), testWalletName01, ""))
+	require.NoError(t, ts.repo.IncreaseWalletBalance(context.Background(), testWalletName01, testAmount.GetInt()))
+	require.NoError(t, ts.repo.CreateWallet(context.Background(), testWalletName02, ""))
 
 	t.Run("failed to decode body", func(t *testing.T) {
 		code, body := ts.doRequest(http.MethodPost, "/wallets/transfer", "}")
@@ -120,17 +125,17 @@ func TestTransfer(t *testing.T) {
 		assert.Equal(t, http.StatusOK, code)
 		assert.Empty(t, body)
 
-		wallet, err := ts.repo.GetWallet(testWalletName01)
+		wallet, err := ts.repo.GetWallet(context.Background(), testWalletName01)
 		require.NoError(t, err)
 		require.NotNil(t, wallet)
 		assert.EqualValues(t, 0, wallet.Balance)
 
-		wallet, err = ts.repo.GetWallet(testWalletName02)
+		wallet, err = ts.repo.GetWallet(context.Background(), testWalletName02)
 		require.NoError(t, err)
 		require.NotNil(t, wallet)
 		assert.EqualValues(t, testAmount.GetInt(), wallet.Balance)
 
-		operations, err := ts.repo.GetOperations(dto.OperationsFilter{Wallet: testWalletName01})
+		operations, err := ts.repo.GetOperations(context.Background(), dto.OperationsFilter{Wallet: testWalletName01})
 		require.NoError(t, err)
 		require.Len(t, operations, 2)
 		assert.Equal(t, testWalletName01, operations[0].Wallet)
@@ -142,7 +147,7 @@ func TestTransfer(t *testing.T) {
 		assert.Equal(t, testAmount, operations[1].Amount)
 		assert.Equal(t, testWalletName02, operations[1].OtherWallet)
 
-		operations, err = ts.repo.GetOperations(dto.OperationsFilter{Wallet: testWalletName02})
+		operations, err = ts.repo.GetOperations(context.Background(), dto.OperationsFilter{Wallet: testWalletName02})
 		require.NoError(t, err)
 		require.Len(t, operations, 1)
 		assert.Equal(t, testWalletName02, operations[0].Wallet)
@@ -166,10 +171,10 @@ func TestGetOperations(t *testing.T) {
 	)
 	ts.cleanWallets(testWalletName01, testWalletName02)
 
-	require.NoError(t, ts.repo.CreateWallet(testWalletName01))
-	require.NoError(t, ts.repo.IncreaseWalletBalance(testWalletName01, testAmount01.GetInt()))
-	require.NoError(t, ts.repo.CreateWallet(testWalletName02))
-	require.NoError(t, ts.svc.Transfer(dto.Transfer{WalletFrom: testWalletName01, WalletTo: testWalletName02, Amount: testAmount02}))
+	require.NoError(t, ts.repo.CreateWallet(context.Background(), testWalletName01, ""))
+	require.NoError(t, ts.repo.IncreaseWalletBalance(context.Background(), testWalletName01, testAmount01.GetInt()))
+	require.NoError(t, ts.repo.CreateWallet(context.Background(), testWalletName02, ""))
+	require.NoError(t, ts.svc.Transfer(context.Background(), dto.Transfer{WalletFrom: testWalletName01, WalletTo: testWalletName02, Amount: testAmount02}))
 
 	unmarshalOperations := func(body string) []dto.Operation {
 		var resp struct {
@@ -272,6 +277,103 @@ func TestGetOperations(t *testing.T) {
 	ts.cleanWallets(testWalletName01, testWalletName02)
 }
 
+func TestListWallets(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	const (
+		testOwner        = "TestListWalletsOwner"
+		testWalletName01 = "TestListWalletsWalletName01"
+		testWalletName02 = "TestListWalletsWalletName02"
+	)
+	ts.cleanWallets(testWalletName01, testWalletName02)
+	defer ts.cleanWallets(testWalletName01, testWalletName02)
+
+	ctx := context.Background()
+	require.NoError(t, ts.svc.CreateWallet(ctx, dto.CreateWalletRequest{Name: testWalletName02, Owner: testOwner}))
+	require.NoError(t, ts.svc.CreateWallet(ctx, dto.CreateWalletRequest{Name: testWalletName01, Owner: testOwner}))
+
+	wallets, err := ts.svc.ListWallets(ctx, dto.WalletsFilter{Owner: testOwner})
+	require.NoError(t, err)
+	require.Len(t, wallets, 2)
+	assert.Equal(t, testWalletName01, wallets[0].Name)
+	assert.Equal(t, testWalletName02, wallets[1].Name)
+
+	wallets, err = ts.repo.ListWallets(ctx, dto.WalletsFilter{Owner: testOwner, Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, wallets, 1)
+	assert.Equal(t, testWalletName02, wallets[0].Name)
+}
+
+func TestVerifyLedger(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	const (
+		testWalletName01            = "TestVerifyLedgerWalletName01"
+		testWalletName02            = "TestVerifyLedgerWalletName02"
+		testAmount       dto.Amount = 10
+	)
+	ts.cleanWallets(testWalletName01, testWalletName02)
+	defer ts.cleanWallets(testWalletName01, testWalletName02)
+
+	ctx := context.Background()
+	require.NoError(t, ts.svc.CreateWallet(ctx, dto.CreateWalletRequest{Name: testWalletName01}))
+	require.NoError(t, ts.svc.CreateWallet(ctx, dto.CreateWalletRequest{Name: testWalletName02}))
+	require.NoError(t, ts.svc.IncreaseWalletBalance(ctx, dto.Deposit{Wallet: testWalletName01, Amount: testAmount * 3}))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, ts.svc.Transfer(ctx, dto.Transfer{
+			WalletFrom: testWalletName01,
+			WalletTo:   testWalletName02,
+			Amount:     testAmount,
+		}))
+	}
+
+	report, err := ts.svc.VerifyLedger(ctx, nil)
+	require.NoError(t, err)
+	require.True(t, report.OK, "%+v", report.Broken)
+
+	_, err = ts.db.Exec(`UPDATE operations SET amount = amount * 2 WHERE wallet = $1 AND seq = 2`, testWalletName02)
+	require.NoError(t, err)
+
+	report, err = ts.svc.VerifyLedger(ctx, nil)
+	require.NoError(t, err)
+	assert.False(t, report.OK)
+	require.NotNil(t, report.Broken)
+	assert.Equal(t, testWalletName02, report.Broken.Wallet)
+	assert.Equal(t, int64(2), report.Broken.Seq)
+	assert.Equal(t, ledger.ReasonHashMismatch, report.Broken.Reason)
+}
+
+func TestMigrateOnStart(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.NewConfig("")
+	require.NoError(t, err)
+	cfg.DB.MigrationsSource = config.MigrationsSourceEmbed
+	cfg.DB.MigrateOnStart = true
+
+	// Instances starting together wait for each other on the migration lock.
+	errs := make(chan error, 3)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			repo, err := repository.NewRepo(zap.NewNop().Sugar(), cfg.DB)
+			if err == nil {
+				err = repo.Close()
+			}
+			errs <- err
+		}()
+	}
+	for i := 0; i < cap(errs); i++ {
+		require.NoError(t, <-errs)
+	}
+
+	cfg.DB.MigrateOnStart = false
+	repo, err := repository.NewRepo(zap.NewNop().Sugar(), cfg.DB)
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+}
+
 // TestServer ---------------------------------------------------------------------------------------------------------
 type TestServer struct {
 	t      *testing.T
@@ -282,6 +384,102 @@ type TestServer struct {
 	router *chi.Mux
 }
 
+func TestShutdownWaitsForTransfer(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	const (
+		testWalletName01            = "TestShutdownWalletName01"
+		testWalletName02            = "TestShutdownWalletName02"
+		testAmount       dto.Amount = 10
+	)
+	ts.cleanWallets(testWalletName01, testWalletName02)
+
+	ctx := context.Background()
+	require.NoError(t, ts.repo.CreateWallet(ctx, testWalletName01, ""))
+	require.NoError(t, ts.repo.IncreaseWalletBalance(ctx, testWalletName01, testAmount.GetInt()))
+	require.NoError(t, ts.repo.CreateWallet(ctx, testWalletName02, ""))
+
+	srv := httpsrv.NewServer(ts.log, 0, ts.svc, nil, nil, nil)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = srv.Serve(lis) }()
+
+	// Hold the lock of the source wallet, so the transfer is in flight when shutdown starts.
+	lock, err := ts.db.Beginx()
+	require.NoError(t, err)
+	_, err = lock.Exec(`SELECT * FROM wallets WHERE name = $1 FOR UPDATE`, testWalletName01)
+	require.NoError(t, err)
+
+	codes := make(chan int, 1)
+	go func() {
+		body, _ := json.Marshal(dto.Transfer{WalletFrom: testWalletName01, WalletTo: testWalletName02, Amount: testAmount})
+		resp, err := http.Post("http://"+lis.Addr().String()+"/v1/wallets/transfer", "application/json", bytes.NewReader(body))
+		if err != nil {
+			codes <- 0
+			return
+		}
+		_ = resp.Body.Close()
+		codes <- resp.StatusCode
+	}()
+	time.Sleep(200 * time.Millisecond)
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		shutdown <- srv.Shutdown(shutdownCtx)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, lock.Commit())
+
+	assert.Equal(t, http.StatusOK, <-codes)
+	assert.NoError(t, <-shutdown)
+
+	wallet, err := ts.repo.GetWallet(ctx, testWalletName02)
+	require.NoError(t, err)
+	require.NotNil(t, wallet)
+	assert.EqualValues(t, testAmount.GetInt(), wallet.Balance)
+
+	ts.cleanWallets(testWalletName01, testWalletName02)
+}
+
+func TestRunWithTransaction_serializationFailure(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	const testWalletName = "TestSerializationWalletName01"
+	ts.cleanWallets(testWalletName)
+
+	ctx := context.Background()
+	require.NoError(t, ts.repo.CreateWallet(ctx, testWalletName, ""))
+
+	attempts := 0
+	err := ts.repo.RunWithTransaction(ctx, func(ctx context.Context, tx storage.Tx) error {
+		attempts++
+		// The first statement takes the snapshot of the transaction.
+		if _, err := tx.(*sqlx.Tx).ExecContext(ctx, `SELECT balance FROM wallets WHERE name = $1`, testWalletName); err != nil {
+			return err
+		}
+		if attempts == 1 {
+			// A concurrent deposit changes the wallet after the snapshot, so updating it fails with 40001.
+			if err := ts.repo.IncreaseWalletBalance(ctx, testWalletName, 1); err != nil {
+				return err
+			}
+		}
+		return ts.repo.IncreaseWalletBalanceTx(ctx, tx, testWalletName, 5)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+
+	wallet, err := ts.repo.GetWallet(ctx, testWalletName)
+	require.NoError(t, err)
+	require.NotNil(t, wallet)
+	assert.EqualValues(t, 6, wallet.Balance)
+
+	ts.cleanWallets(testWalletName)
+}
+
 func newTestService(t *testing.T) TestServer {
 	t.Parallel()
 
@@ -297,13 +495,18 @@ func newTestService(t *testing.T) TestServer {
 	db, err := sqlx.Connect("postgres", dsn)
 	require.NoError(t, err)
 
-	require.NoError(t, repository.MigrateUp(log, db, "file://../../migrations"))
+	migrateDB, err := sqlx.Connect("postgres", dsn)
+	require.NoError(t, err)
+	migrator, err := repository.NewMigrator(log, migrateDB, config.DB{MigrationsSource: config.MigrationsSourceEmbed})
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(context.Background()))
+	require.NoError(t, migrator.Close())
 
 	repo, err := repository.NewRepoWithDB(log, db)
 	require.NoError(t, err)
 
-	svc := service.NewService(log, repo)
-	srv := httpsrv.NewServer(log, 0, svc)
+	svc := service.NewService(log, repo, nil, "XXX")
+	srv := httpsrv.NewServer(log, 0, svc, nil, nil, nil)
 	router := chi.NewMux()
 	router.Group(srv.GetV1ApiRouters())
 
@@ -312,7 +515,7 @@ func newTestService(t *testing.T) TestServer {
 		log:    log,
 		db:     db,
 		repo:   repo,
-		svc:    service.NewService(log, repo),
+		svc:    service.NewService(log, repo, nil, "XXX"),
 		router: router,
 	}
 
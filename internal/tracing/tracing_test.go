@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/ezhdanovskiy/wallets/internal/config"
+)
+
+func TestSetup(t *testing.T) {
+	t.Run("none", func(t *testing.T) {
+		shutdown, err := Setup(context.Background(), config.Tracing{Exporter: config.TracingExporterNone})
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("unsupported exporter", func(t *testing.T) {
+		_, err := Setup(context.Background(), config.Tracing{Exporter: "zipkin"})
+		assert.EqualError(t, err, `unsupported tracing exporter "zipkin"`)
+	})
+}
+
+func TestStartEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	tracer := provider.Tracer("test")
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	_, span := Start(ctx, "child")
+	End(span, errors.New("not enough money"))
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	child := spans[0]
+	assert.Equal(t, "child", child.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), child.Parent().SpanID(), "child of the span from ctx")
+	assert.Equal(t, codes.Error, child.Status().Code)
+	assert.Equal(t, "not enough money", child.Status().Description)
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
+
+func TestLogger(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	log := zap.New(core).Sugar()
+
+	Logger(context.Background(), log).Info("without span")
+
+	tracer := sdktrace.NewTracerProvider().Tracer("test")
+	ctx, span := tracer.Start(context.Background(), "span")
+	defer span.End()
+	Logger(ctx, log).Info("with span")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+	assert.Empty(t, entries[0].Context)
+	assert.Equal(t, map[string]interface{}{
+		"trace_id": span.SpanContext().TraceID().String(),
+		"span_id":  span.SpanContext().SpanID().String(),
+	}, entries[1].ContextMap())
+}
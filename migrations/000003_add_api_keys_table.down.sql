@@ -0,0 +1,2 @@
+ALTER TABLE "wallets" DROP COLUMN IF EXISTS "owner";
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,13 @@
+CREATE TABLE "api_keys"
+(
+    "id"         bigserial PRIMARY KEY,
+    "name"       varchar     NOT NULL UNIQUE,
+    "key_hash"   varchar     NOT NULL UNIQUE,
+    "scopes"     varchar[]   NOT NULL DEFAULT '{}',
+    "wallets"    varchar[]   NOT NULL DEFAULT '{}',
+    "owners"     varchar[]   NOT NULL DEFAULT '{}',
+    "created_at" timestamptz NOT NULL DEFAULT now(),
+    "revoked_at" timestamptz
+);
+
+ALTER TABLE "wallets" ADD COLUMN "owner" varchar NOT NULL DEFAULT '';
@@ -0,0 +1,36 @@
+CREATE TABLE "audit_log"
+(
+    "id"           bigserial PRIMARY KEY,
+    "action"       varchar     NOT NULL,
+    "actor"        varchar     NOT NULL DEFAULT '',
+    "request_id"   varchar     NOT NULL DEFAULT '',
+    "source_ip"    varchar     NOT NULL DEFAULT '',
+    "endpoint"     varchar     NOT NULL DEFAULT '',
+    "payload_hash" varchar     NOT NULL,
+    "result"       varchar     NOT NULL,
+    "error"        varchar     NOT NULL DEFAULT '',
+    "created_at"   timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX ON "audit_log" ("created_at");
+CREATE INDEX ON "audit_log" ("actor", "created_at");
+
+-- The audit log is append-only, rows can't be changed or removed even by the application user.
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update_delete
+    BEFORE UPDATE OR DELETE
+    ON "audit_log"
+    FOR EACH ROW
+EXECUTE FUNCTION audit_log_append_only();
+
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE
+    ON "audit_log"
+    FOR EACH STATEMENT
+EXECUTE FUNCTION audit_log_append_only();
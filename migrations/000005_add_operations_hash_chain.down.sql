@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "ledger_checkpoints";
+
+ALTER TABLE "operations"
+    DROP COLUMN IF EXISTS "seq",
+    DROP COLUMN IF EXISTS "prev_hash",
+    DROP COLUMN IF EXISTS "hash";
@@ -0,0 +1,61 @@
+ALTER TABLE "operations"
+    ADD COLUMN "seq"       bigint,
+    ADD COLUMN "prev_hash" varchar NOT NULL DEFAULT '',
+    ADD COLUMN "hash"      varchar NOT NULL DEFAULT '';
+
+-- Must produce the same value as ledger.Hash in Go.
+CREATE FUNCTION pg_temp.operation_hash(wallet varchar, seq bigint, type text, amount bigint, other_wallet varchar,
+                                       created_at timestamptz, prev_hash varchar) RETURNS varchar AS
+$$
+SELECT encode(sha256(convert_to(
+    octet_length(wallet) || ':' || wallet || ',' ||
+    octet_length(seq::text) || ':' || seq::text || ',' ||
+    octet_length(type) || ':' || type || ',' ||
+    octet_length(amount::text) || ':' || amount::text || ',' ||
+    octet_length(other_wallet) || ':' || other_wallet || ',' ||
+    octet_length(ts) || ':' || ts || ',' ||
+    octet_length(prev_hash) || ':' || prev_hash || ',',
+    'UTF8')), 'hex')
+FROM to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"') AS ts
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Chain the operations created before this migration in the order of insertion.
+DO
+$$
+    DECLARE
+        op          record;
+        last_wallet varchar;
+        last_seq    bigint;
+        last_hash   varchar;
+    BEGIN
+        FOR op IN SELECT * FROM "operations" ORDER BY wallet, id
+            LOOP
+                IF last_wallet IS DISTINCT FROM op.wallet THEN
+                    last_wallet := op.wallet;
+                    last_seq := 0;
+                    last_hash := '';
+                END IF;
+                last_seq := last_seq + 1;
+
+                UPDATE "operations"
+                SET seq       = last_seq,
+                    prev_hash = last_hash,
+                    hash      = pg_temp.operation_hash(op.wallet, last_seq, op.type::text, op.amount,
+                                                       op.other_wallet, op.created_at, last_hash)
+                WHERE id = op.id
+                RETURNING hash INTO last_hash;
+            END LOOP;
+    END
+$$;
+
+ALTER TABLE "operations"
+    ALTER COLUMN "seq" SET NOT NULL;
+
+CREATE UNIQUE INDEX ON "operations" ("wallet", "seq");
+
+CREATE TABLE "ledger_checkpoints"
+(
+    "id"         bigserial PRIMARY KEY,
+    "checkpoint" jsonb       NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT now()
+);
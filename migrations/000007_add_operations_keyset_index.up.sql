@@ -0,0 +1,2 @@
+-- Matches the keyset pagination of operations history by (created_at, id) within a wallet.
+CREATE INDEX "operations_wallet_created_at_id_idx" ON "operations" ("wallet", "created_at", "id");
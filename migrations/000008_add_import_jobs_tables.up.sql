@@ -0,0 +1,36 @@
+CREATE TABLE "import_jobs"
+(
+    "id"           bigserial PRIMARY KEY,
+    "kind"         varchar     NOT NULL,
+    "status"       varchar     NOT NULL,
+    "file_hash"    varchar     NOT NULL,
+    "chunk_size"   integer     NOT NULL,
+    "total_rows"   integer     NOT NULL,
+    "applied_rows" integer     NOT NULL DEFAULT 0,
+    "skipped_rows" integer     NOT NULL DEFAULT 0,
+    "failed_rows"  integer     NOT NULL DEFAULT 0,
+    "created_by"   varchar     NOT NULL DEFAULT '',
+    "request_id"   varchar     NOT NULL DEFAULT '',
+    "created_at"   timestamptz NOT NULL DEFAULT now(),
+    "updated_at"   timestamptz NOT NULL DEFAULT now(),
+    "finished_at"  timestamptz
+);
+
+CREATE INDEX ON "import_jobs" ("status", "id");
+
+-- The wallet is credited by a deposit and debited by a transfer, wallet_to is set only for transfers.
+CREATE TABLE "import_rows"
+(
+    "job_id"    bigint  NOT NULL REFERENCES "import_jobs" ("id"),
+    "line"      integer NOT NULL,
+    "key"       varchar NOT NULL,
+    "wallet"    varchar NOT NULL,
+    "wallet_to" varchar NOT NULL DEFAULT '',
+    "amount"    bigint  NOT NULL,
+    "status"    varchar NOT NULL,
+    "error"     varchar NOT NULL DEFAULT '',
+    PRIMARY KEY ("job_id", "line")
+);
+
+-- An idempotency key is applied at most once by all imports, rows with an applied key are skipped.
+CREATE UNIQUE INDEX "import_rows_applied_key_idx" ON "import_rows" ("key") WHERE "status" = 'applied';
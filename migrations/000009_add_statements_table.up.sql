@@ -0,0 +1,9 @@
+-- Operations of a closed month don't change, so its statement is generated once and served as is.
+CREATE TABLE "statements"
+(
+    "wallet"     varchar     NOT NULL,
+    "period"     varchar     NOT NULL,
+    "statement"  jsonb       NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY ("wallet", "period")
+);
@@ -0,0 +1,9 @@
+-- Balances at the ends of UTC days with operations, the balance at any time is the latest snapshot before it
+-- plus the later operations.
+CREATE TABLE "balance_snapshots"
+(
+    "wallet"   varchar     NOT NULL,
+    "taken_at" timestamptz NOT NULL,
+    "balance"  bigint      NOT NULL,
+    PRIMARY KEY ("wallet", "taken_at")
+);
@@ -0,0 +1,15 @@
+// Package migrations embeds the migrations of the database schema into the binary.
+package migrations
+
+import "embed"
+
+// FS contains the migrations, they are used instead of MIGRATIONS_PATH when MIGRATIONS_SOURCE is embed.
+//
+//go:embed *.sql
+var FS embed.FS
+
+// SQLite contains the migrations of the SQLite storage in the sqlite directory,
+// they are adapted from the Postgres ones and always embedded.
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
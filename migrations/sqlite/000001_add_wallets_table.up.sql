@@ -0,0 +1,8 @@
+-- Timestamps are unix microseconds, the precision of timestamptz in Postgres.
+CREATE TABLE "wallets"
+(
+    "name"       TEXT    PRIMARY KEY,
+    "balance"    INTEGER NOT NULL DEFAULT 0,
+    "created_at" INTEGER NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER)),
+    "updated_at" INTEGER NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER))
+);
@@ -0,0 +1,12 @@
+-- SQLite has no enums, the check constraint replaces the operation_type of Postgres.
+CREATE TABLE "operations"
+(
+    "id"           INTEGER PRIMARY KEY AUTOINCREMENT,
+    "wallet"       TEXT    NOT NULL DEFAULT 'system',
+    "type"         TEXT    NOT NULL CHECK ("type" IN ('deposit', 'withdrawal')),
+    "amount"       INTEGER NOT NULL DEFAULT 0,
+    "other_wallet" TEXT    NOT NULL DEFAULT 'system',
+    "created_at"   INTEGER NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER))
+);
+
+CREATE INDEX "operations_wallet_type_created_at_idx" ON "operations" ("wallet", "type", "created_at");
@@ -0,0 +1,2 @@
+ALTER TABLE "wallets" DROP COLUMN "owner";
+DROP TABLE IF EXISTS api_keys;
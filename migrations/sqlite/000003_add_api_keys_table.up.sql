@@ -0,0 +1,14 @@
+-- Lists of scopes, wallets and owners are JSON arrays.
+CREATE TABLE "api_keys"
+(
+    "id"         INTEGER PRIMARY KEY AUTOINCREMENT,
+    "name"       TEXT    NOT NULL UNIQUE,
+    "key_hash"   TEXT    NOT NULL UNIQUE,
+    "scopes"     TEXT    NOT NULL DEFAULT '[]',
+    "wallets"    TEXT    NOT NULL DEFAULT '[]',
+    "owners"     TEXT    NOT NULL DEFAULT '[]',
+    "created_at" INTEGER NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER)),
+    "revoked_at" INTEGER
+);
+
+ALTER TABLE "wallets" ADD COLUMN "owner" TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,31 @@
+CREATE TABLE "audit_log"
+(
+    "id"           INTEGER PRIMARY KEY AUTOINCREMENT,
+    "action"       TEXT    NOT NULL,
+    "actor"        TEXT    NOT NULL DEFAULT '',
+    "request_id"   TEXT    NOT NULL DEFAULT '',
+    "source_ip"    TEXT    NOT NULL DEFAULT '',
+    "endpoint"     TEXT    NOT NULL DEFAULT '',
+    "payload_hash" TEXT    NOT NULL,
+    "result"       TEXT    NOT NULL,
+    "error"        TEXT    NOT NULL DEFAULT '',
+    "created_at"   INTEGER NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER))
+);
+
+CREATE INDEX "audit_log_created_at_idx" ON "audit_log" ("created_at");
+CREATE INDEX "audit_log_actor_created_at_idx" ON "audit_log" ("actor", "created_at");
+
+-- The audit log is append-only, rows can't be changed or removed.
+CREATE TRIGGER "audit_log_no_update"
+    BEFORE UPDATE
+    ON "audit_log"
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER "audit_log_no_delete"
+    BEFORE DELETE
+    ON "audit_log"
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "ledger_checkpoints";
+
+DROP INDEX IF EXISTS "operations_wallet_seq_idx";
+ALTER TABLE "operations" DROP COLUMN "seq";
+ALTER TABLE "operations" DROP COLUMN "prev_hash";
+ALTER TABLE "operations" DROP COLUMN "hash";
@@ -0,0 +1,13 @@
+-- Unlike Postgres there is nothing to backfill, SQLite databases never had operations without the chain.
+ALTER TABLE "operations" ADD COLUMN "seq" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "operations" ADD COLUMN "prev_hash" TEXT NOT NULL DEFAULT '';
+ALTER TABLE "operations" ADD COLUMN "hash" TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX "operations_wallet_seq_idx" ON "operations" ("wallet", "seq");
+
+CREATE TABLE "ledger_checkpoints"
+(
+    "id"         INTEGER PRIMARY KEY AUTOINCREMENT,
+    "checkpoint" TEXT    NOT NULL CHECK (json_valid("checkpoint")),
+    "created_at" INTEGER NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER))
+);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "operations_wallet_created_at_id_idx";
@@ -0,0 +1,3 @@
+-- Matches the keyset pagination of operations history by (created_at, id) within a wallet.
+-- The version follows the Postgres migration, SQLite has no rate limit buckets of version 6.
+CREATE INDEX "operations_wallet_created_at_id_idx" ON "operations" ("wallet", "created_at", "id");
@@ -0,0 +1,36 @@
+CREATE TABLE "import_jobs"
+(
+    "id"           INTEGER PRIMARY KEY AUTOINCREMENT,
+    "kind"         TEXT    NOT NULL,
+    "status"       TEXT    NOT NULL,
+    "file_hash"    TEXT    NOT NULL,
+    "chunk_size"   INTEGER NOT NULL,
+    "total_rows"   INTEGER NOT NULL,
+    "applied_rows" INTEGER NOT NULL DEFAULT 0,
+    "skipped_rows" INTEGER NOT NULL DEFAULT 0,
+    "failed_rows"  INTEGER NOT NULL DEFAULT 0,
+    "created_by"   TEXT    NOT NULL DEFAULT '',
+    "request_id"   TEXT    NOT NULL DEFAULT '',
+    "created_at"   INTEGER NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER)),
+    "updated_at"   INTEGER NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER)),
+    "finished_at"  INTEGER
+);
+
+CREATE INDEX "import_jobs_status_id_idx" ON "import_jobs" ("status", "id");
+
+-- The wallet is credited by a deposit and debited by a transfer, wallet_to is set only for transfers.
+CREATE TABLE "import_rows"
+(
+    "job_id"    INTEGER NOT NULL REFERENCES "import_jobs" ("id"),
+    "line"      INTEGER NOT NULL,
+    "key"       TEXT    NOT NULL,
+    "wallet"    TEXT    NOT NULL,
+    "wallet_to" TEXT    NOT NULL DEFAULT '',
+    "amount"    INTEGER NOT NULL,
+    "status"    TEXT    NOT NULL,
+    "error"     TEXT    NOT NULL DEFAULT '',
+    PRIMARY KEY ("job_id", "line")
+);
+
+-- An idempotency key is applied at most once by all imports, rows with an applied key are skipped.
+CREATE UNIQUE INDEX "import_rows_applied_key_idx" ON "import_rows" ("key") WHERE "status" = 'applied';
@@ -0,0 +1,9 @@
+-- Operations of a closed month don't change, so its statement is generated once and served as is.
+CREATE TABLE "statements"
+(
+    "wallet"     TEXT    NOT NULL,
+    "period"     TEXT    NOT NULL,
+    "statement"  TEXT    NOT NULL CHECK (json_valid("statement")),
+    "created_at" INTEGER NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER)),
+    PRIMARY KEY ("wallet", "period")
+);
@@ -0,0 +1,9 @@
+-- Balances at the ends of UTC days with operations, the balance at any time is the latest snapshot before it
+-- plus the later operations.
+CREATE TABLE "balance_snapshots"
+(
+    "wallet"   TEXT    NOT NULL,
+    "taken_at" INTEGER NOT NULL,
+    "balance"  INTEGER NOT NULL,
+    PRIMARY KEY ("wallet", "taken_at")
+);